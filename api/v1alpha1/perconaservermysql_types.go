@@ -23,6 +23,7 @@ import (
 	"path"
 	"regexp"
 	"strings"
+	"time"
 
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	"github.com/pkg/errors"
@@ -31,6 +32,7 @@ import (
 	"golang.org/x/text/language"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -62,6 +64,9 @@ type PerconaServerMySQLSpec struct {
 	Toolkit           *ToolkitSpec                         `json:"toolkit,omitempty"`
 	UpgradeOptions    UpgradeOptions                       `json:"upgradeOptions,omitempty"`
 	UpdateStrategy    appsv1.StatefulSetUpdateStrategyType `json:"updateStrategy,omitempty"`
+	// ReplicationChannels make the cluster a standby replicating from external sources.
+	// The cluster is read-only until all its channels are promoted.
+	ReplicationChannels []ReplicationChannel `json:"replicationChannels,omitempty"`
 }
 
 type UnsafeFlags struct {
@@ -84,6 +89,60 @@ type TLSSpec struct {
 	IssuerConf *cmmeta.ObjectReference `json:"issuerConf,omitempty"`
 }
 
+// ReplicationChannel is an asynchronous replication channel the primary of the cluster
+// replicates from an external source with. The source has to have the same users as the cluster,
+// since they are replicated as well, so both clusters should use the same users Secret.
+// The standby should be bootstrapped from a backup of the source with spec.mysql.initFrom.
+// Otherwise the channel fails on the users the source created (error 1396) or on the binary logs
+// the source purged (error 1236). Only group replication clusters support replication channels.
+type ReplicationChannel struct {
+	// +kubebuilder:validation:Pattern=`^[a-z0-9_]+$`
+	// +kubebuilder:validation:MaxLength=64
+	Name string `json:"name"`
+	// Sources the channel fails over between. The available source with the highest weight is used.
+	// +kubebuilder:validation:MinItems=1
+	Sources []ReplicationSource `json:"sources"`
+	// CredentialsSecret is the name of the Secret with the username and password keys
+	// the channel connects to the sources with. The replication user of the cluster is used if it's empty.
+	CredentialsSecret string                    `json:"credentialsSecret,omitempty"`
+	TLS               *ReplicationChannelTLS    `json:"tls,omitempty"`
+	Config            *ReplicationChannelConfig `json:"config,omitempty"`
+	// Promote detaches the channel from its sources. The cluster is made writable
+	// once all its channels are promoted.
+	Promote bool `json:"promote,omitempty"`
+}
+
+type ReplicationSource struct {
+	Host string `json:"host"`
+	// +kubebuilder:validation:Minimum=1
+	Port int32 `json:"port,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight,omitempty"`
+}
+
+type ReplicationChannelTLS struct {
+	// Enabled requires encrypted connections to the sources. Defaults to true.
+	Enabled *bool `json:"enabled,omitempty"`
+	// VerifyServerCert checks the certificates of the sources against the CA of the cluster,
+	// so the clusters should share it, e.g. by using the same spec.tls.issuerConf.
+	VerifyServerCert bool `json:"verifyServerCert,omitempty"`
+}
+
+type ReplicationChannelConfig struct {
+	// SourceRetryCount is the number of reconnection attempts to a source before the channel fails over.
+	// +kubebuilder:validation:Minimum=1
+	SourceRetryCount int32 `json:"sourceRetryCount,omitempty"`
+	// SourceConnectRetry is the interval in seconds between reconnection attempts.
+	// +kubebuilder:validation:Minimum=1
+	SourceConnectRetry int32 `json:"sourceConnectRetry,omitempty"`
+}
+
+// TLSEnabled checks if the channel requires encrypted connections.
+func (c *ReplicationChannel) TLSEnabled() bool {
+	return c.TLS == nil || c.TLS.Enabled == nil || *c.TLS.Enabled
+}
+
 type ClusterType string
 
 const (
@@ -105,18 +164,71 @@ func (t ClusterType) isValid() bool {
 	return false
 }
 
+type ErrantTransactionsRepair string
+
+const (
+	// ErrantTransactionsInjectEmpty commits empty transactions with the errant GTIDs on the primary.
+	ErrantTransactionsInjectEmpty ErrantTransactionsRepair = "InjectEmpty"
+	// ErrantTransactionsReclone clones the replica again from a donor without errant transactions.
+	ErrantTransactionsReclone ErrantTransactionsRepair = "Reclone"
+)
+
 type MySQLSpec struct {
 	ClusterType  ClusterType            `json:"clusterType,omitempty"`
 	Expose       ServiceExposeTogglable `json:"expose,omitempty"`
 	AutoRecovery bool                   `json:"autoRecovery,omitempty"`
 
+	// ErrantTransactionsRepair is the way asynchronous replicas with transactions
+	// the primary doesn't have are repaired. They are only reported if it's empty.
+	// +kubebuilder:validation:Enum=InjectEmpty;Reclone
+	ErrantTransactionsRepair ErrantTransactionsRepair `json:"errantTransactionsRepair,omitempty"`
+
 	Sidecars       []corev1.Container `json:"sidecars,omitempty"`
 	SidecarVolumes []corev1.Volume    `json:"sidecarVolumes,omitempty"`
 	SidecarPVCs    []SidecarPVC       `json:"sidecarPVCs,omitempty"`
 
+	// AutoRebuild re-clones asynchronous replicas stopped by a replication error
+	// that can't be recovered without fixing their data, e.g. a duplicate key.
+	AutoRebuild AutoRebuildSpec `json:"autoRebuild,omitempty"`
+
+	// InitFrom restores data of the first pod from a backup before the cluster is started.
+	// Other members are cloned from it. It's ignored once the MySQL statefulset exists.
+	InitFrom *InitFromSpec `json:"initFrom,omitempty"`
+
 	PodSpec `json:",inline"`
 }
 
+type AutoRebuildSpec struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// ErrorTimeout is the time a replica has to be in the replication error before it's rebuilt. Defaults to 10m.
+	ErrorTimeout *metav1.Duration `json:"errorTimeout,omitempty"`
+}
+
+const defaultAutoRebuildErrorTimeout = 10 * time.Minute
+
+// ErrorTimeoutDuration returns spec.mysql.autoRebuild.errorTimeout or its default.
+func (s *AutoRebuildSpec) ErrorTimeoutDuration() time.Duration {
+	if s.ErrorTimeout == nil || s.ErrorTimeout.Duration <= 0 {
+		return defaultAutoRebuildErrorTimeout
+	}
+	return s.ErrorTimeout.Duration
+}
+
+// InitFromSpec defines the backup a new cluster is bootstrapped from.
+// Either BackupName or BackupSource should be set. The system users are restored from the backup
+// with the passwords of the source cluster, so the cluster uses the users Secret of the source.
+type InitFromSpec struct {
+	// BackupName is the name of PerconaServerMySQLBackup in the namespace of the cluster.
+	BackupName string `json:"backupName,omitempty"`
+	// BackupSource describes a backup taken by another cluster, e.g. in another namespace.
+	BackupSource *PerconaServerMySQLBackupStatus `json:"backupSource,omitempty"`
+	// EncryptionKeySecret overrides the key secret recorded in the status of an encrypted backup.
+	EncryptionKeySecret *corev1.SecretKeySelector `json:"encryptionKeySecret,omitempty"`
+	// SecretsName is the users Secret of the source cluster. It's copied to spec.secretsName
+	// unless that Secret exists already. Defaults to the users Secret of the cluster of BackupName.
+	SecretsName string `json:"secretsName,omitempty"`
+}
+
 // Checks if the MySQL cluster type is asynchronous.
 func (m MySQLSpec) IsAsync() bool {
 	return m.ClusterType == ClusterTypeAsync
@@ -208,6 +320,210 @@ type BackupSpec struct {
 	Storages                 map[string]*BackupStorageSpec `json:"storages,omitempty"`
 	BackoffLimit             *int32                        `json:"backoffLimit,omitempty"`
 	Schedule                 []BackupSchedule              `json:"schedule,omitempty"`
+	PITR                     PITRSpec                      `json:"pitr,omitempty"`
+	Encryption               *BackupEncryptionSpec         `json:"encryption,omitempty"`
+	SourcePolicy             *BackupSourcePolicy           `json:"sourcePolicy,omitempty"`
+	Throttle                 *BackupThrottleSpec           `json:"throttle,omitempty"`
+	ScheduleGuard            *BackupScheduleGuardSpec      `json:"scheduleGuard,omitempty"`
+	Hooks                    *BackupHooks                  `json:"hooks,omitempty"`
+	Timeouts                 *BackupTimeouts               `json:"timeouts,omitempty"`
+	RetryPolicy              *BackupRetryPolicy            `json:"retryPolicy,omitempty"`
+}
+
+// BackupTimeouts limit the time backups and restores of the cluster can take.
+// Backups and restores exceeding them are failed.
+type BackupTimeouts struct {
+	// StartingDeadlineSeconds is the time since the creation of a backup or a restore
+	// it has to start running in.
+	// +kubebuilder:validation:Minimum=1
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+	// ActiveDeadlineSeconds is the time backup and restore jobs can run for, retries included.
+	// +kubebuilder:validation:Minimum=1
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+}
+
+// BackupRetryPolicy configures how the backup job requests the backup
+// while another backup is running on the source node.
+type BackupRetryPolicy struct {
+	// MaxAttempts is the number of requests each job pod makes before it fails. Unlimited if 0.
+	MaxAttempts int32 `json:"maxAttempts,omitempty"`
+	// InitialInterval is the time between the first two requests, it's doubled after each request. Defaults to 10s.
+	InitialInterval *metav1.Duration `json:"initialInterval,omitempty"`
+	// MaxInterval is the maximum time between requests. Defaults to 10m.
+	MaxInterval *metav1.Duration `json:"maxInterval,omitempty"`
+}
+
+// BackupHooks are run by the operator around each backup of the cluster.
+type BackupHooks struct {
+	// PreBackup hooks run before the backup job is created.
+	PreBackup []Hook `json:"preBackup,omitempty"`
+	// PostBackup hooks run after the backup job finished, whether it succeeded or not.
+	// The backup is completed once they finish. A failed hook doesn't fail a succeeded backup,
+	// the PostBackupHooksFailed condition of the backup is set instead.
+	PostBackup []Hook `json:"postBackup,omitempty"`
+}
+
+type HookStage string
+
+const (
+	HookStagePreBackup   HookStage = "preBackup"
+	HookStagePostBackup  HookStage = "postBackup"
+	HookStagePreRestore  HookStage = "preRestore"
+	HookStagePostRestore HookStage = "postRestore"
+)
+
+type HookFailurePolicy string
+
+const (
+	// HookFailurePolicyFail fails the backup or the restore if the hook fails.
+	HookFailurePolicyFail HookFailurePolicy = "Fail"
+	// HookFailurePolicyIgnore records the failure in the status and continues.
+	HookFailurePolicyIgnore HookFailurePolicy = "Ignore"
+)
+
+// Hook is either a list of SQL statements or a job. Hooks of a stage run one by one in the given order.
+type Hook struct {
+	// Name identifies the hook in the status, it must be unique within the stage.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=40
+	Name string `json:"name"`
+	// SQL statements are executed on the primary by the operator user in a single session.
+	// Table names have to be qualified with the database name.
+	SQL []string `json:"sql,omitempty"`
+	// Job runs a container. MYSQL_PRIMARY_HOST, CLUSTER_NAME and HOOK_STAGE are set in its environment.
+	Job *HookJobSpec `json:"job,omitempty"`
+	// FailurePolicy defaults to Fail.
+	// +kubebuilder:validation:Enum=Fail;Ignore
+	FailurePolicy HookFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+type HookJobSpec struct {
+	Image                 string                        `json:"image"`
+	ImagePullPolicy       corev1.PullPolicy             `json:"imagePullPolicy,omitempty"`
+	ImagePullSecrets      []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	Command               []string                      `json:"command,omitempty"`
+	Args                  []string                      `json:"args,omitempty"`
+	Env                   []corev1.EnvVar               `json:"env,omitempty"`
+	EnvFrom               []corev1.EnvFromSource        `json:"envFrom,omitempty"`
+	Resources             corev1.ResourceRequirements   `json:"resources,omitempty"`
+	ServiceAccountName    string                        `json:"serviceAccountName,omitempty"`
+	BackoffLimit          *int32                        `json:"backoffLimit,omitempty"`
+	ActiveDeadlineSeconds *int64                        `json:"activeDeadlineSeconds,omitempty"`
+}
+
+type HookState string
+
+const (
+	HookPending   HookState = "Pending"
+	HookRunning   HookState = "Running"
+	HookSucceeded HookState = "Succeeded"
+	HookFailed    HookState = "Failed"
+)
+
+type HookStatus struct {
+	Name    string    `json:"name"`
+	Stage   HookStage `json:"stage"`
+	State   HookState `json:"state,omitempty"`
+	Message string    `json:"message,omitempty"`
+}
+
+// BackupThrottleSpec limits the disk and network load backups put on the source node.
+type BackupThrottleSpec struct {
+	// Throttle is passed to xtrabackup --throttle. It's the number of 10MB chunks copied per second.
+	// It isn't supported by logical backups.
+	Throttle int32 `json:"throttle,omitempty"`
+	// Parallel is the number of threads xtrabackup or MySQL Shell copies data with.
+	Parallel int32 `json:"parallel,omitempty"`
+	// UploadBandwidth is the maximum number of bytes per second the backup is uploaded with, e.g. 50Mi.
+	UploadBandwidth *resource.Quantity `json:"uploadBandwidth,omitempty"`
+}
+
+// BackupScheduleGuardSpec defers scheduled backups while the cluster is busy.
+// The backup is skipped if the cluster is still busy after MaxDelay.
+type BackupScheduleGuardSpec struct {
+	// MaxReplicationLag is the lag of the backup source above which the backup is deferred.
+	// It's the number of seconds for asynchronous replication and
+	// the number of transactions in the applier queue for group replication.
+	MaxReplicationLag *int64 `json:"maxReplicationLag,omitempty"`
+	// MaxLoadAverage is the 1 minute load average of the source node's host above which the backup is deferred, e.g. "4.5".
+	MaxLoadAverage *resource.Quantity `json:"maxLoadAverage,omitempty"`
+	// CheckInterval is the time between checks of a deferred backup. Defaults to 5m.
+	CheckInterval *metav1.Duration `json:"checkInterval,omitempty"`
+	// MaxDelay is the time the backup can be deferred for. Defaults to 1h.
+	MaxDelay *metav1.Duration `json:"maxDelay,omitempty"`
+}
+
+type BackupSourcePreference string
+
+const (
+	// BackupSourcePreferFirst takes backups from the first replica reported by the cluster.
+	BackupSourcePreferFirst BackupSourcePreference = "first"
+	// BackupSourcePreferLeastLagged takes backups from the replica which is the least behind the primary.
+	// Lag is read from the pt-heartbeat table for asynchronous replication and
+	// from replication_group_member_stats for group replication.
+	BackupSourcePreferLeastLagged BackupSourcePreference = "leastLagged"
+)
+
+// BackupSourcePolicy configures which MySQL node backups are taken from.
+// Replicas are used by default and the primary is used if there are none.
+// Incremental backups ignore the policy and are taken from the node of their base backup.
+type BackupSourcePolicy struct {
+	// +kubebuilder:validation:Enum=first;leastLagged
+	Prefer BackupSourcePreference `json:"prefer,omitempty"`
+	// PreferredPod is the name of the replica pod used if it's online.
+	PreferredPod string `json:"preferredPod,omitempty"`
+	// PreferredZone limits replicas to the ones running on nodes of the zone if there are any.
+	// The operator needs permissions to get nodes to detect zones.
+	PreferredZone string `json:"preferredZone,omitempty"`
+	// DedicatedPod is the name of the replica all backups are taken from, e.g. a delayed one.
+	// Backups fail if it's not an online replica.
+	DedicatedPod string `json:"dedicatedPod,omitempty"`
+	// NeverPrimary makes backups fail instead of being taken from the primary if there are no replicas.
+	NeverPrimary bool `json:"neverPrimary,omitempty"`
+}
+
+type BackupEncryptionCipher string
+
+const (
+	BackupEncryptionAES128 BackupEncryptionCipher = "AES128"
+	BackupEncryptionAES192 BackupEncryptionCipher = "AES192"
+	BackupEncryptionAES256 BackupEncryptionCipher = "AES256"
+)
+
+// KeyLength returns the length of the key in bytes the cipher requires.
+func (c BackupEncryptionCipher) KeyLength() int {
+	switch c {
+	case BackupEncryptionAES128:
+		return 16
+	case BackupEncryptionAES192:
+		return 24
+	case BackupEncryptionAES256:
+		return 32
+	}
+	return 0
+}
+
+// BackupEncryptionSpec configures client-side encryption of backups taken by xtrabackup.
+type BackupEncryptionSpec struct {
+	// +kubebuilder:validation:Enum=AES128;AES192;AES256
+	Cipher BackupEncryptionCipher `json:"cipher,omitempty"`
+	// KeySecret references the secret key with the encryption key.
+	// Length of the key must match the cipher: 16, 24 or 32 bytes.
+	KeySecret *corev1.SecretKeySelector `json:"keySecret"`
+}
+
+// PITRSpec configures the binlog collector used for point-in-time recovery.
+// Restores that replace the data of the cluster start a new timeline: binary logs written after them
+// are uploaded under a separate prefix, so binary logs of diverged histories are never replayed together.
+type PITRSpec struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// StorageName is the name of the storage in spec.backup.storages binary logs are uploaded to.
+	StorageName string `json:"storageName,omitempty"`
+	// TimeBetweenUploads is the number of seconds the collector waits between two uploads.
+	TimeBetweenUploads int32 `json:"timeBetweenUploads,omitempty"`
+
+	Resources                corev1.ResourceRequirements `json:"resources,omitempty"`
+	ContainerSecurityContext *corev1.SecurityContext     `json:"containerSecurityContext,omitempty"`
 }
 
 type BackupSchedule struct {
@@ -218,6 +534,30 @@ type BackupSchedule struct {
 	Keep     int    `json:"keep,omitempty"`
 	// +kubebuilder:validation:Required
 	StorageName string `json:"storageName,omitempty"`
+	// +kubebuilder:validation:Enum=full;incremental
+	Type BackupType `json:"type,omitempty"`
+	// +kubebuilder:validation:Enum=physical;logical
+	Method    BackupMethod         `json:"method,omitempty"`
+	Verify    *BackupVerifySpec    `json:"verify,omitempty"`
+	Retention *BackupRetentionSpec `json:"retention,omitempty"`
+	// ReplicateTo lists the storages scheduled backups are copied to after they succeed.
+	ReplicateTo []string `json:"replicateTo,omitempty"`
+}
+
+// BackupRetentionSpec extends the count-based pruning of BackupSchedule.Keep.
+// A succeeded backup is deleted if it is outside of Keep or older than MaxAge,
+// unless it is protected by MinCount, KeepDaily, KeepWeekly or KeepMonthly.
+type BackupRetentionSpec struct {
+	// MaxAge is the age after which backups are deleted, e.g. 720h.
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+	// MinCount is the number of the most recent backups which are never deleted.
+	MinCount int `json:"minCount,omitempty"`
+	// KeepDaily keeps the most recent backup of each of the last KeepDaily days with backups.
+	KeepDaily int `json:"keepDaily,omitempty"`
+	// KeepWeekly keeps the most recent backup of each of the last KeepWeekly ISO weeks with backups.
+	KeepWeekly int `json:"keepWeekly,omitempty"`
+	// KeepMonthly keeps the most recent backup of each of the last KeepMonthly months with backups.
+	KeepMonthly int `json:"keepMonthly,omitempty"`
 }
 
 // Retrieves the initialization image for the backup.
@@ -225,6 +565,14 @@ func (s *BackupSpec) GetInitImage() string {
 	return s.InitImage
 }
 
+// StartingDeadline returns the time backups and restores have to start in. It's 0 if the time isn't limited.
+func (s *BackupSpec) StartingDeadline() time.Duration {
+	if s == nil || s.Timeouts == nil || s.Timeouts.StartingDeadlineSeconds == nil {
+		return 0
+	}
+	return time.Duration(*s.Timeouts.StartingDeadlineSeconds) * time.Second
+}
+
 type BackupStorageType string
 
 const (
@@ -253,15 +601,65 @@ type BackupStorageSpec struct {
 	ContainerSecurityContext  *corev1.SecurityContext           `json:"containerSecurityContext,omitempty"`
 	RuntimeClassName          *string                           `json:"runtimeClassName,omitempty"`
 	VerifyTLS                 *bool                             `json:"verifyTLS,omitempty"`
+	GarbageCollection         *BackupStorageGCSpec              `json:"garbageCollection,omitempty"`
+}
+
+// BackupStorageGCSpec configures deletion of the cluster's backups which are found on the storage
+// but have no PerconaServerMySQLBackup object. Filesystem storages are not supported.
+// Backups are collected only after they are synced from the storage with the sync-backups annotation,
+// so that backups of a recreated cluster aren't deleted before they are imported.
+// Clusters with the same name in different namespaces can share the storage: only backups whose
+// metadata.json has the namespace of the cluster are deleted. Logical backups and backups taken
+// by older versions of the operator don't have it and are kept.
+type BackupStorageGCSpec struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// MinAge protects recently taken backups from deletion. Defaults to 24h.
+	MinAge *metav1.Duration `json:"minAge,omitempty"`
 }
 
 type BackupStorageS3Spec struct {
-	Bucket            BucketWithPrefix `json:"bucket"`
-	Prefix            string           `json:"prefix,omitempty"`
-	CredentialsSecret string           `json:"credentialsSecret"`
-	Region            string           `json:"region,omitempty"`
-	EndpointURL       string           `json:"endpointUrl,omitempty"`
-	StorageClass      string           `json:"storageClass,omitempty"`
+	Bucket BucketWithPrefix `json:"bucket"`
+	Prefix string           `json:"prefix,omitempty"`
+	// CredentialsSecret contains AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+	// If it's not set, the storage is accessed with the identity of the pod:
+	// the web identity token assuming RoleARN or, without a role, the instance metadata.
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+	Region            string `json:"region,omitempty"`
+	EndpointURL       string `json:"endpointUrl,omitempty"`
+	StorageClass      string `json:"storageClass,omitempty"`
+
+	// RoleARN is the role assumed with the web identity token. Defaults to AWS_ROLE_ARN of the pod.
+	RoleARN string `json:"roleArn,omitempty"`
+	// WebIdentityTokenFile is the path to the web identity token in the pod. Defaults to AWS_WEB_IDENTITY_TOKEN_FILE of the pod.
+	WebIdentityTokenFile string `json:"webIdentityTokenFile,omitempty"`
+	// STSEndpoint is the endpoint the role is assumed at. Defaults to the regional AWS STS endpoint.
+	STSEndpoint string `json:"stsEndpoint,omitempty"`
+
+	ServerSideEncryption *S3ServerSideEncryption `json:"serverSideEncryption,omitempty"`
+	// Tags are set to every object written to the storage in addition to the cluster, namespace and backup type.
+	Tags       map[string]string `json:"tags,omitempty"`
+	ObjectLock *S3ObjectLock     `json:"objectLock,omitempty"`
+}
+
+// S3ServerSideEncryption sets the server-side encryption of the objects.
+type S3ServerSideEncryption struct {
+	// SSEAlgorithm encrypts objects with S3 managed keys (AES256) or KMS keys (aws:kms).
+	// +kubebuilder:validation:Enum=AES256;"aws:kms"
+	SSEAlgorithm string `json:"sseAlgorithm,omitempty"`
+	// KMSKeyID is the KMS key used with aws:kms. The default key of the account is used if it's empty.
+	KMSKeyID string `json:"kmsKeyID,omitempty"`
+	// SSECustomerKeySecret refers to a base64 encoded 256-bit key objects are encrypted with (SSE-C).
+	// The key is needed to read the objects, so it must be kept while there are backups encrypted with it.
+	SSECustomerKeySecret *corev1.SecretKeySelector `json:"sseCustomerKeySecret,omitempty"`
+}
+
+// S3ObjectLock sets the retention to the objects. Object lock must be enabled in the bucket.
+type S3ObjectLock struct {
+	// +kubebuilder:validation:Enum=GOVERNANCE;COMPLIANCE
+	Mode string `json:"mode"`
+	// RetainFor is the period objects can't be deleted or overwritten for since they are written.
+	RetainFor metav1.Duration `json:"retainFor"`
+	LegalHold bool            `json:"legalHold,omitempty"`
 }
 
 // BucketAndPrefix returns bucket name and backup prefix from Bucket concatenated with Prefix.
@@ -296,13 +694,22 @@ func (b BucketWithPrefix) prefix() string {
 }
 
 type BackupStorageGCSSpec struct {
-	Bucket            BucketWithPrefix `json:"bucket"`
-	Prefix            string           `json:"prefix,omitempty"`
-	CredentialsSecret string           `json:"credentialsSecret"`
-	EndpointURL       string           `json:"endpointUrl,omitempty"`
+	Bucket BucketWithPrefix `json:"bucket"`
+	Prefix string           `json:"prefix,omitempty"`
+	// CredentialsSecret contains either HMAC keys in ACCESS_KEY_ID and SECRET_ACCESS_KEY
+	// or a service account JSON key in GCS_SERVICE_ACCOUNT_KEY.
+	// If it's not set, the storage is accessed with the service account of the metadata server.
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+	EndpointURL       string `json:"endpointUrl,omitempty"`
 
 	// STANDARD, NEARLINE, COLDLINE, ARCHIVE
 	StorageClass string `json:"storageClass,omitempty"`
+
+	// KMSKeyName is the Cloud KMS key objects are encrypted with instead of the default key of the bucket.
+	KMSKeyName string `json:"kmsKeyName,omitempty"`
+	// Metadata is set to every object written to the storage in addition to the cluster, namespace and backup type.
+	// GCS doesn't support object tags.
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 // BucketAndPrefix returns bucket name and backup prefix from Bucket concatenated with Prefix.
@@ -329,13 +736,37 @@ type BackupStorageAzureSpec struct {
 	Prefix string `json:"prefix,omitempty"`
 
 	// A generated key that can be used to authorize access to data in your account using the Shared Key authorization.
-	CredentialsSecret string `json:"credentialsSecret"`
+	// The secret can contain a SAS token in AZURE_STORAGE_SAS_TOKEN instead of the account key.
+	// If it's not set, the storage is accessed with the managed identity of the pod.
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+
+	// StorageAccount is used if the credentials secret doesn't contain AZURE_STORAGE_ACCOUNT_NAME.
+	StorageAccount string `json:"storageAccount,omitempty"`
 
 	// The endpoint allows clients to securely access data
 	EndpointURL string `json:"endpointUrl,omitempty"`
 
 	// Hot (Frequently accessed or modified data), Cool (Infrequently accessed or modified data), Archive (Rarely accessed or modified data)
 	StorageClass string `json:"storageClass,omitempty"`
+
+	// EncryptionScope is the encryption scope blobs are encrypted with.
+	EncryptionScope string `json:"encryptionScope,omitempty"`
+	// CustomerKeySecret refers to a base64 encoded 256-bit key blobs are encrypted with.
+	// The key is needed to read the blobs, so it must be kept while there are backups encrypted with it.
+	CustomerKeySecret *corev1.SecretKeySelector `json:"customerKeySecret,omitempty"`
+	// Tags are set to every blob written to the storage in addition to the cluster, namespace and backup type.
+	Tags               map[string]string        `json:"tags,omitempty"`
+	ImmutabilityPolicy *AzureImmutabilityPolicy `json:"immutabilityPolicy,omitempty"`
+}
+
+// AzureImmutabilityPolicy sets the immutability policy to the blobs.
+// Version-level immutability must be enabled in the container.
+type AzureImmutabilityPolicy struct {
+	// +kubebuilder:validation:Enum=Unlocked;Locked
+	Mode string `json:"mode"`
+	// RetainFor is the period blobs can't be deleted or overwritten for since they are written.
+	RetainFor metav1.Duration `json:"retainFor"`
+	LegalHold bool            `json:"legalHold,omitempty"`
 }
 
 // ContainerAndPrefix returns container name from ContainerName and backup prefix from ContainerName concatenated with Prefix.
@@ -463,11 +894,62 @@ type PerconaServerMySQLStatus struct { // INSERT ADDITIONAL STATUS FIELD - defin
 	ToolkitVersion string             `json:"toolkitVersion,omitempty"`
 	Conditions     []metav1.Condition `json:"conditions,omitempty"`
 	// +optional
-	Host string `json:"host"`
+	Host                string                     `json:"host"`
+	ReplicationChannels []ReplicationChannelStatus `json:"replicationChannels,omitempty"`
+	// Members is the replication topology of the MySQL pods. gtidExecuted and thread states
+	// of group replication members are refreshed every 30 seconds.
+	Members []MySQLMemberStatus `json:"members,omitempty"`
+	// BackupStoragesSynced are the storages backups were imported from with the sync-backups annotation.
+	BackupStoragesSynced []string `json:"backupStoragesSynced,omitempty"`
+}
+
+type MySQLMemberRole string
+
+const (
+	MySQLMemberPrimary MySQLMemberRole = "Primary"
+	MySQLMemberReplica MySQLMemberRole = "Replica"
+)
+
+type MySQLMemberStatus struct {
+	// Name is the name of the pod.
+	Name string          `json:"name"`
+	Role MySQLMemberRole `json:"role,omitempty"`
+	// State is the group replication member state for group replication.
+	// It's ONLINE or UNREACHABLE according to Orchestrator for asynchronous replication.
+	State        string `json:"state,omitempty"`
+	GTIDExecuted string `json:"gtidExecuted,omitempty"`
+	// ReplicationLag is the number of seconds behind the primary according to pt-heartbeat
+	// for asynchronous replication and the number of transactions in the applier queue for group replication.
+	ReplicationLag *int64 `json:"replicationLag,omitempty"`
+	// ReceiverState and ApplierState are the states of the replication threads: ON, OFF or CONNECTING.
+	ReceiverState string `json:"receiverState,omitempty"`
+	ApplierState  string `json:"applierState,omitempty"`
+	// Problems are the problems of the instance detected by Orchestrator.
+	Problems []string `json:"problems,omitempty"`
+}
+
+type ReplicationChannelState string
+
+const (
+	ReplicationChannelConnecting ReplicationChannelState = "Connecting"
+	ReplicationChannelRunning    ReplicationChannelState = "Running"
+	ReplicationChannelError      ReplicationChannelState = "Error"
+	ReplicationChannelPromoted   ReplicationChannelState = "Promoted"
+)
+
+type ReplicationChannelStatus struct {
+	Name  string                  `json:"name"`
+	State ReplicationChannelState `json:"state,omitempty"`
+	// Source is the host:port the channel replicates from.
+	Source  string `json:"source,omitempty"`
+	Message string `json:"message,omitempty"`
 }
 
 const ConditionInnoDBClusterBootstrapped string = "InnoDBClusterBootstrapped"
 
+// ConditionErrantTransactions is true if a replica has transactions the primary doesn't have.
+const ConditionErrantTransactions string = "ErrantTransactions"
+
 // PerconaServerMySQL is the Schema for the perconaservermysqls API
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
@@ -555,6 +1037,62 @@ func (cr *PerconaServerMySQL) CheckNSetDefaults(ctx context.Context, serverVersi
 		return errors.New("backup.image can't be empty")
 	}
 
+	if pitr := &cr.Spec.Backup.PITR; pitr.Enabled {
+		if _, ok := cr.Spec.Backup.Storages[pitr.StorageName]; !ok {
+			return errors.Errorf("pitr storage %s doesn't exist", pitr.StorageName)
+		}
+		if pitr.TimeBetweenUploads <= 0 {
+			pitr.TimeBetweenUploads = 60
+		}
+	}
+
+	if initFrom := cr.Spec.MySQL.InitFrom; initFrom != nil {
+		if (initFrom.BackupName == "") == (initFrom.BackupSource == nil) {
+			return errors.New("mysql.initFrom should contain either backupName or backupSource")
+		}
+	}
+
+	if enc := cr.Spec.Backup.Encryption; enc != nil {
+		if enc.KeySecret == nil || enc.KeySecret.Name == "" || enc.KeySecret.Key == "" {
+			return errors.New("backup.encryption.keySecret should contain secret name and key")
+		}
+		if enc.Cipher == "" {
+			enc.Cipher = BackupEncryptionAES256
+		}
+	}
+
+	if len(cr.Spec.ReplicationChannels) > 0 && !cr.Spec.MySQL.IsGR() {
+		return errors.New("replicationChannels are supported only for group replication")
+	}
+	channelNames := make(map[string]struct{}, len(cr.Spec.ReplicationChannels))
+	for i := range cr.Spec.ReplicationChannels {
+		ch := &cr.Spec.ReplicationChannels[i]
+		if _, ok := channelNames[ch.Name]; ok {
+			return errors.Errorf("replication channels should have different names: %s name is used by multiple channels", ch.Name)
+		}
+		channelNames[ch.Name] = struct{}{}
+		if len(ch.Sources) == 0 {
+			return errors.Errorf("replication channel %s should have at least one source", ch.Name)
+		}
+		for j := range ch.Sources {
+			if ch.Sources[j].Port == 0 {
+				ch.Sources[j].Port = 3306
+			}
+			if ch.Sources[j].Weight == 0 {
+				ch.Sources[j].Weight = 100
+			}
+		}
+		if ch.Config == nil {
+			ch.Config = new(ReplicationChannelConfig)
+		}
+		if ch.Config.SourceRetryCount == 0 {
+			ch.Config.SourceRetryCount = 3
+		}
+		if ch.Config.SourceConnectRetry == 0 {
+			ch.Config.SourceConnectRetry = 60
+		}
+	}
+
 	scheduleNames := make(map[string]struct{}, len(cr.Spec.Backup.Schedule))
 	for _, sch := range cr.Spec.Backup.Schedule {
 		if _, ok := scheduleNames[sch.Name]; ok {
@@ -1020,6 +1558,16 @@ func (cr *PerconaServerMySQL) OrchestratorEnabled() bool {
 	return cr.Spec.Orchestrator.Enabled
 }
 
+// IsStandby checks if the cluster replicates from external sources, i.e. has a channel that isn't promoted.
+func (cr *PerconaServerMySQL) IsStandby() bool {
+	for _, ch := range cr.Spec.ReplicationChannels {
+		if !ch.Promote {
+			return true
+		}
+	}
+	return false
+}
+
 var NonAlphaNumeric = regexp.MustCompile("[^a-zA-Z0-9_]+")
 
 // Generates a cluster name by sanitizing the PerconaServerMySQL name.
@@ -20,6 +20,7 @@ import (
 	"path"
 	"strings"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -27,8 +28,72 @@ import (
 type PerconaServerMySQLBackupSpec struct {
 	ClusterName string `json:"clusterName"`
 	StorageName string `json:"storageName"`
+	// +kubebuilder:validation:Enum=full;incremental
+	Type BackupType `json:"type,omitempty"`
+	// BaseBackupName is the name of the backup an incremental backup is taken on top of.
+	// The latest successful full backup on the same storage is used if it's empty.
+	BaseBackupName string `json:"baseBackupName,omitempty"`
+	// Verify makes the operator test-restore the backup after it succeeds.
+	Verify *BackupVerifySpec `json:"verify,omitempty"`
+	// Method is physical for xtrabackup backups or logical for dumps taken with MySQL Shell.
+	// Logical backups can be restored into another major version and loaded into a running cluster.
+	// MySQL Shell is used instead of mysqldump or mydumper since it's shipped with the MySQL image,
+	// dumps and loads tables in parallel chunks and filters schemas and tables on load.
+	// Logical restores don't replace existing objects: they fail if objects of the dump already exist
+	// in the cluster, such objects have to be dropped or excluded with spec.partial of the restore.
+	// +kubebuilder:validation:Enum=physical;logical
+	Method BackupMethod `json:"method,omitempty"`
+	// Cancel stops the backup if it's not finished yet. The data uploaded so far is deleted.
+	// Deleting the object cancels the running backup as well.
+	Cancel bool `json:"cancel,omitempty"`
+	// ReplicateTo lists the storages the backup is copied to after it succeeds.
+	// Restores use a copy if the backup isn't reachable in its own storage.
+	ReplicateTo []string `json:"replicateTo,omitempty"`
 }
 
+// BackupVerifySpec configures the job that restores the backup into a scratch volume,
+// starts mysqld on it and runs the sanity query.
+// The job retries failed restores and evicted pods, the backup isn't verified once the query fails.
+type BackupVerifySpec struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// Query is run against the restored data. The backup is verified if it succeeds.
+	Query string `json:"query,omitempty"`
+	// Resources of the mysqld container. Restored data is kept in an emptyDir volume,
+	// so the node should have enough ephemeral storage for it.
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// DefaultVerifyQuery is run against the restored backup if BackupVerifySpec.Query is empty.
+const DefaultVerifyQuery = "SELECT COUNT(*) FROM information_schema.tables"
+
+const (
+	// BackupConditionVerified is true if the backup was restored and queried successfully.
+	BackupConditionVerified = "Verified"
+
+	BackupReasonVerified           = "Verified"
+	BackupReasonVerificationFailed = "VerificationFailed"
+
+	// BackupConditionPostBackupHooksFailed is true if a post-backup hook with the Fail policy
+	// failed after the backup was uploaded. The backup stays succeeded, it can be restored.
+	BackupConditionPostBackupHooksFailed = "PostBackupHooksFailed"
+
+	BackupReasonHookFailed = "HookFailed"
+)
+
+type BackupMethod string
+
+const (
+	BackupMethodPhysical BackupMethod = "physical"
+	BackupMethodLogical  BackupMethod = "logical"
+)
+
+type BackupType string
+
+const (
+	BackupTypeFull        BackupType = "full"
+	BackupTypeIncremental BackupType = "incremental"
+)
+
 type BackupState string
 
 const (
@@ -38,6 +103,7 @@ const (
 	BackupFailed    BackupState = "Failed"
 	BackupError     BackupState = "Error"
 	BackupSucceeded BackupState = "Succeeded"
+	BackupCanceled  BackupState = "Canceled"
 )
 
 // PerconaServerMySQLBackupStatus defines the observed state of PerconaServerMySQLBackup
@@ -48,12 +114,106 @@ type PerconaServerMySQLBackupStatus struct {
 	Storage     *BackupStorageSpec `json:"storage,omitempty"`
 	CompletedAt *metav1.Time       `json:"completed,omitempty"`
 	Image       string             `json:"image,omitempty"`
+	Type        BackupType         `json:"type,omitempty"`
+	Method      BackupMethod       `json:"method,omitempty"`
+	// Source is the host of the MySQL node the backup is taken from.
+	Source string `json:"source,omitempty"`
+	// BaseBackupName is the name of the backup an incremental backup is taken on top of.
+	BaseBackupName string `json:"baseBackupName,omitempty"`
+	// Chain contains destinations of the backups an incremental backup depends on,
+	// starting with the full one. They are restored in this order before the backup itself.
+	Chain      []BackupDestination     `json:"chain,omitempty"`
+	Encryption *BackupEncryptionStatus `json:"encryption,omitempty"`
+	// Progress is reported while the backup is running.
+	Progress *BackupProgress `json:"progress,omitempty"`
+	// Metadata is reported by xtrabackup after the backup is finished.
+	Metadata   *BackupMetadata    `json:"metadata,omitempty"`
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Copies are the copies of the backup made to the storages in spec.replicateTo.
+	Copies []BackupCopyStatus `json:"copies,omitempty"`
+	// Hooks are the states of spec.backup.hooks of the cluster run for the backup.
+	Hooks []HookStatus `json:"hooks,omitempty"`
+}
+
+// BackupCopyStatus describes the copy of the backup in another storage.
+type BackupCopyStatus struct {
+	StorageName string             `json:"storageName"`
+	State       BackupState        `json:"state,omitempty"`
+	StateDesc   string             `json:"stateDescription,omitempty"`
+	Destination BackupDestination  `json:"destination,omitempty"`
+	Storage     *BackupStorageSpec `json:"storage,omitempty"`
+	// Chain contains destinations of the copies of the backups an incremental backup depends on.
+	Chain       []BackupDestination `json:"chain,omitempty"`
+	CompletedAt *metav1.Time        `json:"completed,omitempty"`
+}
+
+// Copy returns the status of the copy of the backup in the storage or nil if there is no copy.
+func (s *PerconaServerMySQLBackupStatus) Copy(storageName string) *BackupCopyStatus {
+	for i := range s.Copies {
+		if s.Copies[i].StorageName == storageName {
+			return &s.Copies[i]
+		}
+	}
+	return nil
+}
+
+type BackupProgress struct {
+	// BytesRead is the number of bytes xtrabackup has streamed so far.
+	BytesRead int64 `json:"bytesRead"`
+	// BytesUploaded is the number of bytes stored in the storage so far.
+	BytesUploaded int64        `json:"bytesUploaded"`
+	UpdatedAt     *metav1.Time `json:"updatedAt,omitempty"`
+}
+
+type BackupMetadata struct {
+	// SizeBytes is the size of the backup stream.
+	SizeBytes       int64        `json:"sizeBytes,omitempty"`
+	StartedAt       *metav1.Time `json:"startedAt,omitempty"`
+	FinishedAt      *metav1.Time `json:"finishedAt,omitempty"`
+	DurationSeconds int64        `json:"durationSeconds,omitempty"`
+
+	MySQLVersion      string `json:"mysqlVersion,omitempty"`
+	XtraBackupVersion string `json:"xtrabackupVersion,omitempty"`
+
+	// BinlogFile and BinlogPosition are the binary log coordinates of the backup.
+	BinlogFile     string `json:"binlogFile,omitempty"`
+	BinlogPosition int64  `json:"binlogPosition,omitempty"`
+	// GTIDExecuted is the set of transactions the backup contains.
+	GTIDExecuted string `json:"gtidExecuted,omitempty"`
+
+	FromLSN string `json:"fromLSN,omitempty"`
+	ToLSN   string `json:"toLSN,omitempty"`
+	LastLSN string `json:"lastLSN,omitempty"`
+
+	// Namespace is the namespace of the cluster the backup is taken from.
+	// Storage garbage collection deletes only backups of its own namespace.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// BackupEncryptionStatus describes how the backup was encrypted.
+type BackupEncryptionStatus struct {
+	Cipher    BackupEncryptionCipher    `json:"cipher"`
+	KeySecret *corev1.SecretKeySelector `json:"keySecret"`
+	// KeyFingerprint identifies the key the backup was encrypted with,
+	// so the backup can be restored after the key in KeySecret is rotated.
+	KeyFingerprint string `json:"keyFingerprint"`
+}
+
+// IsIncremental returns true if the backup is taken on top of another backup.
+func (s *PerconaServerMySQLBackupStatus) IsIncremental() bool {
+	return s.Type == BackupTypeIncremental
+}
+
+// IsLogical returns true if the backup is a MySQL Shell dump.
+func (s *PerconaServerMySQLBackupStatus) IsLogical() bool {
+	return s.Method == BackupMethodLogical
 }
 
 const (
 	AzureBlobStoragePrefix string = ""
 	AwsBlobStoragePrefix   string = "s3://"
 	GCSStoragePrefix       string = "gs://"
+	PVCStoragePrefix       string = "pvc/"
 )
 
 type BackupDestination string
@@ -77,6 +237,11 @@ func (dest *BackupDestination) SetAzureDestination(container, backupName string)
 	dest.set(AzureBlobStoragePrefix + container + "/" + backupName)
 }
 
+// SetPVCDestination sets the destination of a backup stored on a filesystem of the PVC.
+func (dest *BackupDestination) SetPVCDestination(pvcName, backupName string) {
+	dest.set(PVCStoragePrefix + pvcName + "/" + backupName)
+}
+
 func (dest *BackupDestination) String() string {
 	if dest == nil {
 		return ""
@@ -85,7 +250,7 @@ func (dest *BackupDestination) String() string {
 }
 
 func (dest *BackupDestination) StorageTypePrefix() string {
-	for _, p := range []string{AwsBlobStoragePrefix, GCSStoragePrefix} {
+	for _, p := range []string{AwsBlobStoragePrefix, GCSStoragePrefix, PVCStoragePrefix} {
 		if strings.HasPrefix(dest.String(), p) {
 			return p
 		}
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -28,6 +29,56 @@ type PerconaServerMySQLRestoreSpec struct {
 	ClusterName  string                          `json:"clusterName"`
 	BackupName   string                          `json:"backupName,omitempty"`
 	BackupSource *PerconaServerMySQLBackupStatus `json:"backupSource,omitempty"`
+	PITR         *RestorePITRSpec                `json:"pitr,omitempty"`
+	// EncryptionKeySecret overrides the key secret recorded in the status of an encrypted backup.
+	// It's needed to restore backups taken before the encryption key was rotated.
+	EncryptionKeySecret *corev1.SecretKeySelector `json:"encryptionKeySecret,omitempty"`
+	// Partial restores only the selected databases and tables into the running cluster.
+	// The cluster isn't paused and the rest of its data is kept.
+	Partial *PartialRestoreSpec `json:"partial,omitempty"`
+	Hooks   *RestoreHooks       `json:"hooks,omitempty"`
+}
+
+// RestoreHooks are run by the operator around the restore.
+type RestoreHooks struct {
+	// PreRestore hooks run before the cluster is paused and the restore job is created.
+	PreRestore []Hook `json:"preRestore,omitempty"`
+	// PostRestore hooks run once the cluster is ready after the restore, e.g. to scrub sensitive data.
+	// The restore succeeds after they finish.
+	PostRestore []Hook `json:"postRestore,omitempty"`
+}
+
+// PartialRestoreSpec selects databases and tables to restore. Objects that already exist
+// in the cluster have to be dropped or renamed first, otherwise the restore fails.
+//
+// Physical backups are restored into a scratch volume of the restore job, then the selected
+// objects are dumped from it with MySQL Shell and loaded into the primary.
+type PartialRestoreSpec struct {
+	IncludeDatabases []string `json:"includeDatabases,omitempty"`
+	ExcludeDatabases []string `json:"excludeDatabases,omitempty"`
+	// IncludeTables and ExcludeTables are in the "database.table" format.
+	IncludeTables []string `json:"includeTables,omitempty"`
+	ExcludeTables []string `json:"excludeTables,omitempty"`
+}
+
+type PITRType string
+
+const (
+	PITRDate PITRType = "date"
+	PITRGTID PITRType = "gtid"
+)
+
+// PITRDateFormat is the layout of RestorePITRSpec.Date.
+const PITRDateFormat = "2006-01-02 15:04:05"
+
+// RestorePITRSpec defines the point binary logs are replayed up to after the base backup is restored.
+type RestorePITRSpec struct {
+	// +kubebuilder:validation:Enum=date;gtid
+	Type PITRType `json:"type"`
+	// Date in "YYYY-MM-DD hh:mm:ss" format (UTC). Transactions committed at or after this time are not applied.
+	Date string `json:"date,omitempty"`
+	// GTID of the first transaction that shouldn't be applied.
+	GTID string `json:"gtid,omitempty"`
 }
 
 type RestoreState string
@@ -46,6 +97,7 @@ type PerconaServerMySQLRestoreStatus struct { // INSERT ADDITIONAL STATUS FIELD
 	State       RestoreState `json:"state,omitempty"`
 	StateDesc   string       `json:"stateDescription,omitempty"`
 	CompletedAt *metav1.Time `json:"completed,omitempty"`
+	Hooks       []HookStatus `json:"hooks,omitempty"`
 }
 
 // +kubebuilder:object:root=true
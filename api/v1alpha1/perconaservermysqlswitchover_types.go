@@ -0,0 +1,110 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PerconaServerMySQLSwitchoverSpec defines the desired state of PerconaServerMySQLSwitchover
+type PerconaServerMySQLSwitchoverSpec struct {
+	ClusterName string `json:"clusterName"`
+	// TargetInstance is the name of the MySQL pod to make the primary, e.g. cluster1-mysql-1.
+	TargetInstance string `json:"targetInstance"`
+	// MaxReplicationLag is the lag of the target above which the switchover waits.
+	// It's the number of seconds for asynchronous replication and
+	// the number of transactions in the applier queue for group replication. Defaults to 10.
+	// +kubebuilder:validation:Minimum=0
+	MaxReplicationLag *int64 `json:"maxReplicationLag,omitempty"`
+	// Timeout is the time the switchover waits for the cluster to be ready, for the lag
+	// to drop and for the target to become the primary before it fails. Defaults to 5m.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+const (
+	defaultSwitchoverMaxReplicationLag = 10
+	defaultSwitchoverTimeout           = 5 * time.Minute
+)
+
+// MaxLag returns spec.maxReplicationLag or its default.
+func (s *PerconaServerMySQLSwitchoverSpec) MaxLag() int64 {
+	if s.MaxReplicationLag == nil {
+		return defaultSwitchoverMaxReplicationLag
+	}
+	return *s.MaxReplicationLag
+}
+
+// TimeoutDuration returns spec.timeout or its default.
+func (s *PerconaServerMySQLSwitchoverSpec) TimeoutDuration() time.Duration {
+	if s.Timeout == nil || s.Timeout.Duration <= 0 {
+		return defaultSwitchoverTimeout
+	}
+	return s.Timeout.Duration
+}
+
+type SwitchoverState string
+
+const (
+	SwitchoverNew       SwitchoverState = ""
+	SwitchoverPending   SwitchoverState = "Pending"
+	SwitchoverRunning   SwitchoverState = "Running"
+	SwitchoverFailed    SwitchoverState = "Failed"
+	SwitchoverSucceeded SwitchoverState = "Succeeded"
+)
+
+// PerconaServerMySQLSwitchoverStatus defines the observed state of PerconaServerMySQLSwitchover
+type PerconaServerMySQLSwitchoverStatus struct {
+	State     SwitchoverState `json:"state,omitempty"`
+	StateDesc string          `json:"stateDescription,omitempty"`
+	// PreviousPrimary is the name of the pod that was the primary when the switchover started.
+	PreviousPrimary string       `json:"previousPrimary,omitempty"`
+	StartedAt       *metav1.Time `json:"started,omitempty"`
+	CompletedAt     *metav1.Time `json:"completed,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:resource:shortName=ps-switchover
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=".spec.clusterName"
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=".spec.targetInstance"
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=".status.state"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// PerconaServerMySQLSwitchover is the Schema for the perconaservermysqlswitchovers API
+type PerconaServerMySQLSwitchover struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PerconaServerMySQLSwitchoverSpec   `json:"spec,omitempty"`
+	Status PerconaServerMySQLSwitchoverStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PerconaServerMySQLSwitchoverList contains a list of PerconaServerMySQLSwitchover
+type PerconaServerMySQLSwitchoverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PerconaServerMySQLSwitchover `json:"items"`
+}
+
+// Registers PerconaServerMySQLSwitchover types with the SchemeBuilder.
+func init() {
+	SchemeBuilder.Register(&PerconaServerMySQLSwitchover{}, &PerconaServerMySQLSwitchoverList{})
+}
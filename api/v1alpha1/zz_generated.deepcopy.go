@@ -28,9 +28,245 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoRebuildSpec) DeepCopyInto(out *AutoRebuildSpec) {
+	*out = *in
+	if in.ErrorTimeout != nil {
+		in, out := &in.ErrorTimeout, &out.ErrorTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoRebuildSpec.
+func (in *AutoRebuildSpec) DeepCopy() *AutoRebuildSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoRebuildSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureImmutabilityPolicy) DeepCopyInto(out *AzureImmutabilityPolicy) {
+	*out = *in
+	out.RetainFor = in.RetainFor
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureImmutabilityPolicy.
+func (in *AzureImmutabilityPolicy) DeepCopy() *AzureImmutabilityPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureImmutabilityPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupCopyStatus) DeepCopyInto(out *BackupCopyStatus) {
+	*out = *in
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(BackupStorageSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Chain != nil {
+		in, out := &in.Chain, &out.Chain
+		*out = make([]BackupDestination, len(*in))
+		copy(*out, *in)
+	}
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupCopyStatus.
+func (in *BackupCopyStatus) DeepCopy() *BackupCopyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupCopyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupEncryptionSpec) DeepCopyInto(out *BackupEncryptionSpec) {
+	*out = *in
+	if in.KeySecret != nil {
+		in, out := &in.KeySecret, &out.KeySecret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupEncryptionSpec.
+func (in *BackupEncryptionSpec) DeepCopy() *BackupEncryptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupEncryptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupEncryptionStatus) DeepCopyInto(out *BackupEncryptionStatus) {
+	*out = *in
+	if in.KeySecret != nil {
+		in, out := &in.KeySecret, &out.KeySecret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupEncryptionStatus.
+func (in *BackupEncryptionStatus) DeepCopy() *BackupEncryptionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupEncryptionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupHooks) DeepCopyInto(out *BackupHooks) {
+	*out = *in
+	if in.PreBackup != nil {
+		in, out := &in.PreBackup, &out.PreBackup
+		*out = make([]Hook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PostBackup != nil {
+		in, out := &in.PostBackup, &out.PostBackup
+		*out = make([]Hook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupHooks.
+func (in *BackupHooks) DeepCopy() *BackupHooks {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupHooks)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupMetadata) DeepCopyInto(out *BackupMetadata) {
+	*out = *in
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.FinishedAt != nil {
+		in, out := &in.FinishedAt, &out.FinishedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupMetadata.
+func (in *BackupMetadata) DeepCopy() *BackupMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupProgress) DeepCopyInto(out *BackupProgress) {
+	*out = *in
+	if in.UpdatedAt != nil {
+		in, out := &in.UpdatedAt, &out.UpdatedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupProgress.
+func (in *BackupProgress) DeepCopy() *BackupProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupRetentionSpec) DeepCopyInto(out *BackupRetentionSpec) {
+	*out = *in
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRetentionSpec.
+func (in *BackupRetentionSpec) DeepCopy() *BackupRetentionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupRetentionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupRetryPolicy) DeepCopyInto(out *BackupRetryPolicy) {
+	*out = *in
+	if in.InitialInterval != nil {
+		in, out := &in.InitialInterval, &out.InitialInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxInterval != nil {
+		in, out := &in.MaxInterval, &out.MaxInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRetryPolicy.
+func (in *BackupRetryPolicy) DeepCopy() *BackupRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupSchedule) DeepCopyInto(out *BackupSchedule) {
 	*out = *in
+	if in.Verify != nil {
+		in, out := &in.Verify, &out.Verify
+		*out = new(BackupVerifySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(BackupRetentionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReplicateTo != nil {
+		in, out := &in.ReplicateTo, &out.ReplicateTo
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupSchedule.
@@ -43,6 +279,56 @@ func (in *BackupSchedule) DeepCopy() *BackupSchedule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupScheduleGuardSpec) DeepCopyInto(out *BackupScheduleGuardSpec) {
+	*out = *in
+	if in.MaxReplicationLag != nil {
+		in, out := &in.MaxReplicationLag, &out.MaxReplicationLag
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxLoadAverage != nil {
+		in, out := &in.MaxLoadAverage, &out.MaxLoadAverage
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.CheckInterval != nil {
+		in, out := &in.CheckInterval, &out.CheckInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxDelay != nil {
+		in, out := &in.MaxDelay, &out.MaxDelay
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupScheduleGuardSpec.
+func (in *BackupScheduleGuardSpec) DeepCopy() *BackupScheduleGuardSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupScheduleGuardSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupSourcePolicy) DeepCopyInto(out *BackupSourcePolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupSourcePolicy.
+func (in *BackupSourcePolicy) DeepCopy() *BackupSourcePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupSourcePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupSpec) DeepCopyInto(out *BackupSpec) {
 	*out = *in
@@ -81,7 +367,45 @@ func (in *BackupSpec) DeepCopyInto(out *BackupSpec) {
 	if in.Schedule != nil {
 		in, out := &in.Schedule, &out.Schedule
 		*out = make([]BackupSchedule, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.PITR.DeepCopyInto(&out.PITR)
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(BackupEncryptionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SourcePolicy != nil {
+		in, out := &in.SourcePolicy, &out.SourcePolicy
+		*out = new(BackupSourcePolicy)
+		**out = **in
+	}
+	if in.Throttle != nil {
+		in, out := &in.Throttle, &out.Throttle
+		*out = new(BackupThrottleSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScheduleGuard != nil {
+		in, out := &in.ScheduleGuard, &out.ScheduleGuard
+		*out = new(BackupScheduleGuardSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = new(BackupHooks)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeouts != nil {
+		in, out := &in.Timeouts, &out.Timeouts
+		*out = new(BackupTimeouts)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(BackupRetryPolicy)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -98,6 +422,23 @@ func (in *BackupSpec) DeepCopy() *BackupSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupStorageAzureSpec) DeepCopyInto(out *BackupStorageAzureSpec) {
 	*out = *in
+	if in.CustomerKeySecret != nil {
+		in, out := &in.CustomerKeySecret, &out.CustomerKeySecret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ImmutabilityPolicy != nil {
+		in, out := &in.ImmutabilityPolicy, &out.ImmutabilityPolicy
+		*out = new(AzureImmutabilityPolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStorageAzureSpec.
@@ -113,6 +454,13 @@ func (in *BackupStorageAzureSpec) DeepCopy() *BackupStorageAzureSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupStorageGCSSpec) DeepCopyInto(out *BackupStorageGCSSpec) {
 	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStorageGCSSpec.
@@ -125,9 +473,46 @@ func (in *BackupStorageGCSSpec) DeepCopy() *BackupStorageGCSSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupStorageGCSpec) DeepCopyInto(out *BackupStorageGCSpec) {
+	*out = *in
+	if in.MinAge != nil {
+		in, out := &in.MinAge, &out.MinAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStorageGCSpec.
+func (in *BackupStorageGCSpec) DeepCopy() *BackupStorageGCSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupStorageGCSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupStorageS3Spec) DeepCopyInto(out *BackupStorageS3Spec) {
 	*out = *in
+	if in.ServerSideEncryption != nil {
+		in, out := &in.ServerSideEncryption, &out.ServerSideEncryption
+		*out = new(S3ServerSideEncryption)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ObjectLock != nil {
+		in, out := &in.ObjectLock, &out.ObjectLock
+		*out = new(S3ObjectLock)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStorageS3Spec.
@@ -151,17 +536,17 @@ func (in *BackupStorageSpec) DeepCopyInto(out *BackupStorageSpec) {
 	if in.S3 != nil {
 		in, out := &in.S3, &out.S3
 		*out = new(BackupStorageS3Spec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.GCS != nil {
 		in, out := &in.GCS, &out.GCS
 		*out = new(BackupStorageGCSSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Azure != nil {
 		in, out := &in.Azure, &out.Azure
 		*out = new(BackupStorageAzureSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.NodeSelector != nil {
 		in, out := &in.NodeSelector, &out.NodeSelector
@@ -224,6 +609,11 @@ func (in *BackupStorageSpec) DeepCopyInto(out *BackupStorageSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.GarbageCollection != nil {
+		in, out := &in.GarbageCollection, &out.GarbageCollection
+		*out = new(BackupStorageGCSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStorageSpec.
@@ -236,6 +626,67 @@ func (in *BackupStorageSpec) DeepCopy() *BackupStorageSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupThrottleSpec) DeepCopyInto(out *BackupThrottleSpec) {
+	*out = *in
+	if in.UploadBandwidth != nil {
+		in, out := &in.UploadBandwidth, &out.UploadBandwidth
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupThrottleSpec.
+func (in *BackupThrottleSpec) DeepCopy() *BackupThrottleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupThrottleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupTimeouts) DeepCopyInto(out *BackupTimeouts) {
+	*out = *in
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupTimeouts.
+func (in *BackupTimeouts) DeepCopy() *BackupTimeouts {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupTimeouts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupVerifySpec) DeepCopyInto(out *BackupVerifySpec) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupVerifySpec.
+func (in *BackupVerifySpec) DeepCopy() *BackupVerifySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupVerifySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContainerSpec) DeepCopyInto(out *ContainerSpec) {
 	*out = *in
@@ -296,6 +747,151 @@ func (in *HAProxySpec) DeepCopy() *HAProxySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Hook) DeepCopyInto(out *Hook) {
+	*out = *in
+	if in.SQL != nil {
+		in, out := &in.SQL, &out.SQL
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(HookJobSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Hook.
+func (in *Hook) DeepCopy() *Hook {
+	if in == nil {
+		return nil
+	}
+	out := new(Hook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookJobSpec) DeepCopyInto(out *HookJobSpec) {
+	*out = *in
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EnvFrom != nil {
+		in, out := &in.EnvFrom, &out.EnvFrom
+		*out = make([]corev1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookJobSpec.
+func (in *HookJobSpec) DeepCopy() *HookJobSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HookJobSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookStatus) DeepCopyInto(out *HookStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookStatus.
+func (in *HookStatus) DeepCopy() *HookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InitFromSpec) DeepCopyInto(out *InitFromSpec) {
+	*out = *in
+	if in.BackupSource != nil {
+		in, out := &in.BackupSource, &out.BackupSource
+		*out = new(PerconaServerMySQLBackupStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EncryptionKeySecret != nil {
+		in, out := &in.EncryptionKeySecret, &out.EncryptionKeySecret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitFromSpec.
+func (in *InitFromSpec) DeepCopy() *InitFromSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InitFromSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MySQLMemberStatus) DeepCopyInto(out *MySQLMemberStatus) {
+	*out = *in
+	if in.ReplicationLag != nil {
+		in, out := &in.ReplicationLag, &out.ReplicationLag
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Problems != nil {
+		in, out := &in.Problems, &out.Problems
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MySQLMemberStatus.
+func (in *MySQLMemberStatus) DeepCopy() *MySQLMemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MySQLMemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MySQLRouterSpec) DeepCopyInto(out *MySQLRouterSpec) {
 	*out = *in
@@ -338,6 +934,12 @@ func (in *MySQLSpec) DeepCopyInto(out *MySQLSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	in.AutoRebuild.DeepCopyInto(&out.AutoRebuild)
+	if in.InitFrom != nil {
+		in, out := &in.InitFrom, &out.InitFrom
+		*out = new(InitFromSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	in.PodSpec.DeepCopyInto(&out.PodSpec)
 }
 
@@ -368,6 +970,27 @@ func (in *OrchestratorSpec) DeepCopy() *OrchestratorSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PITRSpec) DeepCopyInto(out *PITRSpec) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.ContainerSecurityContext != nil {
+		in, out := &in.ContainerSecurityContext, &out.ContainerSecurityContext
+		*out = new(corev1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PITRSpec.
+func (in *PITRSpec) DeepCopy() *PITRSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PITRSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PMMSpec) DeepCopyInto(out *PMMSpec) {
 	*out = *in
@@ -384,12 +1007,47 @@ func (in *PMMSpec) DeepCopyInto(out *PMMSpec) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PMMSpec.
-func (in *PMMSpec) DeepCopy() *PMMSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PMMSpec.
+func (in *PMMSpec) DeepCopy() *PMMSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PMMSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PartialRestoreSpec) DeepCopyInto(out *PartialRestoreSpec) {
+	*out = *in
+	if in.IncludeDatabases != nil {
+		in, out := &in.IncludeDatabases, &out.IncludeDatabases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeDatabases != nil {
+		in, out := &in.ExcludeDatabases, &out.ExcludeDatabases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IncludeTables != nil {
+		in, out := &in.IncludeTables, &out.IncludeTables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeTables != nil {
+		in, out := &in.ExcludeTables, &out.ExcludeTables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PartialRestoreSpec.
+func (in *PartialRestoreSpec) DeepCopy() *PartialRestoreSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PMMSpec)
+	out := new(PartialRestoreSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -426,7 +1084,7 @@ func (in *PerconaServerMySQLBackup) DeepCopyInto(out *PerconaServerMySQLBackup)
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -483,6 +1141,16 @@ func (in *PerconaServerMySQLBackupList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PerconaServerMySQLBackupSpec) DeepCopyInto(out *PerconaServerMySQLBackupSpec) {
 	*out = *in
+	if in.Verify != nil {
+		in, out := &in.Verify, &out.Verify
+		*out = new(BackupVerifySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReplicateTo != nil {
+		in, out := &in.ReplicateTo, &out.ReplicateTo
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerconaServerMySQLBackupSpec.
@@ -507,6 +1175,45 @@ func (in *PerconaServerMySQLBackupStatus) DeepCopyInto(out *PerconaServerMySQLBa
 		in, out := &in.CompletedAt, &out.CompletedAt
 		*out = (*in).DeepCopy()
 	}
+	if in.Chain != nil {
+		in, out := &in.Chain, &out.Chain
+		*out = make([]BackupDestination, len(*in))
+		copy(*out, *in)
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(BackupEncryptionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(BackupProgress)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(BackupMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Copies != nil {
+		in, out := &in.Copies, &out.Copies
+		*out = make([]BackupCopyStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]HookStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerconaServerMySQLBackupStatus.
@@ -618,6 +1325,26 @@ func (in *PerconaServerMySQLRestoreSpec) DeepCopyInto(out *PerconaServerMySQLRes
 		*out = new(PerconaServerMySQLBackupStatus)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PITR != nil {
+		in, out := &in.PITR, &out.PITR
+		*out = new(RestorePITRSpec)
+		**out = **in
+	}
+	if in.EncryptionKeySecret != nil {
+		in, out := &in.EncryptionKeySecret, &out.EncryptionKeySecret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Partial != nil {
+		in, out := &in.Partial, &out.Partial
+		*out = new(PartialRestoreSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = new(RestoreHooks)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerconaServerMySQLRestoreSpec.
@@ -637,6 +1364,11 @@ func (in *PerconaServerMySQLRestoreStatus) DeepCopyInto(out *PerconaServerMySQLR
 		in, out := &in.CompletedAt, &out.CompletedAt
 		*out = (*in).DeepCopy()
 	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]HookStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerconaServerMySQLRestoreStatus.
@@ -687,6 +1419,13 @@ func (in *PerconaServerMySQLSpec) DeepCopyInto(out *PerconaServerMySQLSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	out.UpgradeOptions = in.UpgradeOptions
+	if in.ReplicationChannels != nil {
+		in, out := &in.ReplicationChannels, &out.ReplicationChannels
+		*out = make([]ReplicationChannel, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerconaServerMySQLSpec.
@@ -713,6 +1452,23 @@ func (in *PerconaServerMySQLStatus) DeepCopyInto(out *PerconaServerMySQLStatus)
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ReplicationChannels != nil {
+		in, out := &in.ReplicationChannels, &out.ReplicationChannels
+		*out = make([]ReplicationChannelStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]MySQLMemberStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BackupStoragesSynced != nil {
+		in, out := &in.BackupStoragesSynced, &out.BackupStoragesSynced
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerconaServerMySQLStatus.
@@ -725,6 +1481,113 @@ func (in *PerconaServerMySQLStatus) DeepCopy() *PerconaServerMySQLStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PerconaServerMySQLSwitchover) DeepCopyInto(out *PerconaServerMySQLSwitchover) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerconaServerMySQLSwitchover.
+func (in *PerconaServerMySQLSwitchover) DeepCopy() *PerconaServerMySQLSwitchover {
+	if in == nil {
+		return nil
+	}
+	out := new(PerconaServerMySQLSwitchover)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PerconaServerMySQLSwitchover) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PerconaServerMySQLSwitchoverList) DeepCopyInto(out *PerconaServerMySQLSwitchoverList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PerconaServerMySQLSwitchover, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerconaServerMySQLSwitchoverList.
+func (in *PerconaServerMySQLSwitchoverList) DeepCopy() *PerconaServerMySQLSwitchoverList {
+	if in == nil {
+		return nil
+	}
+	out := new(PerconaServerMySQLSwitchoverList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PerconaServerMySQLSwitchoverList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PerconaServerMySQLSwitchoverSpec) DeepCopyInto(out *PerconaServerMySQLSwitchoverSpec) {
+	*out = *in
+	if in.MaxReplicationLag != nil {
+		in, out := &in.MaxReplicationLag, &out.MaxReplicationLag
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerconaServerMySQLSwitchoverSpec.
+func (in *PerconaServerMySQLSwitchoverSpec) DeepCopy() *PerconaServerMySQLSwitchoverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PerconaServerMySQLSwitchoverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PerconaServerMySQLSwitchoverStatus) DeepCopyInto(out *PerconaServerMySQLSwitchoverStatus) {
+	*out = *in
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerconaServerMySQLSwitchoverStatus.
+func (in *PerconaServerMySQLSwitchoverStatus) DeepCopy() *PerconaServerMySQLSwitchoverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PerconaServerMySQLSwitchoverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodAffinity) DeepCopyInto(out *PodAffinity) {
 	*out = *in
@@ -876,6 +1739,181 @@ func (in *ProxySpec) DeepCopy() *ProxySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationChannel) DeepCopyInto(out *ReplicationChannel) {
+	*out = *in
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]ReplicationSource, len(*in))
+		copy(*out, *in)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ReplicationChannelTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(ReplicationChannelConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationChannel.
+func (in *ReplicationChannel) DeepCopy() *ReplicationChannel {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationChannel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationChannelConfig) DeepCopyInto(out *ReplicationChannelConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationChannelConfig.
+func (in *ReplicationChannelConfig) DeepCopy() *ReplicationChannelConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationChannelConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationChannelStatus) DeepCopyInto(out *ReplicationChannelStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationChannelStatus.
+func (in *ReplicationChannelStatus) DeepCopy() *ReplicationChannelStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationChannelStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationChannelTLS) DeepCopyInto(out *ReplicationChannelTLS) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationChannelTLS.
+func (in *ReplicationChannelTLS) DeepCopy() *ReplicationChannelTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationChannelTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationSource) DeepCopyInto(out *ReplicationSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSource.
+func (in *ReplicationSource) DeepCopy() *ReplicationSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreHooks) DeepCopyInto(out *RestoreHooks) {
+	*out = *in
+	if in.PreRestore != nil {
+		in, out := &in.PreRestore, &out.PreRestore
+		*out = make([]Hook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PostRestore != nil {
+		in, out := &in.PostRestore, &out.PostRestore
+		*out = make([]Hook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreHooks.
+func (in *RestoreHooks) DeepCopy() *RestoreHooks {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreHooks)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestorePITRSpec) DeepCopyInto(out *RestorePITRSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestorePITRSpec.
+func (in *RestorePITRSpec) DeepCopy() *RestorePITRSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RestorePITRSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3ObjectLock) DeepCopyInto(out *S3ObjectLock) {
+	*out = *in
+	out.RetainFor = in.RetainFor
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3ObjectLock.
+func (in *S3ObjectLock) DeepCopy() *S3ObjectLock {
+	if in == nil {
+		return nil
+	}
+	out := new(S3ObjectLock)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3ServerSideEncryption) DeepCopyInto(out *S3ServerSideEncryption) {
+	*out = *in
+	if in.SSECustomerKeySecret != nil {
+		in, out := &in.SSECustomerKeySecret, &out.SSECustomerKeySecret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3ServerSideEncryption.
+func (in *S3ServerSideEncryption) DeepCopy() *S3ServerSideEncryption {
+	if in == nil {
+		return nil
+	}
+	out := new(S3ServerSideEncryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceExpose) DeepCopyInto(out *ServiceExpose) {
 	*out = *in
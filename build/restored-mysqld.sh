@@ -0,0 +1,40 @@
+#!/bin/bash
+
+# Sourced by the scripts running mysqld on the data restored by run-restore.sh
+# in the init container. SOCKET is set by the sourcing script.
+
+DATADIR=${DATADIR:-/var/lib/mysql}
+STARTUP_TIMEOUT=${STARTUP_TIMEOUT:-600}
+
+# start_mysqld starts mysqld in the background with the extra arguments and waits
+# until it accepts connections. Restored data is started without the configuration
+# of the source cluster: persisted variables could make it join the group or start replication.
+start_mysqld() {
+	mysqld --no-defaults \
+		--datadir="${DATADIR}" \
+		--socket="${SOCKET}" \
+		--pid-file="${SOCKET%.sock}.pid" \
+		--skip-networking \
+		--skip-grant-tables \
+		--skip-slave-start \
+		--disable-log-bin \
+		--persisted-globals-load=OFF \
+		"$@" &
+	MYSQLD_PID=$!
+
+	for _ in $(seq "${STARTUP_TIMEOUT}"); do
+		if mysqladmin --socket="${SOCKET}" ping >/dev/null 2>&1; then
+			return
+		fi
+		if ! kill -0 "${MYSQLD_PID}" 2>/dev/null; then
+			echo "mysqld exited before accepting connections"
+			exit 1
+		fi
+		sleep 1
+	done
+}
+
+stop_mysqld() {
+	mysqladmin --socket="${SOCKET}" shutdown || kill "${MYSQLD_PID}"
+	wait "${MYSQLD_PID}" || true
+}
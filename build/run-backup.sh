@@ -3,13 +3,27 @@
 set -e
 
 SIDECAR_PORT="6450"
+BACKUP_DIR=${BACKUP_DIR:-/backup}
+BACKUP_STREAM="xtrabackup.stream"
+RETRY_MAX_ATTEMPTS=${BACKUP_RETRY_MAX_ATTEMPTS:-0}
+RETRY_INITIAL_INTERVAL=${BACKUP_RETRY_INITIAL_INTERVAL:-10}
+RETRY_MAX_INTERVAL=${BACKUP_RETRY_MAX_INTERVAL:-600}
+attempts=0
 
+# request_data prints the backup request. It contains the encryption key, the SSE-C key
+# and the Azure customer key, so it's passed to curl on stdin to keep it out of the process list.
 request_data() {
 	case "${STORAGE_TYPE}" in
 		"s3")
 			cat <<-EOF
 				{
 				    "destination": "$(json_escape "${BACKUP_DEST}")",
+				    "encryption": {
+				        "cipher": "$(json_escape "${XB_ENCRYPT_CIPHER}")",
+				        "key": "$(json_escape "${XB_ENCRYPT_KEY}")"
+				    },
+				    "incrementalBaseDestination": "$(json_escape "${INCREMENTAL_BASE_DEST}")",
+				    "throttle": $(throttle_data),
 				    "type": "$(json_escape "${STORAGE_TYPE}")",
 				    "verifyTLS": $(json_escape "${VERIFY_TLS}"),
 				    "s3": {
@@ -18,8 +32,18 @@ request_data() {
 				        "accessKey": "$(json_escape "${AWS_ACCESS_KEY_ID}")",
 				        "secretKey": "$(json_escape "${AWS_SECRET_ACCESS_KEY}")",
 				        "region": "$(json_escape "${AWS_DEFAULT_REGION}")",
+				        "roleArn": "$(json_escape "${S3_ROLE_ARN}")",
+				        "webIdentityTokenFile": "$(json_escape "${S3_WEB_IDENTITY_TOKEN_FILE}")",
+				        "stsEndpoint": "$(json_escape "${S3_STS_ENDPOINT}")",
+				        "sseAlgorithm": "$(json_escape "${S3_SSE_ALGORITHM}")",
+				        "kmsKeyID": "$(json_escape "${S3_SSE_KMS_KEY_ID}")",
+				        "sseCustomerKey": "$(json_escape "${S3_SSE_CUSTOMER_KEY}")",
+				        "objectLockMode": "$(json_escape "${S3_OBJECT_LOCK_MODE}")",
+				        "objectLockRetainFor": "$(json_escape "${S3_OBJECT_LOCK_RETAIN_FOR}")",
+				        "objectLockLegalHold": ${S3_OBJECT_LOCK_LEGAL_HOLD:-false},
 				        "storageClass": "$(json_escape "${S3_STORAGE_CLASS}")"
-				    }
+				    },
+				    "tags": "$(json_escape "${OBJECT_TAGS}")"
 				}
 			EOF
 			;;
@@ -27,15 +51,24 @@ request_data() {
 			cat <<-EOF
 				{
 				    "destination": "$(json_escape "${BACKUP_DEST}")",
+				    "encryption": {
+				        "cipher": "$(json_escape "${XB_ENCRYPT_CIPHER}")",
+				        "key": "$(json_escape "${XB_ENCRYPT_KEY}")"
+				    },
+				    "incrementalBaseDestination": "$(json_escape "${INCREMENTAL_BASE_DEST}")",
 				    "verifyTLS": $(json_escape "${VERIFY_TLS}"),
+				    "throttle": $(throttle_data),
 				    "type": "$(json_escape "${STORAGE_TYPE}")",
 				    "gcs": {
 				        "bucket": "$(json_escape "${GCS_BUCKET}")",
 				        "endpointUrl": "$(json_escape "${GCS_ENDPOINT}")",
 				        "accessKey": "$(json_escape "${ACCESS_KEY_ID}")",
 				        "secretKey": "$(json_escape "${SECRET_ACCESS_KEY}")",
+				        "serviceAccountKey": "$(json_escape "${GCS_SERVICE_ACCOUNT_KEY}")",
+				        "kmsKeyName": "$(json_escape "${GCS_KMS_KEY_NAME}")",
 				        "storageClass": "$(json_escape "${GCS_STORAGE_CLASS}")"
-				    }
+				    },
+				    "tags": "$(json_escape "${OBJECT_TAGS}")"
 				}
 			EOF
 			;;
@@ -43,26 +76,87 @@ request_data() {
 			cat <<-EOF
 				{
 				    "destination": "$(json_escape "${BACKUP_DEST}")",
+				    "encryption": {
+				        "cipher": "$(json_escape "${XB_ENCRYPT_CIPHER}")",
+				        "key": "$(json_escape "${XB_ENCRYPT_KEY}")"
+				    },
+				    "incrementalBaseDestination": "$(json_escape "${INCREMENTAL_BASE_DEST}")",
 				    "verifyTLS": $(json_escape "${VERIFY_TLS}"),
+				    "throttle": $(throttle_data),
 				    "type": "$(json_escape "${STORAGE_TYPE}")",
 				    "azure": {
 				        "containerName": "$(json_escape "${AZURE_CONTAINER_NAME}")",
 				        "storageAccount": "$(json_escape "${AZURE_STORAGE_ACCOUNT}")",
 				        "accessKey": "$(json_escape "${AZURE_ACCESS_KEY}")",
+				        "sasToken": "$(json_escape "${AZURE_SAS_TOKEN}")",
 				        "endpointUrl": "$(json_escape "${AZURE_ENDPOINT}")",
+				        "encryptionScope": "$(json_escape "${AZURE_ENCRYPTION_SCOPE}")",
+				        "customerKey": "$(json_escape "${AZURE_CUSTOMER_KEY}")",
+				        "immutabilityMode": "$(json_escape "${AZURE_IMMUTABILITY_MODE}")",
+				        "immutabilityRetainFor": "$(json_escape "${AZURE_IMMUTABILITY_RETAIN_FOR}")",
+				        "legalHold": ${AZURE_LEGAL_HOLD:-false},
 				        "storageClass": "$(json_escape "${AZURE_STORAGE_CLASS}")"
-				    }
+				    },
+				    "tags": "$(json_escape "${OBJECT_TAGS}")"
+				}
+			EOF
+			;;
+		"filesystem")
+			cat <<-EOF
+				{
+				    "destination": "$(json_escape "${BACKUP_DEST}")",
+				    "encryption": {
+				        "cipher": "$(json_escape "${XB_ENCRYPT_CIPHER}")",
+				        "key": "$(json_escape "${XB_ENCRYPT_KEY}")"
+				    },
+				    "throttle": $(throttle_data),
+				    "type": "$(json_escape "${STORAGE_TYPE}")"
 				}
 			EOF
 			;;
 	esac
 }
 
-# json_escape takes a string and replaces `\` to `\\` and `"` to `\"` to make it safe to insert provided argument into a json string
+throttle_data() {
+	cat <<-EOF
+		{
+		    "throttle": ${XB_THROTTLE:-0},
+		    "parallel": ${XB_PARALLEL:-0},
+		    "uploadBandwidth": ${UPLOAD_BANDWIDTH:-0}
+		}
+	EOF
+}
+
+# json_escape takes a string and replaces `\` to `\\`, `"` to `\"` and line breaks to `\n` and `\r` to make it safe to insert provided argument into a json string
 json_escape() {
 	escaped_backslash=${1//'\'/'\\'}
 	escaped_quotes=${escaped_backslash//'"'/'\"'}
-	echo -n "$escaped_quotes"
+	escaped_cr=${escaped_quotes//$'\r'/'\r'}
+	escaped_newlines=${escaped_cr//$'\n'/'\n'}
+	echo -n "$escaped_newlines"
+}
+
+# retry_after waits before the backup is requested again.
+# The job fails once the number of attempts allowed by the retry policy is made.
+retry_after() {
+	local sleep_duration=$1
+
+	attempts=$((attempts + 1))
+	if [ "${RETRY_MAX_ATTEMPTS}" -gt 0 ] && [ "${attempts}" -ge "${RETRY_MAX_ATTEMPTS}" ]; then
+		echo "Backup could not be started after ${attempts} attempts"
+		exit 1
+	fi
+
+	echo "Trying again after ${sleep_duration} seconds"
+	sleep "${sleep_duration}"
+}
+
+next_interval() {
+	local interval=$(($1 * 2))
+	if [ "${interval}" -gt "${RETRY_MAX_INTERVAL}" ]; then
+		interval=${RETRY_MAX_INTERVAL}
+	fi
+	echo -n "${interval}"
 }
 
 request_backup() {
@@ -71,8 +165,8 @@ request_backup() {
 
 	echo "Trying to run backup ${BACKUP_NAME} on ${SRC_NODE}"
 	http_code=$(
-		curl -s -o /dev/null \
-			-d "$(request_data)" \
+		request_data | curl -s -o /dev/null \
+			--data-binary @- \
 			-H "Content-Type: application/json" \
 			-w "httpcode=%{http_code}" \
 			"http://${SRC_NODE}:${SIDECAR_PORT}/backup/${BACKUP_NAME}" \
@@ -81,6 +175,10 @@ request_backup() {
 	if [ "${http_code}" -eq 200 ]; then
 		return
 	fi
+	if [ "${http_code}" -eq 410 ]; then
+		echo "Backup was canceled"
+		exit 1
+	fi
 	if [ "${http_code}" -eq 409 ]; then
 		echo "Backup is already running on ${SRC_NODE}"
 	else
@@ -89,12 +187,45 @@ request_backup() {
 		exit 1
 	fi
 
-	echo "Trying again after ${sleep_duration} seconds"
-	sleep "${sleep_duration}"
-	if [ "${sleep_duration}" -lt 600 ]; then
-		sleep_duration=$((sleep_duration * 2))
+	retry_after "${sleep_duration}"
+	request_backup "$(next_interval "${sleep_duration}")"
+}
+
+# request_stream receives the backup stream from the sidecar and stores it on the backup volume.
+# Sidecar aborts the connection if xtrabackup fails, so curl exits with an error in this case.
+request_stream() {
+	local sleep_duration=$1
+	local backup_dir="${BACKUP_DIR}/${BACKUP_DEST}"
+	local http_code
+
+	mkdir -p "${backup_dir}"
+	rm -f "${backup_dir}/${BACKUP_STREAM}"
+
+	echo "Trying to run backup ${BACKUP_NAME} on ${SRC_NODE}"
+	if ! http_code=$(
+		request_data | curl -s -o "${backup_dir}/${BACKUP_STREAM}" \
+			--data-binary @- \
+			-H "Content-Type: application/json" \
+			-w "%{http_code}" \
+			"http://${SRC_NODE}:${SIDECAR_PORT}/backup/${BACKUP_NAME}"
+	); then
+		echo "Backup stream was interrupted. Check logs to troubleshoot:"
+		echo "kubectl logs ${SRC_NODE%%.*} xtrabackup"
+		exit 1
 	fi
-	request_backup "${sleep_duration}"
+	if [ "${http_code}" -eq 200 ]; then
+		return
+	fi
+	if [ "${http_code}" -eq 409 ]; then
+		echo "Backup is already running on ${SRC_NODE}"
+	else
+		echo "Backup failed. Check logs to troubleshoot:"
+		echo "kubectl logs ${SRC_NODE%%.*} xtrabackup"
+		exit 1
+	fi
+
+	retry_after "${sleep_duration}"
+	request_stream "$(next_interval "${sleep_duration}")"
 }
 
 request_logs() {
@@ -102,7 +233,11 @@ request_logs() {
 }
 
 main() {
-	request_backup 10
+	if [ "${STORAGE_TYPE}" == "filesystem" ]; then
+		request_stream "${RETRY_INITIAL_INTERVAL}"
+	else
+		request_backup "${RETRY_INITIAL_INTERVAL}"
+	fi
 	request_logs
 
 	echo "Backup finished and uploaded successfully to ${BACKUP_DEST}"
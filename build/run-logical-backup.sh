@@ -0,0 +1,45 @@
+#!/bin/bash
+
+set -e
+set -o pipefail
+
+BACKUP_DIR=${BACKUP_DIR:-/backup}
+DUMP_DIR=${DUMP_DIR:-/var/lib/mysql/dump}
+CREDS_DIR=${CREDS_DIR:-/etc/mysql/mysql-users-secret}
+PARALLEL=${PARALLEL:-${XB_PARALLEL:-0}}
+if [ "${PARALLEL}" -le 0 ]; then
+	PARALLEL=$(grep -c processor /proc/cpuinfo)
+fi
+MYSQL_USER=${MYSQL_USER:-operator}
+
+dump() {
+	local target=$1
+
+	echo "Dumping ${SRC_NODE} to ${target}"
+	mysqlsh --passwords-from-stdin \
+		--uri="${MYSQL_USER}@${SRC_NODE}:3306" \
+		-- util dump-instance "${target}" \
+		--threads="${PARALLEL}" \
+		--consistent=true \
+		--showProgress=false \
+		<"${CREDS_DIR}/${MYSQL_USER}"
+}
+
+main() {
+	echo "Starting logical backup ${BACKUP_NAME}"
+
+	if [ "${STORAGE_TYPE}" == "filesystem" ]; then
+		mkdir -p "$(dirname "${BACKUP_DIR}/${BACKUP_DEST}")"
+		rm -rf "${BACKUP_DIR:?}/${BACKUP_DEST}"
+		dump "${BACKUP_DIR}/${BACKUP_DEST}"
+	else
+		rm -rf "${DUMP_DIR}"
+		dump "${DUMP_DIR}"
+		/opt/percona/backup-stream put-dir "${BACKUP_DEST}" "${DUMP_DIR}"
+		rm -rf "${DUMP_DIR}"
+	fi
+
+	echo "Backup finished and uploaded successfully to ${BACKUP_DEST}"
+}
+
+main
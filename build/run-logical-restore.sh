@@ -0,0 +1,105 @@
+#!/bin/bash
+
+set -e
+set -o pipefail
+
+BACKUP_DIR=${BACKUP_DIR:-/backup}
+DUMP_DIR=${DUMP_DIR:-/var/lib/mysql/dump}
+CREDS_DIR=${CREDS_DIR:-/etc/mysql/mysql-users-secret}
+PARALLEL=${PARALLEL:-$(grep -c processor /proc/cpuinfo)}
+MYSQL_USER=${MYSQL_USER:-operator}
+
+# Partial restores load only the selected objects. Lists are comma-separated.
+FILTER_ARGS=()
+if [ -n "${INCLUDE_DATABASES}" ]; then
+	FILTER_ARGS+=("--includeSchemas=${INCLUDE_DATABASES}")
+fi
+if [ -n "${EXCLUDE_DATABASES}" ]; then
+	FILTER_ARGS+=("--excludeSchemas=${EXCLUDE_DATABASES}")
+fi
+if [ -n "${INCLUDE_TABLES}" ]; then
+	FILTER_ARGS+=("--includeTables=${INCLUDE_TABLES}")
+fi
+if [ -n "${EXCLUDE_TABLES}" ]; then
+	FILTER_ARGS+=("--excludeTables=${EXCLUDE_TABLES}")
+fi
+
+mysql_exec() {
+	local host=$1
+	local query=$2
+
+	MYSQL_PWD="$(<"${CREDS_DIR}/${MYSQL_USER}")" mysql \
+		--host="${host}" --port=3306 --user="${MYSQL_USER}" \
+		--batch --skip-column-names --execute="${query}"
+}
+
+# load_dump loads the dump into the host with MySQL Shell. Extra arguments are passed to util load-dump.
+load_dump() {
+	local host=$1
+	local dump_dir=$2
+	shift 2
+
+	mysqlsh --passwords-from-stdin \
+		--uri="${MYSQL_USER}@${host}:3306" \
+		-- util load-dump "${dump_dir}" \
+		--threads="${PARALLEL}" \
+		--ignoreVersion=true \
+		--skipBinlog=false \
+		--showProgress=false \
+		"${FILTER_ARGS[@]}" \
+		"$@" \
+		<"${CREDS_DIR}/${MYSQL_USER}"
+}
+
+# primary returns the host of MYSQL_HOSTS which accepts writes.
+primary() {
+	local host
+	for host in ${MYSQL_HOSTS}; do
+		if [ "$(mysql_exec "${host}" 'SELECT @@super_read_only' 2>/dev/null)" == "0" ]; then
+			echo "${host}"
+			return
+		fi
+	done
+	return 1
+}
+
+main() {
+	echo "Starting logical restore ${RESTORE_NAME}"
+	echo "Restoring to backup: ${BACKUP_DEST}"
+
+	local dump_dir="${DUMP_DIR}"
+	if [ "${STORAGE_TYPE}" == "filesystem" ]; then
+		dump_dir="${BACKUP_DIR}/${BACKUP_DEST}"
+	else
+		rm -rf "${DUMP_DIR}"
+		/opt/percona/backup-stream get-dir "${BACKUP_DEST}" "${DUMP_DIR}"
+	fi
+
+	# host and local_infile are global: they are used by the EXIT trap.
+	if ! host=$(primary); then
+		echo "Can't find the primary among ${MYSQL_HOSTS}"
+		exit 1
+	fi
+	echo "Loading dump into ${host}"
+
+	# load-dump doesn't replace existing objects, the dry run checks that none of them
+	# exist on the primary before anything is loaded.
+	if ! load_dump "${host}" "${dump_dir}" --dryRun=true; then
+		echo "Dump can't be loaded into ${host}: objects of the dump which already exist have to be dropped or excluded with spec.partial of the restore"
+		exit 1
+	fi
+
+	local_infile=$(mysql_exec "${host}" 'SELECT @@GLOBAL.local_infile')
+	mysql_exec "${host}" 'SET GLOBAL local_infile=ON'
+	trap 'mysql_exec "${host}" "SET GLOBAL local_infile=${local_infile}"' EXIT
+
+	load_dump "${host}" "${dump_dir}"
+
+	if [ "${STORAGE_TYPE}" != "filesystem" ]; then
+		rm -rf "${DUMP_DIR}"
+	fi
+
+	echo "Restore finished"
+}
+
+main
@@ -0,0 +1,50 @@
+#!/bin/bash
+
+set -e
+
+DUMP_DIR=${DUMP_DIR:-/dump}
+SOCKET=/tmp/partial-mysqld.sock
+PARALLEL=${PARALLEL:-$(grep -c processor /proc/cpuinfo)}
+
+# shellcheck source=build/restored-mysqld.sh
+. /opt/percona/restored-mysqld.sh
+
+FILTER_ARGS=()
+if [ -n "${INCLUDE_DATABASES}" ]; then
+	FILTER_ARGS+=("--includeSchemas=${INCLUDE_DATABASES}")
+fi
+if [ -n "${EXCLUDE_DATABASES}" ]; then
+	FILTER_ARGS+=("--excludeSchemas=${EXCLUDE_DATABASES}")
+fi
+if [ -n "${INCLUDE_TABLES}" ]; then
+	FILTER_ARGS+=("--includeTables=${INCLUDE_TABLES}")
+fi
+if [ -n "${EXCLUDE_TABLES}" ]; then
+	FILTER_ARGS+=("--excludeTables=${EXCLUDE_TABLES}")
+fi
+
+main() {
+	echo "Starting partial restore ${RESTORE_NAME} from ${BACKUP_DEST}"
+
+	start_mysqld
+
+	rm -rf "${DUMP_DIR:?}/partial"
+	if ! mysqlsh --socket="${SOCKET}" --user=root --no-password \
+		-- util dump-instance "${DUMP_DIR}/partial" \
+		--threads="${PARALLEL}" \
+		--consistent=false \
+		--users=false \
+		--showProgress=false \
+		"${FILTER_ARGS[@]}"; then
+		echo "Failed to dump selected objects"
+		stop_mysqld
+		exit 1
+	fi
+	stop_mysqld
+
+	# The dump is loaded the same way as a logical backup stored on a filesystem.
+	STORAGE_TYPE=filesystem BACKUP_DIR="${DUMP_DIR}" BACKUP_DEST=partial \
+		/opt/percona/run-logical-restore.sh
+}
+
+main
@@ -1,9 +1,11 @@
 #!/bin/bash
 
 set -e
+set -o pipefail
 set -o xtrace
 
 DATADIR=${DATADIR:-/var/lib/mysql}
+BACKUP_DIR=${BACKUP_DIR:-/backup}
 PARALLEL=$(grep -c processor /proc/cpuinfo)
 XBCLOUD_ARGS="--curl-retriable-errors=7 --parallel=${PARALLEL}"
 if [ -n "$VERIFY_TLS" ] && [[ $VERIFY_TLS == "false" ]]; then
@@ -11,21 +13,52 @@ if [ -n "$VERIFY_TLS" ] && [[ $VERIFY_TLS == "false" ]]; then
 fi
 
 run_s3() {
-	xbcloud get "${XBCLOUD_ARGS}" "${BACKUP_DEST}" --storage=s3 --s3-bucket="${S3_BUCKET}"
+	xbcloud get "${XBCLOUD_ARGS}" "$1" --storage=s3 --s3-bucket="${S3_BUCKET}"
 }
 
 run_gcs() {
-	xbcloud get "${XBCLOUD_ARGS}" "${BACKUP_DEST}" --storage=google --google-bucket="${GCS_BUCKET}"
+	xbcloud get "${XBCLOUD_ARGS}" "$1" --storage=google --google-bucket="${GCS_BUCKET}"
 }
 
 run_azure() {
-	xbcloud get "${XBCLOUD_ARGS}" "${BACKUP_DEST}" --storage=azure
+	xbcloud get "${XBCLOUD_ARGS}" "$1" --storage=azure
+}
+
+run_filesystem() {
+	cat "${BACKUP_DIR}/$1/xtrabackup.stream"
 }
 
 extract() {
 	local targetdir=$1
 
-	xbstream -xv -C "${targetdir}" --parallel="${PARALLEL}"
+	if [ -n "${XB_ENCRYPT_CIPHER}" ]; then
+		xbstream -xv -C "${targetdir}" --parallel="${PARALLEL}" \
+			--decrypt="${XB_ENCRYPT_CIPHER}" --encrypt-key-file="${KEY_FILE}"
+	else
+		xbstream -xv -C "${targetdir}" --parallel="${PARALLEL}"
+	fi
+}
+
+fetch() {
+	local dest=$1
+	local targetdir=$2
+
+	if [ "${STORAGE_TYPE}" == "filesystem" ]; then
+		run_filesystem "${dest}" | extract "${targetdir}"
+		return
+	fi
+
+	if /opt/percona/backup-stream exists "${dest}"; then
+		/opt/percona/backup-stream get "${dest}" | extract "${targetdir}"
+		return
+	fi
+
+	# Backups taken before the sidecar started to upload them itself are in xbcloud format.
+	case ${STORAGE_TYPE} in
+		"s3") run_s3 "${dest}" | extract "${targetdir}" ;;
+		"gcs") run_gcs "${dest}" | extract "${targetdir}" ;;
+		"azure") run_azure "${dest}" | extract "${targetdir}" ;;
+	esac
 }
 
 main() {
@@ -33,18 +66,41 @@ main() {
 	echo "Restoring to backup: ${BACKUP_DEST}"
 
 	rm -rf "${DATADIR:?}"/*
+
+	if [ -n "${XB_ENCRYPT_CIPHER}" ]; then
+		# Key is written to a file to keep it out of the process list and xtrace output.
+		set +o xtrace
+		KEY_FILE=$(mktemp)
+		printf '%s' "${XB_ENCRYPT_KEY}" >"${KEY_FILE}"
+		set -o xtrace
+	fi
 	tmpdir=$(mktemp --directory "${DATADIR}/${RESTORE_NAME}_XXXX")
 
-	case ${STORAGE_TYPE} in
-		"s3") run_s3 | extract "${tmpdir}" ;;
-		"gcs") run_gcs | extract "${tmpdir}" ;;
-		"azure") run_azure | extract "${tmpdir}" ;;
-	esac
+	# BACKUP_CHAIN contains the full backup and incremental backups
+	# the restored backup is taken on top of, in the order they were taken.
+	read -r -a chain <<<"${BACKUP_CHAIN}"
+	if [ ${#chain[@]} -eq 0 ]; then
+		fetch "${BACKUP_DEST}" "${tmpdir}"
+	else
+		echo "Restoring chain: ${BACKUP_CHAIN}"
+		fetch "${chain[0]}" "${tmpdir}"
+		xtrabackup --prepare --apply-log-only --target-dir="${tmpdir}"
+
+		for dest in "${chain[@]:1}" "${BACKUP_DEST}"; do
+			incdir=$(mktemp --directory "${DATADIR}/${RESTORE_NAME}_inc_XXXX")
+			fetch "${dest}" "${incdir}"
+			xtrabackup --prepare --apply-log-only --target-dir="${tmpdir}" --incremental-dir="${incdir}"
+			rm -rf "${incdir}"
+		done
+	fi
 
 	xtrabackup --prepare --rollback-prepared-trx --target-dir="${tmpdir}"
 	xtrabackup --datadir="${DATADIR}" --move-back --force-non-empty-directories --target-dir="${tmpdir}"
 
 	rm -rf "${tmpdir}"
+	if [ -n "${KEY_FILE}" ]; then
+		rm -f "${KEY_FILE}"
+	fi
 
 	echo "Restore finished"
 }
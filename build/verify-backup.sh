@@ -0,0 +1,27 @@
+#!/bin/bash
+
+set -e
+set -o xtrace
+
+SOCKET=/tmp/verify-mysqld.sock
+VERIFY_QUERY=${VERIFY_QUERY:-"SELECT COUNT(*) FROM information_schema.tables"}
+
+# shellcheck source=build/restored-mysqld.sh
+. /opt/percona/restored-mysqld.sh
+
+main() {
+	echo "Verifying backup ${BACKUP_DEST}"
+
+	start_mysqld --log-error-verbosity=3
+
+	if ! mysql --socket="${SOCKET}" --batch --execute="${VERIFY_QUERY}"; then
+		echo "Verification query failed"
+		stop_mysqld
+		exit 1
+	fi
+
+	stop_mysqld
+	echo "Backup verified"
+}
+
+main
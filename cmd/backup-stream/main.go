@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+
+	xb "github.com/percona/percona-server-mysql-operator/pkg/xtrabackup"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/cloud"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
+)
+
+// backup-stream reads backups uploaded by the sidecar and transfers logical backups.
+// Storage is configured with the same environment variables as the backup and restore jobs.
+// The storage copy reads the backup to is configured with the same variables prefixed with TARGET_.
+// UPLOAD_BANDWIDTH limits the number of bytes per second put-dir and copy upload.
+//
+//	backup-stream get <destination>                    writes the xbstream to stdout
+//	backup-stream exists <destination>                 exits with 1 if there is no complete backup
+//	backup-stream put-dir <destination> <dir>          uploads files of the logical backup
+//	backup-stream get-dir <destination> <dir>          downloads files of the logical backup
+//	backup-stream copy <destination> <target-dest>     copies the backup to the target storage
+func main() {
+	if len(os.Args) < 3 || len(os.Args) > 4 {
+		usage()
+	}
+	cmd, dest := os.Args[1], os.Args[2]
+
+	var arg string
+	switch cmd {
+	case "put-dir", "get-dir", "copy":
+		if len(os.Args) != 4 {
+			usage()
+		}
+		arg = os.Args[3]
+	default:
+		if len(os.Args) != 3 {
+			usage()
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	if err := run(ctx, cmd, dest, arg); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s: %v\n", cmd, dest, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: backup-stream get|exists <destination>")
+	fmt.Fprintln(os.Stderr, "       backup-stream put-dir|get-dir <destination> <dir>")
+	fmt.Fprintln(os.Stderr, "       backup-stream copy <destination> <target-destination>")
+	os.Exit(2)
+}
+
+func run(ctx context.Context, cmd, dest, arg string) error {
+	opts, err := storage.GetOptionsFromEnv("")
+	if err != nil {
+		return errors.Wrap(err, "get storage options")
+	}
+	stg, err := storage.NewClient(ctx, opts)
+	if err != nil {
+		return errors.Wrap(err, "new storage")
+	}
+
+	switch cmd {
+	case "get":
+		return cloud.Download(ctx, stg, dest, os.Stdout, cloud.Options{})
+	case "exists":
+		_, err := cloud.GetManifest(ctx, stg, dest)
+		return err
+	case "put-dir":
+		opts, err := uploadOptions()
+		if err != nil {
+			return err
+		}
+		return cloud.UploadDir(ctx, stg, dest, arg, opts)
+	case "get-dir":
+		return cloud.DownloadDir(ctx, stg, dest, arg, cloud.Options{})
+	case "copy":
+		return copyBackup(ctx, stg, dest, arg)
+	default:
+		return errors.Errorf("unknown command %s", cmd)
+	}
+}
+
+func uploadOptions() (cloud.Options, error) {
+	opts := cloud.Options{}
+	if v := os.Getenv("UPLOAD_BANDWIDTH"); v != "" {
+		bandwidth, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return opts, errors.Wrap(err, "parse UPLOAD_BANDWIDTH")
+		}
+		opts.Bandwidth = bandwidth
+	}
+	return opts, nil
+}
+
+func copyBackup(ctx context.Context, src storage.Storage, dest, targetDest string) error {
+	targetOpts, err := storage.GetTargetOptionsFromEnv("")
+	if err != nil {
+		return errors.Wrap(err, "get target storage options")
+	}
+	dst, err := storage.NewClient(ctx, targetOpts)
+	if err != nil {
+		return errors.Wrap(err, "new target storage")
+	}
+
+	opts, err := uploadOptions()
+	if err != nil {
+		return err
+	}
+
+	// Objects stored next to the backup are copied before it,
+	// incremental backups taken on the target storage need the checkpoints of their base.
+	for _, name := range []string{dest + ".md5", xb.CheckpointsObjectName(dest)} {
+		target := targetDest + strings.TrimPrefix(name, dest)
+		if err := cloud.CopyObject(ctx, src, dst, name, target, opts); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+			return err
+		}
+	}
+
+	return cloud.Copy(ctx, src, dst, dest, targetDest, opts)
+}
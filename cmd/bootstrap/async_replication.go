@@ -253,13 +253,40 @@ func selectDonor(ctx context.Context, fqdn, primary string, replicas []string) (
 		return "", errors.Wrapf(err, "get %s password", apiv1alpha1.UserOperator)
 	}
 
+	primaryGTIDs := ""
+	if primary != "" && fqdn != primary {
+		db, err := database.NewDatabase(ctx, apiv1alpha1.UserOperator, operatorPass, primary, mysql.DefaultAdminPort)
+		if err == nil {
+			primaryGTIDs, err = db.GTIDExecuted(ctx)
+			db.Close()
+			if err != nil {
+				return "", errors.Wrapf(err, "get gtid_executed of %s", primary)
+			}
+		}
+	}
+
 	for _, replica := range replicas {
 		db, err := database.NewDatabase(ctx, apiv1alpha1.UserOperator, operatorPass, replica, mysql.DefaultAdminPort)
 		if err != nil {
 			continue
 		}
+
+		// A replica with errant transactions would pass them on to the clone
+		// and a replica stopped by an error may have diverged from the primary.
+		healthy := true
+		if fqdn != replica {
+			healthy, err = isHealthyDonor(ctx, db, primaryGTIDs)
+			if err != nil {
+				log.Printf("Failed to check donor %s: %v", replica, err)
+			}
+		}
 		db.Close()
 
+		if !healthy {
+			log.Printf("Skipping unhealthy donor %s", replica)
+			continue
+		}
+
 		if fqdn != replica {
 			donor = replica
 			break
@@ -273,6 +300,23 @@ func selectDonor(ctx context.Context, fqdn, primary string, replicas []string) (
 	return donor, nil
 }
 
+func isHealthyDonor(ctx context.Context, db *database.DB, primaryGTIDs string) (bool, error) {
+	stopped, err := db.IsApplierStoppedByError(ctx)
+	if err != nil || stopped {
+		return false, err
+	}
+
+	if primaryGTIDs == "" {
+		return true, nil
+	}
+
+	errant, err := db.HasErrantTransactions(ctx, primaryGTIDs)
+	if err != nil {
+		return false, err
+	}
+	return !errant, nil
+}
+
 func isCloneRequired(file string) (bool, error) {
 	_, err := os.Stat(file)
 	if err != nil {
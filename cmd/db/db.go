@@ -133,6 +133,34 @@ func (d *DB) ReportHost(ctx context.Context) (string, error) {
 	return reportHost, errors.Wrap(err, "select report_host param")
 }
 
+func (d *DB) GTIDExecuted(ctx context.Context) (string, error) {
+	var gtid string
+	err := d.db.QueryRowContext(ctx, "SELECT REPLACE(@@GLOBAL.gtid_executed, '\\n', '')").Scan(&gtid)
+	return gtid, errors.Wrap(err, "select gtid_executed")
+}
+
+// HasErrantTransactions checks if the server executed transactions that aren't in the GTID set.
+func (d *DB) HasErrantTransactions(ctx context.Context, gtidSet string) (bool, error) {
+	var subset bool
+	err := d.db.QueryRowContext(ctx, "SELECT GTID_SUBSET(@@GLOBAL.gtid_executed, ?)", gtidSet).Scan(&subset)
+	return !subset, errors.Wrap(err, "select GTID_SUBSET")
+}
+
+// IsApplierStoppedByError checks if the applier of the default channel is stopped by an error.
+func (d *DB) IsApplierStoppedByError(ctx context.Context) (bool, error) {
+	var stopped bool
+	err := d.db.QueryRowContext(ctx, `
+        SELECT COUNT(*) > 0
+        FROM replication_applier_status applier_status
+        JOIN replication_applier_status_by_worker worker
+            ON applier_status.channel_name = worker.channel_name
+        WHERE applier_status.channel_name = ?
+            AND applier_status.SERVICE_STATE = 'OFF'
+            AND worker.LAST_ERROR_NUMBER <> 0
+        `, defaultChannelName).Scan(&stopped)
+	return stopped, errors.Wrap(err, "check applier error")
+}
+
 func (d *DB) Close() error {
 	return d.db.Close()
 }
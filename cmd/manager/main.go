@@ -42,6 +42,7 @@ import (
 	"github.com/percona/percona-server-mysql-operator/pkg/controller/ps"
 	"github.com/percona/percona-server-mysql-operator/pkg/controller/psbackup"
 	"github.com/percona/percona-server-mysql-operator/pkg/controller/psrestore"
+	"github.com/percona/percona-server-mysql-operator/pkg/controller/psswitchover"
 	"github.com/percona/percona-server-mysql-operator/pkg/k8s"
 	"github.com/percona/percona-server-mysql-operator/pkg/platform"
 	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup"
@@ -149,12 +150,13 @@ func main() {
 	}
 
 	if err = (&ps.PerconaServerMySQLReconciler{
-		Client:        nsClient,
-		Scheme:        mgr.GetScheme(),
-		ServerVersion: serverVersion,
-		Recorder:      mgr.GetEventRecorderFor("ps-controller"),
-		ClientCmd:     cliCmd,
-		Crons:         ps.NewCronRegistry(),
+		Client:           nsClient,
+		Scheme:           mgr.GetScheme(),
+		ServerVersion:    serverVersion,
+		Recorder:         mgr.GetEventRecorderFor("ps-controller"),
+		ClientCmd:        cliCmd,
+		NewStorageClient: storage.NewClient,
+		Crons:            ps.NewCronRegistry(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ps-controller")
 		os.Exit(1)
@@ -164,7 +166,9 @@ func main() {
 		Scheme:           mgr.GetScheme(),
 		ServerVersion:    serverVersion,
 		ClientCmd:        cliCmd,
+		APIReader:        mgr.GetAPIReader(),
 		NewSidecarClient: xtrabackup.NewSidecarClient,
+		NewStorageClient: storage.NewClient,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "PerconaServerMySQLBackup")
 		os.Exit(1)
@@ -173,11 +177,20 @@ func main() {
 		Client:           nsClient,
 		Scheme:           mgr.GetScheme(),
 		ServerVersion:    serverVersion,
+		ClientCmd:        cliCmd,
 		NewStorageClient: storage.NewClient,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "PerconaServerMySQLRestore")
 		os.Exit(1)
 	}
+	if err = (&psswitchover.PerconaServerMySQLSwitchoverReconciler{
+		Client:    nsClient,
+		Scheme:    mgr.GetScheme(),
+		ClientCmd: cliCmd,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PerconaServerMySQLSwitchover")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	binlogObjectPrefix = "binlog_"
+	gtidSetSuffix      = ".gtid-set"
+	// gtidExecutedObject keeps the union of GTID sets of all binary logs uploaded to the timeline.
+	gtidExecutedObject = "gtid-executed"
+	// timelineStartObject keeps Previous_gtids of the first binary log uploaded to the timeline,
+	// i.e. the transactions the history of the timeline starts from.
+	timelineStartObject = "gtid-start"
+	timelinePrefix      = "timeline-"
+
+	eventHeaderLen = 19
+)
+
+var binlogMagic = []byte{0xfe, 'b', 'i', 'n'}
+
+// gtidSets does arithmetic on GTID sets. It's implemented by DB, which delegates it to MySQL.
+type gtidSets interface {
+	GTIDSubset(ctx context.Context, set1, set2 string) (bool, error)
+	GTIDSubtract(ctx context.Context, set1, set2 string) (string, error)
+	GTIDUnion(ctx context.Context, set1, set2 string) (string, error)
+}
+
+// binlogObject is a binary log along with the GTID set of the transactions it contains.
+type binlogObject struct {
+	name    string
+	gtidSet string
+}
+
+// binlogObjectName returns the name a binary log is stored under.
+// Names sort in the order binary logs have to be applied.
+func binlogObjectName(firstEventTS uint32, gtidSet string) string {
+	return fmt.Sprintf("%s%d_%x", binlogObjectPrefix, firstEventTS, md5.Sum([]byte(gtidSet)))
+}
+
+// binlogObjectTimestamp returns the timestamp encoded in the object name.
+func binlogObjectTimestamp(name string) (int64, error) {
+	parts := strings.Split(strings.TrimPrefix(name, binlogObjectPrefix), "_")
+	ts, err := strconv.ParseInt(parts[0], 10, 64)
+	return ts, errors.Wrapf(err, "parse timestamp of %s", name)
+}
+
+// timelineDir returns the directory binary logs of the timeline are stored in.
+// Timelines are identified by the creation time of the restore that started them,
+// the timeline of the cluster that was never restored is 0.
+func timelineDir(id int64) string {
+	return timelinePrefix + strconv.FormatInt(id, 10) + "/"
+}
+
+// timelineIDs returns sorted IDs of the timelines the listed objects belong to.
+func timelineIDs(objects []string) []int64 {
+	var ids []int64
+	for _, obj := range objects {
+		dir, _, found := strings.Cut(strings.TrimPrefix(obj, "/"), "/")
+		if !found || !strings.HasPrefix(dir, timelinePrefix) {
+			continue
+		}
+		id, err := strconv.ParseInt(strings.TrimPrefix(dir, timelinePrefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// sortedBinlogObjects filters binary logs from the listed objects and sorts them by name.
+func sortedBinlogObjects(objects []string) []string {
+	var binlogs []string
+	for _, obj := range objects {
+		obj = strings.TrimPrefix(obj, "/")
+		if strings.HasPrefix(obj, binlogObjectPrefix) && !strings.HasSuffix(obj, gtidSetSuffix) {
+			binlogs = append(binlogs, obj)
+		}
+	}
+	sort.Strings(binlogs)
+	return binlogs
+}
+
+// firstEventTimestamp reads the timestamp of the first event with a non-zero timestamp in the binary log file.
+func firstEventTimestamp(path string) (uint32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, errors.Wrapf(err, "open %s", path)
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(binlogMagic))
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return 0, errors.Wrap(err, "read magic number")
+	}
+	if !bytes.Equal(magic, binlogMagic) {
+		return 0, errors.Errorf("%s is not a binary log", path)
+	}
+
+	header := make([]byte, eventHeaderLen)
+	for {
+		if _, err := io.ReadFull(f, header); err != nil {
+			return 0, errors.Wrap(err, "read event header")
+		}
+
+		if ts := binary.LittleEndian.Uint32(header[0:4]); ts != 0 {
+			return ts, nil
+		}
+
+		size := binary.LittleEndian.Uint32(header[9:13])
+		if size < eventHeaderLen {
+			return 0, errors.Errorf("invalid event size %d", size)
+		}
+		if _, err := f.Seek(int64(size-eventHeaderLen), io.SeekCurrent); err != nil {
+			return 0, errors.Wrap(err, "skip event")
+		}
+	}
+}
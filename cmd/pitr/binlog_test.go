@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBinlogObjectName(t *testing.T) {
+	name := binlogObjectName(1700000000, "uuid:1-10")
+	if name != binlogObjectName(1700000000, "uuid:1-10") {
+		t.Fatalf("object name isn't stable: %s", name)
+	}
+	if name == binlogObjectName(1700000000, "uuid:11-20") {
+		t.Fatalf("binary logs with different transactions have the same name %s", name)
+	}
+	if later := binlogObjectName(1700000001, "uuid:11-20"); later <= name {
+		t.Fatalf("object name %s of the later binary log sorts before %s", later, name)
+	}
+
+	ts, err := binlogObjectTimestamp(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts != 1700000000 {
+		t.Fatalf("expected timestamp 1700000000, got %d", ts)
+	}
+
+	if _, err := binlogObjectTimestamp(binlogObjectPrefix + "invalid"); err == nil {
+		t.Fatal("expected error for object name without timestamp")
+	}
+}
+
+func TestSortedBinlogObjects(t *testing.T) {
+	objects := []string{
+		"/binlog_1700000002_b",
+		"binlog_1700000002_b" + gtidSetSuffix,
+		gtidExecutedObject,
+		"binlog_1700000001_a",
+		"binlog_1700000001_a" + gtidSetSuffix,
+	}
+
+	expected := []string{"binlog_1700000001_a", "binlog_1700000002_b"}
+	if got := sortedBinlogObjects(objects); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestTimelineIDs(t *testing.T) {
+	objects := []string{
+		timelineDir(1700000000) + timelineStartObject,
+		timelineDir(1700000000) + "binlog_1700000001_a",
+		"/" + timelineDir(0) + "binlog_1600000000_b",
+		timelineDir(0) + gtidExecutedObject,
+		timelinePrefix + "invalid/" + gtidExecutedObject,
+		"binlog_1600000000_c",
+	}
+
+	expected := []int64{0, 1700000000}
+	if got := timelineIDs(objects); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestFirstEventTimestamp(t *testing.T) {
+	event := func(ts uint32, size uint32) []byte {
+		header := make([]byte, eventHeaderLen)
+		binary.LittleEndian.PutUint32(header[0:4], ts)
+		binary.LittleEndian.PutUint32(header[9:13], size)
+		return append(header, make([]byte, size-eventHeaderLen)...)
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected uint32
+		wantErr  bool
+	}{
+		{
+			name:     "first event has timestamp",
+			data:     bytes.Join([][]byte{binlogMagic, event(1700000000, 30)}, nil),
+			expected: 1700000000,
+		},
+		{
+			name:     "events without timestamp are skipped",
+			data:     bytes.Join([][]byte{binlogMagic, event(0, 40), event(0, eventHeaderLen), event(1700000001, 30)}, nil),
+			expected: 1700000001,
+		},
+		{
+			name:    "not a binary log",
+			data:    []byte("text file"),
+			wantErr: true,
+		},
+		{
+			name:    "no event with timestamp",
+			data:    bytes.Join([][]byte{binlogMagic, event(0, 40)}, nil),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "binlog.000001")
+			if err := os.WriteFile(path, tt.data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			ts, err := firstEventTimestamp(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ts != tt.expected {
+				t.Fatalf("expected %d, got %d", tt.expected, ts)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/pitr"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
+)
+
+func runCollector(ctx context.Context) error {
+	interval, err := strconv.Atoi(os.Getenv("TIME_BETWEEN_UPLOADS"))
+	if err != nil || interval <= 0 {
+		interval = 60
+	}
+
+	timeline, err := strconv.ParseInt(os.Getenv("BINLOG_TIMELINE"), 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "parse timeline")
+	}
+
+	stg, err := getStorage(ctx)
+	if err != nil {
+		return errors.Wrap(err, "get storage")
+	}
+	// History of the cluster diverges after a restore, binary logs written after it
+	// must not be mixed with the ones uploaded before.
+	stg.SetPrefix(stg.GetPrefix() + timelineDir(timeline))
+	log.Info("collecting binary logs", "timeline", timeline)
+
+	for {
+		if err := collect(ctx, stg); err != nil {
+			log.Error(err, "failed to collect binary logs")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Duration(interval) * time.Second):
+		}
+	}
+}
+
+func collect(ctx context.Context, stg storage.Storage) error {
+	host, err := getPrimary(ctx, apiv1alpha1.UserReplication)
+	if err != nil {
+		return errors.Wrap(err, "get primary")
+	}
+
+	db, err := connect(ctx, host, apiv1alpha1.UserReplication)
+	if err != nil {
+		return errors.Wrapf(err, "connect to %s", host)
+	}
+	defer db.Close()
+
+	uploaded, err := readObject(ctx, stg, gtidExecutedObject)
+	if err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+		return errors.Wrap(err, "read uploaded gtid set")
+	}
+
+	executed, err := db.GTIDExecuted(ctx)
+	if err != nil {
+		return err
+	}
+
+	diverged, err := historyDiverged(ctx, db, uploaded, executed)
+	if err != nil {
+		return err
+	}
+	if diverged {
+		log.Info("uploaded transactions are not executed on primary, starting over", "uploaded", uploaded, "executed", executed)
+		uploaded = ""
+	}
+
+	if subset, err := db.GTIDSubset(ctx, executed, uploaded); err != nil {
+		return err
+	} else if subset {
+		return nil
+	}
+
+	if err := db.FlushBinaryLogs(ctx); err != nil {
+		return err
+	}
+
+	binlogs, err := db.BinaryLogs(ctx)
+	if err != nil {
+		return err
+	}
+
+	previous := make([]string, len(binlogs))
+	for i, b := range binlogs {
+		previous[i], err = db.PreviousGTIDs(ctx, b.name)
+		if err != nil {
+			return err
+		}
+	}
+
+	pending, err := pendingBinlogs(ctx, db, binlogs, previous, uploaded)
+	if err != nil {
+		return err
+	}
+
+	// The first upload to the timeline records the transactions its history starts from.
+	if len(pending) > 0 {
+		_, err := readObject(ctx, stg, timelineStartObject)
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			i := slices.IndexFunc(binlogs, func(b binlogFile) bool { return b.name == pending[0].name })
+			err = writeObject(ctx, stg, timelineStartObject, previous[i])
+		}
+		if err != nil {
+			return errors.Wrap(err, "write timeline start")
+		}
+	}
+
+	for _, b := range pending {
+		if err := uploadBinlog(ctx, stg, host, b.name, b.gtidSet); err != nil {
+			return errors.Wrapf(err, "upload %s", b.name)
+		}
+
+		uploaded, err = db.GTIDUnion(ctx, uploaded, b.gtidSet)
+		if err != nil {
+			return err
+		}
+		if err := writeObject(ctx, stg, gtidExecutedObject, uploaded); err != nil {
+			return errors.Wrap(err, "write uploaded gtid set")
+		}
+
+		log.Info("binary log uploaded", "binlog", b.name, "gtidSet", b.gtidSet)
+	}
+
+	return nil
+}
+
+// historyDiverged returns true if some of the uploaded transactions aren't executed on the primary.
+// It happens if the cluster was restored to a point before the last uploaded transaction
+// or if the primary lost transactions on failover.
+func historyDiverged(ctx context.Context, gs gtidSets, uploaded, executed string) (bool, error) {
+	if uploaded == "" {
+		return false, nil
+	}
+
+	subset, err := gs.GTIDSubset(ctx, uploaded, executed)
+	if err != nil {
+		return false, err
+	}
+	return !subset, nil
+}
+
+// pendingBinlogs returns the binary logs with transactions that aren't uploaded yet
+// along with the GTID sets of the transactions they contain. previous holds Previous_gtids
+// of each binary log. The last binary log is the active one and is uploaded after the next flush.
+func pendingBinlogs(ctx context.Context, gs gtidSets, binlogs []binlogFile, previous []string, uploaded string) ([]binlogObject, error) {
+	var pending []binlogObject
+	for i := 0; i < len(binlogs)-1; i++ {
+		gtidSet, err := gs.GTIDSubtract(ctx, previous[i+1], previous[i])
+		if err != nil {
+			return nil, err
+		}
+		if gtidSet == "" {
+			continue
+		}
+
+		subset, err := gs.GTIDSubset(ctx, gtidSet, uploaded)
+		if err != nil {
+			return nil, err
+		}
+		if subset {
+			continue
+		}
+
+		pending = append(pending, binlogObject{name: binlogs[i].name, gtidSet: gtidSet})
+	}
+	return pending, nil
+}
+
+func uploadBinlog(ctx context.Context, stg storage.Storage, host, binlog, gtidSet string) error {
+	pass, err := getSecret(apiv1alpha1.UserReplication)
+	if err != nil {
+		return errors.Wrap(err, "get password")
+	}
+
+	dir := pitr.WorkMountPath + "/"
+	cmd := exec.CommandContext(ctx, "mysqlbinlog",
+		"--read-from-remote-server",
+		"--host="+host,
+		"--user="+string(apiv1alpha1.UserReplication),
+		"--raw",
+		"--result-file="+dir,
+		binlog,
+	)
+	cmd.Env = append(os.Environ(), "MYSQL_PWD="+pass)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "mysqlbinlog: %s", stderr.String())
+	}
+
+	path := filepath.Join(dir, binlog)
+	defer os.Remove(path)
+
+	ts, err := firstEventTimestamp(path)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "open %s", path)
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return errors.Wrapf(err, "stat %s", path)
+	}
+
+	name := binlogObjectName(ts, gtidSet)
+	if err := stg.PutObject(ctx, name, f, fi.Size()); err != nil {
+		return errors.Wrapf(err, "put %s", name)
+	}
+
+	return writeObject(ctx, stg, name+gtidSetSuffix, gtidSet)
+}
+
+func readObject(ctx context.Context, stg storage.Storage, name string) (string, error) {
+	r, err := stg.GetObject(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", errors.Wrapf(err, "read %s", name)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+func writeObject(ctx context.Context, stg storage.Storage, name, data string) error {
+	return stg.PutObject(ctx, name, strings.NewReader(data), int64(len(data)))
+}
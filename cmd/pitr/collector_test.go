@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// fakeGTIDSets does arithmetic on GTID sets written as comma separated single transactions, e.g. "a:1,a:2".
+type fakeGTIDSets struct{}
+
+func gtids(set string) []string {
+	if set == "" {
+		return nil
+	}
+	return strings.Split(set, ",")
+}
+
+func gtidSet(gtids []string) string {
+	sort.Strings(gtids)
+	return strings.Join(slices.Compact(gtids), ",")
+}
+
+func (fakeGTIDSets) GTIDSubset(_ context.Context, set1, set2 string) (bool, error) {
+	for _, gtid := range gtids(set1) {
+		if !slices.Contains(gtids(set2), gtid) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (fakeGTIDSets) GTIDSubtract(_ context.Context, set1, set2 string) (string, error) {
+	var res []string
+	for _, gtid := range gtids(set1) {
+		if !slices.Contains(gtids(set2), gtid) {
+			res = append(res, gtid)
+		}
+	}
+	return gtidSet(res), nil
+}
+
+func (fakeGTIDSets) GTIDUnion(_ context.Context, set1, set2 string) (string, error) {
+	return gtidSet(append(gtids(set1), gtids(set2)...)), nil
+}
+
+func TestHistoryDiverged(t *testing.T) {
+	tests := []struct {
+		name     string
+		uploaded string
+		executed string
+		expected bool
+	}{
+		{
+			name:     "nothing uploaded",
+			executed: "a:1",
+		},
+		{
+			name:     "primary is ahead",
+			uploaded: "a:1",
+			executed: "a:1,a:2",
+		},
+		{
+			name:     "everything is uploaded",
+			uploaded: "a:1,a:2",
+			executed: "a:1,a:2",
+		},
+		{
+			name:     "restored to a point before the last uploaded transaction",
+			uploaded: "a:1,a:2,a:3",
+			executed: "a:1",
+			expected: true,
+		},
+		{
+			name:     "new primary lost uploaded transactions",
+			uploaded: "a:1,a:2",
+			executed: "a:1,b:1",
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diverged, err := historyDiverged(context.Background(), fakeGTIDSets{}, tt.uploaded, tt.executed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diverged != tt.expected {
+				t.Fatalf("expected %t, got %t", tt.expected, diverged)
+			}
+		})
+	}
+}
+
+func TestPendingBinlogs(t *testing.T) {
+	binlogs := []binlogFile{{name: "binlog.000001"}, {name: "binlog.000002"}, {name: "binlog.000003"}, {name: "binlog.000004"}}
+	// binlog.000002 is empty, binlog.000004 is the active one.
+	previous := []string{"", "a:1,a:2", "a:1,a:2", "a:1,a:2,a:3"}
+
+	tests := []struct {
+		name     string
+		uploaded string
+		expected []binlogObject
+	}{
+		{
+			name: "nothing uploaded",
+			expected: []binlogObject{
+				{name: "binlog.000001", gtidSet: "a:1,a:2"},
+				{name: "binlog.000003", gtidSet: "a:3"},
+			},
+		},
+		{
+			name:     "first binary log uploaded",
+			uploaded: "a:1,a:2",
+			expected: []binlogObject{
+				{name: "binlog.000003", gtidSet: "a:3"},
+			},
+		},
+		{
+			name:     "everything uploaded",
+			uploaded: "a:1,a:2,a:3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pending, err := pendingBinlogs(context.Background(), fakeGTIDSets{}, binlogs, previous, tt.uploaded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(pending, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, pending)
+			}
+		})
+	}
+
+	pending, err := pendingBinlogs(context.Background(), fakeGTIDSets{}, binlogs[:1], previous[:1], "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("active binary log must not be uploaded, got %v", pending)
+	}
+}
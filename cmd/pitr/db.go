@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	mysqlpkg "github.com/percona/percona-server-mysql-operator/pkg/mysql"
+)
+
+type DB struct {
+	db *sql.DB
+}
+
+func NewDatabase(ctx context.Context, user apiv1alpha1.SystemUser, pass, host string) (*DB, error) {
+	config := mysql.NewConfig()
+
+	config.User = string(user)
+	config.Passwd = pass
+	config.Net = "tcp"
+	config.Addr = fmt.Sprintf("%s:%d", host, mysqlpkg.DefaultPort)
+	config.Params = map[string]string{
+		"interpolateParams": "true",
+		"timeout":           "10s",
+		"readTimeout":       "30s",
+		"writeTimeout":      "30s",
+		"tls":               "preferred",
+	}
+
+	db, err := sql.Open("mysql", config.FormatDSN())
+	if err != nil {
+		return nil, errors.Wrap(err, "connect to MySQL")
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "ping DB")
+	}
+
+	return &DB{db}, nil
+}
+
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+func (d *DB) GTIDExecuted(ctx context.Context) (string, error) {
+	var gtids string
+	err := d.db.QueryRowContext(ctx, "SELECT @@GLOBAL.gtid_executed").Scan(&gtids)
+	return normalizeGTIDSet(gtids), errors.Wrap(err, "select gtid_executed")
+}
+
+func (d *DB) IsWritable(ctx context.Context) (bool, error) {
+	var readOnly, superReadOnly bool
+	err := d.db.QueryRowContext(ctx, "SELECT @@read_only, @@super_read_only").Scan(&readOnly, &superReadOnly)
+	return !readOnly && !superReadOnly, errors.Wrap(err, "select read_only")
+}
+
+func (d *DB) GRPrimary(ctx context.Context) (string, error) {
+	var host string
+	err := d.db.QueryRowContext(ctx, `
+		SELECT MEMBER_HOST
+		FROM performance_schema.replication_group_members
+		WHERE MEMBER_ROLE='PRIMARY' AND MEMBER_STATE='ONLINE'
+	`).Scan(&host)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", errors.New("group has no online primary")
+	}
+	return host, errors.Wrap(err, "select primary member")
+}
+
+func (d *DB) GTIDSubset(ctx context.Context, set1, set2 string) (bool, error) {
+	var subset bool
+	err := d.db.QueryRowContext(ctx, "SELECT GTID_SUBSET(?, ?)", set1, set2).Scan(&subset)
+	return subset, errors.Wrap(err, "select gtid_subset")
+}
+
+func (d *DB) GTIDSubtract(ctx context.Context, set1, set2 string) (string, error) {
+	var res string
+	err := d.db.QueryRowContext(ctx, "SELECT GTID_SUBTRACT(?, ?)", set1, set2).Scan(&res)
+	return normalizeGTIDSet(res), errors.Wrap(err, "select gtid_subtract")
+}
+
+// GTIDUnion returns the union of two GTID sets in the canonical form.
+func (d *DB) GTIDUnion(ctx context.Context, set1, set2 string) (string, error) {
+	switch {
+	case set1 == "":
+		return d.GTIDSubtract(ctx, set2, "")
+	case set2 == "":
+		return d.GTIDSubtract(ctx, set1, "")
+	}
+	return d.GTIDSubtract(ctx, set1+","+set2, "")
+}
+
+type binlogFile struct {
+	name string
+	size int64
+}
+
+func (d *DB) FlushBinaryLogs(ctx context.Context) error {
+	_, err := d.db.ExecContext(ctx, "FLUSH BINARY LOGS")
+	return errors.Wrap(err, "flush binary logs")
+}
+
+func (d *DB) BinaryLogs(ctx context.Context) ([]binlogFile, error) {
+	rows, err := d.db.QueryContext(ctx, "SHOW BINARY LOGS")
+	if err != nil {
+		return nil, errors.Wrap(err, "show binary logs")
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, errors.Wrap(err, "get columns")
+	}
+
+	var files []binlogFile
+	for rows.Next() {
+		// The number of columns differs between versions (e.g. Encrypted was added in 8.0.14).
+		var f binlogFile
+		dest := []any{&f.name, &f.size}
+		for i := 2; i < len(cols); i++ {
+			dest = append(dest, new(sql.RawBytes))
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, errors.Wrap(err, "scan binary log")
+		}
+		files = append(files, f)
+	}
+
+	return files, rows.Err()
+}
+
+// PreviousGTIDs returns the set of transactions executed before the binary log was opened.
+func (d *DB) PreviousGTIDs(ctx context.Context, binlog string) (string, error) {
+	rows, err := d.db.QueryContext(ctx, "SHOW BINLOG EVENTS IN ? LIMIT 2", binlog)
+	if err != nil {
+		return "", errors.Wrapf(err, "show binlog events in %s", binlog)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			logName, eventType, info string
+			pos, serverID, endPos    int64
+		)
+		if err := rows.Scan(&logName, &pos, &eventType, &serverID, &endPos, &info); err != nil {
+			return "", errors.Wrap(err, "scan binlog event")
+		}
+		if eventType == "Previous_gtids" {
+			return normalizeGTIDSet(info), nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	return "", errors.Errorf("binlog %s has no Previous_gtids event", binlog)
+}
+
+func normalizeGTIDSet(set string) string {
+	return strings.ReplaceAll(strings.TrimSpace(set), "\n", "")
+}
+
+func connect(ctx context.Context, host string, user apiv1alpha1.SystemUser) (*DB, error) {
+	pass, err := getSecret(user)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get %s password", user)
+	}
+	return NewDatabase(ctx, user, pass, host)
+}
+
+// getPrimary returns the host of the cluster member that accepts writes.
+func getPrimary(ctx context.Context, user apiv1alpha1.SystemUser) (string, error) {
+	hosts, err := mysqlHosts()
+	if err != nil {
+		return "", err
+	}
+
+	clusterType := apiv1alpha1.ClusterType(os.Getenv("CLUSTER_TYPE"))
+
+	for _, host := range hosts {
+		db, err := connect(ctx, host, user)
+		if err != nil {
+			log.Info("failed to connect", "host", host, "error", err.Error())
+			continue
+		}
+
+		if clusterType == apiv1alpha1.ClusterTypeGR {
+			primary, err := db.GRPrimary(ctx)
+			db.Close()
+			if err != nil {
+				log.Info("failed to get primary", "host", host, "error", err.Error())
+				continue
+			}
+			return primary, nil
+		}
+
+		writable, err := db.IsWritable(ctx)
+		db.Close()
+		if err != nil {
+			log.Info("failed to check read_only", "host", host, "error", err.Error())
+			continue
+		}
+		if writable {
+			return host, nil
+		}
+	}
+
+	return "", errors.New("primary not found")
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
+)
+
+var log = logf.Log.WithName("pitr")
+
+func main() {
+	opts := zap.Options{Development: true}
+	logf.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+	if len(os.Args) < 2 {
+		log.Info("usage: pitr collect|restore")
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	var err error
+	switch os.Args[1] {
+	case "collect":
+		err = runCollector(ctx)
+	case "restore":
+		err = runRestore(ctx)
+	default:
+		err = errors.Errorf("unknown command %s", os.Args[1])
+	}
+	if err != nil {
+		log.Error(err, os.Args[1]+" failed")
+		os.Exit(1)
+	}
+}
+
+func getSecret(username apiv1alpha1.SystemUser) (string, error) {
+	path := filepath.Join(mysql.CredsMountPath, string(username))
+	sBytes, err := os.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "read %s", path)
+	}
+
+	return strings.TrimSpace(string(sBytes)), nil
+}
+
+func mysqlHosts() ([]string, error) {
+	hosts := strings.Split(os.Getenv("MYSQL_HOSTS"), ",")
+	if len(hosts) == 0 || hosts[0] == "" {
+		return nil, errors.New("MYSQL_HOSTS is empty")
+	}
+	return hosts, nil
+}
+
+func getStorage(ctx context.Context) (storage.Storage, error) {
+	opts, err := storage.GetOptionsFromEnv(os.Getenv("BINLOGS_PREFIX"))
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.NewClient(ctx, opts)
+}
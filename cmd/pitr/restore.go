@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/pitr"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
+)
+
+func runRestore(ctx context.Context) error {
+	target, err := parseRecoveryTarget(os.Getenv("PITR_TYPE"), os.Getenv("PITR_DATE"), os.Getenv("PITR_GTID"))
+	if err != nil {
+		return err
+	}
+
+	stg, err := getStorage(ctx)
+	if err != nil {
+		return errors.Wrap(err, "get storage")
+	}
+
+	host, err := getPrimary(ctx, apiv1alpha1.UserOperator)
+	if err != nil {
+		return errors.Wrap(err, "get primary")
+	}
+
+	db, err := connect(ctx, host, apiv1alpha1.UserOperator)
+	if err != nil {
+		return errors.Wrapf(err, "connect to %s", host)
+	}
+	defer db.Close()
+
+	executed, err := db.GTIDExecuted(ctx)
+	if err != nil {
+		return err
+	}
+
+	objects, err := stg.ListObjects(ctx, "")
+	if err != nil {
+		return errors.Wrap(err, "list objects")
+	}
+
+	var timelines []timeline
+	for _, id := range timelineIDs(objects) {
+		start, err := readObject(ctx, stg, timelineDir(id)+timelineStartObject)
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "read start of timeline %d", id)
+		}
+		timelines = append(timelines, timeline{id: id, start: start})
+	}
+
+	tl, err := selectTimeline(ctx, db, timelines, executed, target)
+	if err != nil {
+		return err
+	}
+	log.Info("applying binary logs of timeline", "timeline", tl.id, "start", tl.start)
+
+	stg.SetPrefix(stg.GetPrefix() + timelineDir(tl.id))
+	objects, err = stg.ListObjects(ctx, "")
+	if err != nil {
+		return errors.Wrapf(err, "list objects of timeline %d", tl.id)
+	}
+
+	var stored []binlogObject
+	for _, name := range sortedBinlogObjects(objects) {
+		gtidSet, err := readObject(ctx, stg, name+gtidSetSuffix)
+		if err != nil {
+			return errors.Wrapf(err, "read gtid set of %s", name)
+		}
+		stored = append(stored, binlogObject{name: name, gtidSet: gtidSet})
+	}
+
+	binlogs, err := binlogsToApply(ctx, db, stored, executed, target)
+	if err != nil {
+		return err
+	}
+
+	for i, b := range binlogs {
+		path, err := downloadBinlog(ctx, stg, b.name)
+		if err != nil {
+			return errors.Wrapf(err, "download %s", b.name)
+		}
+
+		var args []string
+		switch {
+		case target.pitrType == apiv1alpha1.PITRDate:
+			args = append(args, "--stop-datetime="+target.date.Format(apiv1alpha1.PITRDateFormat))
+		case i == len(binlogs)-1:
+			pos, err := gtidPosition(ctx, path, target.gtid)
+			if err != nil {
+				os.Remove(path)
+				return err
+			}
+			args = append(args, "--stop-position="+strconv.FormatInt(pos, 10))
+		}
+
+		err = applyBinlog(ctx, host, path, args...)
+		os.Remove(path)
+		if err != nil {
+			return errors.Wrapf(err, "apply %s", b.name)
+		}
+
+		log.Info("binary log applied", "binlog", b.name, "gtidSet", b.gtidSet)
+	}
+
+	return nil
+}
+
+// recoveryTarget is the point binary logs are applied up to.
+type recoveryTarget struct {
+	pitrType apiv1alpha1.PITRType
+	date     time.Time
+	gtid     string
+}
+
+func parseRecoveryTarget(pitrType, date, gtid string) (recoveryTarget, error) {
+	target := recoveryTarget{pitrType: apiv1alpha1.PITRType(pitrType), gtid: gtid}
+
+	switch target.pitrType {
+	case apiv1alpha1.PITRDate:
+		var err error
+		target.date, err = time.ParseInLocation(apiv1alpha1.PITRDateFormat, date, time.UTC)
+		if err != nil {
+			return target, errors.Wrap(err, "parse date")
+		}
+	case apiv1alpha1.PITRGTID:
+		if gtid == "" {
+			return target, errors.New("gtid is empty")
+		}
+	default:
+		return target, errors.Errorf("unknown pitr type %s", pitrType)
+	}
+
+	return target, nil
+}
+
+// timeline is the history of the cluster between two restores.
+type timeline struct {
+	id int64
+	// start is the set of transactions executed before the first binary log of the timeline.
+	start string
+}
+
+// selectTimeline returns the latest timeline the history of the restored base backup continues in,
+// i.e. the one which starts from transactions executed in the backup. If the backup is the point
+// a restore started the timeline from, the timeline before the restore continues the backup as well
+// and is used for dates before the restore.
+func selectTimeline(ctx context.Context, gs gtidSets, timelines []timeline, executed string, target recoveryTarget) (timeline, error) {
+	var started *timeline
+	for i := len(timelines) - 1; i >= 0; i-- {
+		tl := timelines[i]
+
+		inHistory, err := gs.GTIDSubset(ctx, tl.start, executed)
+		if err != nil {
+			return timeline{}, err
+		}
+		if !inHistory {
+			continue
+		}
+
+		if target.pitrType == apiv1alpha1.PITRDate && tl.id > target.date.Unix() {
+			isStart, err := gs.GTIDSubset(ctx, executed, tl.start)
+			if err != nil {
+				return timeline{}, err
+			}
+			if isStart {
+				if started == nil {
+					started = &timelines[i]
+				}
+				continue
+			}
+		}
+
+		return tl, nil
+	}
+
+	if started != nil {
+		return *started, nil
+	}
+	return timeline{}, errors.New("binary logs continuing the base backup are not found")
+}
+
+// binlogsToApply returns the stored binary logs that have to be applied on top of the executed
+// transactions to reach the target. Binary logs must be sorted in the order they are applied.
+// The last returned binary log is applied partially: up to the target date or the target transaction.
+func binlogsToApply(ctx context.Context, gs gtidSets, binlogs []binlogObject, executed string, target recoveryTarget) ([]binlogObject, error) {
+	var apply []binlogObject
+	for _, b := range binlogs {
+		// Transactions of the base backup are already applied.
+		subset, err := gs.GTIDSubset(ctx, b.gtidSet, executed)
+		if err != nil {
+			return nil, err
+		}
+		if subset {
+			continue
+		}
+
+		if target.pitrType == apiv1alpha1.PITRDate {
+			ts, err := binlogObjectTimestamp(b.name)
+			if err != nil {
+				return nil, err
+			}
+			if ts > target.date.Unix() {
+				break
+			}
+			apply = append(apply, b)
+			continue
+		}
+
+		apply = append(apply, b)
+		found, err := gs.GTIDSubset(ctx, target.gtid, b.gtidSet)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return apply, nil
+		}
+	}
+
+	if target.pitrType == apiv1alpha1.PITRGTID {
+		return nil, errors.Errorf("gtid %s not found in binary logs", target.gtid)
+	}
+
+	return apply, nil
+}
+
+func downloadBinlog(ctx context.Context, stg storage.Storage, name string) (string, error) {
+	r, err := stg.GetObject(ctx, name)
+	if err != nil {
+		return "", errors.Wrap(err, "get object")
+	}
+	defer r.Close()
+
+	path := filepath.Join(pitr.WorkMountPath, name)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "create %s", path)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		os.Remove(path)
+		return "", errors.Wrapf(err, "write %s", path)
+	}
+
+	return path, nil
+}
+
+// gtidPosition returns the position of the GTID event of the transaction in the binary log.
+func gtidPosition(ctx context.Context, path, gtid string) (int64, error) {
+	cmd := exec.CommandContext(ctx, "mysqlbinlog", path)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, errors.Wrap(err, "stdout pipe")
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, errors.Wrap(err, "start mysqlbinlog")
+	}
+
+	pos, err := stopPosition(stdout, gtid)
+
+	// Output is not needed anymore, mysqlbinlog is killed if it's still running.
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+
+	return pos, errors.Wrapf(err, "find gtid %s in %s", gtid, path)
+}
+
+// stopPosition returns the position of the GTID event of the transaction in the mysqlbinlog output.
+// The transaction and all the following ones are skipped if the binary log is applied up to it.
+func stopPosition(r io.Reader, gtid string) (int64, error) {
+	gtidNext := "SET @@SESSION.GTID_NEXT= '" + gtid + "'"
+	pos := int64(-1)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if p, ok := strings.CutPrefix(line, "# at "); ok {
+			var err error
+			pos, err = strconv.ParseInt(strings.TrimSpace(p), 10, 64)
+			if err != nil {
+				return 0, errors.Wrapf(err, "parse position %s", p)
+			}
+			continue
+		}
+		if strings.HasPrefix(line, gtidNext) {
+			if pos < 0 {
+				return 0, errors.New("gtid event has no position")
+			}
+			return pos, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, errors.Wrap(err, "read mysqlbinlog output")
+	}
+
+	return 0, errors.New("gtid not found")
+}
+
+func applyBinlog(ctx context.Context, host, path string, args ...string) error {
+	pass, err := getSecret(apiv1alpha1.UserOperator)
+	if err != nil {
+		return errors.Wrap(err, "get password")
+	}
+
+	binlogCmd := exec.CommandContext(ctx, "mysqlbinlog", append(args, path)...)
+	binlogCmd.Env = append(os.Environ(), "TZ=UTC")
+	mysqlCmd := exec.CommandContext(ctx, "mysql", "-h", host, "-u", string(apiv1alpha1.UserOperator))
+	mysqlCmd.Env = append(os.Environ(), "MYSQL_PWD="+pass)
+
+	var binlogErr, mysqlErr bytes.Buffer
+	binlogCmd.Stderr = &binlogErr
+	mysqlCmd.Stderr = &mysqlErr
+
+	stdout, err := binlogCmd.StdoutPipe()
+	if err != nil {
+		return errors.Wrap(err, "stdout pipe")
+	}
+	mysqlCmd.Stdin = stdout
+
+	if err := binlogCmd.Start(); err != nil {
+		return errors.Wrap(err, "start mysqlbinlog")
+	}
+	if err := mysqlCmd.Start(); err != nil {
+		_ = binlogCmd.Process.Kill()
+		_ = binlogCmd.Wait()
+		return errors.Wrap(err, "start mysql")
+	}
+
+	mysqlWaitErr := mysqlCmd.Wait()
+	if mysqlWaitErr != nil {
+		_ = binlogCmd.Process.Kill()
+	}
+	if err := binlogCmd.Wait(); err != nil && mysqlWaitErr == nil {
+		return errors.Wrapf(err, "mysqlbinlog: %s", binlogErr.String())
+	}
+	if mysqlWaitErr != nil {
+		return errors.Wrapf(mysqlWaitErr, "mysql: %s", mysqlErr.String())
+	}
+
+	return nil
+}
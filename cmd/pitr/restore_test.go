@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+)
+
+func TestParseRecoveryTarget(t *testing.T) {
+	target, err := parseRecoveryTarget(string(apiv1alpha1.PITRDate), "2024-01-02 03:04:05", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC); !target.date.Equal(expected) {
+		t.Fatalf("expected %s, got %s", expected, target.date)
+	}
+
+	for _, args := range [][3]string{
+		{string(apiv1alpha1.PITRDate), "2024-01-02T03:04:05Z", ""},
+		{string(apiv1alpha1.PITRGTID), "", ""},
+		{"unknown", "", "a:1"},
+	} {
+		if _, err := parseRecoveryTarget(args[0], args[1], args[2]); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func TestSelectTimeline(t *testing.T) {
+	// Timeline 0 has transactions a:1-a:5. The cluster was restored at 1000 to a:1,a:2
+	// and new transactions a:3-a:4 were written after the restore.
+	timelines := []timeline{
+		{id: 0, start: ""},
+		{id: 1000, start: "a:1,a:2"},
+	}
+
+	tests := []struct {
+		name     string
+		executed string
+		target   recoveryTarget
+		expected int64
+	}{
+		{
+			name:     "backup before the restore point",
+			executed: "a:1",
+			target:   recoveryTarget{pitrType: apiv1alpha1.PITRDate, date: time.Unix(2000, 0)},
+			expected: 0,
+		},
+		{
+			name:     "backup taken after the restore",
+			executed: "a:1,a:2,a:3",
+			target:   recoveryTarget{pitrType: apiv1alpha1.PITRDate, date: time.Unix(2000, 0)},
+			expected: 1000,
+		},
+		{
+			name:     "backup taken after the restore, date before the restore",
+			executed: "a:1,a:2,a:3",
+			target:   recoveryTarget{pitrType: apiv1alpha1.PITRDate, date: time.Unix(500, 0)},
+			expected: 1000,
+		},
+		{
+			name:     "backup is the restore point, date after the restore",
+			executed: "a:1,a:2",
+			target:   recoveryTarget{pitrType: apiv1alpha1.PITRDate, date: time.Unix(2000, 0)},
+			expected: 1000,
+		},
+		{
+			name:     "backup is the restore point, date before the restore",
+			executed: "a:1,a:2",
+			target:   recoveryTarget{pitrType: apiv1alpha1.PITRDate, date: time.Unix(500, 0)},
+			expected: 0,
+		},
+		{
+			name:     "backup is the restore point, gtid",
+			executed: "a:1,a:2",
+			target:   recoveryTarget{pitrType: apiv1alpha1.PITRGTID, gtid: "a:4"},
+			expected: 1000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl, err := selectTimeline(context.Background(), fakeGTIDSets{}, timelines, tt.executed, tt.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tl.id != tt.expected {
+				t.Fatalf("expected timeline %d, got %d", tt.expected, tl.id)
+			}
+		})
+	}
+
+	// Only the timeline started by a restore to a point after the backup is stored.
+	_, err := selectTimeline(context.Background(), fakeGTIDSets{}, timelines[1:], "a:1", recoveryTarget{pitrType: apiv1alpha1.PITRGTID, gtid: "a:4"})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestBinlogsToApply(t *testing.T) {
+	binlogs := []binlogObject{
+		{name: binlogObjectName(1000, "a:1,a:2"), gtidSet: "a:1,a:2"},
+		{name: binlogObjectName(2000, "a:3,a:4"), gtidSet: "a:3,a:4"},
+		{name: binlogObjectName(3000, "a:5"), gtidSet: "a:5"},
+	}
+
+	tests := []struct {
+		name     string
+		executed string
+		target   recoveryTarget
+		expected []binlogObject
+		err      string
+	}{
+		{
+			name:     "date",
+			executed: "a:1,a:2",
+			target:   recoveryTarget{pitrType: apiv1alpha1.PITRDate, date: time.Unix(2500, 0)},
+			expected: binlogs[1:2],
+		},
+		{
+			name:     "date in the middle of binary log",
+			executed: "a:1",
+			target:   recoveryTarget{pitrType: apiv1alpha1.PITRDate, date: time.Unix(1500, 0)},
+			expected: binlogs[:1],
+		},
+		{
+			name:     "date after the last binary log",
+			executed: "a:1,a:2",
+			target:   recoveryTarget{pitrType: apiv1alpha1.PITRDate, date: time.Unix(5000, 0)},
+			expected: binlogs[1:],
+		},
+		{
+			name:     "date before the base backup",
+			executed: "a:1,a:2,a:3,a:4",
+			target:   recoveryTarget{pitrType: apiv1alpha1.PITRDate, date: time.Unix(1500, 0)},
+		},
+		{
+			name:     "gtid",
+			executed: "a:1,a:2",
+			target:   recoveryTarget{pitrType: apiv1alpha1.PITRGTID, gtid: "a:5"},
+			expected: binlogs[1:],
+		},
+		{
+			name:     "gtid in the first binary log after the base backup",
+			executed: "a:1,a:2",
+			target:   recoveryTarget{pitrType: apiv1alpha1.PITRGTID, gtid: "a:4"},
+			expected: binlogs[1:2],
+		},
+		{
+			name:     "gtid of the base backup",
+			executed: "a:1,a:2",
+			target:   recoveryTarget{pitrType: apiv1alpha1.PITRGTID, gtid: "a:2"},
+			err:      "gtid a:2 not found in binary logs",
+		},
+		{
+			name:     "unknown gtid",
+			executed: "a:1,a:2",
+			target:   recoveryTarget{pitrType: apiv1alpha1.PITRGTID, gtid: "b:1"},
+			err:      "gtid b:1 not found in binary logs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apply, err := binlogsToApply(context.Background(), fakeGTIDSets{}, binlogs, tt.executed, tt.target)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(apply, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, apply)
+			}
+		})
+	}
+}
+
+func TestStopPosition(t *testing.T) {
+	output := `# at 4
+#240101 12:00:00 server id 1  end_log_pos 126 CRC32 0x1	Start: binlog v 4
+# at 126
+#240101 12:00:00 server id 1  end_log_pos 197 CRC32 0x2	Previous-GTIDs
+# at 197
+#240101 12:00:01 server id 1  end_log_pos 276 CRC32 0x3	GTID	last_committed=0	sequence_number=1
+SET @@SESSION.GTID_NEXT= 'a:1'/*!*/;
+# at 276
+BEGIN
+/*!*/;
+# at 512
+#240101 12:00:02 server id 1  end_log_pos 591 CRC32 0x4	GTID	last_committed=1	sequence_number=2
+SET @@SESSION.GTID_NEXT= 'a:2'/*!*/;
+# at 591
+BEGIN
+/*!*/;
+`
+
+	tests := []struct {
+		gtid     string
+		expected int64
+		wantErr  bool
+	}{
+		{gtid: "a:1", expected: 197},
+		{gtid: "a:2", expected: 512},
+		{gtid: "a:3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.gtid, func(t *testing.T) {
+			pos, err := stopPosition(strings.NewReader(output), tt.gtid)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pos != tt.expected {
+				t.Fatalf("expected %d, got %d", tt.expected, pos)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -15,6 +16,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -25,6 +27,7 @@ import (
 	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
 	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
 	xb "github.com/percona/percona-server-mysql-operator/pkg/xtrabackup"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/cloud"
 	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
 )
 
@@ -38,6 +41,12 @@ var status Status
 type Status struct {
 	isRunning         atomic.Bool
 	currentBackupConf *xb.BackupConfig
+	currentBackupName string
+	progress          func() (read, uploaded int64)
+
+	cancelBackupName string
+	cancelBackup     context.CancelFunc
+	canceled         atomic.Bool
 
 	mu sync.Mutex
 }
@@ -62,6 +71,64 @@ func (s *Status) RemoveBackupConfig() {
 	s.mu.Unlock()
 }
 
+// SetProgress registers the function reporting progress of the running backup.
+func (s *Status) SetProgress(backupName string, progress func() (read, uploaded int64)) {
+	s.mu.Lock()
+	s.currentBackupName = backupName
+	s.progress = progress
+	s.mu.Unlock()
+}
+
+func (s *Status) RemoveProgress() {
+	s.SetProgress("", nil)
+}
+
+// GetProgress returns progress of the backup if it's running.
+func (s *Status) GetProgress(backupName string) (read, uploaded int64, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.progress == nil || s.currentBackupName != backupName {
+		return 0, 0, false
+	}
+	read, uploaded = s.progress()
+	return read, uploaded, true
+}
+
+// SetCancel registers the function stopping the running backup.
+func (s *Status) SetCancel(backupName string, cancel context.CancelFunc) {
+	s.mu.Lock()
+	s.cancelBackupName = backupName
+	s.cancelBackup = cancel
+	s.canceled.Store(false)
+	s.mu.Unlock()
+}
+
+func (s *Status) RemoveCancel() {
+	s.mu.Lock()
+	s.cancelBackupName = ""
+	s.cancelBackup = nil
+	s.mu.Unlock()
+}
+
+// CancelBackup stops the backup. It returns false if the backup is not running.
+func (s *Status) CancelBackup(backupName string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancelBackup == nil || s.cancelBackupName != backupName {
+		return false
+	}
+	s.canceled.Store(true)
+	s.cancelBackup()
+	return true
+}
+
+// Canceled returns true if the running backup was stopped by CancelBackup.
+func (s *Status) Canceled() bool {
+	return s.canceled.Load()
+}
+
 func (s *Status) GetBackupConfig() *xb.BackupConfig {
 	s.mu.Lock()
 	cfg := *s.currentBackupConf
@@ -80,6 +147,9 @@ func main() {
 	})
 	mux.HandleFunc("/backup/", backupHandler)
 	mux.HandleFunc("/logs/", logHandler)
+	mux.HandleFunc("/progress/", progressHandler)
+	mux.HandleFunc("/metadata/", metadataHandler)
+	mux.HandleFunc("/cancel/", cancelHandler)
 
 	log.Info("starting http server")
 	log.Error(http.ListenAndServe(":"+strconv.Itoa(mysql.SidecarHTTPPort), mux), "http server failed")
@@ -114,16 +184,90 @@ func getNamespace() (string, error) {
 	return string(ns), nil
 }
 
-func xtrabackupArgs(user, pass string) []string {
-	return []string{
+func xtrabackupArgs(user, pass, lsnDir, incrementalLSN string) []string {
+	args := []string{
 		"--backup",
 		"--stream=xbstream",
 		"--safe-slave-backup",
 		"--slave-info",
 		"--target-dir=/backup/",
+		fmt.Sprintf("--extra-lsndir=%s", lsnDir),
 		fmt.Sprintf("--user=%s", user),
 		fmt.Sprintf("--password=%s", pass),
 	}
+	if incrementalLSN != "" {
+		args = append(args, fmt.Sprintf("--incremental-lsn=%s", incrementalLSN))
+	}
+	return args
+}
+
+func newStorage(ctx context.Context, cfg *xb.BackupConfig) (storage.Storage, error) {
+	opts, err := storage.GetOptionsFromBackupConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "get options from backup config")
+	}
+	return storage.NewClient(ctx, opts)
+}
+
+func getIncrementalLSN(ctx context.Context, cfg *xb.BackupConfig) (string, error) {
+	stg, err := newStorage(ctx, cfg)
+	if err != nil {
+		return "", errors.Wrap(err, "new storage")
+	}
+
+	name := xb.CheckpointsObjectName(cfg.IncrementalBaseDestination)
+	r, err := stg.GetObject(ctx, name)
+	if err != nil {
+		return "", errors.Wrapf(err, "get %s", name)
+	}
+	defer r.Close()
+
+	checkpoints, err := parseKeyValues(r)
+	if err != nil {
+		return "", errors.Wrapf(err, "read %s", name)
+	}
+	if lsn, ok := checkpoints["to_lsn"]; ok {
+		return lsn, nil
+	}
+
+	return "", errors.Errorf("to_lsn not found in %s", name)
+}
+
+func uploadCheckpoints(ctx context.Context, cfg *xb.BackupConfig, lsnDir string) error {
+	stg, err := newStorage(ctx, cfg)
+	if err != nil {
+		return errors.Wrap(err, "new storage")
+	}
+
+	f, err := os.Open(filepath.Join(lsnDir, "xtrabackup_checkpoints"))
+	if err != nil {
+		return errors.Wrap(err, "open checkpoints file")
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return errors.Wrap(err, "stat checkpoints file")
+	}
+
+	return stg.PutObject(ctx, xb.CheckpointsObjectName(cfg.Destination), f, fi.Size())
+}
+
+// writeEncryptionKey stores the key in a file readable only by the sidecar.
+// The key isn't passed with --encrypt-key to keep it out of the process list.
+func writeEncryptionKey(key string) (string, error) {
+	f, err := os.CreateTemp("", "xtrabackup-key-")
+	if err != nil {
+		return "", errors.Wrap(err, "create key file")
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(key); err != nil {
+		os.Remove(f.Name())
+		return "", errors.Wrap(err, "write key file")
+	}
+
+	return f.Name(), nil
 }
 
 func backupHandler(w http.ResponseWriter, req *http.Request) {
@@ -147,7 +291,13 @@ func getBackupHandler(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	data, err := json.Marshal(status.GetBackupConfig())
+	// The running backup is only identified by its destination, the keys aren't sent back.
+	cfg := status.GetBackupConfig()
+	cfg.Encryption.Key = ""
+	cfg.S3.SSECustomerKey = ""
+	cfg.Azure.CustomerKey = ""
+
+	data, err := json.Marshal(cfg)
 	if err != nil {
 		log.Error(err, "failed to marshal data")
 		http.Error(w, "backup failed", http.StatusInternalServerError)
@@ -193,7 +343,7 @@ func deleteBackupHandler(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	if err := deleteBackup(req.Context(), &backupConf, backupName); err != nil {
+	if err := deleteBackup(req.Context(), &backupConf); err != nil {
 		log.Error(err, "failed to delete backup")
 		http.Error(w, "backup failed", http.StatusInternalServerError)
 		return
@@ -202,38 +352,23 @@ func deleteBackupHandler(w http.ResponseWriter, req *http.Request) {
 	log.Info("Backup deleted successfully", "destination", backupConf.Destination, "storage", backupConf.Type)
 }
 
-func deleteBackup(ctx context.Context, cfg *xb.BackupConfig, backupName string) error {
-	logWriter := io.Writer(os.Stderr)
-	if backupName != "" {
-		backupLog, err := os.OpenFile(filepath.Join(mysql.BackupLogDir, backupName+".log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o666)
-		if err != nil {
-			return errors.Wrap(err, "failed to open log file")
-		}
-		defer backupLog.Close()
-		logWriter = io.MultiWriter(backupLog, os.Stderr)
-	}
-	xbcloud := exec.CommandContext(ctx, "xbcloud", xb.XBCloudArgs(xb.XBCloudActionDelete, cfg)...)
-	xbcloudErr, err := xbcloud.StderrPipe()
+func deleteBackup(ctx context.Context, cfg *xb.BackupConfig) error {
+	stg, err := newStorage(ctx, cfg)
 	if err != nil {
-		return errors.Wrap(err, "xbcloud stderr pipe failed")
-	}
-	defer xbcloudErr.Close()
-	log.Info(
-		"Deleting Backup",
-		"destination", cfg.Destination,
-		"storage", cfg.Type,
-		"xbcloudCmd", sanitizeCmd(xbcloud),
-	)
-	if err := xbcloud.Start(); err != nil {
-		return errors.Wrap(err, "failed to start xbcloud")
+		return errors.Wrap(err, "new storage")
 	}
 
-	if _, err := io.Copy(logWriter, xbcloudErr); err != nil {
-		return errors.Wrap(err, "failed to copy xbcloud stderr")
+	log.Info("Deleting Backup", "destination", cfg.Destination, "storage", cfg.Type)
+
+	if err := cloud.Delete(ctx, stg, cfg.Destination); err != nil {
+		return errors.Wrap(err, "delete backup")
 	}
 
-	if err := xbcloud.Wait(); err != nil {
-		return errors.Wrap(err, "failed waiting for xbcloud to finish")
+	// Backups uploaded by xbcloud have md5 sums of their chunks stored next to them.
+	for _, name := range []string{cfg.Destination + ".md5", xb.CheckpointsObjectName(cfg.Destination)} {
+		if err := stg.DeleteObject(ctx, name); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+			return errors.Wrapf(err, "delete %s", name)
+		}
 	}
 	return nil
 }
@@ -257,38 +392,6 @@ func backupExists(ctx context.Context, cfg *xb.BackupConfig) (bool, error) {
 	return true, nil
 }
 
-func checkBackupMD5Size(ctx context.Context, cfg *xb.BackupConfig) error {
-	// xbcloud doesn't create md5 file for azure
-	if cfg.Type == apiv1alpha1.BackupStorageAzure {
-		return nil
-	}
-
-	opts, err := storage.GetOptionsFromBackupConfig(cfg)
-	if err != nil {
-		return errors.Wrap(err, "get options from backup config")
-	}
-	storage, err := storage.NewClient(ctx, opts)
-	if err != nil {
-		return errors.Wrap(err, "new storage")
-	}
-	r, err := storage.GetObject(ctx, cfg.Destination+".md5")
-	if err != nil {
-		return errors.Wrap(err, "get object")
-	}
-	defer r.Close()
-	data, err := io.ReadAll(r)
-	if err != nil {
-		return errors.Wrap(err, "read all")
-	}
-
-	// Q: what value we should use here?
-	// size of the `demand-backup` test md5 file is 4575
-	if len(data) < 3000 {
-		return errors.Errorf("backup was finished unsuccessful: md5 size: %d", len(data))
-	}
-	return nil
-}
-
 func createBackupHandler(w http.ResponseWriter, req *http.Request) {
 	if !status.TryRunBackup() {
 		log.Info("backup is already running", "host", req.RemoteAddr)
@@ -312,6 +415,11 @@ func createBackupHandler(w http.ResponseWriter, req *http.Request) {
 	backupName := path[2]
 	log = log.WithValues("namespace", ns, "name", backupName)
 
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+	status.SetCancel(backupName, cancel)
+	defer status.RemoveCancel()
+
 	data, err := io.ReadAll(req.Body)
 	if err != nil {
 		log.Error(err, "failed to read request data")
@@ -331,16 +439,21 @@ func createBackupHandler(w http.ResponseWriter, req *http.Request) {
 	defer func() {
 		status.RemoveBackupConfig()
 	}()
-	log.V(1).Info("Checking if backup exists")
-	exists, err := backupExists(req.Context(), &backupConf)
-	if err != nil {
-		log.Error(err, "failed to check if backup exists")
-		http.Error(w, "backup failed", http.StatusBadRequest)
-		return
+	isFilesystem := backupConf.Type == apiv1alpha1.BackupStorageFilesystem
+
+	exists := false
+	if !isFilesystem {
+		log.V(1).Info("Checking if backup exists")
+		exists, err = backupExists(req.Context(), &backupConf)
+		if err != nil {
+			log.Error(err, "failed to check if backup exists")
+			http.Error(w, "backup failed", http.StatusBadRequest)
+			return
+		}
 	}
 	if exists {
 		log.V(1).Info("Backup exists. Deleting backup")
-		if err := deleteBackup(req.Context(), &backupConf, backupName); err != nil {
+		if err := deleteBackup(req.Context(), &backupConf); err != nil {
 			log.Error(err, "failed to delete existing backup")
 			http.Error(w, "backup failed", http.StatusBadRequest)
 			return
@@ -357,9 +470,47 @@ func createBackupHandler(w http.ResponseWriter, req *http.Request) {
 		http.Error(w, "backup failed", http.StatusInternalServerError)
 		return
 	}
-	g, gCtx := errgroup.WithContext(req.Context())
 
-	xtrabackup := exec.CommandContext(gCtx, "xtrabackup", xtrabackupArgs(string(backupUser), backupPass)...)
+	incrementalLSN := ""
+	if backupConf.IncrementalBaseDestination != "" {
+		incrementalLSN, err = getIncrementalLSN(req.Context(), &backupConf)
+		if err != nil {
+			log.Error(err, "failed to get LSN of the base backup")
+			http.Error(w, "backup failed", http.StatusInternalServerError)
+			return
+		}
+		log.Info("Taking incremental backup", "base", backupConf.IncrementalBaseDestination, "lsn", incrementalLSN)
+	}
+
+	lsnDir, err := os.MkdirTemp("", backupName+"-lsn-")
+	if err != nil {
+		log.Error(err, "failed to create LSN directory")
+		http.Error(w, "backup failed", http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(lsnDir)
+
+	xbArgs := xtrabackupArgs(string(backupUser), backupPass, lsnDir, incrementalLSN)
+	if backupConf.Encryption.Cipher != "" {
+		keyFile, err := writeEncryptionKey(backupConf.Encryption.Key)
+		if err != nil {
+			log.Error(err, "failed to write encryption key")
+			http.Error(w, "backup failed", http.StatusInternalServerError)
+			return
+		}
+		defer os.Remove(keyFile)
+		xbArgs = append(xbArgs, "--encrypt="+backupConf.Encryption.Cipher, "--encrypt-key-file="+keyFile)
+	}
+	if t := backupConf.Throttle.Throttle; t > 0 {
+		xbArgs = append(xbArgs, fmt.Sprintf("--throttle=%d", t))
+	}
+	if p := backupConf.Throttle.Parallel; p > 0 {
+		xbArgs = append(xbArgs, fmt.Sprintf("--parallel=%d", p))
+	}
+
+	g, gCtx := errgroup.WithContext(ctx)
+
+	xtrabackup := exec.CommandContext(gCtx, "xtrabackup", xbArgs...)
 
 	xbOut, err := xtrabackup.StdoutPipe()
 	if err != nil {
@@ -386,38 +537,82 @@ func createBackupHandler(w http.ResponseWriter, req *http.Request) {
 	defer backupLog.Close()
 	logWriter := io.MultiWriter(backupLog, os.Stderr)
 
-	xbcloud := exec.CommandContext(gCtx, "xbcloud", xb.XBCloudArgs(xb.XBCloudActionPut, &backupConf)...)
-	xbcloud.Stdin = xbOut
+	if isFilesystem {
+		log.Info(
+			"Backup starting",
+			"destination", backupConf.Destination,
+			"storage", backupConf.Type,
+			"xtrabackupCmd", sanitizeCmd(xtrabackup),
+		)
+
+		cw := &countingWriter{w: w}
+		status.SetProgress(backupName, func() (int64, int64) {
+			n := cw.n.Load()
+			return n, n
+		})
+		defer status.RemoveProgress()
+
+		out := cloud.LimitReader(gCtx, xbOut, backupConf.Throttle.UploadBandwidth)
+		if err := streamBackup(cw, xtrabackup, out, xbErr, logWriter); err != nil {
+			if status.Canceled() {
+				log.Info("Backup canceled", "destination", backupConf.Destination, "storage", backupConf.Type)
+			} else {
+				log.Error(err, "failed to stream backup")
+			}
+			// Response status is already sent, so the only way to report
+			// the failure to the backup job is to abort the connection.
+			panic(http.ErrAbortHandler)
+		}
+
+		if _, err := collectMetadata(backupName, ns, lsnDir, cw.n.Load()); err != nil {
+			log.Error(err, "failed to collect backup metadata")
+		}
+
+		log.Info("Backup finished successfully", "destination", backupConf.Destination, "storage", backupConf.Type)
+		return
+	}
 
-	xbcloudErr, err := xbcloud.StderrPipe()
+	stg, err := newStorage(req.Context(), &backupConf)
 	if err != nil {
-		log.Error(err, "xbcloud stderr pipe failed")
+		log.Error(err, "failed to create storage client")
+		http.Error(w, "backup failed", http.StatusInternalServerError)
+		return
+	}
+	// Metadata with the namespace is stored before the backup, so garbage collection of clusters
+	// in other namespaces sharing the storage doesn't delete the backup if it's never finished.
+	// It's replaced with the complete metadata after the backup.
+	ownerMetadata, err := json.Marshal(apiv1alpha1.BackupMetadata{Namespace: ns})
+	if err != nil {
+		log.Error(err, "failed to marshal backup metadata")
+		http.Error(w, "backup failed", http.StatusInternalServerError)
+		return
+	}
+	if err := stg.PutObject(req.Context(), xb.MetadataObjectName(backupConf.Destination), bytes.NewReader(ownerMetadata), int64(len(ownerMetadata))); err != nil {
+		log.Error(err, "failed to upload backup metadata")
 		http.Error(w, "backup failed", http.StatusInternalServerError)
 		return
 	}
-	defer xbcloudErr.Close()
+
+	uploader := cloud.NewUploader(stg, cloud.Options{Bandwidth: backupConf.Throttle.UploadBandwidth})
+	status.SetProgress(backupName, uploader.Progress)
+	defer status.RemoveProgress()
 
 	log.Info(
 		"Backup starting",
 		"destination", backupConf.Destination,
 		"storage", backupConf.Type,
 		"xtrabackupCmd", sanitizeCmd(xtrabackup),
-		"xbcloudCmd", sanitizeCmd(xbcloud),
 	)
 
+	var manifest *cloud.Manifest
+	uploadDone := make(chan struct{})
 	g.Go(func() error {
-		if err := xbcloud.Start(); err != nil {
-			log.Error(err, "failed to start xbcloud")
-			return err
-		}
-
-		if _, err := io.Copy(logWriter, xbcloudErr); err != nil {
-			log.Error(err, "failed to copy xbcloud stderr")
-			return err
-		}
+		defer close(uploadDone)
 
-		if err := xbcloud.Wait(); err != nil {
-			log.Error(err, "failed waiting for xbcloud to finish")
+		var err error
+		manifest, err = uploader.Upload(gCtx, backupConf.Destination, xbOut)
+		if err != nil {
+			log.Error(err, "failed to upload backup")
 			return err
 		}
 		return nil
@@ -434,6 +629,9 @@ func createBackupHandler(w http.ResponseWriter, req *http.Request) {
 			return err
 		}
 
+		// Wait closes stdout, so it has to be read till the end first.
+		<-uploadDone
+
 		if err := xtrabackup.Wait(); err != nil {
 			log.Error(err, "failed waiting for xtrabackup to finish")
 			return err
@@ -441,18 +639,180 @@ func createBackupHandler(w http.ResponseWriter, req *http.Request) {
 		return nil
 	})
 
-	if err := g.Wait(); err != nil {
+	stopProgress := logProgress(uploader)
+	err = g.Wait()
+	stopProgress()
+	if err != nil {
+		if status.Canceled() {
+			cleanupCanceledBackup(context.WithoutCancel(req.Context()), &backupConf)
+			http.Error(w, "backup canceled", http.StatusGone)
+			return
+		}
 		http.Error(w, "backup failed", http.StatusInternalServerError)
 		return
 	}
-	if err := checkBackupMD5Size(req.Context(), &backupConf); err != nil {
-		log.Error(err, "check backup md5 file size")
+
+	if err := uploadCheckpoints(req.Context(), &backupConf, lsnDir); err != nil {
+		log.Error(err, "upload checkpoints")
+		http.Error(w, "backup failed", http.StatusInternalServerError)
+		return
+	}
+	// Metadata is informational, failing to collect it doesn't fail the backup.
+	if metadata, err := collectMetadata(backupName, ns, lsnDir, manifest.Size); err != nil {
+		log.Error(err, "failed to collect backup metadata")
+	} else if err := stg.PutObject(req.Context(), xb.MetadataObjectName(backupConf.Destination), bytes.NewReader(metadata), int64(len(metadata))); err != nil {
+		log.Error(err, "failed to upload backup metadata")
+	}
+	// Manifest is written only after xtrabackup exited successfully,
+	// since the stream ends the same way if xtrabackup fails.
+	if err := cloud.PutManifest(req.Context(), stg, backupConf.Destination, manifest, cloud.Options{}); err != nil {
+		log.Error(err, "put backup manifest")
 		http.Error(w, "backup failed", http.StatusInternalServerError)
 		return
 	}
 	log.Info("Backup finished successfully", "destination", backupConf.Destination, "storage", backupConf.Type)
 }
 
+// cleanupCanceledBackup deletes the objects uploaded before the backup was canceled.
+func cleanupCanceledBackup(ctx context.Context, cfg *xb.BackupConfig) {
+	log.Info("Backup canceled, deleting uploaded objects", "destination", cfg.Destination, "storage", cfg.Type)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	if err := deleteBackup(ctx, cfg); err != nil {
+		log.Error(err, "failed to delete canceled backup")
+	}
+}
+
+// cancelHandler stops the running backup. Objects uploaded by then are deleted by createBackupHandler.
+func cancelHandler(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.Split(req.URL.Path, "/")
+	if len(path) < 3 || path[2] == "" {
+		http.Error(w, "backup name must be provided in URL", http.StatusBadRequest)
+		return
+	}
+	backupName := path[2]
+
+	if !status.CancelBackup(backupName) {
+		http.Error(w, "backup is not running", http.StatusNotFound)
+		return
+	}
+	log.Info("Canceling backup", "name", backupName)
+}
+
+// logProgress periodically logs the number of bytes uploaded by the uploader until the returned function is called.
+func logProgress(uploader *cloud.Uploader) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				read, uploaded := uploader.Progress()
+				log.Info("Backup uploaded", "read", read, "uploaded", uploaded)
+				return
+			case <-ticker.C:
+				read, uploaded := uploader.Progress()
+				log.Info("Backup progress", "read", read, "uploaded", uploaded)
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// streamBackup writes xtrabackup output to the response.
+// It's used for filesystem storage, where the backup job stores the stream on its volume.
+func streamBackup(w io.Writer, xtrabackup *exec.Cmd, xbOut, xbErr io.Reader, logWriter io.Writer) error {
+	if err := xtrabackup.Start(); err != nil {
+		return errors.Wrap(err, "start xtrabackup command")
+	}
+
+	var g errgroup.Group
+	g.Go(func() error {
+		_, err := io.Copy(logWriter, xbErr)
+		return errors.Wrap(err, "copy xtrabackup stderr")
+	})
+
+	_, copyErr := io.Copy(w, xbOut)
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	if err := xtrabackup.Wait(); err != nil {
+		return errors.Wrap(err, "wait for xtrabackup to finish")
+	}
+	if copyErr != nil {
+		return errors.Wrap(copyErr, "write backup stream")
+	}
+
+	return nil
+}
+
+// countingWriter counts bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n atomic.Int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+func progressHandler(w http.ResponseWriter, req *http.Request) {
+	path := strings.Split(req.URL.Path, "/")
+	if len(path) < 3 {
+		http.Error(w, "backup name must be provided in URL", http.StatusBadRequest)
+		return
+	}
+
+	read, uploaded, ok := status.GetProgress(path[2])
+	if !ok {
+		http.Error(w, "backup is not running", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(apiv1alpha1.BackupProgress{
+		BytesRead:     read,
+		BytesUploaded: uploaded,
+	})
+	if err != nil {
+		log.Error(err, "failed to write progress")
+	}
+}
+
+func metadataHandler(w http.ResponseWriter, req *http.Request) {
+	path := strings.Split(req.URL.Path, "/")
+	if len(path) < 3 {
+		http.Error(w, "backup name must be provided in URL", http.StatusBadRequest)
+		return
+	}
+
+	data, err := os.ReadFile(metadataFile(path[2]))
+	if err != nil {
+		if os.IsNotExist(err) {
+			http.Error(w, "metadata not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to read metadata", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
 func logHandler(w http.ResponseWriter, req *http.Request) {
 	path := strings.Split(req.URL.Path, "/")
 	if len(path) < 3 {
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
+)
+
+const xtrabackupTimeFormat = "2006-01-02 15:04:05"
+
+// binlogPos matches binlog_pos of xtrabackup_info, e.g.
+// filename 'binlog.000002', position '157', GTID of the last change '3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5'
+var binlogPos = regexp.MustCompile(`filename '([^']*)', position '(\d+)'(?:, GTID of the last change '([^']*)')?`)
+
+// parseKeyValues parses "key = value" lines of xtrabackup_checkpoints and xtrabackup_info.
+func parseKeyValues(r io.Reader) (map[string]string, error) {
+	values := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	key := ""
+	for scanner.Scan() {
+		k, v, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			// GTID sets with multiple UUIDs are split into multiple lines.
+			if key != "" {
+				values[key] += strings.TrimSpace(scanner.Text())
+			}
+			continue
+		}
+		key = strings.TrimSpace(k)
+		values[key] = strings.TrimSpace(v)
+	}
+
+	return values, scanner.Err()
+}
+
+func readKeyValues(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "open %s", path)
+	}
+	defer f.Close()
+
+	values, err := parseKeyValues(f)
+	return values, errors.Wrapf(err, "read %s", path)
+}
+
+// backupMetadata collects metadata of the backup from the files xtrabackup writes to lsnDir.
+func backupMetadata(lsnDir string, size int64) (*apiv1alpha1.BackupMetadata, error) {
+	info, err := readKeyValues(filepath.Join(lsnDir, "xtrabackup_info"))
+	if err != nil {
+		return nil, err
+	}
+	checkpoints, err := readKeyValues(filepath.Join(lsnDir, "xtrabackup_checkpoints"))
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &apiv1alpha1.BackupMetadata{
+		SizeBytes:         size,
+		MySQLVersion:      info["server_version"],
+		XtraBackupVersion: info["tool_version"],
+		FromLSN:           checkpoints["from_lsn"],
+		ToLSN:             checkpoints["to_lsn"],
+		LastLSN:           checkpoints["last_lsn"],
+	}
+
+	if t, err := time.ParseInLocation(xtrabackupTimeFormat, info["start_time"], time.Local); err == nil {
+		metadata.StartedAt = &metav1.Time{Time: t}
+	}
+	if t, err := time.ParseInLocation(xtrabackupTimeFormat, info["end_time"], time.Local); err == nil {
+		metadata.FinishedAt = &metav1.Time{Time: t}
+	}
+	if metadata.StartedAt != nil && metadata.FinishedAt != nil {
+		metadata.DurationSeconds = int64(metadata.FinishedAt.Sub(metadata.StartedAt.Time).Seconds())
+	}
+
+	if m := binlogPos.FindStringSubmatch(info["binlog_pos"]); m != nil {
+		metadata.BinlogFile = m[1]
+		metadata.BinlogPosition, _ = strconv.ParseInt(m[2], 10, 64)
+		metadata.GTIDExecuted = m[3]
+	} else if data, err := os.ReadFile(filepath.Join(lsnDir, "xtrabackup_binlog_info")); err == nil {
+		// xtrabackup_binlog_info has tab separated file name, position and GTID set.
+		fields := strings.SplitN(strings.TrimSpace(string(data)), "\t", 3)
+		metadata.BinlogFile = fields[0]
+		if len(fields) > 1 {
+			metadata.BinlogPosition, _ = strconv.ParseInt(fields[1], 10, 64)
+		}
+		if len(fields) > 2 {
+			metadata.GTIDExecuted = strings.ReplaceAll(fields[2], "\n", "")
+		}
+	}
+
+	return metadata, nil
+}
+
+func metadataFile(backupName string) string {
+	return filepath.Join(mysql.BackupLogDir, backupName+".metadata.json")
+}
+
+// collectMetadata stores metadata of the backup in the log directory, so it can be served to the operator.
+func collectMetadata(backupName, namespace, lsnDir string, size int64) ([]byte, error) {
+	metadata, err := backupMetadata(lsnDir, size)
+	if err != nil {
+		return nil, errors.Wrap(err, "get backup metadata")
+	}
+	metadata.Namespace = namespace
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal metadata")
+	}
+
+	if err := os.WriteFile(metadataFile(backupName), data, 0o666); err != nil {
+		return nil, errors.Wrap(err, "write metadata")
+	}
+
+	return data, nil
+}
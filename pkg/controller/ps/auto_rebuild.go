@@ -0,0 +1,140 @@
+package ps
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	database "github.com/percona/percona-server-mysql-operator/pkg/db"
+	"github.com/percona/percona-server-mysql-operator/pkg/k8s"
+	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
+	"github.com/percona/percona-server-mysql-operator/pkg/naming"
+)
+
+// nonRecoverableReplicationErrors are the applier errors caused by the data of the replica
+// diverged from the primary. Restarting replication doesn't help with them.
+var nonRecoverableReplicationErrors = map[int]string{
+	1007: "ER_DB_CREATE_EXISTS",
+	1008: "ER_DB_DROP_EXISTS",
+	1032: "ER_KEY_NOT_FOUND",
+	1050: "ER_TABLE_EXISTS_ERROR",
+	1051: "ER_BAD_TABLE_ERROR",
+	1062: "ER_DUP_ENTRY",
+	1146: "ER_NO_SUCH_TABLE",
+	1452: "ER_NO_REFERENCED_ROW_2",
+}
+
+// reconcileAutoRebuild re-clones asynchronous replicas stopped by a non-recoverable replication error.
+// Such replicas are marked with the time the error was found and rebuilt once
+// spec.mysql.autoRebuild.errorTimeout passes. Replicas are rebuilt one at a time.
+// Replicas are checked once per replicationCheckInterval.
+func (r *PerconaServerMySQLReconciler) reconcileAutoRebuild(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL) error {
+	log := logf.FromContext(ctx).WithName("reconcileAutoRebuild")
+
+	if !cr.Spec.MySQL.IsAsync() || !cr.Spec.MySQL.AutoRebuild.Enabled || !cr.OrchestratorEnabled() || cr.Status.Orchestrator.Ready == 0 {
+		return nil
+	}
+	if !r.replicationCheckDue(cr, "auto-rebuild") {
+		return nil
+	}
+
+	primary, err := r.getPrimaryFromOrchestrator(ctx, cr)
+	if err != nil {
+		return errors.Wrap(err, "get primary")
+	}
+	if primary.Alias == "" {
+		return nil
+	}
+
+	pods, err := k8s.PodsByLabels(ctx, r.Client, mysql.MatchLabels(cr), cr.Namespace)
+	if err != nil {
+		return errors.Wrap(err, "get pods")
+	}
+
+	operatorPass, err := k8s.UserPassword(ctx, r.Client, cr, apiv1alpha1.UserOperator)
+	if err != nil {
+		return errors.Wrap(err, "get operator password")
+	}
+
+	timeout := cr.Spec.MySQL.AutoRebuild.ErrorTimeoutDuration()
+	var broken *corev1.Pod
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Name == primary.Alias || !k8s.IsPodReady(*pod) {
+			continue
+		}
+
+		rm := database.NewReplicationManager(pod, r.ClientCmd, apiv1alpha1.UserOperator, operatorPass, mysql.PodFQDN(cr, pod))
+		errNum, errMsg, err := rm.GetReplicationApplierError(ctx)
+		if err != nil {
+			log.Error(err, "failed to get replication applier error", "pod", pod.Name)
+			continue
+		}
+
+		since, marked := pod.Annotations[naming.AnnotationReplicationErrorSince.String()]
+		if _, ok := nonRecoverableReplicationErrors[errNum]; !ok {
+			if marked {
+				log.Info("Replication error is gone", "pod", pod.Name)
+				if err := r.markReplicationError(ctx, pod, ""); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		sinceTime, err := time.Parse(time.RFC3339, since)
+		if !marked || err != nil {
+			log.Info("Replica is stopped by replication error", "pod", pod.Name, "error", errNum, "message", errMsg)
+			r.Recorder.Event(cr, "Warning", "ReplicationBroken",
+				fmt.Sprintf("%s is stopped by replication error %d (%s), it will be rebuilt in %s",
+					pod.Name, errNum, nonRecoverableReplicationErrors[errNum], timeout))
+			if err := r.markReplicationError(ctx, pod, time.Now().UTC().Format(time.RFC3339)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if time.Since(sinceTime) >= timeout && broken == nil {
+			broken = pod
+		}
+	}
+
+	if broken == nil {
+		return nil
+	}
+
+	// The clone needs a healthy donor, the other pods should be up.
+	if cr.Status.MySQL.Ready != cr.MySQLSpec().Size {
+		log.V(1).Info("Waiting for all MySQL pods to be ready to rebuild replica", "pod", broken.Name)
+		return nil
+	}
+
+	r.Recorder.Event(cr, "Normal", "ReplicaRebuilding",
+		fmt.Sprintf("Rebuilding %s stopped by replication error since %s", broken.Name, broken.Annotations[naming.AnnotationReplicationErrorSince.String()]))
+	if err := r.recloneReplica(ctx, broken); err != nil {
+		return errors.Wrapf(err, "rebuild %s", broken.Name)
+	}
+
+	return nil
+}
+
+// markReplicationError sets the time of the replication error of the pod or removes it if it's empty.
+func (r *PerconaServerMySQLReconciler) markReplicationError(ctx context.Context, pod *corev1.Pod, since string) error {
+	patched := pod.DeepCopy()
+	if since == "" {
+		delete(patched.Annotations, naming.AnnotationReplicationErrorSince.String())
+	} else {
+		k8s.AddAnnotation(patched, naming.AnnotationReplicationErrorSince.String(), since)
+	}
+
+	if err := r.Client.Patch(ctx, patched, client.MergeFrom(pod)); err != nil {
+		return errors.Wrapf(err, "patch pod %s", pod.Name)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package ps
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/naming"
+)
+
+// fakeApplierExec models the applier errors of the MySQL pods. The primary is cluster1-mysql-0.
+type fakeApplierExec struct {
+	errors   map[string]int
+	commands map[string][]string
+}
+
+func (e *fakeApplierExec) Exec(_ context.Context, pod *corev1.Pod, _ string, command []string, _ io.Reader, stdout, _ io.Writer, _ bool) error {
+	cmd := strings.Join(command, " ")
+
+	switch {
+	case strings.Contains(cmd, "api/master/"):
+		fmt.Fprint(stdout, `{"InstanceAlias":"cluster1-mysql-0"}`)
+		return nil
+	case strings.Contains(cmd, "replication_applier_status_by_worker"):
+		if num := e.errors[pod.Name]; num != 0 {
+			fmt.Fprintf(stdout, "error_number\terror_message\n%d\tCould not execute Write_rows event\n", num)
+		}
+		return nil
+	}
+
+	e.commands[pod.Name] = append(e.commands[pod.Name], command[len(command)-1])
+	return nil
+}
+
+func (e *fakeApplierExec) REST() restclient.Interface {
+	return nil
+}
+
+func TestReconcileAutoRebuild(t *testing.T) {
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	longAgo := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name    string
+		errNum  int
+		since   string
+		ready   int32
+		marked  bool
+		rebuilt bool
+	}{
+		{
+			name:   "mark replica",
+			errNum: 1062,
+			ready:  2,
+			marked: true,
+		},
+		{
+			name:   "wait for timeout",
+			errNum: 1032,
+			since:  time.Now().UTC().Format(time.RFC3339),
+			ready:  2,
+			marked: true,
+		},
+		{
+			name:    "rebuild",
+			errNum:  1062,
+			since:   longAgo,
+			ready:   2,
+			rebuilt: true,
+		},
+		{
+			name:   "wait for donor",
+			errNum: 1062,
+			since:  longAgo,
+			ready:  1,
+			marked: true,
+		},
+		{
+			name:   "recoverable error",
+			errNum: 2003,
+			ready:  2,
+		},
+		{
+			name:  "error is gone",
+			since: longAgo,
+			ready: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr, err := readDefaultCR("cluster1", "ns")
+			if err != nil {
+				t.Fatal(err)
+			}
+			cr.Spec.MySQL.ClusterType = apiv1alpha1.ClusterTypeAsync
+			cr.Spec.MySQL.Size = 2
+			cr.Spec.MySQL.AutoRebuild.Enabled = true
+			cr.Spec.Orchestrator.Enabled = true
+			cr.Status.MySQL.Ready = tt.ready
+			cr.Status.Orchestrator.Ready = 1
+
+			pods := makeFakeReadyPods(cr, 2, "mysql")
+			if tt.since != "" {
+				pods[1].SetAnnotations(map[string]string{naming.AnnotationReplicationErrorSince.String(): tt.since})
+			}
+			objects := appendSlices(pods, makeFakeReadyPods(cr, 1, "orchestrator"))
+			objects = append(objects, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: cr.InternalSecretName(), Namespace: cr.Namespace},
+				Data:       map[string][]byte{string(apiv1alpha1.UserOperator): []byte("operator-pass")},
+			})
+
+			exec := &fakeApplierExec{
+				errors:   map[string]int{"cluster1-mysql-1": tt.errNum},
+				commands: make(map[string][]string),
+			}
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			r := &PerconaServerMySQLReconciler{
+				Client:    cl,
+				Scheme:    scheme,
+				ClientCmd: exec,
+				Recorder:  record.NewFakeRecorder(10),
+				Crons:     NewCronRegistry(),
+			}
+
+			if err := r.reconcileAutoRebuild(ctx, cr); err != nil {
+				t.Fatal(err)
+			}
+
+			pod := new(corev1.Pod)
+			err = cl.Get(ctx, types.NamespacedName{Name: "cluster1-mysql-1", Namespace: cr.Namespace}, pod)
+			if tt.rebuilt {
+				if !k8serrors.IsNotFound(err) {
+					t.Fatalf("expected replica pod to be deleted, got %v", err)
+				}
+				if fmt.Sprint(exec.commands["cluster1-mysql-1"]) != "[/var/lib/mysql/clone.lock]" {
+					t.Fatalf("expected clone lock to be removed, got %q", exec.commands)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(exec.commands) != 0 {
+				t.Fatalf("expected replica not to be rebuilt, got %q", exec.commands)
+			}
+
+			since, ok := pod.Annotations[naming.AnnotationReplicationErrorSince.String()]
+			if ok != tt.marked {
+				t.Fatalf("expected replica to be marked: %t, got %v", tt.marked, pod.Annotations)
+			}
+			if tt.since != "" && tt.marked && since != tt.since {
+				t.Fatalf("expected mark %s to be kept, got %s", tt.since, since)
+			}
+		})
+	}
+}
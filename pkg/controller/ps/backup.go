@@ -4,7 +4,9 @@ import (
 	"context"
 	"crypto/sha1"
 	"encoding/hex"
+	"fmt"
 	"hash/crc32"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
@@ -15,7 +17,6 @@ import (
 	"github.com/robfig/cron/v3"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -27,12 +28,18 @@ import (
 type cronRegistry struct {
 	crons      *cron.Cron
 	backupJobs *sync.Map
+	// lastStorageGC keeps the time storages were garbage collected last.
+	lastStorageGC *sync.Map
+	// lastReplicationCheck keeps the time replication state was queried on each MySQL pod last.
+	lastReplicationCheck *sync.Map
 }
 
 func NewCronRegistry() cronRegistry {
 	c := cronRegistry{
-		crons:      cron.New(),
-		backupJobs: new(sync.Map),
+		crons:                cron.New(),
+		backupJobs:           new(sync.Map),
+		lastStorageGC:        new(sync.Map),
+		lastReplicationCheck: new(sync.Map),
 	}
 
 	c.crons.Start()
@@ -81,13 +88,13 @@ func (r *cronRegistry) getBackupJob(bcp apiv1alpha1.BackupSchedule) BackupSchedu
 	return sch
 }
 
-func (r *cronRegistry) addBackupJob(ctx context.Context, cl client.Client, cluster *apiv1alpha1.PerconaServerMySQL, bcp apiv1alpha1.BackupSchedule) error {
+func (r *cronRegistry) addBackupJob(ctx context.Context, cl client.Client, cluster *apiv1alpha1.PerconaServerMySQL, bcp apiv1alpha1.BackupSchedule, isBusy scheduleGuardFunc) error {
 	if bcp.Schedule == "" {
 		r.stopBackupJob(bcp.Name)
 		return nil
 	}
 	r.deleteBackupJob(bcp.Name)
-	jobID, err := r.addFuncWithSeconds(bcp.Schedule, r.createBackupJobFunc(ctx, cl, cluster, bcp))
+	jobID, err := r.addFuncWithSeconds(bcp.Schedule, r.createBackupJobFunc(ctx, cl, cluster, bcp, isBusy))
 	if err != nil {
 		return errors.Wrap(err, "add func")
 	}
@@ -99,7 +106,7 @@ func (r *cronRegistry) addBackupJob(ctx context.Context, cl client.Client, clust
 	return nil
 }
 
-func (r *cronRegistry) createBackupJobFunc(ctx context.Context, cl client.Client, cluster *apiv1alpha1.PerconaServerMySQL, backupJob apiv1alpha1.BackupSchedule) func() {
+func (r *cronRegistry) createBackupJobFunc(ctx context.Context, cl client.Client, cluster *apiv1alpha1.PerconaServerMySQL, backupJob apiv1alpha1.BackupSchedule, isBusy scheduleGuardFunc) func() {
 	log := logf.FromContext(ctx)
 
 	return func() {
@@ -114,6 +121,12 @@ func (r *cronRegistry) createBackupJobFunc(ctx context.Context, cl client.Client
 			log.Error(err, "failed to get cluster")
 		}
 
+		if cr.Spec.Backup != nil && cr.Spec.Backup.ScheduleGuard != nil && isBusy != nil {
+			if !waitForIdleCluster(ctx, cr, backupJob.Name, isBusy) {
+				return
+			}
+		}
+
 		bcp := &apiv1alpha1.PerconaServerMySQLBackup{
 			ObjectMeta: metav1.ObjectMeta{
 				Finalizers: []string{naming.FinalizerDeleteBackup},
@@ -128,6 +141,10 @@ func (r *cronRegistry) createBackupJobFunc(ctx context.Context, cl client.Client
 			Spec: apiv1alpha1.PerconaServerMySQLBackupSpec{
 				ClusterName: cr.Name,
 				StorageName: backupJob.StorageName,
+				Type:        backupJob.Type,
+				Method:      backupJob.Method,
+				Verify:      backupJob.Verify,
+				ReplicateTo: backupJob.ReplicateTo,
 			},
 		}
 		err = cl.Create(ctx, bcp)
@@ -154,12 +171,13 @@ func (r *PerconaServerMySQLReconciler) reconcileScheduledBackup(ctx context.Cont
 		backups[bcp.Name] = bcp
 
 		sch := r.Crons.getBackupJob(bcp)
-		if ok && sch.Schedule == bcp.Schedule && sch.StorageName == bcp.StorageName {
+		if ok && sch.Schedule == bcp.Schedule && sch.StorageName == bcp.StorageName && sch.Type == bcp.Type &&
+			sch.Method == bcp.Method && reflect.DeepEqual(sch.Verify, bcp.Verify) && reflect.DeepEqual(sch.ReplicateTo, bcp.ReplicateTo) {
 			continue
 		}
 
 		log.Info("Creating or updating backup job", "name", bcp.Name, "schedule", bcp.Schedule)
-		if err := r.Crons.addBackupJob(ctx, r.Client, cr, bcp); err != nil {
+		if err := r.Crons.addBackupJob(ctx, r.Client, cr, bcp, r.scheduledBackupBusyReason); err != nil {
 			log.Error(err, "can't add backup job", "backup name", cr.Spec.Backup.Schedule[i].Name, "schedule", bcp.Schedule)
 		}
 	}
@@ -177,11 +195,11 @@ func (r *PerconaServerMySQLReconciler) reconcileScheduledBackup(ctx context.Cont
 			return true
 		}
 
-		if spec.Keep <= 0 {
+		if spec.Keep <= 0 && spec.Retention == nil {
 			return true
 		}
 
-		oldBackups, err := r.oldScheduledBackups(ctx, cr, item.Name, spec.Keep)
+		oldBackups, err := r.oldScheduledBackups(ctx, cr, item.Name, spec, time.Now())
 		if err != nil {
 			log.Error(err, "failed to list old backups", "name", item.Name)
 			return true
@@ -206,44 +224,106 @@ func backupJobClusterPrefix(clusterName string) string {
 	return hex.EncodeToString(h.Sum(nil))[:5]
 }
 
-func (r *PerconaServerMySQLReconciler) oldScheduledBackups(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL, ancestor string, keep int) ([]apiv1alpha1.PerconaServerMySQLBackup, error) {
+func (r *PerconaServerMySQLReconciler) oldScheduledBackups(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL, ancestor string, spec apiv1alpha1.BackupSchedule, now time.Time) ([]apiv1alpha1.PerconaServerMySQLBackup, error) {
+	// All backups of the cluster are listed: incremental backups of other schedules and manual ones
+	// can be taken on top of the backups of this schedule.
 	bcpList := apiv1alpha1.PerconaServerMySQLBackupList{}
-	err := r.List(ctx,
-		&bcpList,
-		&client.ListOptions{
-			Namespace: cr.Namespace,
-			LabelSelector: labels.SelectorFromSet(map[string]string{
-				naming.LabelCluster:        cr.Name,
-				naming.LabelBackupAncestor: ancestor,
-			}),
-		},
-	)
+	err := r.List(ctx, &bcpList, &client.ListOptions{Namespace: cr.Namespace})
 	if err != nil {
 		return []apiv1alpha1.PerconaServerMySQLBackup{}, err
 	}
 
-	if len(bcpList.Items) <= keep {
-		return []apiv1alpha1.PerconaServerMySQLBackup{}, nil
-	}
-
 	backups := []apiv1alpha1.PerconaServerMySQLBackup{}
 	for _, bcp := range bcpList.Items {
+		if bcp.Labels[naming.LabelCluster] != cr.Name || bcp.Labels[naming.LabelBackupAncestor] != ancestor {
+			continue
+		}
 		if bcp.Status.State == apiv1alpha1.BackupSucceeded {
 			backups = append(backups, bcp)
 		}
 	}
 
-	if len(backups) <= keep {
-		return []apiv1alpha1.PerconaServerMySQLBackup{}, nil
+	backups = outdatedBackups(backups, spec, now)
+
+	// Backups incremental backups are taken on top of are kept until the incremental ones are deleted.
+	required := make(map[apiv1alpha1.BackupDestination]struct{})
+	for _, bcp := range bcpList.Items {
+		if bcp.Spec.ClusterName != cr.Name {
+			continue
+		}
+		for _, dest := range bcp.Status.Chain {
+			required[dest] = struct{}{}
+		}
 	}
 
+	outdated := make([]apiv1alpha1.PerconaServerMySQLBackup, 0, len(backups))
+	for _, bcp := range backups {
+		if _, ok := required[bcp.Status.Destination]; ok {
+			continue
+		}
+		outdated = append(outdated, bcp)
+	}
+
+	return outdated, nil
+}
+
+// outdatedBackups returns backups which are outside of spec.keep or older than spec.retention.maxAge
+// and are not protected by the other rules of spec.retention.
+func outdatedBackups(backups []apiv1alpha1.PerconaServerMySQLBackup, spec apiv1alpha1.BackupSchedule, now time.Time) []apiv1alpha1.PerconaServerMySQLBackup {
 	sort.Slice(backups, func(i, j int) bool {
-		return backups[i].CreationTimestamp.Compare(backups[j].CreationTimestamp.Time) == -1
+		return backups[i].CreationTimestamp.After(backups[j].CreationTimestamp.Time)
 	})
 
-	backups = backups[:len(backups)-keep]
+	retention := apiv1alpha1.BackupRetentionSpec{}
+	if spec.Retention != nil {
+		retention = *spec.Retention
+	}
+
+	protected := make(map[string]struct{})
+	keepNewestPerPeriod(backups, retention.KeepDaily, protected, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	keepNewestPerPeriod(backups, retention.KeepWeekly, protected, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-%d", year, week)
+	})
+	keepNewestPerPeriod(backups, retention.KeepMonthly, protected, func(t time.Time) string {
+		return t.Format("2006-01")
+	})
+
+	outdated := []apiv1alpha1.PerconaServerMySQLBackup{}
+	for i, bcp := range backups {
+		expired := spec.Keep > 0 && i >= spec.Keep
+		if retention.MaxAge != nil && now.Sub(bcp.CreationTimestamp.Time) > retention.MaxAge.Duration {
+			expired = true
+		}
+		if !expired || i < retention.MinCount {
+			continue
+		}
+		if _, ok := protected[bcp.Name]; ok {
+			continue
+		}
+		outdated = append(outdated, bcp)
+	}
+
+	return outdated
+}
 
-	return backups, nil
+// keepNewestPerPeriod adds the newest backup of each of the last n periods to protected.
+// Backups must be sorted from the newest to the oldest.
+func keepNewestPerPeriod(backups []apiv1alpha1.PerconaServerMySQLBackup, n int, protected map[string]struct{}, period func(time.Time) string) {
+	seen := make(map[string]struct{})
+	for _, bcp := range backups {
+		if len(seen) >= n {
+			return
+		}
+		p := period(bcp.CreationTimestamp.UTC())
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		protected[bcp.Name] = struct{}{}
+	}
 }
 
 func generateBackupName(cr *apiv1alpha1.PerconaServerMySQL, backupJob apiv1alpha1.BackupSchedule) string {
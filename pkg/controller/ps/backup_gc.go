@@ -0,0 +1,213 @@
+package ps
+
+import (
+	"context"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
+)
+
+const (
+	backupStorageGCInterval = time.Hour
+	defaultBackupGCMinAge   = 24 * time.Hour
+)
+
+// reconcileBackupStorageGC deletes the cluster's backups which are found on storages with
+// garbageCollection enabled but have no PerconaServerMySQLBackup. Each storage is listed
+// at most once per backupStorageGCInterval.
+func (r *PerconaServerMySQLReconciler) reconcileBackupStorageGC(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL) error {
+	log := logf.FromContext(ctx).WithName("reconcileBackupStorageGC")
+
+	if cr.Spec.Backup == nil || !cr.Spec.Backup.Enabled || r.NewStorageClient == nil {
+		return nil
+	}
+
+	for name, stg := range cr.Spec.Backup.Storages {
+		if stg == nil || stg.GarbageCollection == nil || !stg.GarbageCollection.Enabled {
+			continue
+		}
+		if stg.Type == apiv1alpha1.BackupStorageFilesystem {
+			log.V(1).Info("Garbage collection is not supported for filesystem storages", "storage", name)
+			continue
+		}
+		// Backups of a recreated cluster are orphaned until they are imported.
+		if !slices.Contains(cr.Status.BackupStoragesSynced, name) || backupSyncPending(cr, name) {
+			log.V(1).Info("Garbage collection waits for backups to be synced from the storage", "storage", name)
+			continue
+		}
+
+		key := cr.Namespace + "/" + cr.Name + "/" + name
+		if last, ok := r.Crons.lastStorageGC.Load(key); ok && time.Since(last.(time.Time)) < backupStorageGCInterval {
+			continue
+		}
+		r.Crons.lastStorageGC.Store(key, time.Now())
+
+		if err := r.deleteOrphanedBackups(ctx, cr, stg, time.Now()); err != nil {
+			log.Error(err, "failed to delete orphaned backups", "storage", name)
+		}
+	}
+
+	return nil
+}
+
+func (r *PerconaServerMySQLReconciler) deleteOrphanedBackups(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL, stg *apiv1alpha1.BackupStorageSpec, now time.Time) error {
+	log := logf.FromContext(ctx)
+
+	opts, err := storage.GetOptionsFromStorage(ctx, r.Client, cr.Namespace, stg)
+	if err != nil {
+		return errors.Wrap(err, "get storage options")
+	}
+	stgClient, err := r.NewStorageClient(ctx, opts)
+	if err != nil {
+		return errors.Wrap(err, "new storage client")
+	}
+
+	referenced, err := r.referencedBackupDestinations(ctx, cr.Namespace)
+	if err != nil {
+		return errors.Wrap(err, "get referenced backups")
+	}
+
+	objects, err := stgClient.ListObjects(ctx, "")
+	if err != nil {
+		return errors.Wrap(err, "list objects")
+	}
+
+	minAge := defaultBackupGCMinAge
+	if stg.GarbageCollection.MinAge != nil {
+		minAge = stg.GarbageCollection.MinAge.Duration
+	}
+
+	dirs := orphanedBackupDirs(cr.Name, objects, func(dir string) bool {
+		_, ok := referenced[storageDestination(stg, dir)]
+		return ok
+	}, now.Add(-minAge))
+
+	names := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		names = append(names, dir)
+	}
+	sort.Strings(names)
+
+	for _, dir := range names {
+		// Clusters with the same name in other namespaces can use the storage.
+		metadata, err := readBackupMetadata(ctx, stgClient, dir)
+		if err != nil {
+			return errors.Wrapf(err, "read metadata of backup %s", dir)
+		}
+		if metadata == nil || metadata.Namespace != cr.Namespace {
+			log.V(1).Info("Skipping orphaned backup of another or unknown namespace", "backup", dir)
+			continue
+		}
+
+		log.Info("Deleting orphaned backup from storage", "backup", dir, "objects", len(dirs[dir]))
+		for _, obj := range dirs[dir] {
+			if err := stgClient.DeleteObject(ctx, obj); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+				return errors.Wrapf(err, "delete object %s", obj)
+			}
+		}
+	}
+
+	return nil
+}
+
+// referencedBackupDestinations returns destinations of backups which have PerconaServerMySQLBackup objects
+// and their copies, backups incremental ones are taken on top of and backups restores are made from.
+func (r *PerconaServerMySQLReconciler) referencedBackupDestinations(ctx context.Context, namespace string) (map[apiv1alpha1.BackupDestination]struct{}, error) {
+	referenced := make(map[apiv1alpha1.BackupDestination]struct{})
+
+	backups := new(apiv1alpha1.PerconaServerMySQLBackupList)
+	if err := r.Client.List(ctx, backups, client.InNamespace(namespace)); err != nil {
+		return nil, errors.Wrap(err, "list backups")
+	}
+	for _, bcp := range backups.Items {
+		if bcp.Status.Destination != "" {
+			referenced[bcp.Status.Destination] = struct{}{}
+		}
+		for _, dest := range bcp.Status.Chain {
+			referenced[dest] = struct{}{}
+		}
+		for _, c := range bcp.Status.Copies {
+			if c.Destination != "" {
+				referenced[c.Destination] = struct{}{}
+			}
+			for _, dest := range c.Chain {
+				referenced[dest] = struct{}{}
+			}
+		}
+	}
+
+	restores := new(apiv1alpha1.PerconaServerMySQLRestoreList)
+	if err := r.Client.List(ctx, restores, client.InNamespace(namespace)); err != nil {
+		return nil, errors.Wrap(err, "list restores")
+	}
+	for _, restore := range restores.Items {
+		if restore.Spec.BackupSource != nil {
+			referenced[restore.Spec.BackupSource.Destination] = struct{}{}
+		}
+	}
+
+	return referenced, nil
+}
+
+// orphanedBackupDirs groups objects by the top-level directory and returns directories
+// which are named like backups of the cluster, were taken before createdBefore and are not referenced.
+// The <dir>.md5 and <dir>.checkpoints objects stored next to a backup belong to its directory.
+func orphanedBackupDirs(clusterName string, objects []string, isReferenced func(dir string) bool, createdBefore time.Time) map[string][]string {
+	re := backupDirRegexp(clusterName)
+
+	dirs := make(map[string][]string)
+	for _, obj := range objects {
+		dir, _, found := strings.Cut(strings.TrimPrefix(obj, "/"), "/")
+		if !found {
+			dir, found = strings.CutSuffix(dir, ".md5")
+		}
+		if !found {
+			dir, found = strings.CutSuffix(dir, ".checkpoints")
+		}
+		if !found {
+			continue
+		}
+
+		m := re.FindStringSubmatch(dir)
+		if m == nil {
+			continue
+		}
+		created, err := time.Parse("2006-01-02-15:04:05", m[1])
+		if err != nil || !created.Before(createdBefore) {
+			continue
+		}
+		if isReferenced(dir) {
+			continue
+		}
+
+		dirs[dir] = append(dirs[dir], obj)
+	}
+
+	return dirs
+}
+
+// storageDestination returns the destination the backup in the directory would have if it was taken by psbackup.
+func storageDestination(stg *apiv1alpha1.BackupStorageSpec, dir string) apiv1alpha1.BackupDestination {
+	var d apiv1alpha1.BackupDestination
+	switch stg.Type {
+	case apiv1alpha1.BackupStorageS3:
+		bucket, prefix := stg.S3.BucketAndPrefix()
+		d.SetS3Destination(path.Join(bucket, prefix), dir)
+	case apiv1alpha1.BackupStorageGCS:
+		bucket, prefix := stg.GCS.BucketAndPrefix()
+		d.SetGCSDestination(path.Join(bucket, prefix), dir)
+	case apiv1alpha1.BackupStorageAzure:
+		container, prefix := stg.Azure.ContainerAndPrefix()
+		d.SetAzureDestination(path.Join(container, prefix), dir)
+	}
+	return d
+}
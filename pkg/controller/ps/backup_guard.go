@@ -0,0 +1,200 @@
+package ps
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	database "github.com/percona/percona-server-mysql-operator/pkg/db"
+	"github.com/percona/percona-server-mysql-operator/pkg/k8s"
+	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
+)
+
+const (
+	defaultScheduleGuardCheckInterval = 5 * time.Minute
+	defaultScheduleGuardMaxDelay      = time.Hour
+)
+
+// scheduleGuardFunc returns the reason a scheduled backup should be deferred for
+// or an empty string if the backup can be taken now.
+type scheduleGuardFunc func(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL) (string, error)
+
+// nodeLoad is the load of the node a backup can be taken from. Unknown values are nil.
+type nodeLoad struct {
+	host        string
+	lag         *int64
+	loadAverage *float64
+}
+
+// scheduledBackupBusyReason measures the load of the nodes the backup can be taken from.
+// Replicas are used if there are any, the primary otherwise.
+func (r *PerconaServerMySQLReconciler) scheduledBackupBusyReason(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL) (string, error) {
+	log := logf.FromContext(ctx)
+
+	guard := cr.Spec.Backup.ScheduleGuard
+	if guard == nil || (guard.MaxReplicationLag == nil && guard.MaxLoadAverage == nil) {
+		return "", nil
+	}
+
+	operatorPass, err := k8s.UserPassword(ctx, r.Client, cr, apiv1alpha1.UserOperator)
+	if err != nil {
+		return "", errors.Wrap(err, "get operator password")
+	}
+
+	primary, err := r.getPrimaryHost(ctx, cr)
+	if err != nil {
+		return "", errors.Wrap(err, "get primary host")
+	}
+	primaryPod, _, _ := strings.Cut(primary, ".")
+
+	pods, err := k8s.PodsByLabels(ctx, r.Client, mysql.MatchLabels(cr), cr.Namespace)
+	if err != nil {
+		return "", errors.Wrap(err, "get pods")
+	}
+
+	var queues map[string]int64
+	if guard.MaxReplicationLag != nil && cr.Spec.MySQL.IsGR() {
+		for _, pod := range pods {
+			if pod.Name != primaryPod {
+				continue
+			}
+			rm := database.NewReplicationManager(&pod, r.ClientCmd, apiv1alpha1.UserOperator, operatorPass, mysql.PodFQDN(cr, &pod))
+			queues, err = rm.GetGroupReplicationApplierQueues(ctx)
+			if err != nil {
+				return "", errors.Wrap(err, "get applier queues")
+			}
+		}
+	}
+
+	var replicas, primaries []nodeLoad
+	for _, pod := range pods {
+		if !k8s.IsPodReady(pod) {
+			continue
+		}
+		host := mysql.PodFQDN(cr, &pod)
+		node := nodeLoad{host: host}
+
+		if guard.MaxLoadAverage != nil {
+			var outb, errb bytes.Buffer
+			cmd := []string{"cat", "/proc/loadavg"}
+			if err := r.ClientCmd.Exec(ctx, &pod, "mysql", cmd, nil, &outb, &errb, false); err != nil {
+				log.Error(err, "failed to get load average", "pod", pod.Name, "stderr", errb.String())
+			} else if load, err := parseLoadAverage(outb.String()); err != nil {
+				log.Error(err, "failed to parse load average", "pod", pod.Name)
+			} else {
+				node.loadAverage = &load
+			}
+		}
+
+		if pod.Name == primaryPod {
+			primaries = append(primaries, node)
+			continue
+		}
+
+		if guard.MaxReplicationLag != nil {
+			if cr.Spec.MySQL.IsGR() {
+				if q, ok := queues[host]; ok {
+					node.lag = &q
+				}
+			} else {
+				rm := database.NewReplicationManager(&pod, r.ClientCmd, apiv1alpha1.UserOperator, operatorPass, host)
+				if lag, err := rm.GetHeartbeatLag(ctx); err != nil {
+					log.Error(err, "failed to get replication lag", "pod", pod.Name)
+				} else {
+					node.lag = &lag
+				}
+			}
+		}
+		replicas = append(replicas, node)
+	}
+
+	if len(replicas) == 0 {
+		return busyReason(guard, primaries), nil
+	}
+	return busyReason(guard, replicas), nil
+}
+
+// busyReason returns an empty string if at least one of the nodes is below the thresholds.
+// Nodes with unknown load are treated as busy.
+func busyReason(guard *apiv1alpha1.BackupScheduleGuardSpec, nodes []nodeLoad) string {
+	if len(nodes) == 0 {
+		return "no ready MySQL pods"
+	}
+
+	reasons := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		var reason string
+		switch {
+		case guard.MaxReplicationLag != nil && node.lag == nil:
+			reason = "replication lag is unknown"
+		case guard.MaxReplicationLag != nil && *node.lag > *guard.MaxReplicationLag:
+			reason = fmt.Sprintf("replication lag %d is above %d", *node.lag, *guard.MaxReplicationLag)
+		case guard.MaxLoadAverage != nil && node.loadAverage == nil:
+			reason = "load average is unknown"
+		case guard.MaxLoadAverage != nil && *node.loadAverage > guard.MaxLoadAverage.AsApproximateFloat64():
+			reason = fmt.Sprintf("load average %.2f is above %s", *node.loadAverage, guard.MaxLoadAverage.String())
+		default:
+			return ""
+		}
+		reasons = append(reasons, node.host+": "+reason)
+	}
+
+	return strings.Join(reasons, "; ")
+}
+
+// parseLoadAverage returns the 1 minute load average from the content of /proc/loadavg.
+func parseLoadAverage(loadavg string) (float64, error) {
+	fields := strings.Fields(loadavg)
+	if len(fields) == 0 {
+		return 0, errors.New("empty loadavg")
+	}
+	return strconv.ParseFloat(fields[0], 64)
+}
+
+// waitForIdleCluster defers the scheduled backup while the guard reports the cluster is busy.
+// It returns false if the backup should be skipped.
+func waitForIdleCluster(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL, backupName string, isBusy scheduleGuardFunc) bool {
+	log := logf.FromContext(ctx)
+
+	guard := cr.Spec.Backup.ScheduleGuard
+	interval := defaultScheduleGuardCheckInterval
+	if guard.CheckInterval != nil && guard.CheckInterval.Duration > 0 {
+		interval = guard.CheckInterval.Duration
+	}
+	maxDelay := defaultScheduleGuardMaxDelay
+	if guard.MaxDelay != nil {
+		maxDelay = guard.MaxDelay.Duration
+	}
+	deadline := time.Now().Add(maxDelay)
+
+	for {
+		reason, err := isBusy(ctx, cr)
+		if err != nil {
+			// The backup is taken on schedule if the load can't be measured.
+			log.Error(err, "failed to check if cluster is busy", "name", backupName)
+			return true
+		}
+		if reason == "" {
+			return true
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			log.Info("Skipping scheduled backup, cluster is busy", "name", backupName, "reason", reason)
+			return false
+		}
+		log.Info("Deferring scheduled backup, cluster is busy", "name", backupName, "reason", reason, "retryAfter", interval)
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(interval):
+		}
+	}
+}
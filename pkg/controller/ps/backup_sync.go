@@ -0,0 +1,294 @@
+package ps
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/naming"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/cloud"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
+)
+
+// reconcileBackupSync imports backups from the storages listed in the sync-backups annotation
+// as PerconaServerMySQLBackup objects and removes the annotation afterwards.
+func (r *PerconaServerMySQLReconciler) reconcileBackupSync(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL) error {
+	log := logf.FromContext(ctx).WithName("reconcileBackupSync")
+
+	value, ok := cr.Annotations[string(naming.AnnotationSyncBackups)]
+	if !ok || cr.Spec.Backup == nil || r.NewStorageClient == nil {
+		return nil
+	}
+
+	var synced []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		stg, ok := cr.Spec.Backup.Storages[name]
+		if !ok || stg == nil {
+			log.Info("Storage to sync backups from is not found in spec.backup.storages", "storage", name)
+			continue
+		}
+		if stg.Type == apiv1alpha1.BackupStorageFilesystem {
+			log.Info("Syncing backups is not supported for filesystem storages", "storage", name)
+			continue
+		}
+
+		if err := r.importBackups(ctx, cr, name, stg); err != nil {
+			return errors.Wrapf(err, "import backups from storage %s", name)
+		}
+		synced = append(synced, name)
+	}
+
+	status := cr.Status
+	patch := client.MergeFrom(cr.DeepCopy())
+	delete(cr.Annotations, string(naming.AnnotationSyncBackups))
+	if err := r.Client.Patch(ctx, cr, patch); err != nil {
+		return errors.Wrapf(err, "remove %s annotation", naming.AnnotationSyncBackups)
+	}
+
+	// Patch overwrites the status with the stored one, it's written at the end of the reconcile.
+	cr.Status = status
+	for _, name := range synced {
+		if !slices.Contains(cr.Status.BackupStoragesSynced, name) {
+			cr.Status.BackupStoragesSynced = append(cr.Status.BackupStoragesSynced, name)
+		}
+	}
+
+	return nil
+}
+
+func (r *PerconaServerMySQLReconciler) importBackups(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL, storageName string, stg *apiv1alpha1.BackupStorageSpec) error {
+	log := logf.FromContext(ctx)
+
+	opts, err := storage.GetOptionsFromStorage(ctx, r.Client, cr.Namespace, stg)
+	if err != nil {
+		return errors.Wrap(err, "get storage options")
+	}
+	stgClient, err := r.NewStorageClient(ctx, opts)
+	if err != nil {
+		return errors.Wrap(err, "new storage client")
+	}
+
+	objects, err := stgClient.ListObjects(ctx, "")
+	if err != nil {
+		return errors.Wrap(err, "list objects")
+	}
+
+	known := make(map[apiv1alpha1.BackupDestination]*apiv1alpha1.PerconaServerMySQLBackup)
+	backups := new(apiv1alpha1.PerconaServerMySQLBackupList)
+	if err := r.Client.List(ctx, backups, client.InNamespace(cr.Namespace)); err != nil {
+		return errors.Wrap(err, "list backups")
+	}
+	for i := range backups.Items {
+		known[backups.Items[i].Status.Destination] = &backups.Items[i]
+	}
+
+	// Directories are sorted by creation time, so bases of incremental backups are found before them.
+	var found []storageBackup
+	for _, dir := range completeBackupDirs(cr.Name, objects) {
+		dest := storageDestination(stg, dir)
+
+		metadata, err := readBackupMetadata(ctx, stgClient, dir)
+		if err != nil {
+			return errors.Wrapf(err, "read metadata of backup %s", dir)
+		}
+
+		// Backups which already have PerconaServerMySQLBackup objects aren't imported,
+		// but incremental backups can be taken on top of them.
+		if bcp, ok := known[dest]; ok {
+			sb := storageBackup{
+				name:     bcp.Name,
+				dest:     dest,
+				metadata: bcp.Status.Metadata,
+				base:     bcp.Status.BaseBackupName,
+				chain:    bcp.Status.Chain,
+			}
+			if sb.metadata == nil {
+				sb.metadata = metadata
+			}
+			found = append(found, sb)
+			continue
+		}
+
+		sb := storageBackup{name: importedBackupName(dir), dest: dest, metadata: metadata}
+		if metadata != nil && metadata.FromLSN != "" && metadata.FromLSN != "0" {
+			base := incrementalBackupBase(found, metadata.FromLSN)
+			if base == nil {
+				log.Info("Skipping incremental backup, its base is not found on the storage", "backup", dir, "fromLSN", metadata.FromLSN)
+				continue
+			}
+			sb.base = base.name
+			sb.chain = append(append([]apiv1alpha1.BackupDestination{}, base.chain...), base.dest)
+		}
+		found = append(found, sb)
+
+		bcp := importedBackup(cr, storageName, stg, dir, sb)
+		log.Info("Importing backup from storage", "backup", bcp.Name, "destination", dest)
+
+		status := bcp.Status
+		if err := r.Client.Create(ctx, bcp); err != nil {
+			if k8serrors.IsAlreadyExists(err) {
+				log.Info("Backup with the same name already exists, skipping", "backup", bcp.Name, "destination", dest)
+				continue
+			}
+			return errors.Wrapf(err, "create backup %s", bcp.Name)
+		}
+		bcp.Status = status
+		if err := r.Client.Status().Update(ctx, bcp); err != nil {
+			return errors.Wrapf(err, "update status of backup %s", bcp.Name)
+		}
+	}
+
+	return nil
+}
+
+// storageBackup is a backup found on the storage.
+type storageBackup struct {
+	name     string
+	dest     apiv1alpha1.BackupDestination
+	metadata *apiv1alpha1.BackupMetadata
+	// base and chain are set for incremental backups.
+	base  string
+	chain []apiv1alpha1.BackupDestination
+}
+
+// incrementalBackupBase returns the latest backup an incremental backup with fromLSN can be taken on top of,
+// i.e. the one that ends at fromLSN. Backups must be sorted from the oldest to the newest.
+func incrementalBackupBase(backups []storageBackup, fromLSN string) *storageBackup {
+	for i := len(backups) - 1; i >= 0; i-- {
+		if backups[i].metadata != nil && backups[i].metadata.ToLSN == fromLSN {
+			return &backups[i]
+		}
+	}
+	return nil
+}
+
+// backupDirRegexp matches the backup directories of the cluster and captures their creation time.
+// Backup directories are named by psbackup as <cluster>-<creation time>-full.
+func backupDirRegexp(clusterName string) *regexp.Regexp {
+	return regexp.MustCompile(`^` + regexp.QuoteMeta(clusterName) + `-(\d{4}-\d{2}-\d{2}-\d{2}:\d{2}:\d{2})-full$`)
+}
+
+// completeBackupDirs returns sorted top-level directories of the storage which contain a backup manifest
+// of the cluster. Backups without the manifest weren't finished. Storages can be shared by clusters,
+// backups of the other clusters are skipped.
+func completeBackupDirs(clusterName string, objects []string) []string {
+	re := backupDirRegexp(clusterName)
+
+	var dirs []string
+	for _, obj := range objects {
+		dir, name, found := strings.Cut(strings.TrimPrefix(obj, "/"), "/")
+		if found && name == cloud.ManifestName && re.MatchString(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+func readBackupMetadata(ctx context.Context, stg storage.Storage, dir string) (*apiv1alpha1.BackupMetadata, error) {
+	r, err := stg.GetObject(ctx, xtrabackup.MetadataObjectName(dir))
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "get object")
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "read object")
+	}
+
+	metadata := new(apiv1alpha1.BackupMetadata)
+	if err := json.Unmarshal(data, metadata); err != nil {
+		return nil, errors.Wrap(err, "unmarshal")
+	}
+	return metadata, nil
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// importedBackupName converts the name of the backup directory to a valid object name.
+func importedBackupName(dir string) string {
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(dir), "")
+	if len(name) > 253 {
+		name = name[:253]
+	}
+	return strings.Trim(name, ".-")
+}
+
+func importedBackup(
+	cr *apiv1alpha1.PerconaServerMySQL,
+	storageName string,
+	stg *apiv1alpha1.BackupStorageSpec,
+	dir string,
+	sb storageBackup,
+) *apiv1alpha1.PerconaServerMySQLBackup {
+	backupType := apiv1alpha1.BackupTypeFull
+	if len(sb.chain) > 0 {
+		backupType = apiv1alpha1.BackupTypeIncremental
+	}
+
+	bcp := &apiv1alpha1.PerconaServerMySQLBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      importedBackupName(dir),
+			Namespace: cr.Namespace,
+			Labels: map[string]string{
+				naming.LabelCluster:    cr.Name,
+				naming.LabelBackupType: naming.BackupTypeImported,
+			},
+		},
+		Spec: apiv1alpha1.PerconaServerMySQLBackupSpec{
+			ClusterName:    cr.Name,
+			StorageName:    storageName,
+			Type:           backupType,
+			BaseBackupName: sb.base,
+		},
+		Status: apiv1alpha1.PerconaServerMySQLBackupStatus{
+			State:          apiv1alpha1.BackupSucceeded,
+			StateDesc:      "imported from storage " + storageName,
+			Destination:    sb.dest,
+			Storage:        stg.DeepCopy(),
+			Type:           backupType,
+			BaseBackupName: sb.base,
+			Chain:          sb.chain,
+			Metadata:       sb.metadata,
+		},
+	}
+	if sb.metadata != nil {
+		bcp.Status.CompletedAt = sb.metadata.FinishedAt
+	}
+	return bcp
+}
+
+// backupSyncPending returns true if backups are going to be imported from the storage.
+func backupSyncPending(cr *apiv1alpha1.PerconaServerMySQL, storageName string) bool {
+	value, ok := cr.Annotations[string(naming.AnnotationSyncBackups)]
+	if !ok {
+		return false
+	}
+	for _, name := range strings.Split(value, ",") {
+		if strings.TrimSpace(name) == storageName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,654 @@
+package ps
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/naming"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/cloud"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
+	fakestorage "github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage/fake"
+)
+
+func TestOutdatedBackups(t *testing.T) {
+	now := time.Date(2024, time.March, 15, 12, 0, 0, 0, time.UTC)
+
+	backup := func(name string, age time.Duration) apiv1alpha1.PerconaServerMySQLBackup {
+		return apiv1alpha1.PerconaServerMySQLBackup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+			},
+		}
+	}
+	day := 24 * time.Hour
+
+	// Two backups a day for the last 60 days, named by their age.
+	var backups []apiv1alpha1.PerconaServerMySQLBackup
+	for i := 0; i < 120; i++ {
+		age := time.Duration(i) * 12 * time.Hour
+		backups = append(backups, backup(age.String(), age))
+	}
+
+	tests := []struct {
+		name     string
+		spec     apiv1alpha1.BackupSchedule
+		expected int
+		kept     []string
+	}{
+		{
+			name:     "no rules",
+			spec:     apiv1alpha1.BackupSchedule{},
+			expected: 0,
+		},
+		{
+			name:     "keep",
+			spec:     apiv1alpha1.BackupSchedule{Keep: 10},
+			expected: 110,
+		},
+		{
+			name: "max age",
+			spec: apiv1alpha1.BackupSchedule{Retention: &apiv1alpha1.BackupRetentionSpec{
+				MaxAge: &metav1.Duration{Duration: 7 * day},
+			}},
+			// Backups of the last 7 days are kept: ages 0h, 12h, ..., 168h.
+			expected: 105,
+		},
+		{
+			name: "max age with min count",
+			spec: apiv1alpha1.BackupSchedule{Retention: &apiv1alpha1.BackupRetentionSpec{
+				MaxAge:   &metav1.Duration{Duration: time.Hour},
+				MinCount: 5,
+			}},
+			expected: 115,
+		},
+		{
+			name: "keep with daily and weekly",
+			spec: apiv1alpha1.BackupSchedule{
+				Keep: 1,
+				Retention: &apiv1alpha1.BackupRetentionSpec{
+					KeepDaily:  3,
+					KeepWeekly: 2,
+				},
+			},
+			// The newest backups of the last 3 days and the newest backup of the previous ISO week, Sunday 10 March.
+			kept: []string{"0s", "24h0m0s", "48h0m0s", "120h0m0s"},
+		},
+		{
+			name: "max age with monthly",
+			spec: apiv1alpha1.BackupSchedule{Retention: &apiv1alpha1.BackupRetentionSpec{
+				MaxAge:      &metav1.Duration{Duration: time.Hour},
+				KeepMonthly: 3,
+			}},
+			// The newest backups of March, February and January.
+			kept: []string{"0s", "360h0m0s", "1056h0m0s"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := make([]apiv1alpha1.PerconaServerMySQLBackup, len(backups))
+			copy(in, backups)
+
+			outdated := outdatedBackups(in, tt.spec, now)
+
+			if tt.kept == nil {
+				if len(outdated) != tt.expected {
+					t.Fatalf("expected %d outdated backups, got %d", tt.expected, len(outdated))
+				}
+				return
+			}
+
+			deleted := make(map[string]struct{})
+			for _, bcp := range outdated {
+				deleted[bcp.Name] = struct{}{}
+			}
+			var kept []string
+			for _, bcp := range backups {
+				if _, ok := deleted[bcp.Name]; !ok {
+					kept = append(kept, bcp.Name)
+				}
+			}
+			if !reflect.DeepEqual(kept, tt.kept) {
+				t.Fatalf("expected kept backups %v, got %v", tt.kept, kept)
+			}
+		})
+	}
+}
+
+func TestOldScheduledBackups(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, time.March, 15, 12, 0, 0, 0, time.UTC)
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	cr, err := readDefaultCR("cluster1", "retention")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backup := func(name, ancestor string, age time.Duration, chain ...string) *apiv1alpha1.PerconaServerMySQLBackup {
+		bcp := &apiv1alpha1.PerconaServerMySQLBackup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         cr.Namespace,
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+				Labels: map[string]string{
+					naming.LabelCluster:        cr.Name,
+					naming.LabelBackupAncestor: ancestor,
+				},
+			},
+			Spec: apiv1alpha1.PerconaServerMySQLBackupSpec{ClusterName: cr.Name},
+		}
+		bcp.Status.State = apiv1alpha1.BackupSucceeded
+		bcp.Status.Destination.SetS3Destination("bucket", name)
+		for _, base := range chain {
+			var dest apiv1alpha1.BackupDestination
+			dest.SetS3Destination("bucket", base)
+			bcp.Status.Chain = append(bcp.Status.Chain, dest)
+		}
+		return bcp
+	}
+	day := 24 * time.Hour
+
+	// The weekly schedule takes full backups, the daily one takes incremental backups on top of the latest of them.
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		backup("full-1", "weekly", 14*day),
+		backup("full-2", "weekly", 7*day),
+		backup("full-3", "weekly", 0),
+		backup("inc-1", "daily", 6*day, "full-2"),
+		backup("inc-2", "daily", 5*day, "full-2", "inc-1"),
+	).Build()
+	r := &PerconaServerMySQLReconciler{Client: cl}
+
+	outdated, err := r.oldScheduledBackups(ctx, cr, "weekly", apiv1alpha1.BackupSchedule{Keep: 1}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, bcp := range outdated {
+		names = append(names, bcp.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"full-1"}) {
+		t.Fatalf("expected only full-1 to be outdated, got %v", names)
+	}
+
+	outdated, err = r.oldScheduledBackups(ctx, cr, "daily", apiv1alpha1.BackupSchedule{Keep: 1}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names = nil
+	for _, bcp := range outdated {
+		names = append(names, bcp.Name)
+	}
+	if len(names) != 0 {
+		t.Fatalf("expected inc-1 to be kept as the base of inc-2, got outdated %v", names)
+	}
+}
+
+func TestDeleteOrphanedBackups(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, time.March, 15, 12, 0, 0, 0, time.UTC)
+
+	cr, err := readDefaultCR("cluster1", "gc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stg := &apiv1alpha1.BackupStorageSpec{
+		Type: apiv1alpha1.BackupStorageS3,
+		S3: &apiv1alpha1.BackupStorageS3Spec{
+			Bucket:            "bucket/prefix",
+			CredentialsSecret: "s3-secret",
+		},
+		GarbageCollection: &apiv1alpha1.BackupStorageGCSpec{Enabled: true},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	referenced := "cluster1-2024-03-01-00:00:00-full"
+	restored := "cluster1-2024-03-02-00:00:00-full"
+	orphaned := "cluster1-2024-03-03-00:00:00-full"
+	copied := "cluster1-2024-03-04-00:00:00-full"
+	recent := "cluster1-2024-03-15-00:00:00-full"
+	otherCluster := "cluster11-2024-03-03-00:00:00-full"
+	otherNamespace := "cluster1-2024-03-05-00:00:00-full"
+	unknownNamespace := "cluster1-2024-03-06-00:00:00-full"
+
+	bcp := &apiv1alpha1.PerconaServerMySQLBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup1", Namespace: cr.Namespace},
+		Status:     apiv1alpha1.PerconaServerMySQLBackupStatus{Destination: storageDestination(stg, referenced)},
+	}
+	// The backup is stored on another storage and copied to the collected one.
+	copiedBcp := &apiv1alpha1.PerconaServerMySQLBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup2", Namespace: cr.Namespace},
+		Status: apiv1alpha1.PerconaServerMySQLBackupStatus{
+			Destination: apiv1alpha1.BackupDestination("s3://other-bucket/" + copied),
+			Copies: []apiv1alpha1.BackupCopyStatus{
+				{StorageName: "gc", State: apiv1alpha1.BackupSucceeded, Destination: storageDestination(stg, copied)},
+			},
+		},
+	}
+	restore := &apiv1alpha1.PerconaServerMySQLRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "restore1", Namespace: cr.Namespace},
+		Spec: apiv1alpha1.PerconaServerMySQLRestoreSpec{
+			BackupSource: &apiv1alpha1.PerconaServerMySQLBackupStatus{Destination: storageDestination(stg, restored)},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3-secret", Namespace: cr.Namespace},
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr, bcp, copiedBcp, restore, secret).Build()
+
+	mem := fakestorage.NewMemoryStorage()
+	for _, name := range []string{
+		referenced + "/xtrabackup_info",
+		restored + "/xtrabackup_info",
+		copied + "/xtrabackup_info",
+		orphaned + "/xtrabackup_info",
+		orphaned + "/ibdata1",
+		orphaned + ".md5",
+		xtrabackup.CheckpointsObjectName(orphaned),
+		referenced + ".md5",
+		recent + "/xtrabackup_info",
+		otherCluster + "/xtrabackup_info",
+		otherNamespace + "/xtrabackup_info",
+		unknownNamespace + "/xtrabackup_info",
+		"binlogs/binlog.000001",
+	} {
+		if err := mem.PutObject(ctx, name, bytes.NewReader(nil), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for dir, namespace := range map[string]string{
+		orphaned:       cr.Namespace,
+		otherNamespace: "other",
+	} {
+		data := []byte(`{"namespace":"` + namespace + `"}`)
+		if err := mem.PutObject(ctx, xtrabackup.MetadataObjectName(dir), bytes.NewReader(data), int64(len(data))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := &PerconaServerMySQLReconciler{
+		Client: cl,
+		Scheme: scheme,
+		NewStorageClient: func(ctx context.Context, opts storage.Options) (storage.Storage, error) {
+			return mem, nil
+		},
+	}
+	if err := r.deleteOrphanedBackups(ctx, cr, stg, now); err != nil {
+		t.Fatal(err)
+	}
+
+	objects, err := mem.ListObjects(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"binlogs/binlog.000001",
+		referenced + "/xtrabackup_info",
+		referenced + ".md5",
+		restored + "/xtrabackup_info",
+		copied + "/xtrabackup_info",
+		recent + "/xtrabackup_info",
+		otherCluster + "/xtrabackup_info",
+		otherNamespace + "/xtrabackup_info",
+		xtrabackup.MetadataObjectName(otherNamespace),
+		unknownNamespace + "/xtrabackup_info",
+	}
+	sort.Strings(expected)
+	if !reflect.DeepEqual(objects, expected) {
+		t.Fatalf("expected objects %v, got %v", expected, objects)
+	}
+}
+
+func TestReconcileBackupStorageGC(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		synced    []string
+		collected bool
+	}{
+		{
+			name: "not synced",
+		},
+		{
+			name:      "synced",
+			synced:    []string{"s3-us-west"},
+			collected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr, err := readDefaultCR("cluster1", "gc")
+			if err != nil {
+				t.Fatal(err)
+			}
+			cr.Spec.Backup.Storages["s3-us-west"].GarbageCollection = &apiv1alpha1.BackupStorageGCSpec{Enabled: true}
+			cr.Status.BackupStoragesSynced = tt.synced
+
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: cr.Spec.Backup.Storages["s3-us-west"].S3.CredentialsSecret, Namespace: cr.Namespace},
+			}
+
+			collected := false
+			r := &PerconaServerMySQLReconciler{
+				Client: fake.NewClientBuilder().WithObjects(secret).Build(),
+				Crons:  NewCronRegistry(),
+				NewStorageClient: func(ctx context.Context, opts storage.Options) (storage.Storage, error) {
+					collected = true
+					return nil, errors.New("unreachable")
+				},
+			}
+			if err := r.reconcileBackupStorageGC(ctx, cr); err != nil {
+				t.Fatal(err)
+			}
+			if collected != tt.collected {
+				t.Fatalf("expected storage to be collected: %t, got %t", tt.collected, collected)
+			}
+		})
+	}
+}
+
+func TestReconcileBackupSync(t *testing.T) {
+	ctx := context.Background()
+
+	cr, err := readDefaultCR("cluster1", "sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stg := cr.Spec.Backup.Storages["s3-us-west"]
+	cr.Annotations = map[string]string{string(naming.AnnotationSyncBackups): "s3-us-west"}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	full := "cluster1-2024-03-01-00:00:00-full"
+	incremental := "cluster1-2024-03-02-00:00:00-full"
+	unfinished := "cluster1-2024-03-03-00:00:00-full"
+	known := "cluster1-2024-03-04-00:00:00-full"
+	otherCluster := "cluster10-2024-03-05-00:00:00-full"
+	incremental2 := "cluster1-2024-03-06-00:00:00-full"
+	noBase := "cluster1-2024-03-07-00:00:00-full"
+
+	existing := &apiv1alpha1.PerconaServerMySQLBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup1", Namespace: cr.Namespace},
+		Status:     apiv1alpha1.PerconaServerMySQLBackupStatus{Destination: storageDestination(stg, known)},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: stg.S3.CredentialsSecret, Namespace: cr.Namespace},
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(cr, existing, secret).
+		WithStatusSubresource(&apiv1alpha1.PerconaServerMySQLBackup{}).
+		Build()
+
+	mem := fakestorage.NewMemoryStorage()
+	objects := map[string]string{
+		full + "/" + cloud.ManifestName:             "{}",
+		xtrabackup.MetadataObjectName(full):         `{"mysqlVersion":"8.0.36-28","fromLSN":"0","toLSN":"20180335"}`,
+		incremental + "/" + cloud.ManifestName:      "{}",
+		xtrabackup.MetadataObjectName(incremental):  `{"fromLSN":"20180335","toLSN":"20190000","namespace":"sync"}`,
+		incremental2 + "/" + cloud.ManifestName:     "{}",
+		xtrabackup.MetadataObjectName(incremental2): `{"fromLSN":"20190000","toLSN":"20200000","namespace":"sync"}`,
+		noBase + "/" + cloud.ManifestName:           "{}",
+		xtrabackup.MetadataObjectName(noBase):       `{"fromLSN":"10000000","toLSN":"20210000","namespace":"sync"}`,
+		unfinished + "/chunk-00000000000000000000":  "",
+		known + "/" + cloud.ManifestName:            "{}",
+		otherCluster + "/" + cloud.ManifestName:     "{}",
+		"binlogs/binlog.000001":                     "",
+	}
+	for name, data := range objects {
+		if err := mem.PutObject(ctx, name, bytes.NewReader([]byte(data)), int64(len(data))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := &PerconaServerMySQLReconciler{
+		Client: cl,
+		Scheme: scheme,
+		NewStorageClient: func(ctx context.Context, opts storage.Options) (storage.Storage, error) {
+			return mem, nil
+		},
+	}
+	if err := r.reconcileBackupSync(ctx, cr); err != nil {
+		t.Fatal(err)
+	}
+
+	backups := new(apiv1alpha1.PerconaServerMySQLBackupList)
+	if err := cl.List(ctx, backups); err != nil {
+		t.Fatal(err)
+	}
+	if len(backups.Items) != 4 {
+		t.Fatalf("expected 4 backups, got %d", len(backups.Items))
+	}
+
+	bcp := new(apiv1alpha1.PerconaServerMySQLBackup)
+	if err := cl.Get(ctx, types.NamespacedName{Name: "cluster1-2024-03-01-000000-full", Namespace: cr.Namespace}, bcp); err != nil {
+		t.Fatal(err)
+	}
+	if bcp.Labels[naming.LabelBackupType] != naming.BackupTypeImported {
+		t.Fatalf("expected backup to be labeled as imported, got %v", bcp.Labels)
+	}
+	if bcp.Status.State != apiv1alpha1.BackupSucceeded {
+		t.Fatalf("expected state %s, got %s", apiv1alpha1.BackupSucceeded, bcp.Status.State)
+	}
+	if bcp.Status.Destination != storageDestination(stg, full) {
+		t.Fatalf("unexpected destination %s", bcp.Status.Destination)
+	}
+	if bcp.Status.Storage == nil || bcp.Status.Storage.Type != apiv1alpha1.BackupStorageS3 {
+		t.Fatalf("unexpected storage %+v", bcp.Status.Storage)
+	}
+	if bcp.Status.Metadata == nil || bcp.Status.Metadata.MySQLVersion != "8.0.36-28" {
+		t.Fatalf("unexpected metadata %+v", bcp.Status.Metadata)
+	}
+
+	cluster := new(apiv1alpha1.PerconaServerMySQL)
+	if err := cl.Get(ctx, types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, cluster); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cluster.Annotations[string(naming.AnnotationSyncBackups)]; ok {
+		t.Fatal("expected sync annotation to be removed")
+	}
+	if !reflect.DeepEqual(cr.Status.BackupStoragesSynced, []string{"s3-us-west"}) {
+		t.Fatalf("expected storage to be marked as synced, got %v", cr.Status.BackupStoragesSynced)
+	}
+
+	// Incremental backups are imported with the chain of their bases.
+	if err := cl.Get(ctx, types.NamespacedName{Name: "cluster1-2024-03-06-000000-full", Namespace: cr.Namespace}, bcp); err != nil {
+		t.Fatal(err)
+	}
+	expectedChain := []apiv1alpha1.BackupDestination{storageDestination(stg, full), storageDestination(stg, incremental)}
+	if !bcp.Status.IsIncremental() || !reflect.DeepEqual(bcp.Status.Chain, expectedChain) {
+		t.Fatalf("expected incremental backup with chain %v, got %s with %v", expectedChain, bcp.Status.Type, bcp.Status.Chain)
+	}
+	if bcp.Status.BaseBackupName != "cluster1-2024-03-02-000000-full" {
+		t.Fatalf("unexpected base backup %s", bcp.Status.BaseBackupName)
+	}
+
+	// Imported incremental backups aren't collected as orphaned.
+	stg.GarbageCollection = &apiv1alpha1.BackupStorageGCSpec{Enabled: true}
+	if err := r.deleteOrphanedBackups(ctx, cr, stg, time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{full, incremental, incremental2} {
+		if _, err := mem.GetObject(ctx, xtrabackup.MetadataObjectName(dir)); err != nil {
+			t.Fatalf("expected backup %s to be kept: %v", dir, err)
+		}
+	}
+	if _, err := mem.GetObject(ctx, xtrabackup.MetadataObjectName(noBase)); !errors.Is(err, storage.ErrObjectNotFound) {
+		t.Fatalf("expected incremental backup without base to be collected, got %v", err)
+	}
+}
+
+func TestBusyReason(t *testing.T) {
+	maxLag := int64(10)
+	maxLoad := resource.MustParse("4.5")
+	lag := func(v int64) *int64 { return &v }
+	load := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name   string
+		guard  apiv1alpha1.BackupScheduleGuardSpec
+		nodes  []nodeLoad
+		reason string
+	}{
+		{
+			name:   "no nodes",
+			guard:  apiv1alpha1.BackupScheduleGuardSpec{MaxReplicationLag: &maxLag},
+			reason: "no ready MySQL pods",
+		},
+		{
+			name:  "one idle replica",
+			guard: apiv1alpha1.BackupScheduleGuardSpec{MaxReplicationLag: &maxLag, MaxLoadAverage: &maxLoad},
+			nodes: []nodeLoad{
+				{host: "mysql-1", lag: lag(20), loadAverage: load(1)},
+				{host: "mysql-2", lag: lag(5), loadAverage: load(4.5)},
+			},
+		},
+		{
+			name:  "all busy",
+			guard: apiv1alpha1.BackupScheduleGuardSpec{MaxReplicationLag: &maxLag, MaxLoadAverage: &maxLoad},
+			nodes: []nodeLoad{
+				{host: "mysql-1", lag: lag(20), loadAverage: load(1)},
+				{host: "mysql-2", lag: lag(5), loadAverage: load(6)},
+			},
+			reason: "mysql-1: replication lag 20 is above 10; mysql-2: load average 6.00 is above 4500m",
+		},
+		{
+			name:  "unknown lag",
+			guard: apiv1alpha1.BackupScheduleGuardSpec{MaxReplicationLag: &maxLag},
+			nodes: []nodeLoad{
+				{host: "mysql-1", loadAverage: load(1)},
+			},
+			reason: "mysql-1: replication lag is unknown",
+		},
+		{
+			name:  "lag is not checked",
+			guard: apiv1alpha1.BackupScheduleGuardSpec{MaxLoadAverage: &maxLoad},
+			nodes: []nodeLoad{
+				{host: "mysql-0", loadAverage: load(2)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if reason := busyReason(&tt.guard, tt.nodes); reason != tt.reason {
+				t.Fatalf("expected reason %q, got %q", tt.reason, reason)
+			}
+		})
+	}
+
+	if l, err := parseLoadAverage("0.52 0.58 0.59 1/389 12345\n"); err != nil || l != 0.52 {
+		t.Fatalf("unexpected load average %f: %v", l, err)
+	}
+}
+
+func TestScheduledBackupGuard(t *testing.T) {
+	ctx := context.Background()
+
+	cr, err := readDefaultCR("cluster1", "guard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cr.Spec.Backup.ScheduleGuard = &apiv1alpha1.BackupScheduleGuardSpec{
+		CheckInterval: &metav1.Duration{Duration: 20 * time.Millisecond},
+		MaxDelay:      &metav1.Duration{Duration: 50 * time.Millisecond},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	schedule := apiv1alpha1.BackupSchedule{Name: "daily", Schedule: "0 0 * * *", StorageName: "s3-us-west"}
+
+	tests := []struct {
+		name    string
+		busy    []string
+		created bool
+		checks  int
+	}{
+		{
+			name:    "idle",
+			busy:    []string{""},
+			created: true,
+			checks:  1,
+		},
+		{
+			name:    "deferred",
+			busy:    []string{"busy", "busy", ""},
+			created: true,
+			checks:  3,
+		},
+		{
+			name:   "skipped",
+			busy:   []string{"busy", "busy", "busy", "busy", "busy"},
+			checks: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr.DeepCopy()).Build()
+
+			checks := 0
+			isBusy := func(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL) (string, error) {
+				reason := tt.busy[checks]
+				checks++
+				return reason, nil
+			}
+
+			crons := NewCronRegistry()
+			crons.createBackupJobFunc(ctx, cl, cr, schedule, isBusy)()
+
+			if checks != tt.checks {
+				t.Fatalf("expected %d checks, got %d", tt.checks, checks)
+			}
+			backups := new(apiv1alpha1.PerconaServerMySQLBackupList)
+			if err := cl.List(ctx, backups); err != nil {
+				t.Fatal(err)
+			}
+			if created := len(backups.Items) > 0; created != tt.created {
+				t.Fatalf("expected backup created: %t, got %t", tt.created, created)
+			}
+		})
+	}
+}
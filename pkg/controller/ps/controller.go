@@ -39,8 +39,10 @@ import (
 	"k8s.io/client-go/tools/record"
 	k8sretry "k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
 	"github.com/percona/percona-server-mysql-operator/pkg/clientcmd"
@@ -55,6 +57,7 @@ import (
 	"github.com/percona/percona-server-mysql-operator/pkg/platform"
 	"github.com/percona/percona-server-mysql-operator/pkg/router"
 	"github.com/percona/percona-server-mysql-operator/pkg/util"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
 )
 
 // PerconaServerMySQLReconciler reconciles a PerconaServerMySQL object
@@ -65,6 +68,8 @@ type PerconaServerMySQLReconciler struct {
 	Recorder      record.EventRecorder
 	ClientCmd     clientcmd.Client
 
+	NewStorageClient storage.NewClientFunc
+
 	Crons cronRegistry
 }
 
@@ -79,8 +84,14 @@ type PerconaServerMySQLReconciler struct {
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *PerconaServerMySQLReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// The status is written on every reconcile and changes with the replication lag and GTIDs of members,
+	// so its updates would reconcile the cluster again right away. It's requeued periodically anyway.
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&apiv1alpha1.PerconaServerMySQL{}).
+		For(&apiv1alpha1.PerconaServerMySQL{}, builder.WithPredicates(predicate.Or(
+			predicate.GenerationChangedPredicate{},
+			predicate.AnnotationChangedPredicate{},
+			predicate.LabelChangedPredicate{},
+		))).
 		Named("ps-controller").
 		Complete(r)
 }
@@ -364,6 +375,9 @@ func (r *PerconaServerMySQLReconciler) doReconcile(
 	if err := r.reconcileVersions(ctx, cr); err != nil {
 		log.Error(err, "failed to reconcile versions")
 	}
+	if err := r.ensureInitFromUserSecrets(ctx, cr); err != nil {
+		return errors.Wrap(err, "init from users secret")
+	}
 	if err := r.ensureUserSecrets(ctx, cr); err != nil {
 		return errors.Wrap(err, "users secret")
 	}
@@ -376,6 +390,14 @@ func (r *PerconaServerMySQLReconciler) doReconcile(
 	if err := r.reconcileServices(ctx, cr); err != nil {
 		return errors.Wrap(err, "services")
 	}
+	initialized, err := r.reconcileInitFrom(ctx, cr)
+	if err != nil {
+		return errors.Wrap(err, "init from backup")
+	}
+	if !initialized {
+		log.V(1).Info("Waiting for data to be restored from backup")
+		return nil
+	}
 	if err := r.reconcileDatabase(ctx, cr); err != nil {
 		return errors.Wrap(err, "database")
 	}
@@ -385,6 +407,15 @@ func (r *PerconaServerMySQLReconciler) doReconcile(
 	if err := r.reconcileReplication(ctx, cr); err != nil {
 		return errors.Wrap(err, "replication")
 	}
+	if err := r.reconcileErrantTransactions(ctx, cr); err != nil {
+		return errors.Wrap(err, "errant transactions")
+	}
+	if err := r.reconcileAutoRebuild(ctx, cr); err != nil {
+		return errors.Wrap(err, "auto rebuild")
+	}
+	if err := r.reconcileReplicationChannels(ctx, cr); err != nil {
+		return errors.Wrap(err, "replication channels")
+	}
 	if err := r.reconcileHAProxy(ctx, cr); err != nil {
 		return errors.Wrap(err, "HAProxy")
 	}
@@ -394,6 +425,15 @@ func (r *PerconaServerMySQLReconciler) doReconcile(
 	if err := r.reconcileScheduledBackup(ctx, cr); err != nil {
 		return errors.Wrap(err, "scheduled backup")
 	}
+	if err := r.reconcileBackupSync(ctx, cr); err != nil {
+		return errors.Wrap(err, "sync backups from storage")
+	}
+	if err := r.reconcileBackupStorageGC(ctx, cr); err != nil {
+		return errors.Wrap(err, "backup storage garbage collection")
+	}
+	if err := r.reconcileBinlogCollector(ctx, cr); err != nil {
+		return errors.Wrap(err, "binlog collector")
+	}
 	if err := r.cleanupOutdated(ctx, cr); err != nil {
 		return errors.Wrap(err, "cleanup outdated")
 	}
@@ -1108,6 +1148,10 @@ func (r *PerconaServerMySQLReconciler) cleanupOutdated(ctx context.Context, cr *
 		return errors.Wrap(err, "cleanup proxies")
 	}
 
+	if err := r.cleanupBinlogCollector(ctx, cr); err != nil {
+		return errors.Wrap(err, "cleanup binlog collector")
+	}
+
 	return nil
 }
 
@@ -0,0 +1,182 @@
+package ps
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	database "github.com/percona/percona-server-mysql-operator/pkg/db"
+	"github.com/percona/percona-server-mysql-operator/pkg/k8s"
+	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
+)
+
+// maxInjectedTransactions limits the number of empty transactions committed on the primary
+// for the errant transactions of a replica. Such replicas should be re-cloned instead.
+const maxInjectedTransactions = 1000
+
+// reconcileErrantTransactions compares gtid_executed of the asynchronous replicas with the primary's.
+// Replicas with transactions the primary doesn't have are reported with the ErrantTransactions condition
+// and repaired according to spec.mysql.errantTransactionsRepair.
+// Replicas are checked once per replicationCheckInterval.
+func (r *PerconaServerMySQLReconciler) reconcileErrantTransactions(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL) error {
+	log := logf.FromContext(ctx).WithName("reconcileErrantTransactions")
+
+	if !cr.Spec.MySQL.IsAsync() || !cr.OrchestratorEnabled() || cr.Status.Orchestrator.Ready == 0 {
+		return nil
+	}
+	if !r.replicationCheckDue(cr, "errant-transactions") {
+		return nil
+	}
+
+	primary, err := r.getPrimaryFromOrchestrator(ctx, cr)
+	if err != nil {
+		return errors.Wrap(err, "get primary")
+	}
+	if primary.Alias == "" {
+		return nil
+	}
+
+	pods, err := k8s.PodsByLabels(ctx, r.Client, mysql.MatchLabels(cr), cr.Namespace)
+	if err != nil {
+		return errors.Wrap(err, "get pods")
+	}
+
+	var primaryPod *corev1.Pod
+	for i := range pods {
+		if pods[i].Name == primary.Alias {
+			primaryPod = &pods[i]
+		}
+	}
+	if primaryPod == nil || !k8s.IsPodReady(*primaryPod) {
+		log.V(1).Info("Waiting for primary to be ready", "primary", primary.Alias)
+		return nil
+	}
+
+	operatorPass, err := k8s.UserPassword(ctx, r.Client, cr, apiv1alpha1.UserOperator)
+	if err != nil {
+		return errors.Wrap(err, "get operator password")
+	}
+	primaryDB := database.NewReplicationManager(primaryPod, r.ClientCmd, apiv1alpha1.UserOperator, operatorPass, mysql.PodFQDN(cr, primaryPod))
+
+	errant := make(map[string]string)
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Name == primaryPod.Name || !k8s.IsPodReady(*pod) {
+			continue
+		}
+
+		rm := database.NewReplicationManager(pod, r.ClientCmd, apiv1alpha1.UserOperator, operatorPass, mysql.PodFQDN(cr, pod))
+		state, err := rm.GetServerReplicationState(ctx, "")
+		if err != nil {
+			log.Error(err, "failed to get gtid_executed", "pod", pod.Name)
+			continue
+		}
+
+		gtids, err := primaryDB.GetErrantTransactions(ctx, state.GTIDExecuted)
+		if err != nil {
+			return errors.Wrapf(err, "get errant transactions of %s", pod.Name)
+		}
+		if gtids != "" {
+			errant[pod.Name] = gtids
+		}
+	}
+
+	if len(errant) == 0 {
+		if meta.IsStatusConditionTrue(cr.Status.Conditions, apiv1alpha1.ConditionErrantTransactions) {
+			r.Recorder.Event(cr, "Normal", "ErrantTransactionsResolved", "No replica has errant transactions")
+		}
+		meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+			Type:               apiv1alpha1.ConditionErrantTransactions,
+			Status:             metav1.ConditionFalse,
+			Reason:             "NoErrantTransactions",
+			LastTransitionTime: metav1.Now(),
+		})
+		return nil
+	}
+
+	replicas := make([]string, 0, len(errant))
+	for name := range errant {
+		replicas = append(replicas, name)
+	}
+	sort.Strings(replicas)
+
+	msgs := make([]string, 0, len(replicas))
+	for _, name := range replicas {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", name, errant[name]))
+	}
+	msg := strings.Join(msgs, "; ")
+
+	log.Info("Replicas have errant transactions", "transactions", msg)
+	r.Recorder.Event(cr, "Warning", "ErrantTransactions", msg)
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+		Type:               apiv1alpha1.ConditionErrantTransactions,
+		Status:             metav1.ConditionTrue,
+		Reason:             "ErrantTransactionsDetected",
+		Message:            msg,
+		LastTransitionTime: metav1.Now(),
+	})
+
+	switch cr.Spec.MySQL.ErrantTransactionsRepair {
+	case apiv1alpha1.ErrantTransactionsInjectEmpty:
+		for _, name := range replicas {
+			gtids, err := database.ExpandGTIDSet(errant[name], maxInjectedTransactions)
+			if err != nil {
+				log.Error(err, "can't inject empty transactions, the replica should be re-cloned", "replica", name)
+				r.Recorder.Event(cr, "Warning", "ErrantTransactionsNotRepaired", fmt.Sprintf("%s: %s", name, err))
+				continue
+			}
+
+			log.Info("Injecting empty transactions on primary", "primary", primaryPod.Name, "replica", name, "transactions", errant[name])
+			if err := primaryDB.InjectEmptyTransactions(ctx, gtids); err != nil {
+				return errors.Wrapf(err, "inject errant transactions of %s", name)
+			}
+			r.Recorder.Event(cr, "Normal", "ErrantTransactionsRepaired",
+				fmt.Sprintf("Injected empty transactions %s of %s on primary %s", errant[name], name, primaryPod.Name))
+		}
+	case apiv1alpha1.ErrantTransactionsReclone:
+		// The clone needs a donor, so replicas are re-cloned one at a time.
+		if cr.Status.MySQL.Ready != cr.MySQLSpec().Size {
+			log.V(1).Info("Waiting for all MySQL pods to be ready to re-clone replica")
+			return nil
+		}
+		for i := range pods {
+			if pods[i].Name != replicas[0] {
+				continue
+			}
+			if err := r.recloneReplica(ctx, &pods[i]); err != nil {
+				return errors.Wrapf(err, "re-clone %s", pods[i].Name)
+			}
+			r.Recorder.Event(cr, "Normal", "ErrantTransactionsRepaired", fmt.Sprintf("Re-cloning %s with errant transactions", pods[i].Name))
+		}
+	}
+
+	return nil
+}
+
+// recloneReplica removes the clone lock of the replica and deletes the pod.
+// The bootstrap of the new pod clones the data from a donor since the lock is missing.
+func (r *PerconaServerMySQLReconciler) recloneReplica(ctx context.Context, pod *corev1.Pod) error {
+	logf.FromContext(ctx).Info("Re-cloning replica", "pod", pod.Name)
+
+	var outb, errb bytes.Buffer
+	cmd := []string{"rm", "-f", filepath.Join(mysql.DataMountPath, "clone.lock")}
+	if err := r.ClientCmd.Exec(ctx, pod, "mysql", cmd, nil, &outb, &errb, false); err != nil {
+		return errors.Wrapf(err, "run %s, stdout: %s, stderr: %s", cmd, outb.String(), errb.String())
+	}
+
+	if err := r.Client.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, "delete pod")
+	}
+	return nil
+}
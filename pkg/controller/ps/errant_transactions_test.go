@@ -0,0 +1,176 @@
+package ps
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+)
+
+// fakeGTIDExec models gtid_executed of the MySQL pods. The primary is cluster1-mysql-0.
+type fakeGTIDExec struct {
+	gtids    map[string]string
+	commands map[string][]string
+}
+
+var gtidSubtractRe = regexp.MustCompile(`GTID_SUBTRACT\('([^']*)'`)
+
+func (e *fakeGTIDExec) Exec(_ context.Context, pod *corev1.Pod, _ string, command []string, _ io.Reader, stdout, _ io.Writer, _ bool) error {
+	cmd := strings.Join(command, " ")
+
+	switch {
+	case strings.Contains(cmd, "api/master/"):
+		fmt.Fprint(stdout, `{"InstanceAlias":"cluster1-mysql-0"}`)
+		return nil
+	case gtidSubtractRe.MatchString(cmd):
+		var errant []string
+		for _, set := range strings.Split(gtidSubtractRe.FindStringSubmatch(cmd)[1], ",") {
+			if !strings.Contains(e.gtids[pod.Name], set) {
+				errant = append(errant, set)
+			}
+		}
+		if len(errant) > 0 {
+			fmt.Fprintf(stdout, "errant\n%s\n", strings.Join(errant, ","))
+		}
+		return nil
+	case strings.Contains(cmd, "@@GLOBAL.gtid_executed"):
+		fmt.Fprintf(stdout, "gtid_executed\treceiver_state\tapplier_state\n%s\tON\tON\n", e.gtids[pod.Name])
+		return nil
+	}
+
+	e.commands[pod.Name] = append(e.commands[pod.Name], command[len(command)-1])
+	return nil
+}
+
+func (e *fakeGTIDExec) REST() restclient.Interface {
+	return nil
+}
+
+func TestReconcileErrantTransactions(t *testing.T) {
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		repair    apiv1alpha1.ErrantTransactionsRepair
+		replica   string
+		condition metav1.ConditionStatus
+		message   string
+		commands  map[string][]string
+		deleted   bool
+	}{
+		{
+			name:      "no errant transactions",
+			replica:   "uuid:1-10",
+			condition: metav1.ConditionFalse,
+			commands:  map[string][]string{},
+		},
+		{
+			name:      "report",
+			replica:   "uuid:1-10,errant:1-2",
+			condition: metav1.ConditionTrue,
+			message:   "cluster1-mysql-1: errant:1-2",
+			commands:  map[string][]string{},
+		},
+		{
+			name:      "inject empty transactions",
+			repair:    apiv1alpha1.ErrantTransactionsInjectEmpty,
+			replica:   "uuid:1-10,errant:1-2",
+			condition: metav1.ConditionTrue,
+			message:   "cluster1-mysql-1: errant:1-2",
+			commands: map[string][]string{
+				"cluster1-mysql-0": {"SET GTID_NEXT='errant:1'; BEGIN; COMMIT; SET GTID_NEXT='errant:2'; BEGIN; COMMIT; SET GTID_NEXT='AUTOMATIC'"},
+			},
+		},
+		{
+			name:      "reclone",
+			repair:    apiv1alpha1.ErrantTransactionsReclone,
+			replica:   "uuid:1-10,errant:1-2",
+			condition: metav1.ConditionTrue,
+			message:   "cluster1-mysql-1: errant:1-2",
+			commands: map[string][]string{
+				"cluster1-mysql-1": {"/var/lib/mysql/clone.lock"},
+			},
+			deleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr, err := readDefaultCR("cluster1", "ns")
+			if err != nil {
+				t.Fatal(err)
+			}
+			cr.Spec.MySQL.ClusterType = apiv1alpha1.ClusterTypeAsync
+			cr.Spec.MySQL.Size = 2
+			cr.Spec.Orchestrator.Enabled = true
+			cr.Spec.MySQL.ErrantTransactionsRepair = tt.repair
+			cr.Status.MySQL.Ready = 2
+			cr.Status.Orchestrator.Ready = 1
+
+			objects := appendSlices(
+				makeFakeReadyPods(cr, 2, "mysql"),
+				makeFakeReadyPods(cr, 1, "orchestrator"),
+			)
+			objects = append(objects, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: cr.InternalSecretName(), Namespace: cr.Namespace},
+				Data:       map[string][]byte{string(apiv1alpha1.UserOperator): []byte("operator-pass")},
+			})
+
+			exec := &fakeGTIDExec{
+				gtids: map[string]string{
+					"cluster1-mysql-0": "uuid:1-10",
+					"cluster1-mysql-1": tt.replica,
+				},
+				commands: make(map[string][]string),
+			}
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			r := &PerconaServerMySQLReconciler{
+				Client:    cl,
+				Scheme:    scheme,
+				ClientCmd: exec,
+				Recorder:  record.NewFakeRecorder(10),
+				Crons:     NewCronRegistry(),
+			}
+
+			if err := r.reconcileErrantTransactions(ctx, cr); err != nil {
+				t.Fatal(err)
+			}
+
+			cond := meta.FindStatusCondition(cr.Status.Conditions, apiv1alpha1.ConditionErrantTransactions)
+			if cond == nil || cond.Status != tt.condition || cond.Message != tt.message {
+				t.Fatalf("expected %s condition with %q, got %+v", tt.condition, tt.message, cond)
+			}
+			if fmt.Sprint(exec.commands) != fmt.Sprint(tt.commands) {
+				t.Fatalf("expected commands %q, got %q", tt.commands, exec.commands)
+			}
+
+			err = cl.Get(ctx, types.NamespacedName{Name: "cluster1-mysql-1", Namespace: cr.Namespace}, new(corev1.Pod))
+			if k8serrors.IsNotFound(err) != tt.deleted {
+				t.Fatalf("expected replica pod to be deleted: %t, got %v", tt.deleted, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package ps
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/k8s"
+	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
+	"github.com/percona/percona-server-mysql-operator/pkg/naming"
+)
+
+func initRestoreName(cr *apiv1alpha1.PerconaServerMySQL) string {
+	return cr.Name + "-init-from"
+}
+
+// ensureInitFromUserSecrets copies the users Secret of the source cluster to spec.secretsName
+// before the cluster is bootstrapped from spec.mysql.initFrom. The backup restores the system users
+// with the passwords of the source, so generated passwords wouldn't work.
+func (r *PerconaServerMySQLReconciler) ensureInitFromUserSecrets(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL) error {
+	initFrom := cr.Spec.MySQL.InitFrom
+	if initFrom == nil {
+		return nil
+	}
+
+	exists, err := k8s.ObjectExists(ctx, r.Client, mysql.NamespacedName(cr), new(appsv1.StatefulSet))
+	if err != nil {
+		return errors.Wrap(err, "check if statefulset exists")
+	}
+	if exists {
+		return nil
+	}
+
+	// The existing Secret is expected to have the passwords of the source.
+	nn := types.NamespacedName{Name: cr.Spec.SecretsName, Namespace: cr.Namespace}
+	exists, err = k8s.ObjectExists(ctx, r.Client, nn, new(corev1.Secret))
+	if err != nil {
+		return errors.Wrapf(err, "check if secret %s exists", nn.Name)
+	}
+	if exists {
+		return nil
+	}
+
+	sourceName := initFrom.SecretsName
+	if sourceName == "" && initFrom.BackupName != "" {
+		sourceName, err = r.backupClusterSecretsName(ctx, cr, initFrom.BackupName)
+		if err != nil {
+			return err
+		}
+	}
+	if sourceName == "" {
+		return errors.Errorf("mysql.initFrom.secretsName or secret %s with the passwords of the source cluster is required", nn.Name)
+	}
+
+	source := new(corev1.Secret)
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: sourceName, Namespace: cr.Namespace}, source); err != nil {
+		return errors.Wrapf(err, "get secret %s", sourceName)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: nn.Name, Namespace: nn.Namespace},
+		Data:       source.Data,
+	}
+	if err := r.Client.Create(ctx, secret); err != nil {
+		return errors.Wrapf(err, "create secret %s", nn.Name)
+	}
+
+	logf.FromContext(ctx).Info("Copied users secret of the source cluster", "source", sourceName, "secret", nn.Name)
+
+	return nil
+}
+
+// backupClusterSecretsName returns the users Secret of the cluster the backup was taken from.
+// It's empty if the cluster doesn't exist anymore.
+func (r *PerconaServerMySQLReconciler) backupClusterSecretsName(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL, backupName string) (string, error) {
+	backup := new(apiv1alpha1.PerconaServerMySQLBackup)
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: backupName, Namespace: cr.Namespace}, backup); err != nil {
+		return "", errors.Wrapf(err, "get backup %s", backupName)
+	}
+	if backup.Spec.ClusterName == cr.Name {
+		return "", nil
+	}
+
+	cluster := new(apiv1alpha1.PerconaServerMySQL)
+	err := r.Client.Get(ctx, types.NamespacedName{Name: backup.Spec.ClusterName, Namespace: cr.Namespace}, cluster)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "get cluster %s", backup.Spec.ClusterName)
+	}
+	return cluster.Spec.SecretsName, nil
+}
+
+// reconcileInitFrom restores the data volume of the first MySQL pod from spec.mysql.initFrom
+// before the statefulset is created. It returns false until the restore succeeds.
+func (r *PerconaServerMySQLReconciler) reconcileInitFrom(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL) (bool, error) {
+	log := logf.FromContext(ctx).WithName("reconcileInitFrom")
+
+	initFrom := cr.Spec.MySQL.InitFrom
+	if initFrom == nil {
+		return true, nil
+	}
+
+	exists, err := k8s.ObjectExists(ctx, r.Client, mysql.NamespacedName(cr), new(appsv1.StatefulSet))
+	if err != nil {
+		return false, errors.Wrap(err, "check if statefulset exists")
+	}
+	if exists {
+		return true, nil
+	}
+
+	restore := new(apiv1alpha1.PerconaServerMySQLRestore)
+	nn := types.NamespacedName{Name: initRestoreName(cr), Namespace: cr.Namespace}
+	err = r.Client.Get(ctx, nn, restore)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return false, errors.Wrapf(err, "get restore %s", nn.Name)
+	}
+
+	if k8serrors.IsNotFound(err) {
+		if spec := cr.MySQLSpec().VolumeSpec; spec == nil || spec.PersistentVolumeClaim == nil {
+			return false, errors.New("mysql.initFrom requires mysql.volumeSpec.persistentVolumeClaim")
+		}
+
+		// The statefulset adopts the claim, since it has the name of the claim it would create for the first pod.
+		pvc := mysql.DataVolumeClaim(cr, 0)
+		if err := r.Client.Create(ctx, pvc); err != nil && !k8serrors.IsAlreadyExists(err) {
+			return false, errors.Wrapf(err, "create PVC %s", pvc.Name)
+		}
+
+		restore = &apiv1alpha1.PerconaServerMySQLRestore{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      nn.Name,
+				Namespace: nn.Namespace,
+				Labels:    map[string]string{naming.LabelInitFrom: cr.Name},
+			},
+			Spec: apiv1alpha1.PerconaServerMySQLRestoreSpec{
+				ClusterName:         cr.Name,
+				BackupName:          initFrom.BackupName,
+				BackupSource:        initFrom.BackupSource,
+				EncryptionKeySecret: initFrom.EncryptionKeySecret,
+			},
+		}
+		if err := controllerutil.SetControllerReference(cr, restore, r.Scheme); err != nil {
+			return false, errors.Wrapf(err, "set controller reference to restore %s", nn.Name)
+		}
+		if err := r.Client.Create(ctx, restore); err != nil {
+			return false, errors.Wrapf(err, "create restore %s", nn.Name)
+		}
+
+		log.Info("Restoring data of the first pod from backup", "restore", nn.Name, "pvc", pvc.Name)
+		return false, nil
+	}
+
+	switch restore.Status.State {
+	case apiv1alpha1.RestoreSucceeded:
+		log.Info("Data of the first pod is restored, starting cluster", "restore", nn.Name)
+		return true, nil
+	case apiv1alpha1.RestoreFailed:
+		return false, errors.Errorf("restore %s failed, delete it to retry", nn.Name)
+	case apiv1alpha1.RestoreError:
+		return false, errors.Errorf("restore %s: %s", nn.Name, restore.Status.StateDesc)
+	}
+
+	return false, nil
+}
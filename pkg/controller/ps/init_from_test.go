@@ -0,0 +1,230 @@
+package ps
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
+	"github.com/percona/percona-server-mysql-operator/pkg/naming"
+)
+
+func TestReconcileInitFrom(t *testing.T) {
+	ctx := context.Background()
+
+	cr, err := readDefaultCR("cluster1", "init-from")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cr.Spec.MySQL.InitFrom = &apiv1alpha1.InitFromSpec{BackupName: "backup1"}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	restoreNN := types.NamespacedName{Name: initRestoreName(cr), Namespace: cr.Namespace}
+
+	t.Run("creates PVC and restore", func(t *testing.T) {
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr.DeepCopy()).Build()
+		r := &PerconaServerMySQLReconciler{Client: cl, Scheme: scheme}
+
+		initialized, err := r.reconcileInitFrom(ctx, cr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if initialized {
+			t.Fatal("expected cluster to wait for restore")
+		}
+
+		pvc := new(corev1.PersistentVolumeClaim)
+		pvcName := mysql.DataVolumeName + "-" + mysql.PodName(cr, 0)
+		if err := cl.Get(ctx, types.NamespacedName{Name: pvcName, Namespace: cr.Namespace}, pvc); err != nil {
+			t.Fatal(err)
+		}
+
+		restore := new(apiv1alpha1.PerconaServerMySQLRestore)
+		if err := cl.Get(ctx, restoreNN, restore); err != nil {
+			t.Fatal(err)
+		}
+		if restore.Spec.ClusterName != cr.Name || restore.Spec.BackupName != "backup1" {
+			t.Fatalf("unexpected restore spec: %+v", restore.Spec)
+		}
+		if restore.Labels[naming.LabelInitFrom] != cr.Name {
+			t.Fatalf("expected restore to have %s label", naming.LabelInitFrom)
+		}
+		if !metav1.IsControlledBy(restore, cr) {
+			t.Fatal("expected restore to be controlled by cluster")
+		}
+	})
+
+	tests := []struct {
+		name        string
+		state       apiv1alpha1.RestoreState
+		initialized bool
+		err         string
+	}{
+		{name: "restore is running", state: apiv1alpha1.RestoreRunning},
+		{name: "restore succeeded", state: apiv1alpha1.RestoreSucceeded, initialized: true},
+		{name: "restore failed", state: apiv1alpha1.RestoreFailed, err: "restore cluster1-init-from failed, delete it to retry"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restore := &apiv1alpha1.PerconaServerMySQLRestore{
+				ObjectMeta: metav1.ObjectMeta{Name: restoreNN.Name, Namespace: restoreNN.Namespace},
+				Status:     apiv1alpha1.PerconaServerMySQLRestoreStatus{State: tt.state},
+			}
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr.DeepCopy(), restore).Build()
+			r := &PerconaServerMySQLReconciler{Client: cl, Scheme: scheme}
+
+			initialized, err := r.reconcileInitFrom(ctx, cr)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if initialized != tt.initialized {
+				t.Fatalf("expected initialized %t, got %t", tt.initialized, initialized)
+			}
+		})
+	}
+
+	t.Run("requires PVC", func(t *testing.T) {
+		cr := cr.DeepCopy()
+		cr.Spec.MySQL.VolumeSpec = &apiv1alpha1.VolumeSpec{EmptyDir: &corev1.EmptyDirVolumeSource{}}
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr.DeepCopy()).Build()
+		r := &PerconaServerMySQLReconciler{Client: cl, Scheme: scheme}
+
+		_, err := r.reconcileInitFrom(ctx, cr)
+		if err == nil || err.Error() != "mysql.initFrom requires mysql.volumeSpec.persistentVolumeClaim" {
+			t.Fatalf("expected PVC to be required, got %v", err)
+		}
+	})
+
+	t.Run("statefulset exists", func(t *testing.T) {
+		sts := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: mysql.Name(cr), Namespace: cr.Namespace},
+		}
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr.DeepCopy(), sts).Build()
+		r := &PerconaServerMySQLReconciler{Client: cl, Scheme: scheme}
+
+		initialized, err := r.reconcileInitFrom(ctx, cr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !initialized {
+			t.Fatal("expected existing cluster to be initialized")
+		}
+	})
+}
+
+func TestEnsureInitFromUserSecrets(t *testing.T) {
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	cr, err := readDefaultCR("cluster1", "init-from")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cr.Spec.SecretsName = "cluster1-secrets"
+
+	source := &apiv1alpha1.PerconaServerMySQL{
+		ObjectMeta: metav1.ObjectMeta{Name: "source", Namespace: cr.Namespace},
+		Spec:       apiv1alpha1.PerconaServerMySQLSpec{SecretsName: "source-secrets"},
+	}
+	backup := &apiv1alpha1.PerconaServerMySQLBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup1", Namespace: cr.Namespace},
+		Spec:       apiv1alpha1.PerconaServerMySQLBackupSpec{ClusterName: source.Name},
+	}
+	secret := func(name, pass string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: cr.Namespace},
+			Data:       map[string][]byte{string(apiv1alpha1.UserOperator): []byte(pass)},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		initFrom apiv1alpha1.InitFromSpec
+		objects  []client.Object
+		pass     string
+		err      string
+	}{
+		{
+			name:     "copy secret of backup cluster",
+			initFrom: apiv1alpha1.InitFromSpec{BackupName: backup.Name},
+			objects:  []client.Object{source, backup, secret("source-secrets", "source-pass")},
+			pass:     "source-pass",
+		},
+		{
+			name:     "copy secret from spec",
+			initFrom: apiv1alpha1.InitFromSpec{BackupName: backup.Name, SecretsName: "other-secrets"},
+			objects:  []client.Object{source, backup, secret("other-secrets", "other-pass")},
+			pass:     "other-pass",
+		},
+		{
+			name:     "keep existing secret",
+			initFrom: apiv1alpha1.InitFromSpec{BackupName: backup.Name},
+			objects:  []client.Object{source, backup, secret("source-secrets", "source-pass"), secret("cluster1-secrets", "own-pass")},
+			pass:     "own-pass",
+		},
+		{
+			name:     "backup cluster is deleted",
+			initFrom: apiv1alpha1.InitFromSpec{BackupName: backup.Name},
+			objects:  []client.Object{backup},
+			err:      "mysql.initFrom.secretsName or secret cluster1-secrets with the passwords of the source cluster is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := cr.DeepCopy()
+			cr.Spec.MySQL.InitFrom = &tt.initFrom
+
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.objects...).Build()
+			r := &PerconaServerMySQLReconciler{Client: cl, Scheme: scheme}
+
+			err := r.ensureInitFromUserSecrets(ctx, cr)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s := new(corev1.Secret)
+			if err := cl.Get(ctx, types.NamespacedName{Name: cr.Spec.SecretsName, Namespace: cr.Namespace}, s); err != nil {
+				t.Fatal(err)
+			}
+			if pass := string(s.Data[string(apiv1alpha1.UserOperator)]); pass != tt.pass {
+				t.Fatalf("expected password %q, got %q", tt.pass, pass)
+			}
+		})
+	}
+}
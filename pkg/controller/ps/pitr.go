@@ -0,0 +1,54 @@
+package ps
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/k8s"
+	"github.com/percona/percona-server-mysql-operator/pkg/pitr"
+)
+
+func (r *PerconaServerMySQLReconciler) reconcileBinlogCollector(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL) error {
+	if !cr.Spec.Backup.PITR.Enabled {
+		return nil
+	}
+
+	initImage, err := k8s.InitImage(ctx, r.Client, cr, &cr.Spec.MySQL.PodSpec)
+	if err != nil {
+		return errors.Wrap(err, "get init image")
+	}
+
+	deployment, err := pitr.Deployment(cr, initImage)
+	if err != nil {
+		return errors.Wrap(err, "get binlog collector deployment")
+	}
+
+	if err := k8s.EnsureObjectWithHash(ctx, r.Client, cr, deployment, r.Scheme); err != nil {
+		return errors.Wrap(err, "reconcile binlog collector deployment")
+	}
+
+	return nil
+}
+
+func (r *PerconaServerMySQLReconciler) cleanupBinlogCollector(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL) error {
+	if cr.Spec.Backup.PITR.Enabled {
+		return nil
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pitr.Name(cr),
+			Namespace: cr.Namespace,
+		},
+	}
+	if err := r.Delete(ctx, deployment); err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to delete binlog collector deployment")
+	}
+
+	return nil
+}
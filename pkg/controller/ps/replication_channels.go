@@ -0,0 +1,419 @@
+package ps
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	database "github.com/percona/percona-server-mysql-operator/pkg/db"
+	"github.com/percona/percona-server-mysql-operator/pkg/k8s"
+	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
+)
+
+// reconcileReplicationChannels configures spec.replicationChannels on the group replication primary.
+// The channels are configured but kept stopped on the secondaries, so group replication starts them
+// on a new primary. The primary is kept read-only while the cluster is a standby. Promoted channels and channels
+// removed from the spec are detached, and the primary is made writable once no channel is left.
+func (r *PerconaServerMySQLReconciler) reconcileReplicationChannels(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL) error {
+	log := logf.FromContext(ctx).WithName("reconcileReplicationChannels")
+
+	if !cr.Spec.MySQL.IsGR() || len(cr.Spec.ReplicationChannels) == 0 && len(cr.Status.ReplicationChannels) == 0 {
+		return nil
+	}
+
+	cond := meta.FindStatusCondition(cr.Status.Conditions, apiv1alpha1.ConditionInnoDBClusterBootstrapped)
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		log.V(1).Info("Waiting for InnoDB cluster to be bootstrapped")
+		return nil
+	}
+
+	primary, err := r.getPrimaryFromGR(ctx, cr)
+	if err != nil {
+		return errors.Wrap(err, "get primary")
+	}
+	pod, err := getReadyMySQLPod(ctx, r.Client, cr)
+	if err != nil {
+		return errors.Wrap(err, "get ready mysql pod")
+	}
+	operatorPass, err := k8s.UserPassword(ctx, r.Client, cr, apiv1alpha1.UserOperator)
+	if err != nil {
+		return errors.Wrap(err, "get operator password")
+	}
+	rm := database.NewReplicationManager(pod, r.ClientCmd, apiv1alpha1.UserOperator, operatorPass, primary)
+
+	wasStandby := false
+	for _, st := range cr.Status.ReplicationChannels {
+		if st.State != apiv1alpha1.ReplicationChannelPromoted {
+			wasStandby = true
+		}
+	}
+
+	// Writes to the standby would diverge it from the sources.
+	if cr.IsStandby() {
+		if err := rm.SetSuperReadOnly(ctx, true); err != nil {
+			return errors.Wrapf(err, "make %s read-only", primary)
+		}
+	}
+
+	var statuses []apiv1alpha1.ReplicationChannelStatus
+	names := make(map[string]struct{}, len(cr.Spec.ReplicationChannels))
+	for i := range cr.Spec.ReplicationChannels {
+		ch := &cr.Spec.ReplicationChannels[i]
+		names[ch.Name] = struct{}{}
+
+		st, err := r.reconcileReplicationChannel(ctx, cr, rm, ch)
+		if err != nil {
+			return errors.Wrapf(err, "reconcile replication channel %s", ch.Name)
+		}
+		statuses = append(statuses, st)
+	}
+
+	for _, st := range cr.Status.ReplicationChannels {
+		if _, ok := names[st.Name]; ok {
+			continue
+		}
+		if err := detachReplicationChannel(ctx, rm, st.Name); err != nil {
+			return errors.Wrapf(err, "detach removed replication channel %s", st.Name)
+		}
+	}
+
+	if err := r.reconcileSecondaryReplicationChannels(ctx, cr, primary, operatorPass); err != nil {
+		return errors.Wrap(err, "reconcile replication channels on secondaries")
+	}
+
+	if !cr.IsStandby() && wasStandby {
+		log.Info("Making promoted cluster writable", "primary", primary)
+		if err := rm.SetSuperReadOnly(ctx, false); err != nil {
+			return errors.Wrapf(err, "make %s writable", primary)
+		}
+	}
+
+	cr.Status.ReplicationChannels = statuses
+
+	return nil
+}
+
+func (r *PerconaServerMySQLReconciler) reconcileReplicationChannel(
+	ctx context.Context,
+	cr *apiv1alpha1.PerconaServerMySQL,
+	rm *database.ReplicationDBManager,
+	ch *apiv1alpha1.ReplicationChannel,
+) (apiv1alpha1.ReplicationChannelStatus, error) {
+	log := logf.FromContext(ctx)
+
+	status := apiv1alpha1.ReplicationChannelStatus{Name: ch.Name}
+
+	if ch.Promote {
+		if err := detachReplicationChannel(ctx, rm, ch.Name); err != nil {
+			return status, errors.Wrap(err, "detach channel")
+		}
+		status.State = apiv1alpha1.ReplicationChannelPromoted
+		return status, nil
+	}
+
+	user, pass, err := r.replicationChannelCredentials(ctx, cr, ch)
+	if err != nil {
+		return status, errors.Wrap(err, "get credentials")
+	}
+	desired := replicationChannelConfig(ch, user, pass)
+
+	current, err := rm.ReplicationChannelStatus(ctx, ch.Name)
+	if err != nil {
+		return status, errors.Wrap(err, "get channel status")
+	}
+
+	start := false
+	if current == nil || !replicationChannelUpToDate(current, desired, ch.Sources) {
+		log.Info("Configuring replication channel", "channel", ch.Name, "source", fmt.Sprintf("%s:%d", desired.Host, desired.Port))
+		if current != nil {
+			if err := rm.StopReplicationChannel(ctx, ch.Name); err != nil {
+				return status, err
+			}
+		}
+		if err := rm.ChangeReplicationChannel(ctx, desired); err != nil {
+			return status, err
+		}
+		current = nil
+		start = true
+	}
+
+	if err := syncReplicationChannelSources(ctx, rm, ch); err != nil {
+		return status, errors.Wrap(err, "sync sources")
+	}
+
+	// The channel stopped because of an error isn't restarted, the error is reported instead.
+	if current != nil && current.Error == "" && (current.ConnState == "OFF" || current.ApplierState == "OFF") {
+		start = true
+	}
+	if start {
+		if err := rm.StartReplicationChannel(ctx, ch.Name); err != nil {
+			return status, err
+		}
+	}
+
+	switch {
+	case current == nil:
+		status.State = apiv1alpha1.ReplicationChannelConnecting
+		status.Source = fmt.Sprintf("%s:%d", desired.Host, desired.Port)
+		return status, nil
+	case start:
+		status.State = apiv1alpha1.ReplicationChannelConnecting
+	case current.Error != "":
+		status.State = apiv1alpha1.ReplicationChannelError
+		status.Message = current.Error + replicationChannelErrorHint(current.Error)
+	case current.ConnState == "ON" && current.ApplierState == "ON":
+		status.State = apiv1alpha1.ReplicationChannelRunning
+	default:
+		status.State = apiv1alpha1.ReplicationChannelConnecting
+	}
+	status.Source = fmt.Sprintf("%s:%d", current.Host, current.Port)
+
+	return status, nil
+}
+
+// reconcileSecondaryReplicationChannels configures the channels on the ready secondaries without starting them.
+// Group replication starts the failover channels on the new primary when the primary changes, but only
+// if they're configured on it. The failover sources are managed on the primary and propagated by the group.
+func (r *PerconaServerMySQLReconciler) reconcileSecondaryReplicationChannels(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL, primary, operatorPass string) error {
+	pods, err := k8s.PodsByLabels(ctx, r.Client, mysql.MatchLabels(cr), cr.Namespace)
+	if err != nil {
+		return errors.Wrap(err, "get pods")
+	}
+
+	names := make(map[string]struct{}, len(cr.Spec.ReplicationChannels))
+	for _, ch := range cr.Spec.ReplicationChannels {
+		names[ch.Name] = struct{}{}
+	}
+
+	for i := range pods {
+		pod := &pods[i]
+		host := mysql.PodFQDN(cr, pod)
+		if host == primary || !k8s.IsPodReady(*pod) {
+			continue
+		}
+		rm := database.NewReplicationManager(pod, r.ClientCmd, apiv1alpha1.UserOperator, operatorPass, host)
+
+		for j := range cr.Spec.ReplicationChannels {
+			ch := &cr.Spec.ReplicationChannels[j]
+			if ch.Promote {
+				if err := resetReplicationChannel(ctx, rm, ch.Name); err != nil {
+					return errors.Wrapf(err, "reset promoted channel %s on %s", ch.Name, pod.Name)
+				}
+				continue
+			}
+			if err := r.configureSecondaryReplicationChannel(ctx, cr, rm, ch); err != nil {
+				return errors.Wrapf(err, "configure channel %s on %s", ch.Name, pod.Name)
+			}
+		}
+
+		for _, st := range cr.Status.ReplicationChannels {
+			if _, ok := names[st.Name]; ok {
+				continue
+			}
+			if err := resetReplicationChannel(ctx, rm, st.Name); err != nil {
+				return errors.Wrapf(err, "reset removed channel %s on %s", st.Name, pod.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// configureSecondaryReplicationChannel configures the channel on a secondary and stops it if it's running,
+// e.g. on the former primary.
+func (r *PerconaServerMySQLReconciler) configureSecondaryReplicationChannel(
+	ctx context.Context,
+	cr *apiv1alpha1.PerconaServerMySQL,
+	rm *database.ReplicationDBManager,
+	ch *apiv1alpha1.ReplicationChannel,
+) error {
+	user, pass, err := r.replicationChannelCredentials(ctx, cr, ch)
+	if err != nil {
+		return errors.Wrap(err, "get credentials")
+	}
+	desired := replicationChannelConfig(ch, user, pass)
+
+	current, err := rm.ReplicationChannelStatus(ctx, ch.Name)
+	if err != nil {
+		return errors.Wrap(err, "get channel status")
+	}
+
+	if current != nil && (current.ConnState != "OFF" || current.ApplierState != "OFF") {
+		if err := rm.StopReplicationChannel(ctx, ch.Name); err != nil {
+			return err
+		}
+	}
+	if current != nil && replicationChannelUpToDate(current, desired, ch.Sources) {
+		return nil
+	}
+
+	return rm.ChangeReplicationChannel(ctx, desired)
+}
+
+// replicationChannelErrorHint explains the channel errors caused by a standby that wasn't seeded from its sources.
+func replicationChannelErrorHint(msg string) string {
+	if strings.Contains(msg, "Operation CREATE USER failed") || strings.Contains(msg, "fatal error 1236") {
+		return "; the standby should be bootstrapped from a backup of the source with spec.mysql.initFrom"
+	}
+	return ""
+}
+
+// replicationChannelCredentials returns the user and the password from spec.replicationChannels[].credentialsSecret
+// or the replication user of the cluster if the secret isn't set.
+func (r *PerconaServerMySQLReconciler) replicationChannelCredentials(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL, ch *apiv1alpha1.ReplicationChannel) (string, string, error) {
+	if ch.CredentialsSecret == "" {
+		pass, err := k8s.UserPassword(ctx, r.Client, cr, apiv1alpha1.UserReplication)
+		if err != nil {
+			return "", "", errors.Wrap(err, "get replication password")
+		}
+		return string(apiv1alpha1.UserReplication), pass, nil
+	}
+
+	secret := new(corev1.Secret)
+	nn := types.NamespacedName{Name: ch.CredentialsSecret, Namespace: cr.Namespace}
+	if err := r.Client.Get(ctx, nn, secret); err != nil {
+		return "", "", errors.Wrapf(err, "get secret %s", nn.Name)
+	}
+
+	user, pass := secret.Data[corev1.BasicAuthUsernameKey], secret.Data[corev1.BasicAuthPasswordKey]
+	if len(user) == 0 || len(pass) == 0 {
+		return "", "", errors.Errorf("secret %s should contain %s and %s keys", nn.Name, corev1.BasicAuthUsernameKey, corev1.BasicAuthPasswordKey)
+	}
+	return string(user), string(pass), nil
+}
+
+// replicationChannelConfig returns the configuration of the channel. The source with the highest weight is used,
+// MySQL fails over to the other ones by itself.
+func replicationChannelConfig(ch *apiv1alpha1.ReplicationChannel, user, pass string) database.ReplicationChannel {
+	src := ch.Sources[0]
+	for _, s := range ch.Sources[1:] {
+		if s.Weight > src.Weight {
+			src = s
+		}
+	}
+
+	cfg := database.ReplicationChannel{
+		Name:         ch.Name,
+		Host:         src.Host,
+		Port:         src.Port,
+		User:         user,
+		Password:     pass,
+		SSL:          ch.TLSEnabled(),
+		RetryCount:   ch.Config.SourceRetryCount,
+		ConnectRetry: ch.Config.SourceConnectRetry,
+	}
+	if cfg.SSL && ch.TLS != nil && ch.TLS.VerifyServerCert {
+		cfg.SSLVerify = true
+		cfg.SSLCA = mysql.TLSCAPath
+	}
+	return cfg
+}
+
+// replicationChannelUpToDate checks if the channel is configured as desired.
+// The channel can use any of the sources, since it fails over between them.
+// Password changes aren't detected.
+func replicationChannelUpToDate(current *database.ReplicationChannelStatus, desired database.ReplicationChannel, sources []apiv1alpha1.ReplicationSource) bool {
+	if current.User != desired.User ||
+		(current.SSL == "YES") != desired.SSL ||
+		(current.SSLVerify == "YES") != desired.SSLVerify ||
+		current.RetryCount != desired.RetryCount ||
+		current.ConnectRetry != desired.ConnectRetry {
+		return false
+	}
+
+	for _, src := range sources {
+		if src.Host == current.Host && src.Port == current.Port {
+			return true
+		}
+	}
+	return false
+}
+
+// syncReplicationChannelSources makes the failover source list of the channel match its spec.
+func syncReplicationChannelSources(ctx context.Context, rm *database.ReplicationDBManager, ch *apiv1alpha1.ReplicationChannel) error {
+	current, err := rm.ReplicationChannelSources(ctx, ch.Name)
+	if err != nil {
+		return err
+	}
+
+	key := func(host string, port int32) string {
+		return fmt.Sprintf("%s:%d", host, port)
+	}
+	desired := make(map[string]int32, len(ch.Sources))
+	for _, src := range ch.Sources {
+		desired[key(src.Host, src.Port)] = src.Weight
+	}
+
+	synced := make(map[string]struct{}, len(current))
+	for _, src := range current {
+		k := key(src.Host, src.Port)
+		if weight, ok := desired[k]; ok && weight == src.Weight {
+			synced[k] = struct{}{}
+			continue
+		}
+		if err := rm.DeleteReplicationChannelSource(ctx, ch.Name, src); err != nil {
+			return err
+		}
+	}
+
+	for _, src := range ch.Sources {
+		k := key(src.Host, src.Port)
+		if _, ok := synced[k]; ok {
+			continue
+		}
+		err := rm.AddReplicationChannelSource(ctx, ch.Name, database.ReplicationSource{Host: src.Host, Port: src.Port, Weight: src.Weight})
+		if err != nil {
+			return err
+		}
+		synced[k] = struct{}{}
+	}
+
+	return nil
+}
+
+// detachReplicationChannel stops the channel and removes its configuration and sources if it's configured.
+func detachReplicationChannel(ctx context.Context, rm *database.ReplicationDBManager, name string) error {
+	current, err := rm.ReplicationChannelStatus(ctx, name)
+	if err != nil {
+		return errors.Wrap(err, "get channel status")
+	}
+	if current == nil {
+		return nil
+	}
+
+	logf.FromContext(ctx).Info("Detaching replication channel", "channel", name)
+
+	sources, err := rm.ReplicationChannelSources(ctx, name)
+	if err != nil {
+		return err
+	}
+	for _, src := range sources {
+		if err := rm.DeleteReplicationChannelSource(ctx, name, src); err != nil {
+			return err
+		}
+	}
+
+	return rm.ResetReplicationChannel(ctx, name)
+}
+
+// resetReplicationChannel stops the channel and removes its configuration if it's configured.
+// Its sources are left alone, since they can be changed only on the primary.
+func resetReplicationChannel(ctx context.Context, rm *database.ReplicationDBManager, name string) error {
+	current, err := rm.ReplicationChannelStatus(ctx, name)
+	if err != nil {
+		return errors.Wrap(err, "get channel status")
+	}
+	if current == nil {
+		return nil
+	}
+
+	return rm.ResetReplicationChannel(ctx, name)
+}
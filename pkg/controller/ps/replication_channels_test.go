@@ -0,0 +1,335 @@
+package ps
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	restclient "k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/platform"
+)
+
+type fakeChannel struct {
+	host    string
+	port    int
+	user    string
+	ssl     string
+	running bool
+	err     string
+	sources map[string]int
+}
+
+// fakeChannelExec models replication channels, their failover sources and super_read_only of the primary
+// cluster1-mysql-0 and replication channels of the secondary cluster1-mysql-1.
+type fakeChannelExec struct {
+	channels          map[string]*fakeChannel
+	secondaryChannels map[string]*fakeChannel
+	superReadOnly     bool
+	statements        []string
+}
+
+var (
+	channelNameRe = regexp.MustCompile(`CHANNEL(?:_NAME =)? '([a-z0-9_]+)'`)
+	changeOptRe   = regexp.MustCompile(`SOURCE_(HOST|PORT|USER|SSL)=(?:'([^']*)'|(\d+))`)
+	sourceFuncRe  = regexp.MustCompile(`asynchronous_connection_failover_(add|delete)_source\('([a-z0-9_]+)', '([^']+)', (\d+), ''(?:, (\d+))?\)`)
+)
+
+func (e *fakeChannelExec) Exec(_ context.Context, _ *corev1.Pod, _ string, command []string, _ io.Reader, stdout, _ io.Writer, _ bool) error {
+	stm := command[len(command)-1]
+
+	channels := e.channels
+	secondary := strings.Contains(strings.Join(command, " "), "-h cluster1-mysql-1.")
+	if secondary {
+		channels = e.secondaryChannels
+		if sourceFuncRe.MatchString(stm) || strings.Contains(stm, "super_read_only") {
+			return fmt.Errorf("unexpected statement on secondary: %s", stm)
+		}
+	}
+
+	var name string
+	if m := channelNameRe.FindStringSubmatch(stm); m != nil {
+		name = m[1]
+	}
+	ch := channels[name]
+
+	switch {
+	case strings.Contains(stm, "replication_group_members"):
+		fmt.Fprint(stdout, "host\ncluster1-mysql-0.cluster1-mysql.ns\n")
+		return nil
+	case strings.Contains(stm, "replication_connection_configuration"):
+		if ch != nil {
+			state := "OFF"
+			if ch.running {
+				state = "ON"
+			}
+			fmt.Fprint(stdout, "host\tport\tuser\tssl\tssl_verify\tssl_ca\tretry_count\tconnect_retry\tconn_state\tapplier_state\terror\n")
+			fmt.Fprintf(stdout, "%s\t%d\t%s\t%s\tNO\t\t3\t60\t%s\t%s\t%s\n", ch.host, ch.port, ch.user, ch.ssl, state, state, ch.err)
+		}
+		return nil
+	case strings.Contains(stm, "FROM replication_asynchronous_connection_failover"):
+		if ch != nil && len(ch.sources) > 0 {
+			fmt.Fprint(stdout, "host\tport\tweight\n")
+			for src, weight := range ch.sources {
+				host, port, _ := strings.Cut(src, ":")
+				fmt.Fprintf(stdout, "%s\t%s\t%d\n", host, port, weight)
+			}
+		}
+		return nil
+	}
+
+	if !secondary {
+		e.statements = append(e.statements, stm)
+	}
+
+	switch {
+	case strings.HasPrefix(stm, "CHANGE REPLICATION SOURCE"):
+		if ch == nil {
+			ch = &fakeChannel{sources: make(map[string]int)}
+			channels[name] = ch
+		}
+		for _, m := range changeOptRe.FindAllStringSubmatch(stm, -1) {
+			switch m[1] {
+			case "HOST":
+				ch.host = m[2]
+			case "PORT":
+				ch.port, _ = strconv.Atoi(m[3])
+			case "USER":
+				ch.user = m[2]
+			case "SSL":
+				ch.ssl = map[string]string{"0": "NO", "1": "YES"}[m[3]]
+			}
+		}
+	case strings.HasPrefix(stm, "START REPLICA"):
+		ch.running = true
+	case strings.Contains(stm, "RESET REPLICA ALL"):
+		delete(channels, name)
+	case strings.HasPrefix(stm, "STOP REPLICA"):
+		ch.running = false
+	case sourceFuncRe.MatchString(stm):
+		m := sourceFuncRe.FindStringSubmatch(stm)
+		src := m[3] + ":" + m[4]
+		if m[1] == "add" {
+			weight, _ := strconv.Atoi(m[5])
+			e.channels[m[2]].sources[src] = weight
+		} else {
+			delete(e.channels[m[2]].sources, src)
+		}
+	case strings.Contains(stm, "super_read_only=ON"):
+		e.superReadOnly = true
+	case strings.Contains(stm, "super_read_only=OFF"):
+		e.superReadOnly = false
+	}
+	return nil
+}
+
+func (e *fakeChannelExec) REST() restclient.Interface {
+	return nil
+}
+
+func TestReconcileReplicationChannels(t *testing.T) {
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	cr, err := readDefaultCR("cluster1", "ns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cr.Spec.ReplicationChannels = []apiv1alpha1.ReplicationChannel{{
+		Name: "dr",
+		Sources: []apiv1alpha1.ReplicationSource{
+			{Host: "prod-mysql-0.prod.svc", Weight: 50},
+			{Host: "prod-mysql-1.prod.svc", Port: 3307},
+		},
+		CredentialsSecret: "dr-credentials",
+	}}
+	if err := cr.CheckNSetDefaults(ctx, new(platform.ServerVersion)); err != nil {
+		t.Fatal(err)
+	}
+	meta.SetStatusCondition(&cr.Status.Conditions, metav1.Condition{
+		Type:   apiv1alpha1.ConditionInnoDBClusterBootstrapped,
+		Status: metav1.ConditionTrue,
+		Reason: apiv1alpha1.ConditionInnoDBClusterBootstrapped,
+	})
+
+	objects := makeFakeReadyPods(cr, 2, "mysql")
+	objects = append(objects,
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: cr.InternalSecretName(), Namespace: cr.Namespace},
+			Data:       map[string][]byte{string(apiv1alpha1.UserOperator): []byte("operator-pass")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "dr-credentials", Namespace: cr.Namespace},
+			Data: map[string][]byte{
+				corev1.BasicAuthUsernameKey: []byte("standby"),
+				corev1.BasicAuthPasswordKey: []byte("standby-pass"),
+			},
+		},
+	)
+	for _, obj := range objects {
+		obj.SetNamespace(cr.Namespace)
+	}
+
+	exec := &fakeChannelExec{
+		channels:          make(map[string]*fakeChannel),
+		secondaryChannels: make(map[string]*fakeChannel),
+	}
+	r := &PerconaServerMySQLReconciler{
+		Client:    fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+		Scheme:    scheme,
+		ClientCmd: exec,
+	}
+
+	t.Run("configure", func(t *testing.T) {
+		if err := r.reconcileReplicationChannels(ctx, cr); err != nil {
+			t.Fatal(err)
+		}
+
+		ch := exec.channels["dr"]
+		if ch == nil || !ch.running {
+			t.Fatalf("expected channel to be configured and started, got %+v", ch)
+		}
+		if ch.host != "prod-mysql-1.prod.svc" || ch.port != 3307 || ch.user != "standby" || ch.ssl != "YES" {
+			t.Fatalf("expected channel to use the source with the highest weight over TLS, got %+v", ch)
+		}
+		expected := map[string]int{"prod-mysql-0.prod.svc:3306": 50, "prod-mysql-1.prod.svc:3307": 100}
+		if fmt.Sprint(ch.sources) != fmt.Sprint(expected) {
+			t.Fatalf("expected sources %v, got %v", expected, ch.sources)
+		}
+		if !exec.superReadOnly {
+			t.Fatal("expected standby to be read-only")
+		}
+		if sch := exec.secondaryChannels["dr"]; sch == nil || sch.running || sch.host != ch.host || sch.user != ch.user {
+			t.Fatalf("expected channel to be configured but not started on secondary, got %+v", sch)
+		}
+		st := cr.Status.ReplicationChannels
+		if len(st) != 1 || st[0].State != apiv1alpha1.ReplicationChannelConnecting || st[0].Source != "prod-mysql-1.prod.svc:3307" {
+			t.Fatalf("unexpected status: %+v", st)
+		}
+
+		// The configured channel is left alone.
+		exec.statements = nil
+		if err := r.reconcileReplicationChannels(ctx, cr); err != nil {
+			t.Fatal(err)
+		}
+		if len(exec.statements) != 1 || !strings.Contains(exec.statements[0], "super_read_only=ON") {
+			t.Fatalf("expected channel not to be reconfigured, got %q", exec.statements)
+		}
+		if st := cr.Status.ReplicationChannels; st[0].State != apiv1alpha1.ReplicationChannelRunning {
+			t.Fatalf("expected running channel, got %+v", st)
+		}
+	})
+
+	t.Run("former primary", func(t *testing.T) {
+		exec.secondaryChannels["dr"].running = true
+
+		if err := r.reconcileReplicationChannels(ctx, cr); err != nil {
+			t.Fatal(err)
+		}
+		if sch := exec.secondaryChannels["dr"]; sch == nil || sch.running {
+			t.Fatalf("expected channel to be stopped on secondary, got %+v", sch)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		exec.channels["dr"].running = false
+		exec.channels["dr"].err = "error connecting to source"
+
+		exec.statements = nil
+		if err := r.reconcileReplicationChannels(ctx, cr); err != nil {
+			t.Fatal(err)
+		}
+		if len(exec.statements) != 1 {
+			t.Fatalf("expected failed channel not to be restarted, got %q", exec.statements)
+		}
+		st := cr.Status.ReplicationChannels
+		if st[0].State != apiv1alpha1.ReplicationChannelError || st[0].Message != "error connecting to source" {
+			t.Fatalf("expected channel error in status, got %+v", st)
+		}
+
+		exec.channels["dr"].err = "Got fatal error 1236 from source when reading data from binary log"
+		if err := r.reconcileReplicationChannels(ctx, cr); err != nil {
+			t.Fatal(err)
+		}
+		if msg := cr.Status.ReplicationChannels[0].Message; !strings.Contains(msg, "spec.mysql.initFrom") {
+			t.Fatalf("expected seeding hint in status, got %q", msg)
+		}
+
+		exec.channels["dr"].running = true
+		exec.channels["dr"].err = ""
+	})
+
+	t.Run("promote", func(t *testing.T) {
+		cr.Spec.ReplicationChannels[0].Promote = true
+
+		if err := r.reconcileReplicationChannels(ctx, cr); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := exec.channels["dr"]; ok {
+			t.Fatal("expected channel to be detached")
+		}
+		if _, ok := exec.secondaryChannels["dr"]; ok {
+			t.Fatal("expected channel to be reset on secondary")
+		}
+		if exec.superReadOnly {
+			t.Fatal("expected promoted cluster to be writable")
+		}
+		st := cr.Status.ReplicationChannels
+		if len(st) != 1 || st[0].State != apiv1alpha1.ReplicationChannelPromoted {
+			t.Fatalf("expected promoted channel, got %+v", st)
+		}
+
+		exec.statements = nil
+		if err := r.reconcileReplicationChannels(ctx, cr); err != nil {
+			t.Fatal(err)
+		}
+		if len(exec.statements) != 0 {
+			t.Fatalf("expected promoted cluster not to be touched, got %q", exec.statements)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		cr.Spec.ReplicationChannels[0].Promote = false
+		if err := r.reconcileReplicationChannels(ctx, cr); err != nil {
+			t.Fatal(err)
+		}
+		if exec.channels["dr"] == nil || exec.secondaryChannels["dr"] == nil || !exec.superReadOnly {
+			t.Fatal("expected cluster to be a standby again")
+		}
+
+		cr.Spec.ReplicationChannels = nil
+		if err := r.reconcileReplicationChannels(ctx, cr); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := exec.channels["dr"]; ok {
+			t.Fatal("expected removed channel to be detached")
+		}
+		if _, ok := exec.secondaryChannels["dr"]; ok {
+			t.Fatal("expected removed channel to be reset on secondary")
+		}
+		if exec.superReadOnly {
+			t.Fatal("expected cluster without channels to be writable")
+		}
+		if cr.Status.ReplicationChannels != nil {
+			t.Fatalf("expected empty status, got %+v", cr.Status.ReplicationChannels)
+		}
+	})
+}
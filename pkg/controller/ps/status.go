@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
@@ -179,6 +181,17 @@ func (r *PerconaServerMySQLReconciler) reconcileCRStatus(ctx context.Context, cr
 		}
 	}
 
+	// The previous members are used while the state of each member isn't refreshed.
+	var members []apiv1alpha1.MySQLMemberStatus
+	if cr.Status.MySQL.Ready > 0 {
+		members, err = r.mysqlMembers(ctx, cr)
+		if err != nil {
+			// The topology is informational, so the rest of the status is written anyway.
+			log.Error(err, "failed to get MySQL members")
+		}
+	}
+	cr.Status.Members = members
+
 	cr.Status.Host, err = appHost(ctx, r.Client, cr)
 	if err != nil {
 		return errors.Wrap(err, "get app host")
@@ -312,6 +325,172 @@ func (r *PerconaServerMySQLReconciler) isAsyncReady(ctx context.Context, cr *api
 	return msg == "", msg, nil
 }
 
+// mysqlMembers returns the replication topology of the MySQL pods. It's taken from Orchestrator
+// for asynchronous replication and from the group members for group replication.
+func (r *PerconaServerMySQLReconciler) mysqlMembers(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL) ([]apiv1alpha1.MySQLMemberStatus, error) {
+	var members []apiv1alpha1.MySQLMemberStatus
+	var err error
+	switch {
+	case cr.Spec.MySQL.IsGR():
+		members, err = r.groupReplicationMembers(ctx, cr)
+	case cr.OrchestratorEnabled():
+		members, err = r.orchestratorMembers(ctx, cr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Name < members[j].Name
+	})
+	return members, nil
+}
+
+func (r *PerconaServerMySQLReconciler) orchestratorMembers(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL) ([]apiv1alpha1.MySQLMemberStatus, error) {
+	pod, err := getReadyOrcPod(ctx, r.Client, cr)
+	if err != nil {
+		return nil, err
+	}
+
+	instances, err := orchestrator.Cluster(ctx, r.ClientCmd, pod, cr.ClusterHint())
+	if err != nil {
+		return nil, errors.Wrap(err, "get orchestrator cluster")
+	}
+
+	members := make([]apiv1alpha1.MySQLMemberStatus, 0, len(instances))
+	for _, i := range instances {
+		m := apiv1alpha1.MySQLMemberStatus{
+			Name:         i.Alias,
+			Role:         apiv1alpha1.MySQLMemberPrimary,
+			State:        string(innodbcluster.MemberStateOnline),
+			GTIDExecuted: strings.ReplaceAll(i.ExecutedGtidSet, "\n", ""),
+			Problems:     i.Problems,
+		}
+		if !i.IsLastCheckValid {
+			m.State = string(innodbcluster.MemberStateUnreachable)
+		}
+		if i.MasterKey.Hostname != "" {
+			m.Role = apiv1alpha1.MySQLMemberReplica
+			m.ReceiverState = threadState(i.ReplicationIOThreadRunning)
+			m.ApplierState = threadState(i.ReplicationSQLThreadRunning)
+			if i.ReplicationLagSeconds.Valid {
+				m.ReplicationLag = &i.ReplicationLagSeconds.Int64
+			}
+		}
+		members = append(members, m)
+	}
+
+	return members, nil
+}
+
+// replicationCheckInterval is how often the replication state is queried on each MySQL pod.
+// Every query is an exec into the pod, so they aren't run on every reconcile.
+const replicationCheckInterval = 30 * time.Second
+
+// replicationCheckDue returns true and records the current time if the check of the cluster
+// wasn't run for replicationCheckInterval.
+func (r *PerconaServerMySQLReconciler) replicationCheckDue(cr *apiv1alpha1.PerconaServerMySQL, check string) bool {
+	key := cr.Namespace + "/" + cr.Name + "/" + check
+	if last, ok := r.Crons.lastReplicationCheck.Load(key); ok && time.Since(last.(time.Time)) < replicationCheckInterval {
+		return false
+	}
+	r.Crons.lastReplicationCheck.Store(key, time.Now())
+	return true
+}
+
+// groupReplicationMembers returns the state of all MySQL pods. Pods that aren't in the group are OFFLINE.
+// Members and applier queues are queried on one pod on every call, gtid_executed and thread states
+// are queried on each pod once per replicationCheckInterval and taken from the status otherwise.
+func (r *PerconaServerMySQLReconciler) groupReplicationMembers(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL) ([]apiv1alpha1.MySQLMemberStatus, error) {
+	log := logf.FromContext(ctx)
+
+	operatorPass, err := k8s.UserPassword(ctx, r.Client, cr, apiv1alpha1.UserOperator)
+	if err != nil {
+		return nil, errors.Wrap(err, "get operator password")
+	}
+
+	pod, err := getReadyMySQLPod(ctx, r.Client, cr)
+	if err != nil {
+		return nil, errors.Wrap(err, "get ready mysql pod")
+	}
+
+	rm := database.NewReplicationManager(pod, r.ClientCmd, apiv1alpha1.UserOperator, operatorPass, mysql.PodFQDN(cr, pod))
+	grMembers, err := rm.GetGroupReplicationMembers(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get group replication members")
+	}
+	queues, err := rm.GetGroupReplicationApplierQueues(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get applier queues")
+	}
+
+	byHost := make(map[string]innodbcluster.Member, len(grMembers))
+	for _, m := range grMembers {
+		byHost[m.Address] = m
+	}
+
+	pods, err := k8s.PodsByLabels(ctx, r.Client, mysql.MatchLabels(cr), cr.Namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "get pods")
+	}
+
+	previous := make(map[string]apiv1alpha1.MySQLMemberStatus, len(cr.Status.Members))
+	for _, m := range cr.Status.Members {
+		previous[m.Name] = m
+	}
+	refresh := r.replicationCheckDue(cr, "members")
+
+	members := make([]apiv1alpha1.MySQLMemberStatus, 0, len(pods))
+	for i := range pods {
+		host := mysql.PodFQDN(cr, &pods[i])
+		m := apiv1alpha1.MySQLMemberStatus{
+			Name:  pods[i].Name,
+			State: string(innodbcluster.MemberStateOffline),
+		}
+
+		if gm, ok := byHost[host]; ok {
+			m.State = string(gm.MemberState)
+			m.Role = apiv1alpha1.MySQLMemberReplica
+			if gm.MemberRole == innodbcluster.MemberRolePrimary {
+				m.Role = apiv1alpha1.MySQLMemberPrimary
+			} else if q, ok := queues[host]; ok {
+				m.ReplicationLag = &q
+			}
+		}
+
+		switch {
+		case !k8s.IsPodReady(pods[i]):
+		case !refresh:
+			if p, ok := previous[pods[i].Name]; ok {
+				m.GTIDExecuted = p.GTIDExecuted
+				m.ReceiverState = p.ReceiverState
+				m.ApplierState = p.ApplierState
+			}
+		default:
+			rm := database.NewReplicationManager(&pods[i], r.ClientCmd, apiv1alpha1.UserOperator, operatorPass, host)
+			state, err := rm.GetServerReplicationState(ctx, "group_replication_applier")
+			if err != nil {
+				log.Error(err, "failed to get replication state", "pod", pods[i].Name)
+			} else {
+				m.GTIDExecuted = state.GTIDExecuted
+				m.ReceiverState = state.ReceiverState
+				m.ApplierState = state.ApplierState
+			}
+		}
+
+		members = append(members, m)
+	}
+
+	return members, nil
+}
+
+func threadState(running bool) string {
+	if running {
+		return "ON"
+	}
+	return "OFF"
+}
+
 func (r *PerconaServerMySQLReconciler) allLoadBalancersReady(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQL) (bool, error) {
 	opts := &client.ListOptions{Namespace: cr.Namespace, LabelSelector: labels.SelectorFromSet(cr.Labels())}
 	svcList := &corev1.ServiceList{}
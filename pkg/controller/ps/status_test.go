@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/gocarina/gocsv"
@@ -383,6 +384,7 @@ func TestReconcileStatusAsync(t *testing.T) {
 				ServerVersion: &platform.ServerVersion{
 					Platform: platform.PlatformKubernetes,
 				},
+				Crons: NewCronRegistry(),
 			}
 
 			err = r.reconcileCRStatus(ctx, cr, nil)
@@ -647,6 +649,7 @@ func TestReconcileStatusHAProxyGR(t *testing.T) {
 				},
 				ClientCmd: cliCmd,
 				Recorder:  new(record.FakeRecorder),
+				Crons:     NewCronRegistry(),
 			}
 
 			err = r.reconcileCRStatus(ctx, cr, nil)
@@ -911,6 +914,7 @@ func TestReconcileStatusRouterGR(t *testing.T) {
 				},
 				ClientCmd: cliCmd,
 				Recorder:  new(record.FakeRecorder),
+				Crons:     NewCronRegistry(),
 			}
 
 			err = r.reconcileCRStatus(ctx, cr, nil)
@@ -1003,6 +1007,174 @@ func TestReconcileErrorStatus(t *testing.T) {
 	}
 }
 
+// fakeTopologyExec answers the queries and Orchestrator requests used to get the MySQL members.
+// The state of each pod can't be queried if stateCached is set.
+type fakeTopologyExec struct {
+	stateCached bool
+}
+
+func (e *fakeTopologyExec) Exec(_ context.Context, pod *corev1.Pod, _ string, command []string, _ io.Reader, stdout, _ io.Writer, _ bool) error {
+	cmd := strings.Join(command, " ")
+
+	switch {
+	case strings.Contains(cmd, "api/cluster/"):
+		fmt.Fprint(stdout, `[
+			{"InstanceAlias":"cluster1-mysql-0","ExecutedGtidSet":"uuid:1-10","IsLastCheckValid":true},
+			{"InstanceAlias":"cluster1-mysql-1","MasterKey":{"Hostname":"cluster1-mysql-0"},"ExecutedGtidSet":"uuid:1-8",
+				"ReplicationLagSeconds":{"Int64":2,"Valid":true},"ReplicationIOThreadRuning":true,"ReplicationSQLThreadRuning":true,"IsLastCheckValid":true},
+			{"InstanceAlias":"cluster1-mysql-2","MasterKey":{"Hostname":"cluster1-mysql-0"},"ExecutedGtidSet":"uuid:1-5",
+				"ReplicationLagSeconds":{"Int64":0,"Valid":false},"ReplicationIOThreadRuning":false,"ReplicationSQLThreadRuning":true,
+				"IsLastCheckValid":false,"Problems":["not_replicating"]}
+		]`)
+	case strings.Contains(cmd, "COUNT_TRANSACTIONS_REMOTE_IN_APPLIER_QUEUE"):
+		fmt.Fprint(stdout, "host\tqueue\n")
+		fmt.Fprint(stdout, "cluster1-mysql-0.cluster1-mysql.status-1\t0\n")
+		fmt.Fprint(stdout, "cluster1-mysql-1.cluster1-mysql.status-1\t7\n")
+	case strings.Contains(cmd, "FROM replication_group_members"):
+		fmt.Fprint(stdout, "member\tstate\trole\n")
+		fmt.Fprint(stdout, "cluster1-mysql-0.cluster1-mysql.status-1\tONLINE\tPRIMARY\n")
+		fmt.Fprint(stdout, "cluster1-mysql-1.cluster1-mysql.status-1\tRECOVERING\tSECONDARY\n")
+	case strings.Contains(cmd, "gtid_executed"):
+		if e.stateCached {
+			return errors.Errorf("state of %s is queried before it's due", pod.Name)
+		}
+		fmt.Fprint(stdout, "gtid_executed\treceiver_state\tapplier_state\n")
+		fmt.Fprintf(stdout, "%s-uuid:1-10\tON\tON\n", pod.Name)
+	default:
+		return errors.Errorf("unexpected command: %s", cmd)
+	}
+	return nil
+}
+
+func (e *fakeTopologyExec) REST() restclient.Interface {
+	return nil
+}
+
+func TestMySQLMembers(t *testing.T) {
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	int64Ptr := func(i int64) *int64 { return &i }
+
+	tests := []struct {
+		name        string
+		clusterType apiv1alpha1.ClusterType
+		expected    []apiv1alpha1.MySQLMemberStatus
+	}{
+		{
+			name:        "group replication",
+			clusterType: apiv1alpha1.ClusterTypeGR,
+			expected: []apiv1alpha1.MySQLMemberStatus{
+				{
+					Name:          "cluster1-mysql-0",
+					Role:          apiv1alpha1.MySQLMemberPrimary,
+					State:         "ONLINE",
+					GTIDExecuted:  "cluster1-mysql-0-uuid:1-10",
+					ReceiverState: "ON",
+					ApplierState:  "ON",
+				},
+				{
+					Name:           "cluster1-mysql-1",
+					Role:           apiv1alpha1.MySQLMemberReplica,
+					State:          "RECOVERING",
+					GTIDExecuted:   "cluster1-mysql-1-uuid:1-10",
+					ReplicationLag: int64Ptr(7),
+					ReceiverState:  "ON",
+					ApplierState:   "ON",
+				},
+				{
+					Name:  "cluster1-mysql-2",
+					State: "OFFLINE",
+				},
+			},
+		},
+		{
+			name:        "async",
+			clusterType: apiv1alpha1.ClusterTypeAsync,
+			expected: []apiv1alpha1.MySQLMemberStatus{
+				{
+					Name:         "cluster1-mysql-0",
+					Role:         apiv1alpha1.MySQLMemberPrimary,
+					State:        "ONLINE",
+					GTIDExecuted: "uuid:1-10",
+				},
+				{
+					Name:           "cluster1-mysql-1",
+					Role:           apiv1alpha1.MySQLMemberReplica,
+					State:          "ONLINE",
+					GTIDExecuted:   "uuid:1-8",
+					ReplicationLag: int64Ptr(2),
+					ReceiverState:  "ON",
+					ApplierState:   "ON",
+				},
+				{
+					Name:          "cluster1-mysql-2",
+					Role:          apiv1alpha1.MySQLMemberReplica,
+					State:         "UNREACHABLE",
+					GTIDExecuted:  "uuid:1-5",
+					ReceiverState: "OFF",
+					ApplierState:  "ON",
+					Problems:      []string{"not_replicating"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr, err := readDefaultCR("cluster1", "status-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			cr.Spec.MySQL.ClusterType = tt.clusterType
+
+			pods := makeFakeReadyPods(cr, 3, "mysql")
+			// The pod that isn't in the group isn't ready, so it isn't queried.
+			pods[2].(*corev1.Pod).Status.Conditions = nil
+
+			objects := appendSlices(pods, makeFakeReadyPods(cr, 3, "orchestrator"), []client.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: cr.InternalSecretName(), Namespace: cr.Namespace},
+					Data:       map[string][]byte{string(apiv1alpha1.UserOperator): []byte("test")},
+				},
+			})
+
+			r := &PerconaServerMySQLReconciler{
+				Client:    fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+				Scheme:    scheme,
+				ClientCmd: new(fakeTopologyExec),
+				Crons:     NewCronRegistry(),
+			}
+
+			members, err := r.mysqlMembers(ctx, cr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.expected, members); diff != "" {
+				t.Errorf("unexpected members (-want +got):\n%s", diff)
+			}
+
+			// The state of each member is kept from the status until it's due to be refreshed.
+			cr.Status.Members = members
+			r.ClientCmd = &fakeTopologyExec{stateCached: true}
+			members, err = r.mysqlMembers(ctx, cr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.expected, members); diff != "" {
+				t.Errorf("unexpected members before refresh (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 type fakeClient struct {
 	scripts   []fakeClientScript
 	execCount int
@@ -1114,15 +1286,17 @@ func getFakeClient(cr *apiv1alpha1.PerconaServerMySQL, mysqlMemberStates []innod
 		type member struct {
 			Member string `csv:"member"`
 			State  string `csv:"state"`
+			Role   string `csv:"role"`
 		}
 		var members []*member
 		for _, state := range mysqlMemberStates {
 			members = append(members, &member{
 				Member: cr.Name + "-mysql-0." + cr.Namespace,
 				State:  string(state),
+				Role:   string(innodbcluster.MemberRoleSecondary),
 			})
 		}
-		scripts = append(scripts, queryScript("SELECT MEMBER_HOST as member, MEMBER_STATE as state FROM replication_group_members", members))
+		scripts = append(scripts, queryScript("SELECT MEMBER_HOST as member, MEMBER_STATE as state, MEMBER_ROLE as role FROM replication_group_members", members))
 	}
 
 	scripts = append(scripts, fakeClientScript{
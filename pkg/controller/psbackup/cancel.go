@@ -0,0 +1,75 @@
+package psbackup
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup"
+)
+
+// cancelRequested returns true if the backup should be canceled
+// because spec.cancel is set or the object is deleted before the backup is finished.
+func cancelRequested(cr *apiv1alpha1.PerconaServerMySQLBackup) bool {
+	switch cr.Status.State {
+	case apiv1alpha1.BackupStarting, apiv1alpha1.BackupRunning:
+		return cr.Spec.Cancel || cr.DeletionTimestamp != nil
+	case apiv1alpha1.BackupNew:
+		return cr.Spec.Cancel
+	}
+	return false
+}
+
+// cancelBackup stops the backup job and deletes the data the backup has stored so far.
+func (r *PerconaServerMySQLBackupReconciler) cancelBackup(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup) error {
+	log := logf.FromContext(ctx)
+
+	job := &batchv1.Job{}
+	nn := xtrabackup.JobNamespacedName(cr)
+	if err := r.Client.Get(ctx, nn, job); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "get job %s", nn)
+	}
+
+	srcNode, _ := backupJobEnv(job)
+
+	// xtrabackup runs in the sidecar of the source node. The sidecar stops it
+	// and deletes the uploaded objects itself.
+	if !cr.Status.IsLogical() && srcNode != "" {
+		log.Info("Canceling backup", "node", srcNode)
+		if err := r.NewSidecarClient(srcNode).CancelBackup(ctx, cr.Name); err != nil {
+			return errors.Wrap(err, "cancel backup in sidecar")
+		}
+	}
+
+	// Otherwise the job requests the backup again or keeps dumping the data.
+	if err := r.Client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+		return errors.Wrapf(err, "delete job %s", nn)
+	}
+
+	switch {
+	case cr.Status.Storage != nil && cr.Status.Storage.Type == apiv1alpha1.BackupStorageFilesystem:
+		if _, err := r.deleteBackupPVC(ctx, cr); err != nil {
+			return errors.Wrap(err, "delete backup PVC")
+		}
+	case cr.Status.IsLogical() && srcNode != "":
+		// Logical backups are uploaded by the job, the sidecar is only used to clean them up.
+		backupConf, err := r.backupConfig(ctx, cr)
+		if err != nil {
+			return errors.Wrap(err, "failed to create sidecar backup config")
+		}
+		if err := r.NewSidecarClient(srcNode).DeleteBackup(ctx, cr.Name, *backupConf); err != nil {
+			return errors.Wrap(err, "delete backup")
+		}
+	}
+
+	return nil
+}
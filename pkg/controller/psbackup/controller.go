@@ -20,12 +20,17 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -37,11 +42,13 @@ import (
 
 	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
 	"github.com/percona/percona-server-mysql-operator/pkg/clientcmd"
+	"github.com/percona/percona-server-mysql-operator/pkg/hooks"
 	"github.com/percona/percona-server-mysql-operator/pkg/k8s"
 	"github.com/percona/percona-server-mysql-operator/pkg/naming"
 	"github.com/percona/percona-server-mysql-operator/pkg/platform"
 	"github.com/percona/percona-server-mysql-operator/pkg/secret"
 	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
 )
 
 // PerconaServerMySQLBackupReconciler reconciles a PerconaServerMySQLBackup object
@@ -50,11 +57,15 @@ type PerconaServerMySQLBackupReconciler struct {
 	Scheme        *runtime.Scheme
 	ServerVersion *platform.ServerVersion
 	ClientCmd     clientcmd.Client
+	// APIReader reads objects which aren't cached by the manager.
+	APIReader client.Reader
 
 	NewSidecarClient xtrabackup.NewSidecarClientFunc
+	NewStorageClient storage.NewClientFunc
 }
 
 //+kubebuilder:rbac:groups=ps.percona.com,resources=perconaservermysqlbackups;perconaservermysqlbackups/status;perconaservermysqlbackups/finalizers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 
@@ -89,10 +100,17 @@ func (r *PerconaServerMySQLBackupReconciler) Reconcile(ctx context.Context, req
 		return rr, errors.Wrapf(err, "get %v", req.NamespacedName.String())
 	}
 
-	status := cr.Status
+	// Imported backups are read-only: the cluster controller creates them with the status filled in.
+	if cr.Labels[naming.LabelBackupType] == naming.BackupTypeImported {
+		return rr, nil
+	}
+
+	// Status is deep copied, otherwise changes to its slices are made to cr.Status as well
+	// and aren't detected before the update.
+	status := *cr.Status.DeepCopy()
 
 	defer func() {
-		if status.State == cr.Status.State && status.Destination == cr.Status.Destination {
+		if reflect.DeepEqual(status, cr.Status) {
 			return
 		}
 
@@ -102,8 +120,10 @@ func (r *PerconaServerMySQLBackupReconciler) Reconcile(ctx context.Context, req
 				return errors.Wrapf(err, "get %v", req.NamespacedName.String())
 			}
 
+			if cr.Status.State != status.State {
+				log.Info("Updating status", "state", status.State)
+			}
 			cr.Status = status
-			log.Info("Updating status", "state", cr.Status.State)
 			return r.Client.Status().Update(ctx, cr)
 		})
 		if err != nil {
@@ -111,10 +131,39 @@ func (r *PerconaServerMySQLBackupReconciler) Reconcile(ctx context.Context, req
 		}
 	}()
 
-	r.checkFinalizers(ctx, cr)
+	if cancelRequested(cr) {
+		if err := r.cancelBackup(ctx, cr); err != nil {
+			return rr, errors.Wrap(err, "cancel backup")
+		}
+		status.State = apiv1alpha1.BackupCanceled
+		status.StateDesc = "backup is canceled"
+		status.Progress = nil
+		return rr, nil
+	}
+
+	r.checkFinalizers(ctx, cr, &status)
 
 	switch cr.Status.State {
-	case apiv1alpha1.BackupFailed, apiv1alpha1.BackupSucceeded:
+	case apiv1alpha1.BackupFailed, apiv1alpha1.BackupCanceled:
+		return rr, nil
+	case apiv1alpha1.BackupSucceeded:
+		// The sidecar might be unreachable when the job completes, metadata is fetched until it's set.
+		if status.Metadata == nil && !status.IsLogical() {
+			job := &batchv1.Job{}
+			err := r.Client.Get(ctx, xtrabackup.JobNamespacedName(cr), job)
+			if client.IgnoreNotFound(err) != nil {
+				return rr, errors.Wrap(err, "get backup job")
+			}
+			if err == nil {
+				r.updateMetadata(ctx, cr, job, &status)
+			}
+		}
+		if err := r.reconcileVerification(ctx, cr, &status); err != nil {
+			return rr, errors.Wrap(err, "verify backup")
+		}
+		if err := r.reconcileCopies(ctx, cr, &status); err != nil {
+			return rr, errors.Wrap(err, "copy backup")
+		}
 		return rr, nil
 	}
 
@@ -148,6 +197,13 @@ func (r *PerconaServerMySQLBackupReconciler) Reconcile(ctx context.Context, req
 
 	if cluster.Status.MySQL.State != apiv1alpha1.StateReady {
 		log.Info("Cluster is not ready", "cluster", cr.Name)
+		if cr.Status.State == apiv1alpha1.BackupNew && !hooks.Started(status.Hooks, apiv1alpha1.HookStagePreBackup) && startingDeadlineExceeded(cr, cluster) {
+			status.StateDesc = "cluster is not ready"
+			if err := r.failStarting(ctx, cr, cluster, &status); err != nil {
+				return rr, errors.Wrap(err, "fail backup")
+			}
+			return rr, nil
+		}
 		status.State = apiv1alpha1.BackupNew
 		status.StateDesc = "cluster is not ready"
 		return rr, nil
@@ -161,6 +217,85 @@ func (r *PerconaServerMySQLBackupReconciler) Reconcile(ctx context.Context, req
 	}
 
 	if k8serrors.IsNotFound(err) {
+		if cr.Spec.Method == apiv1alpha1.BackupMethodLogical {
+			if cr.Spec.Type == apiv1alpha1.BackupTypeIncremental {
+				status.State = apiv1alpha1.BackupError
+				status.StateDesc = "incremental backups are not supported for logical backups"
+				return rr, nil
+			}
+			if cluster.Spec.Backup.Encryption != nil {
+				status.State = apiv1alpha1.BackupError
+				status.StateDesc = "encryption is not supported for logical backups"
+				return rr, nil
+			}
+		}
+
+		if enc := cluster.Spec.Backup.Encryption; enc != nil {
+			encStatus, err := r.getEncryptionStatus(ctx, cr.Namespace, enc)
+			if err != nil {
+				status.State = apiv1alpha1.BackupError
+				status.StateDesc = err.Error()
+				return rr, nil
+			}
+			status.Encryption = encStatus
+		}
+
+		if cr.Spec.Type == apiv1alpha1.BackupTypeIncremental && storage.Type == apiv1alpha1.BackupStorageFilesystem {
+			status.State = apiv1alpha1.BackupError
+			status.StateDesc = "incremental backups are not supported for filesystem storage"
+			return rr, nil
+		}
+
+		if cr.Spec.Type == apiv1alpha1.BackupTypeIncremental {
+			base, err := r.getIncrementalBase(ctx, cr)
+			if err != nil {
+				status.State = apiv1alpha1.BackupError
+				status.StateDesc = err.Error()
+				return rr, nil
+			}
+			// The whole chain is decrypted with a single key during restore.
+			if keyFingerprint(base.Status.Encryption) != keyFingerprint(status.Encryption) {
+				status.State = apiv1alpha1.BackupError
+				status.StateDesc = fmt.Sprintf("base backup %s is encrypted with another key, full backup is required", base.Name)
+				return rr, nil
+			}
+			status.BaseBackupName = base.Name
+			status.Chain = append(append([]apiv1alpha1.BackupDestination{}, base.Status.Chain...), base.Status.Destination)
+
+			if base.Status.Source == "" {
+				status.State = apiv1alpha1.BackupError
+				status.StateDesc = fmt.Sprintf("source node of base backup %s is unknown, full backup is required", base.Name)
+				return rr, nil
+			}
+			src, err := r.getIncrementalBackupSource(ctx, cluster, base)
+			if err != nil {
+				return rr, errors.Wrap(err, "get incremental backup source node")
+			}
+			if src == "" {
+				status.State = apiv1alpha1.BackupError
+				status.StateDesc = fmt.Sprintf("source node %s of base backup %s is not an online replica, full backup is required", hostPodName(base.Status.Source), base.Name)
+				return rr, nil
+			}
+			status.Source = src
+		}
+
+		if !hooks.Started(status.Hooks, apiv1alpha1.HookStagePreBackup) && startingDeadlineExceeded(cr, cluster) {
+			if err := r.failStarting(ctx, cr, cluster, &status); err != nil {
+				return rr, errors.Wrap(err, "fail backup")
+			}
+			return rr, nil
+		}
+
+		if hks := cluster.Spec.Backup.Hooks; hks != nil && len(hks.PreBackup) > 0 {
+			done, err := r.runHooks(ctx, cr, cluster, apiv1alpha1.HookStagePreBackup, hks.PreBackup, &status)
+			if err != nil {
+				return rr, errors.Wrap(err, "run pre-backup hooks")
+			}
+			if !done || status.State == apiv1alpha1.BackupFailed {
+				return rr, nil
+			}
+		}
+
 		log.Info("Creating backup job", "jobName", nn.Name)
 
 		if err := r.createBackupJob(ctx, cr, cluster, storage, &status); err != nil {
@@ -178,6 +313,11 @@ func (r *PerconaServerMySQLBackupReconciler) Reconcile(ctx context.Context, req
 		switch cond.Type {
 		case batchv1.JobFailed:
 			status.State = apiv1alpha1.BackupFailed
+			if cond.Reason == batchv1.JobReasonDeadlineExceeded {
+				if err := r.stopTimedOut(ctx, cr, job, &status); err != nil {
+					return rr, errors.Wrap(err, "stop timed out backup")
+				}
+			}
 		case batchv1.JobComplete:
 			status.State = apiv1alpha1.BackupSucceeded
 		}
@@ -185,9 +325,39 @@ func (r *PerconaServerMySQLBackupReconciler) Reconcile(ctx context.Context, req
 		status.CompletedAt = job.Status.CompletionTime
 	}
 
+	// Post-backup hooks run whether the job succeeded or not, e.g. to revert changes
+	// made by pre-backup ones. The backup stays running until they finish.
+	if hks := cluster.Spec.Backup.Hooks; hks != nil && len(hks.PostBackup) > 0 &&
+		(status.State == apiv1alpha1.BackupSucceeded || status.State == apiv1alpha1.BackupFailed) {
+		desc := status.StateDesc
+		done, err := r.runHooks(ctx, cr, cluster, apiv1alpha1.HookStagePostBackup, hks.PostBackup, &status)
+		if err != nil {
+			return rr, errors.Wrap(err, "run post-backup hooks")
+		}
+		if !done {
+			status.State = apiv1alpha1.BackupRunning
+			status.CompletedAt = cr.Status.CompletedAt
+			return rr, nil
+		}
+		if status.StateDesc == "" {
+			status.StateDesc = desc
+		}
+	}
+
 	switch status.State {
 	case apiv1alpha1.BackupStarting:
 		if job.Status.Active == 0 {
+			if startingDeadlineExceeded(cr, cluster) {
+				if err := r.failStarting(ctx, cr, cluster, &status); err != nil {
+					return rr, errors.Wrap(err, "fail backup")
+				}
+			}
+			return rr, nil
+		}
+
+		// Logical backups are taken by the job itself, the sidecar isn't involved.
+		if status.IsLogical() {
+			status.State = apiv1alpha1.BackupRunning
 			return rr, nil
 		}
 
@@ -198,12 +368,28 @@ func (r *PerconaServerMySQLBackupReconciler) Reconcile(ctx context.Context, req
 
 		if running {
 			status.State = apiv1alpha1.BackupRunning
+			return rr, nil
+		}
+
+		// The job keeps requesting the backup while another one is running on the source node.
+		if startingDeadlineExceeded(cr, cluster) {
+			if err := r.failStarting(ctx, cr, cluster, &status); err != nil {
+				return rr, errors.Wrap(err, "fail backup")
+			}
 		}
 	case apiv1alpha1.BackupRunning:
 		if job.Status.Active > 0 {
+			if !status.IsLogical() {
+				r.updateProgress(ctx, cr, job, &status)
+			}
 			return rr, nil
 		}
-	case apiv1alpha1.BackupFailed, apiv1alpha1.BackupSucceeded:
+	case apiv1alpha1.BackupSucceeded:
+		if status.Metadata == nil && !status.IsLogical() {
+			r.updateMetadata(ctx, cr, job, &status)
+		}
+		return rr, nil
+	case apiv1alpha1.BackupFailed:
 		return rr, nil
 	default:
 		status.State = apiv1alpha1.BackupStarting
@@ -218,8 +404,27 @@ func (r *PerconaServerMySQLBackupReconciler) isBackupJobRunning(ctx context.Cont
 		return false, nil
 	}
 
-	srcNode := ""
-	destination := ""
+	srcNode, destination := backupJobEnv(job)
+
+	sc := r.NewSidecarClient(srcNode)
+	cfg, err := sc.GetRunningBackupConfig(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "get running backup config")
+	}
+
+	if cfg == nil || cfg.Destination != destination {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// backupJobEnv returns the source node and the destination of the backup job.
+func backupJobEnv(job *batchv1.Job) (srcNode, destination string) {
+	if len(job.Spec.Template.Spec.Containers) == 0 {
+		return "", ""
+	}
+
 	for _, env := range job.Spec.Template.Spec.Containers[0].Env {
 		switch env.Name {
 		case "SRC_NODE":
@@ -228,18 +433,45 @@ func (r *PerconaServerMySQLBackupReconciler) isBackupJobRunning(ctx context.Cont
 			destination = env.Value
 		}
 	}
+	return srcNode, destination
+}
 
-	sc := r.NewSidecarClient(srcNode)
-	cfg, err := sc.GetRunningBackupConfig(ctx)
+// updateProgress sets the number of bytes the sidecar has read and uploaded so far.
+// Failures are only logged, since progress is informational.
+func (r *PerconaServerMySQLBackupReconciler) updateProgress(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup, job *batchv1.Job, status *apiv1alpha1.PerconaServerMySQLBackupStatus) {
+	log := logf.FromContext(ctx)
+
+	srcNode, _ := backupJobEnv(job)
+	progress, err := r.NewSidecarClient(srcNode).GetBackupProgress(ctx, cr.Name)
 	if err != nil {
-		return false, errors.Wrap(err, "get running backup config")
+		log.Error(err, "failed to get backup progress")
+		return
 	}
-
-	if cfg == nil || cfg.Destination != destination {
-		return false, nil
+	if progress == nil {
+		return
+	}
+	if status.Progress != nil &&
+		status.Progress.BytesRead == progress.BytesRead &&
+		status.Progress.BytesUploaded == progress.BytesUploaded {
+		return
 	}
 
-	return true, nil
+	now := metav1.Now()
+	progress.UpdatedAt = &now
+	status.Progress = progress
+}
+
+// updateMetadata copies metadata the sidecar collected after the backup finished.
+func (r *PerconaServerMySQLBackupReconciler) updateMetadata(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup, job *batchv1.Job, status *apiv1alpha1.PerconaServerMySQLBackupStatus) {
+	log := logf.FromContext(ctx)
+
+	srcNode, _ := backupJobEnv(job)
+	metadata, err := r.NewSidecarClient(srcNode).GetBackupMetadata(ctx, cr.Name)
+	if err != nil {
+		log.Error(err, "failed to get backup metadata")
+		return
+	}
+	status.Metadata = metadata
 }
 
 func (r *PerconaServerMySQLBackupReconciler) createBackupJob(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup, cluster *apiv1alpha1.PerconaServerMySQL, storage *apiv1alpha1.BackupStorageSpec, status *apiv1alpha1.PerconaServerMySQLBackupStatus) error {
@@ -248,11 +480,21 @@ func (r *PerconaServerMySQLBackupReconciler) createBackupJob(ctx context.Context
 		return errors.Wrap(err, "get operator image")
 	}
 
-	destination, err := getDestination(storage, cr.Spec.ClusterName, cr.CreationTimestamp.Format("2006-01-02-15:04:05"))
+	destination, err := getDestination(storage, cr)
 	if err != nil {
 		return errors.Wrap(err, "get backup destination")
 	}
-	job := xtrabackup.Job(cluster, cr, destination, initImage, storage)
+	var job *batchv1.Job
+	status.Method = apiv1alpha1.BackupMethodPhysical
+	if cr.Spec.Method == apiv1alpha1.BackupMethodLogical {
+		job, err = xtrabackup.LogicalJob(cluster, cr, destination, initImage, storage)
+		if err != nil {
+			return errors.Wrap(err, "logical backup job")
+		}
+		status.Method = apiv1alpha1.BackupMethodLogical
+	} else {
+		job = xtrabackup.Job(cluster, cr, destination, initImage, storage)
+	}
 
 	switch storage.Type {
 	case apiv1alpha1.BackupStorageS3:
@@ -260,14 +502,8 @@ func (r *PerconaServerMySQLBackupReconciler) createBackupJob(ctx context.Context
 			return errors.New("s3 stanza is required in storage")
 		}
 
-		nn := types.NamespacedName{Name: storage.S3.CredentialsSecret, Namespace: cr.Namespace}
-		exists, err := k8s.ObjectExists(ctx, r.Client, nn, &corev1.Secret{})
-		if err != nil {
-			return errors.Wrapf(err, "check %s exists", nn)
-		}
-
-		if !exists {
-			return errors.Errorf("secret %s not found", nn)
+		if err := r.checkCredentialsSecret(ctx, cr.Namespace, storage.S3.CredentialsSecret); err != nil {
+			return err
 		}
 
 		if err := xtrabackup.SetStorageS3(job, storage.S3); err != nil {
@@ -280,14 +516,8 @@ func (r *PerconaServerMySQLBackupReconciler) createBackupJob(ctx context.Context
 			return errors.New("gcs stanza is required in storage")
 		}
 
-		nn := types.NamespacedName{Name: storage.GCS.CredentialsSecret, Namespace: cr.Namespace}
-		exists, err := k8s.ObjectExists(ctx, r.Client, nn, &corev1.Secret{})
-		if err != nil {
-			return errors.Wrapf(err, "check %s exists", nn)
-		}
-
-		if !exists {
-			return errors.Errorf("secret %s not found", nn)
+		if err := r.checkCredentialsSecret(ctx, cr.Namespace, storage.GCS.CredentialsSecret); err != nil {
+			return err
 		}
 
 		if err := xtrabackup.SetStorageGCS(job, storage.GCS); err != nil {
@@ -300,36 +530,94 @@ func (r *PerconaServerMySQLBackupReconciler) createBackupJob(ctx context.Context
 			return errors.New("azure stanza is required in storage")
 		}
 
-		nn := types.NamespacedName{Name: storage.Azure.CredentialsSecret, Namespace: cr.Namespace}
-		exists, err := k8s.ObjectExists(ctx, r.Client, nn, &corev1.Secret{})
-		if err != nil {
-			return errors.Wrapf(err, "check %s exists", nn)
-		}
-
-		if !exists {
-			return errors.Errorf("secret %s not found", nn)
+		if err := r.checkCredentialsSecret(ctx, cr.Namespace, storage.Azure.CredentialsSecret); err != nil {
+			return err
 		}
 
 		if err := xtrabackup.SetStorageAzure(job, storage.Azure); err != nil {
 			return errors.Wrap(err, "set storage Azure")
 		}
 
+		status.Destination = destination
+	case apiv1alpha1.BackupStorageFilesystem:
+		if storage.Volume == nil || storage.Volume.PersistentVolumeClaim == nil {
+			return errors.New("volumeSpec.persistentVolumeClaim is required in filesystem storage")
+		}
+
+		pvc := xtrabackup.PVC(cluster, cr, storage)
+		err := r.Client.Create(ctx, pvc)
+		if err != nil && !k8serrors.IsAlreadyExists(err) {
+			return errors.Wrapf(err, "create PVC %s/%s", pvc.Namespace, pvc.Name)
+		}
+
+		if err := xtrabackup.SetStoragePVC(job, pvc.Name); err != nil {
+			return errors.Wrap(err, "set storage PVC")
+		}
+
 		status.Destination = destination
 	default:
 		return errors.Errorf("storage type %s is not supported", storage.Type)
 	}
 
+	if enc := status.Encryption; enc != nil {
+		if err := xtrabackup.SetEncryption(job, enc.Cipher, enc.KeySecret); err != nil {
+			return errors.Wrap(err, "set encryption")
+		}
+	}
+
+	if throttle := cluster.Spec.Backup.Throttle; throttle != nil {
+		if err := xtrabackup.SetThrottle(job, throttle); err != nil {
+			return errors.Wrap(err, "set throttle")
+		}
+	}
+
+	if timeouts := cluster.Spec.Backup.Timeouts; timeouts != nil {
+		xtrabackup.SetTimeouts(job, timeouts)
+	}
+
+	if policy := cluster.Spec.Backup.RetryPolicy; policy != nil {
+		if err := xtrabackup.SetRetryPolicy(job, policy); err != nil {
+			return errors.Wrap(err, "set retry policy")
+		}
+	}
+
 	status.Image = cluster.Spec.Backup.Image
 	status.Storage = storage
+	status.Type = apiv1alpha1.BackupTypeFull
 
-	src, err := r.getBackupSource(ctx, cluster)
-	if err != nil {
-		return errors.Wrap(err, "get backup source node")
+	if cr.Spec.Type == apiv1alpha1.BackupTypeIncremental {
+		if len(status.Chain) == 0 {
+			return errors.New("base backup is not set")
+		}
+		if err := xtrabackup.SetIncrementalBase(job, status.Chain[len(status.Chain)-1]); err != nil {
+			return errors.Wrap(err, "set incremental base")
+		}
+		status.Type = apiv1alpha1.BackupTypeIncremental
+	}
+
+	if storage.Type != apiv1alpha1.BackupStorageFilesystem {
+		backupType := string(status.Type)
+		if status.Method == apiv1alpha1.BackupMethodLogical {
+			backupType = string(apiv1alpha1.BackupMethodLogical)
+		}
+		if err := xtrabackup.SetObjectTags(job, xtrabackup.ObjectTags(cluster, storage, backupType)); err != nil {
+			return errors.Wrap(err, "set object tags")
+		}
+	}
+
+	// Incremental backups are taken from the node of their base, it's set in status.Source before.
+	src := status.Source
+	if cr.Spec.Type != apiv1alpha1.BackupTypeIncremental {
+		src, err = r.getBackupSource(ctx, cluster, cluster.Spec.Backup.SourcePolicy)
+		if err != nil {
+			return errors.Wrap(err, "get backup source node")
+		}
 	}
 
 	if err := xtrabackup.SetSourceNode(job, src); err != nil {
 		return errors.Wrap(err, "set backup source node")
 	}
+	status.Source = src
 
 	if err := controllerutil.SetControllerReference(cr, job, r.Scheme); err != nil {
 		return errors.Wrapf(err, "set controller reference to Job %s/%s", job.Namespace, job.Name)
@@ -342,52 +630,252 @@ func (r *PerconaServerMySQLBackupReconciler) createBackupJob(ctx context.Context
 	return nil
 }
 
-func getDestination(storage *apiv1alpha1.BackupStorageSpec, clusterName, creationTimeStamp string) (apiv1alpha1.BackupDestination, error) {
-	backupName := fmt.Sprintf("%s-%s-full", clusterName, creationTimeStamp)
+// reconcileVerification test-restores the succeeded backup if spec.verify is enabled
+// and sets the Verified condition after the verification job finishes.
+func (r *PerconaServerMySQLBackupReconciler) reconcileVerification(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup, status *apiv1alpha1.PerconaServerMySQLBackupStatus) error {
+	log := logf.FromContext(ctx)
+
+	if cr.Spec.Verify == nil || !cr.Spec.Verify.Enabled {
+		return nil
+	}
+	if meta.FindStatusCondition(status.Conditions, apiv1alpha1.BackupConditionVerified) != nil {
+		return nil
+	}
+	if status.IsLogical() {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:    apiv1alpha1.BackupConditionVerified,
+			Status:  metav1.ConditionFalse,
+			Reason:  apiv1alpha1.BackupReasonVerificationFailed,
+			Message: "verification of logical backups is not supported",
+		})
+		return nil
+	}
+
+	job := new(batchv1.Job)
+	nn := types.NamespacedName{Name: xtrabackup.VerifyJobName(cr), Namespace: cr.Namespace}
+	err := r.Client.Get(ctx, nn, job)
+	if client.IgnoreNotFound(err) != nil {
+		return errors.Wrapf(err, "get job %s", nn.Name)
+	}
+
+	if k8serrors.IsNotFound(err) {
+		job, err := r.verifyJob(ctx, cr)
+		if err != nil {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:    apiv1alpha1.BackupConditionVerified,
+				Status:  metav1.ConditionFalse,
+				Reason:  apiv1alpha1.BackupReasonVerificationFailed,
+				Message: err.Error(),
+			})
+			return nil
+		}
+
+		log.Info("Creating backup verification job", "jobName", job.Name)
+		if err := r.Client.Create(ctx, job); err != nil {
+			return errors.Wrapf(err, "create job %s", job.Name)
+		}
+		return nil
+	}
+
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		switch cond.Type {
+		case batchv1.JobComplete:
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:    apiv1alpha1.BackupConditionVerified,
+				Status:  metav1.ConditionTrue,
+				Reason:  apiv1alpha1.BackupReasonVerified,
+				Message: "backup is restored and the verification query succeeded",
+			})
+		case batchv1.JobFailed:
+			msg := fmt.Sprintf("job %s failed: %s", job.Name, cond.Message)
+			if cond.Reason == batchv1.JobReasonPodFailurePolicy {
+				msg = fmt.Sprintf("verification query failed on the restored backup, see logs of job %s", job.Name)
+			}
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:    apiv1alpha1.BackupConditionVerified,
+				Status:  metav1.ConditionFalse,
+				Reason:  apiv1alpha1.BackupReasonVerificationFailed,
+				Message: msg,
+			})
+		}
+	}
+
+	return nil
+}
+
+func (r *PerconaServerMySQLBackupReconciler) verifyJob(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup) (*batchv1.Job, error) {
+	cluster := &apiv1alpha1.PerconaServerMySQL{}
+	nn := types.NamespacedName{Name: cr.Spec.ClusterName, Namespace: cr.Namespace}
+	if err := r.Client.Get(ctx, nn, cluster); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, errors.Errorf("PerconaServerMySQL %s in namespace %s is not found", nn.Name, nn.Namespace)
+		}
+		return nil, errors.Wrapf(err, "get %v", nn.String())
+	}
+	if err := cluster.CheckNSetDefaults(ctx, r.ServerVersion); err != nil {
+		return nil, errors.Wrapf(err, "check and set defaults for %v", nn.String())
+	}
+
+	initImage, err := k8s.InitImage(ctx, r.Client, cluster, cluster.Spec.Backup)
+	if err != nil {
+		return nil, errors.Wrap(err, "get operator image")
+	}
+
+	storage := cr.Status.Storage
+	if storage == nil {
+		return nil, errors.New("backup's status.storage is empty")
+	}
+	job := xtrabackup.VerifyJob(cluster, cr, storage, initImage)
 
-	var d apiv1alpha1.BackupDestination
 	switch storage.Type {
 	case apiv1alpha1.BackupStorageS3:
-		bucket, prefix := storage.S3.BucketAndPrefix()
-		d.SetS3Destination(path.Join(bucket, prefix), backupName)
+		err = xtrabackup.SetStorageS3(job, storage.S3)
 	case apiv1alpha1.BackupStorageGCS:
-		bucket, prefix := storage.GCS.BucketAndPrefix()
-		d.SetGCSDestination(path.Join(bucket, prefix), backupName)
+		err = xtrabackup.SetStorageGCS(job, storage.GCS)
 	case apiv1alpha1.BackupStorageAzure:
-		container, prefix := storage.Azure.ContainerAndPrefix()
-		d.SetAzureDestination(path.Join(container, prefix), backupName)
+		err = xtrabackup.SetStorageAzure(job, storage.Azure)
+	case apiv1alpha1.BackupStorageFilesystem:
+		pvcName, _ := cr.Status.Destination.BucketAndPrefix()
+		err = xtrabackup.SetStoragePVC(job, pvcName)
 	default:
-		return d, errors.Errorf("storage type %s is not supported", storage.Type)
+		err = errors.Errorf("storage type %s is not supported", storage.Type)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "set storage")
 	}
 
-	return d, nil
+	if cr.Status.IsIncremental() {
+		if err := xtrabackup.SetBackupChain(job, cr.Status.Chain); err != nil {
+			return nil, errors.Wrap(err, "set backup chain")
+		}
+	}
+	if enc := cr.Status.Encryption; enc != nil {
+		if err := xtrabackup.SetEncryption(job, enc.Cipher, enc.KeySecret); err != nil {
+			return nil, errors.Wrap(err, "set encryption")
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(cr, job, r.Scheme); err != nil {
+		return nil, errors.Wrapf(err, "set controller reference to Job %s/%s", job.Namespace, job.Name)
+	}
+
+	return job, nil
 }
 
-func (r *PerconaServerMySQLBackupReconciler) getBackupSource(ctx context.Context, cluster *apiv1alpha1.PerconaServerMySQL) (string, error) {
-	log := logf.FromContext(ctx).WithName("getBackupSource")
+// getEncryptionStatus reads the encryption key and validates it against the cipher.
+func (r *PerconaServerMySQLBackupReconciler) getEncryptionStatus(ctx context.Context, namespace string, enc *apiv1alpha1.BackupEncryptionSpec) (*apiv1alpha1.BackupEncryptionStatus, error) {
+	s := new(corev1.Secret)
+	nn := types.NamespacedName{Name: enc.KeySecret.Name, Namespace: namespace}
+	if err := r.Client.Get(ctx, nn, s); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, errors.Errorf("encryption key secret %s is not found", nn.Name)
+		}
+		return nil, errors.Wrapf(err, "get secret %s", nn.Name)
+	}
 
-	operatorPass, err := k8s.UserPassword(ctx, r.Client, cluster, apiv1alpha1.UserOperator)
-	if err != nil {
-		return "", errors.Wrap(err, "get operator password")
+	key, ok := s.Data[enc.KeySecret.Key]
+	if !ok {
+		return nil, errors.Errorf("secret %s has no %s key", nn.Name, enc.KeySecret.Key)
+	}
+	if len(key) != enc.Cipher.KeyLength() {
+		return nil, errors.Errorf("%s requires %d bytes long encryption key, got %d", enc.Cipher, enc.Cipher.KeyLength(), len(key))
 	}
 
-	top, err := getDBTopology(ctx, r.Client, r.ClientCmd, cluster, operatorPass)
-	if err != nil {
-		return "", errors.Wrap(err, "get topology")
+	return &apiv1alpha1.BackupEncryptionStatus{
+		Cipher:         enc.Cipher,
+		KeySecret:      enc.KeySecret.DeepCopy(),
+		KeyFingerprint: xtrabackup.EncryptionKeyFingerprint(key),
+	}, nil
+}
+
+func keyFingerprint(enc *apiv1alpha1.BackupEncryptionStatus) string {
+	if enc == nil {
+		return ""
 	}
+	return enc.KeyFingerprint
+}
 
-	var source string
-	if len(top.replicas) < 1 {
-		source = top.primary
-		log.Info("no replicas found, using primary as the backup source", "primary", top.primary)
-	} else {
-		source = top.replicas[0]
+// getIncrementalBase returns the backup an incremental backup is taken on top of.
+func (r *PerconaServerMySQLBackupReconciler) getIncrementalBase(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup) (*apiv1alpha1.PerconaServerMySQLBackup, error) {
+	if cr.Spec.BaseBackupName != "" {
+		base := new(apiv1alpha1.PerconaServerMySQLBackup)
+		nn := types.NamespacedName{Name: cr.Spec.BaseBackupName, Namespace: cr.Namespace}
+		if err := r.Client.Get(ctx, nn, base); err != nil {
+			if k8serrors.IsNotFound(err) {
+				return nil, errors.Errorf("base backup %s is not found", nn.Name)
+			}
+			return nil, errors.Wrapf(err, "get base backup %s", nn.Name)
+		}
+
+		switch {
+		case base.Status.State != apiv1alpha1.BackupSucceeded:
+			return nil, errors.Errorf("base backup %s is not succeeded", base.Name)
+		case base.Spec.ClusterName != cr.Spec.ClusterName:
+			return nil, errors.Errorf("base backup %s belongs to another cluster", base.Name)
+		case base.Spec.StorageName != cr.Spec.StorageName:
+			return nil, errors.Errorf("base backup %s is stored on another storage", base.Name)
+		case base.Status.IsLogical():
+			return nil, errors.Errorf("base backup %s is a logical backup, incremental backups require a physical one", base.Name)
+		}
+
+		return base, nil
 	}
 
-	return source, nil
+	bcpList := new(apiv1alpha1.PerconaServerMySQLBackupList)
+	if err := r.Client.List(ctx, bcpList, &client.ListOptions{Namespace: cr.Namespace}); err != nil {
+		return nil, errors.Wrap(err, "list backups")
+	}
+
+	var base *apiv1alpha1.PerconaServerMySQLBackup
+	for i := range bcpList.Items {
+		bcp := &bcpList.Items[i]
+		if bcp.Spec.ClusterName != cr.Spec.ClusterName ||
+			bcp.Spec.StorageName != cr.Spec.StorageName ||
+			bcp.Status.State != apiv1alpha1.BackupSucceeded ||
+			bcp.Status.IsIncremental() ||
+			bcp.Status.IsLogical() ||
+			bcp.Status.CompletedAt == nil {
+			continue
+		}
+		if base == nil || base.Status.CompletedAt.Before(bcp.Status.CompletedAt) {
+			base = bcp
+		}
+	}
+	if base == nil {
+		return nil, errors.Errorf("no successful full backup found on storage %s", cr.Spec.StorageName)
+	}
+
+	return base, nil
 }
 
-func (r *PerconaServerMySQLBackupReconciler) checkFinalizers(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup) {
+func getDestination(storage *apiv1alpha1.BackupStorageSpec, cr *apiv1alpha1.PerconaServerMySQLBackup) (apiv1alpha1.BackupDestination, error) {
+	backupName := fmt.Sprintf("%s-%s-full", cr.Spec.ClusterName, cr.CreationTimestamp.Format("2006-01-02-15:04:05"))
+
+	var d apiv1alpha1.BackupDestination
+	switch storage.Type {
+	case apiv1alpha1.BackupStorageS3:
+		bucket, prefix := storage.S3.BucketAndPrefix()
+		d.SetS3Destination(path.Join(bucket, prefix), backupName)
+	case apiv1alpha1.BackupStorageGCS:
+		bucket, prefix := storage.GCS.BucketAndPrefix()
+		d.SetGCSDestination(path.Join(bucket, prefix), backupName)
+	case apiv1alpha1.BackupStorageAzure:
+		container, prefix := storage.Azure.ContainerAndPrefix()
+		d.SetAzureDestination(path.Join(container, prefix), backupName)
+	case apiv1alpha1.BackupStorageFilesystem:
+		d.SetPVCDestination(xtrabackup.PVCName(cr), backupName)
+	default:
+		return d, errors.Errorf("storage type %s is not supported", storage.Type)
+	}
+
+	return d, nil
+}
+
+func (r *PerconaServerMySQLBackupReconciler) checkFinalizers(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup, status *apiv1alpha1.PerconaServerMySQLBackupStatus) {
 	if cr.DeletionTimestamp == nil || cr.Status.State == apiv1alpha1.BackupStarting || cr.Status.State == apiv1alpha1.BackupRunning {
 		return
 	}
@@ -411,7 +899,7 @@ func (r *PerconaServerMySQLBackupReconciler) checkFinalizers(ctx context.Context
 		switch finalizer {
 		case naming.FinalizerDeleteBackup:
 			var ok bool
-			ok, err = r.deleteBackup(ctx, cr)
+			ok, err = r.deleteBackup(ctx, cr, status)
 			if !ok {
 				finalizers.Insert(finalizer)
 			}
@@ -426,6 +914,25 @@ func (r *PerconaServerMySQLBackupReconciler) checkFinalizers(ctx context.Context
 	cr.Finalizers = finalizers.List()
 }
 
+// checkCredentialsSecret returns an error if the storage credentials secret doesn't exist.
+// Storages without the secret are accessed with the identity of the pod.
+func (r *PerconaServerMySQLBackupReconciler) checkCredentialsSecret(ctx context.Context, namespace, name string) error {
+	if name == "" {
+		return nil
+	}
+
+	nn := types.NamespacedName{Name: name, Namespace: namespace}
+	exists, err := k8s.ObjectExists(ctx, r.Client, nn, &corev1.Secret{})
+	if err != nil {
+		return errors.Wrapf(err, "check %s exists", nn)
+	}
+
+	if !exists {
+		return errors.Errorf("secret %s not found", nn)
+	}
+	return nil
+}
+
 func (r *PerconaServerMySQLBackupReconciler) backupConfig(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup) (*xtrabackup.BackupConfig, error) {
 	storage := cr.Status.Storage
 	if storage == nil {
@@ -435,7 +942,7 @@ func (r *PerconaServerMySQLBackupReconciler) backupConfig(ctx context.Context, c
 	if storage.VerifyTLS != nil {
 		verifyTLS = *storage.VerifyTLS
 	}
-	destination, err := getDestination(storage, cr.Spec.ClusterName, cr.CreationTimestamp.Format("2006-01-02-15:04:05"))
+	destination, err := getDestination(storage, cr)
 	if err != nil {
 		return nil, errors.Wrap(err, "get backup destination")
 	}
@@ -443,74 +950,125 @@ func (r *PerconaServerMySQLBackupReconciler) backupConfig(ctx context.Context, c
 		Destination: destination.PathWithoutBucket(),
 		VerifyTLS:   verifyTLS,
 	}
-	s := new(corev1.Secret)
-	nn := types.NamespacedName{
-		Namespace: cr.Namespace,
-	}
-	switch storage.Type {
-	case apiv1alpha1.BackupStorageS3:
-		s3 := storage.S3
-		nn.Name = s3.CredentialsSecret
+	// Without the credentials secret the storage is accessed with the identity of the pod.
+	getSecret := func(name string) (*corev1.Secret, error) {
+		s := new(corev1.Secret)
+		if name == "" {
+			return s, nil
+		}
+		nn := types.NamespacedName{Name: name, Namespace: cr.Namespace}
 		if err := r.Get(ctx, nn, s); err != nil {
 			return nil, errors.Wrapf(err, "get secret/%s", nn.Name)
 		}
-		accessKey, ok := s.Data[secret.CredentialsAWSAccessKey]
-		if !ok {
-			return nil, errors.Errorf("no credentials for S3 in secret %s", nn.Name)
+		return s, nil
+	}
+	getSecretKey := func(sel *corev1.SecretKeySelector) (string, error) {
+		if sel == nil {
+			return "", nil
 		}
-		secretKey, ok := s.Data[secret.CredentialsAWSSecretKey]
+		s, err := getSecret(sel.Name)
+		if err != nil {
+			return "", err
+		}
+		value, ok := s.Data[sel.Key]
 		if !ok {
-			return nil, errors.Errorf("no credentials for S3 in secret %s", nn.Name)
+			return "", errors.Errorf("no key %s in secret %s", sel.Key, sel.Name)
+		}
+		return string(value), nil
+	}
+	switch storage.Type {
+	case apiv1alpha1.BackupStorageS3:
+		s3 := storage.S3
+		s, err := getSecret(s3.CredentialsSecret)
+		if err != nil {
+			return nil, err
+		}
+		if s3.CredentialsSecret != "" {
+			accessKey, ok := s.Data[secret.CredentialsAWSAccessKey]
+			if !ok {
+				return nil, errors.Errorf("no credentials for S3 in secret %s", s3.CredentialsSecret)
+			}
+			secretKey, ok := s.Data[secret.CredentialsAWSSecretKey]
+			if !ok {
+				return nil, errors.Errorf("no credentials for S3 in secret %s", s3.CredentialsSecret)
+			}
+			conf.S3.AccessKey = string(accessKey)
+			conf.S3.SecretKey = string(secretKey)
 		}
 		bucket, _ := s3.BucketAndPrefix()
 		conf.S3.Bucket = bucket
 		conf.S3.Region = s3.Region
 		conf.S3.EndpointURL = s3.EndpointURL
 		conf.S3.StorageClass = s3.StorageClass
-		conf.S3.AccessKey = string(accessKey)
-		conf.S3.SecretKey = string(secretKey)
+		conf.S3.RoleARN = s3.RoleARN
+		conf.S3.WebIdentityTokenFile = s3.WebIdentityTokenFile
+		conf.S3.STSEndpoint = s3.STSEndpoint
+		if sse := s3.ServerSideEncryption; sse != nil {
+			// Objects encrypted with the customer key can't be read without it.
+			key, err := getSecretKey(sse.SSECustomerKeySecret)
+			if err != nil {
+				return nil, errors.Wrap(err, "get SSE customer key")
+			}
+			conf.S3.SSEAlgorithm = sse.SSEAlgorithm
+			conf.S3.KMSKeyID = sse.KMSKeyID
+			conf.S3.SSECustomerKey = key
+		}
 		conf.Type = apiv1alpha1.BackupStorageS3
 	case apiv1alpha1.BackupStorageGCS:
 		gcs := storage.GCS
-		nn.Name = gcs.CredentialsSecret
-		if err := r.Get(ctx, nn, s); err != nil {
-			return nil, errors.Wrapf(err, "get secret/%s", nn.Name)
-		}
-		accessKey, ok := s.Data[secret.CredentialsGCSAccessKey]
-		if !ok {
-			return nil, errors.Errorf("no credentials for GCS in secret %s", nn.Name)
+		s, err := getSecret(gcs.CredentialsSecret)
+		if err != nil {
+			return nil, err
 		}
-		secretKey, ok := s.Data[secret.CredentialsGCSSecretKey]
-		if !ok {
-			return nil, errors.Errorf("no credentials for GCS in secret %s", nn.Name)
+		if gcs.CredentialsSecret != "" {
+			accessKey, hasAccessKey := s.Data[secret.CredentialsGCSAccessKey]
+			secretKey, hasSecretKey := s.Data[secret.CredentialsGCSSecretKey]
+			serviceAccountKey, hasServiceAccountKey := s.Data[secret.CredentialsGCSServiceAccount]
+			if !hasServiceAccountKey && (!hasAccessKey || !hasSecretKey) {
+				return nil, errors.Errorf("no credentials for GCS in secret %s", gcs.CredentialsSecret)
+			}
+			conf.GCS.AccessKey = string(accessKey)
+			conf.GCS.SecretKey = string(secretKey)
+			conf.GCS.ServiceAccountKey = string(serviceAccountKey)
 		}
 		bucket, _ := gcs.BucketAndPrefix()
 		conf.GCS.Bucket = bucket
 		conf.GCS.EndpointURL = gcs.EndpointURL
 		conf.GCS.StorageClass = gcs.StorageClass
-		conf.GCS.AccessKey = string(accessKey)
-		conf.GCS.SecretKey = string(secretKey)
+		conf.GCS.KMSKeyName = gcs.KMSKeyName
 		conf.Type = apiv1alpha1.BackupStorageGCS
 	case apiv1alpha1.BackupStorageAzure:
 		azure := storage.Azure
-		nn.Name = azure.CredentialsSecret
-		if err := r.Get(ctx, nn, s); err != nil {
-			return nil, errors.Wrapf(err, "get secret/%s", nn.Name)
+		s, err := getSecret(azure.CredentialsSecret)
+		if err != nil {
+			return nil, err
 		}
 		storageAccount, ok := s.Data[secret.CredentialsAzureStorageAccount]
 		if !ok {
-			return nil, errors.Errorf("no credentials for Azure in secret %s", nn.Name)
+			storageAccount = []byte(azure.StorageAccount)
 		}
-		accessKey, ok := s.Data[secret.CredentialsAzureAccessKey]
-		if !ok {
-			return nil, errors.Errorf("no credentials for Azure in secret %s", nn.Name)
+		if len(storageAccount) == 0 {
+			return nil, errors.New("no storage account for Azure")
+		}
+		if azure.CredentialsSecret != "" {
+			accessKey, hasAccessKey := s.Data[secret.CredentialsAzureAccessKey]
+			sasToken, hasSASToken := s.Data[secret.CredentialsAzureSASToken]
+			if !hasAccessKey && !hasSASToken {
+				return nil, errors.Errorf("no credentials for Azure in secret %s", azure.CredentialsSecret)
+			}
+			conf.Azure.AccessKey = string(accessKey)
+			conf.Azure.SASToken = string(sasToken)
 		}
 		container, _ := azure.ContainerAndPrefix()
 		conf.Azure.ContainerName = container
 		conf.Azure.EndpointURL = azure.EndpointURL
 		conf.Azure.StorageClass = azure.StorageClass
 		conf.Azure.StorageAccount = string(storageAccount)
-		conf.Azure.AccessKey = string(accessKey)
+		conf.Azure.EncryptionScope = azure.EncryptionScope
+		conf.Azure.CustomerKey, err = getSecretKey(azure.CustomerKeySecret)
+		if err != nil {
+			return nil, errors.Wrap(err, "get Azure customer key")
+		}
 		conf.Type = apiv1alpha1.BackupStorageAzure
 	default:
 		return nil, errors.New("unknown backup storage type")
@@ -518,11 +1076,29 @@ func (r *PerconaServerMySQLBackupReconciler) backupConfig(ctx context.Context, c
 	return conf, nil
 }
 
-func (r *PerconaServerMySQLBackupReconciler) deleteBackup(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup) (bool, error) {
+func (r *PerconaServerMySQLBackupReconciler) deleteBackup(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup, status *apiv1alpha1.PerconaServerMySQLBackupStatus) (bool, error) {
 	if cr.Status.State != apiv1alpha1.BackupSucceeded {
 		return true, nil
 	}
 
+	// Incremental backups can't be restored without the backups in their chain.
+	dependents, err := r.dependentBackups(ctx, cr)
+	if err != nil {
+		return false, errors.Wrap(err, "get dependent backups")
+	}
+	if len(dependents) > 0 {
+		status.StateDesc = fmt.Sprintf("backup is kept until incremental backups depending on it are deleted: %s", strings.Join(dependents, ", "))
+		return false, nil
+	}
+
+	if err := r.deleteCopies(ctx, cr); err != nil {
+		return false, errors.Wrap(err, "delete backup copies")
+	}
+
+	if cr.Status.Storage != nil && cr.Status.Storage.Type == apiv1alpha1.BackupStorageFilesystem {
+		return r.deleteBackupPVC(ctx, cr)
+	}
+
 	backupConf, err := r.backupConfig(ctx, cr)
 	if err != nil {
 		return false, errors.Wrap(err, "failed to create sidecar backup config")
@@ -568,7 +1144,8 @@ func (r *PerconaServerMySQLBackupReconciler) deleteBackup(ctx context.Context, c
 		}
 		return complete, nil
 	}
-	src, err := r.getBackupSource(ctx, cluster)
+	// Any node can delete the backup, the source policy doesn't apply.
+	src, err := r.getBackupSource(ctx, cluster, nil)
 	if err != nil {
 		return false, errors.Wrap(err, "get backup source node")
 	}
@@ -578,3 +1155,51 @@ func (r *PerconaServerMySQLBackupReconciler) deleteBackup(ctx context.Context, c
 	}
 	return true, nil
 }
+
+// dependentBackups returns names of the backups of the cluster that have the backup in their chain.
+func (r *PerconaServerMySQLBackupReconciler) dependentBackups(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup) ([]string, error) {
+	if cr.Status.Destination == "" {
+		return nil, nil
+	}
+
+	bcpList := new(apiv1alpha1.PerconaServerMySQLBackupList)
+	if err := r.Client.List(ctx, bcpList, &client.ListOptions{Namespace: cr.Namespace}); err != nil {
+		return nil, errors.Wrap(err, "list backups")
+	}
+
+	var names []string
+	for _, bcp := range bcpList.Items {
+		if bcp.Name == cr.Name || bcp.Spec.ClusterName != cr.Spec.ClusterName {
+			continue
+		}
+		for _, dest := range bcp.Status.Chain {
+			if dest == cr.Status.Destination {
+				names = append(names, bcp.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// deleteBackupPVC removes the PVC the backup is stored on. Each filesystem backup has its own PVC.
+func (r *PerconaServerMySQLBackupReconciler) deleteBackupPVC(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup) (bool, error) {
+	bucket, _ := cr.Status.Destination.BucketAndPrefix()
+	if bucket == "" {
+		bucket = xtrabackup.PVCName(cr)
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bucket,
+			Namespace: cr.Namespace,
+		},
+	}
+	if err := r.Client.Delete(ctx, pvc); err != nil && !k8serrors.IsNotFound(err) {
+		return false, errors.Wrapf(err, "delete PVC %s", bucket)
+	}
+
+	return true, nil
+}
@@ -3,27 +3,36 @@ package psbackup
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	restclient "k8s.io/client-go/rest"
 	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/hooks"
 	"github.com/percona/percona-server-mysql-operator/pkg/naming"
 	"github.com/percona/percona-server-mysql-operator/pkg/platform"
 	"github.com/percona/percona-server-mysql-operator/pkg/secret"
 	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
+	fakestorage "github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage/fake"
 )
 
 func TestBackupStatusErrStateDesc(t *testing.T) {
@@ -82,6 +91,30 @@ func TestBackupStatusErrStateDesc(t *testing.T) {
 			),
 			stateDesc: fmt.Sprintf("%s not found in spec.backup.storages in PerconaServerMySQL CustomResource", cr.Spec.StorageName),
 		},
+		{
+			name: "incremental on filesystem storage",
+			cr: updateResource(
+				cr.DeepCopy(),
+				func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+					cr.Spec.Type = apiv1alpha1.BackupTypeIncremental
+				},
+			),
+			cluster: updateResource(
+				cluster.DeepCopy(),
+				func(cluster *apiv1alpha1.PerconaServerMySQL) {
+					cluster.Namespace = namespace
+					cluster.Spec.Backup = &apiv1alpha1.BackupSpec{
+						Image:   "some-image",
+						Enabled: true,
+						Storages: map[string]*apiv1alpha1.BackupStorageSpec{
+							cr.Spec.StorageName: {Type: apiv1alpha1.BackupStorageFilesystem},
+						},
+					}
+					cluster.Status.MySQL.State = apiv1alpha1.StateReady
+				},
+			),
+			stateDesc: "incremental backups are not supported for filesystem storage",
+		},
 	}
 
 	scheme := runtime.NewScheme()
@@ -297,7 +330,7 @@ func TestCheckFinalizers(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			r.checkFinalizers(ctx, cr)
+			r.checkFinalizers(ctx, cr, &cr.Status)
 			if !reflect.DeepEqual(cr.Finalizers, tt.expectedFinalizers) {
 				t.Fatalf("expected finalizers %v, got %v", tt.expectedFinalizers, tt.cr.Finalizers)
 			}
@@ -305,6 +338,128 @@ func TestCheckFinalizers(t *testing.T) {
 	}
 }
 
+func TestCheckFinalizersFilesystem(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add client-go scheme")
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add apis scheme")
+	}
+	namespace := "some-namespace"
+	cr, err := readDefaultCRBackup("some-name", namespace)
+	if err != nil {
+		t.Fatal(err, "failed to read default backup")
+	}
+	cr.Finalizers = []string{naming.FinalizerDeleteBackup}
+	cr.Status.State = apiv1alpha1.BackupSucceeded
+	cr.Status.Storage = &apiv1alpha1.BackupStorageSpec{
+		Type: apiv1alpha1.BackupStorageFilesystem,
+	}
+	cr.Status.Destination.SetPVCDestination(xtrabackup.PVCName(cr), "backup")
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      xtrabackup.PVCName(cr),
+			Namespace: namespace,
+		},
+	}
+
+	cb := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr, pvc)
+	r := PerconaServerMySQLBackupReconciler{
+		Client:        cb.Build(),
+		Scheme:        scheme,
+		ServerVersion: &platform.ServerVersion{Platform: platform.PlatformKubernetes},
+	}
+	if err := r.Delete(ctx, cr); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, cr); err != nil {
+		t.Fatal(err)
+	}
+
+	r.checkFinalizers(ctx, cr, &cr.Status)
+	if len(cr.Finalizers) != 0 {
+		t.Fatalf("expected no finalizers, got %v", cr.Finalizers)
+	}
+
+	err = r.Get(ctx, types.NamespacedName{Name: pvc.Name, Namespace: pvc.Namespace}, new(corev1.PersistentVolumeClaim))
+	if !k8serrors.IsNotFound(err) {
+		t.Fatalf("expected PVC %s to be deleted, got %v", pvc.Name, err)
+	}
+}
+
+func TestCheckFinalizersIncrementalChain(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add client-go scheme")
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add apis scheme")
+	}
+	namespace := "some-namespace"
+	cr, err := readDefaultCRBackup("full", namespace)
+	if err != nil {
+		t.Fatal(err, "failed to read default backup")
+	}
+	cr.Finalizers = []string{naming.FinalizerDeleteBackup}
+	cr.Status.State = apiv1alpha1.BackupSucceeded
+	cr.Status.Storage = &apiv1alpha1.BackupStorageSpec{
+		Type: apiv1alpha1.BackupStorageS3,
+		S3: &apiv1alpha1.BackupStorageS3Spec{
+			Bucket: "some-bucket",
+		},
+	}
+	cr.Status.Destination.SetS3Destination("some-bucket", "cluster1-full")
+
+	inc := cr.DeepCopy()
+	inc.Name = "incremental"
+	inc.Finalizers = nil
+	inc.Status.Type = apiv1alpha1.BackupTypeIncremental
+	inc.Status.Destination.SetS3Destination("some-bucket", "cluster1-inc")
+	inc.Status.Chain = []apiv1alpha1.BackupDestination{cr.Status.Destination}
+
+	job := xtrabackup.GetDeleteJob(cr, new(xtrabackup.BackupConfig))
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr, inc, job).Build()
+	r := PerconaServerMySQLBackupReconciler{
+		Client:        cl,
+		Scheme:        scheme,
+		ServerVersion: &platform.ServerVersion{Platform: platform.PlatformKubernetes},
+	}
+	if err := r.Delete(ctx, cr); err != nil {
+		t.Fatal(err)
+	}
+	nn := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
+	if err := r.Get(ctx, nn, cr); err != nil {
+		t.Fatal(err)
+	}
+
+	status := cr.Status.DeepCopy()
+	r.checkFinalizers(ctx, cr, status)
+	if !reflect.DeepEqual(cr.Finalizers, []string{naming.FinalizerDeleteBackup}) {
+		t.Fatalf("expected finalizer to be kept while incremental backup exists, got %v", cr.Finalizers)
+	}
+	expectedDesc := "backup is kept until incremental backups depending on it are deleted: incremental"
+	if status.StateDesc != expectedDesc {
+		t.Fatalf("expected state description %q, got %q", expectedDesc, status.StateDesc)
+	}
+
+	if err := r.Delete(ctx, inc); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, nn, cr); err != nil {
+		t.Fatal(err)
+	}
+	r.checkFinalizers(ctx, cr, cr.Status.DeepCopy())
+	if len(cr.Finalizers) != 0 {
+		t.Fatalf("expected no finalizers, got %v", cr.Finalizers)
+	}
+}
+
 func TestRunningState(t *testing.T) {
 	ctx := context.Background()
 	scheme := runtime.NewScheme()
@@ -403,60 +558,1640 @@ func TestRunningState(t *testing.T) {
 	}
 }
 
-type fakeSidecarClient struct {
-	destination string
-}
+func TestProgressAndMetadata(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add client-go scheme")
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add apis scheme")
+	}
+	namespace := "some-namespace"
 
-func (f *fakeSidecarClient) GetRunningBackupConfig(ctx context.Context) (*xtrabackup.BackupConfig, error) {
-	if f.destination == "" {
-		return nil, nil
+	cr, err := readDefaultCRBackup("some-name", namespace)
+	if err != nil {
+		t.Fatal(err, "failed to read default backup")
+	}
+	cr.Status.State = apiv1alpha1.BackupRunning
+	cr.Spec.StorageName = "s3-us-west"
+	cluster, err := readDefaultCR("cluster1", namespace)
+	if err != nil {
+		t.Fatal(err, "failed to read default cr")
+	}
+	cluster.Status.MySQL.State = apiv1alpha1.StateReady
+	storage, ok := cluster.Spec.Backup.Storages["s3-us-west"]
+	if !ok {
+		t.Fatal("storage not found")
 	}
-	return &xtrabackup.BackupConfig{
-		Destination: f.destination,
-	}, nil
-}
 
-func (f *fakeSidecarClient) DeleteBackup(ctx context.Context, name string, cfg xtrabackup.BackupConfig) error {
-	return nil
+	sidecarClient := &fakeSidecarClient{
+		destination: "container",
+		progress:    &apiv1alpha1.BackupProgress{BytesRead: 2048, BytesUploaded: 1024},
+		metadata: &apiv1alpha1.BackupMetadata{
+			SizeBytes:    2048,
+			MySQLVersion: "8.0.36-28",
+			BinlogFile:   "binlog.000002",
+			ToLSN:        "20180335",
+		},
+	}
+
+	reconcileBackup := func(bcp *apiv1alpha1.PerconaServerMySQLBackup, job *batchv1.Job) *apiv1alpha1.PerconaServerMySQLBackup {
+		t.Helper()
+
+		cb := fake.NewClientBuilder().WithScheme(scheme).WithObjects(bcp, cluster.DeepCopy(), job).WithStatusSubresource(bcp, job)
+		r := PerconaServerMySQLBackupReconciler{
+			Client:        cb.Build(),
+			Scheme:        scheme,
+			ServerVersion: &platform.ServerVersion{Platform: platform.PlatformKubernetes},
+			NewSidecarClient: func(srcNode string) xtrabackup.SidecarClient {
+				return sidecarClient
+			},
+		}
+		nn := types.NamespacedName{Name: bcp.Name, Namespace: bcp.Namespace}
+		if _, err := r.Reconcile(ctx, controllerruntime.Request{NamespacedName: nn}); err != nil {
+			t.Fatal(err, "failed to reconcile")
+		}
+		if err := r.Get(ctx, nn, bcp); err != nil {
+			t.Fatal(err, "failed to get backup")
+		}
+		return bcp
+	}
+	reconcile := func(job *batchv1.Job) *apiv1alpha1.PerconaServerMySQLBackup {
+		t.Helper()
+		return reconcileBackup(cr.DeepCopy(), job)
+	}
+
+	t.Run("running", func(t *testing.T) {
+		job := xtrabackup.Job(cluster, cr, "s3://bucket/container", "init-image", storage)
+		job.Status.Active = 1
+
+		bcp := reconcile(job)
+		if bcp.Status.Progress == nil {
+			t.Fatal("expected progress to be set")
+		}
+		if bcp.Status.Progress.BytesRead != 2048 || bcp.Status.Progress.BytesUploaded != 1024 {
+			t.Fatalf("unexpected progress %+v", bcp.Status.Progress)
+		}
+		if bcp.Status.Progress.UpdatedAt == nil {
+			t.Fatal("expected progress update time to be set")
+		}
+		if bcp.Status.Metadata != nil {
+			t.Fatal("expected metadata to be empty while backup is running")
+		}
+	})
+
+	t.Run("succeeded", func(t *testing.T) {
+		job := xtrabackup.Job(cluster, cr, "s3://bucket/container", "init-image", storage)
+		job.Status.Conditions = []batchv1.JobCondition{
+			{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+		}
+
+		bcp := reconcile(job)
+		if bcp.Status.State != apiv1alpha1.BackupSucceeded {
+			t.Fatalf("expected state %s, got %s", apiv1alpha1.BackupSucceeded, bcp.Status.State)
+		}
+		if !reflect.DeepEqual(bcp.Status.Metadata, sidecarClient.metadata) {
+			t.Fatalf("expected metadata %+v, got %+v", sidecarClient.metadata, bcp.Status.Metadata)
+		}
+	})
+
+	t.Run("metadata of succeeded backup", func(t *testing.T) {
+		job := xtrabackup.Job(cluster, cr, "s3://bucket/container", "init-image", storage)
+		job.Status.Conditions = []batchv1.JobCondition{
+			{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+		}
+
+		succeeded := cr.DeepCopy()
+		succeeded.Status.State = apiv1alpha1.BackupSucceeded
+		bcp := reconcileBackup(succeeded, job)
+		if !reflect.DeepEqual(bcp.Status.Metadata, sidecarClient.metadata) {
+			t.Fatalf("expected metadata %+v, got %+v", sidecarClient.metadata, bcp.Status.Metadata)
+		}
+	})
 }
 
-func readDefaultCR(name, namespace string) (*apiv1alpha1.PerconaServerMySQL, error) {
-	data, err := os.ReadFile(filepath.Join("..", "..", "..", "deploy", "cr.yaml"))
+func TestBackupHooks(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add client-go scheme")
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add apis scheme")
+	}
+	namespace := "some-namespace"
+
+	cr, err := readDefaultCRBackup("some-name", namespace)
 	if err != nil {
-		return nil, err
+		t.Fatal(err, "failed to read default backup")
 	}
+	cr.Spec.StorageName = "s3-us-west"
+	cluster, err := readDefaultCR("cluster1", namespace)
+	if err != nil {
+		t.Fatal(err, "failed to read default cr")
+	}
+	cluster.Status.MySQL.State = apiv1alpha1.StateReady
+	cluster.Spec.Backup.Hooks = &apiv1alpha1.BackupHooks{
+		PreBackup:  []apiv1alpha1.Hook{{Name: "prepare", Job: &apiv1alpha1.HookJobSpec{Image: "hook-image"}}},
+		PostBackup: []apiv1alpha1.Hook{{Name: "cleanup", Job: &apiv1alpha1.HookJobSpec{Image: "hook-image"}}},
+	}
+	storage := cluster.Spec.Backup.Storages["s3-us-west"]
 
-	cr := &apiv1alpha1.PerconaServerMySQL{}
+	hookJob := func(stage apiv1alpha1.HookStage, hook *apiv1alpha1.Hook, cond batchv1.JobConditionType) *batchv1.Job {
+		job := xtrabackup.HookJob(cluster, cr.Name, stage, hook, nil)
+		if cond != "" {
+			job.Status.Conditions = []batchv1.JobCondition{{Type: cond, Status: corev1.ConditionTrue}}
+		} else {
+			job.Status.Active = 1
+		}
+		return job
+	}
+	reconcile := func(cr *apiv1alpha1.PerconaServerMySQLBackup, objs ...client.Object) (*apiv1alpha1.PerconaServerMySQLBackup, client.Client) {
+		t.Helper()
 
-	if err := yaml.Unmarshal(data, cr); err != nil {
-		return nil, err
+		bcp := cr.DeepCopy()
+		cl := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(append(objs, bcp, cluster.DeepCopy())...).
+			WithStatusSubresource(bcp).Build()
+		r := PerconaServerMySQLBackupReconciler{
+			Client:        cl,
+			Scheme:        scheme,
+			ServerVersion: &platform.ServerVersion{Platform: platform.PlatformKubernetes},
+			NewSidecarClient: func(srcNode string) xtrabackup.SidecarClient {
+				return &fakeSidecarClient{}
+			},
+		}
+		nn := types.NamespacedName{Name: bcp.Name, Namespace: bcp.Namespace}
+		if _, err := r.Reconcile(ctx, controllerruntime.Request{NamespacedName: nn}); err != nil {
+			t.Fatal(err, "failed to reconcile")
+		}
+		if err := cl.Get(ctx, nn, bcp); err != nil {
+			t.Fatal(err, "failed to get backup")
+		}
+		return bcp, cl
 	}
 
-	cr.Name = name
-	cr.Namespace = namespace
-	return cr, nil
+	t.Run("pre-backup hook failed", func(t *testing.T) {
+		pre := hookJob(apiv1alpha1.HookStagePreBackup, &cluster.Spec.Backup.Hooks.PreBackup[0], batchv1.JobFailed)
+
+		bcp, cl := reconcile(cr, pre)
+		if bcp.Status.State != apiv1alpha1.BackupFailed || !strings.HasPrefix(bcp.Status.StateDesc, "preBackup hook prepare failed") {
+			t.Fatalf("expected backup to fail, got %+v", bcp.Status)
+		}
+		err := cl.Get(ctx, xtrabackup.JobNamespacedName(cr), new(batchv1.Job))
+		if !k8serrors.IsNotFound(err) {
+			t.Fatalf("expected backup job not to be created, got %v", err)
+		}
+	})
+
+	t.Run("pre-backup hook running", func(t *testing.T) {
+		pre := hookJob(apiv1alpha1.HookStagePreBackup, &cluster.Spec.Backup.Hooks.PreBackup[0], "")
+
+		bcp, cl := reconcile(cr, pre)
+		if bcp.Status.State != apiv1alpha1.BackupNew || bcp.Status.StateDesc != "running preBackup hooks" {
+			t.Fatalf("expected backup to wait for hooks, got %+v", bcp.Status)
+		}
+		err := cl.Get(ctx, xtrabackup.JobNamespacedName(cr), new(batchv1.Job))
+		if !k8serrors.IsNotFound(err) {
+			t.Fatalf("expected backup job not to be created, got %v", err)
+		}
+	})
+
+	running := cr.DeepCopy()
+	running.Status.State = apiv1alpha1.BackupRunning
+	running.Status.Hooks = []apiv1alpha1.HookStatus{{Name: "prepare", Stage: apiv1alpha1.HookStagePreBackup, State: apiv1alpha1.HookSucceeded}}
+	job := xtrabackup.Job(cluster, cr, "s3://bucket/container", "init-image", storage)
+	completedAt := metav1.Date(2024, time.March, 1, 1, 0, 0, 0, time.UTC)
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	job.Status.CompletionTime = &completedAt
+
+	t.Run("post-backup hook running", func(t *testing.T) {
+		post := hookJob(apiv1alpha1.HookStagePostBackup, &cluster.Spec.Backup.Hooks.PostBackup[0], "")
+
+		bcp, _ := reconcile(running, job.DeepCopy(), post)
+		if bcp.Status.State != apiv1alpha1.BackupRunning || bcp.Status.StateDesc != "running postBackup hooks" || bcp.Status.CompletedAt != nil {
+			t.Fatalf("expected backup to wait for hooks, got %+v", bcp.Status)
+		}
+	})
+
+	t.Run("post-backup hook succeeded", func(t *testing.T) {
+		post := hookJob(apiv1alpha1.HookStagePostBackup, &cluster.Spec.Backup.Hooks.PostBackup[0], batchv1.JobComplete)
+
+		bcp, _ := reconcile(running, job.DeepCopy(), post)
+		if bcp.Status.State != apiv1alpha1.BackupSucceeded || bcp.Status.StateDesc != "" {
+			t.Fatalf("expected backup to succeed, got %+v", bcp.Status)
+		}
+		st := hooks.Status(bcp.Status.Hooks, apiv1alpha1.HookStagePostBackup, "cleanup")
+		if st == nil || st.State != apiv1alpha1.HookSucceeded {
+			t.Fatalf("expected succeeded hook, got %+v", st)
+		}
+	})
+
+	t.Run("post-backup hook failed", func(t *testing.T) {
+		post := hookJob(apiv1alpha1.HookStagePostBackup, &cluster.Spec.Backup.Hooks.PostBackup[0], batchv1.JobFailed)
+
+		bcp, _ := reconcile(running, job.DeepCopy(), post)
+		if bcp.Status.State != apiv1alpha1.BackupSucceeded {
+			t.Fatalf("expected uploaded backup to stay succeeded, got %+v", bcp.Status)
+		}
+		if !meta.IsStatusConditionTrue(bcp.Status.Conditions, apiv1alpha1.BackupConditionPostBackupHooksFailed) {
+			t.Fatalf("expected hook failure condition, got %+v", bcp.Status.Conditions)
+		}
+	})
 }
 
-func readDefaultCRBackup(name, namespace string) (*apiv1alpha1.PerconaServerMySQLBackup, error) {
-	data, err := os.ReadFile(filepath.Join("..", "..", "..", "deploy", "backup.yaml"))
+func TestBackupTimeouts(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add client-go scheme")
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add apis scheme")
+	}
+	namespace := "some-namespace"
+
+	cluster, err := readDefaultCR("cluster1", namespace)
 	if err != nil {
-		return nil, err
+		t.Fatal(err, "failed to read default cr")
+	}
+	cluster.Status.MySQL.State = apiv1alpha1.StateReady
+	startingDeadline, activeDeadline := int64(60), int64(3600)
+	cluster.Spec.Backup.Timeouts = &apiv1alpha1.BackupTimeouts{
+		StartingDeadlineSeconds: &startingDeadline,
+		ActiveDeadlineSeconds:   &activeDeadline,
+	}
+	cluster.Spec.Backup.RetryPolicy = &apiv1alpha1.BackupRetryPolicy{
+		MaxAttempts:     5,
+		InitialInterval: &metav1.Duration{Duration: 30 * time.Second},
 	}
+	storage := cluster.Spec.Backup.Storages["s3-us-west"]
 
-	cr := &apiv1alpha1.PerconaServerMySQLBackup{}
+	cr, err := readDefaultCRBackup("some-name", namespace)
+	if err != nil {
+		t.Fatal(err, "failed to read default backup")
+	}
+	cr.Spec.StorageName = "s3-us-west"
+	cr.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	cr.Status.Storage = storage
 
-	if err := yaml.Unmarshal(data, cr); err != nil {
-		return nil, err
+	t.Run("job", func(t *testing.T) {
+		job := xtrabackup.Job(cluster, cr, "s3://bucket/container", "init-image", storage)
+		xtrabackup.SetTimeouts(job, cluster.Spec.Backup.Timeouts)
+		if err := xtrabackup.SetRetryPolicy(job, cluster.Spec.Backup.RetryPolicy); err != nil {
+			t.Fatal(err)
+		}
+
+		if job.Spec.ActiveDeadlineSeconds == nil || *job.Spec.ActiveDeadlineSeconds != activeDeadline {
+			t.Fatalf("expected active deadline %d, got %v", activeDeadline, job.Spec.ActiveDeadlineSeconds)
+		}
+		env := make(map[string]string)
+		for _, e := range job.Spec.Template.Spec.Containers[0].Env {
+			env[e.Name] = e.Value
+		}
+		if env["BACKUP_RETRY_MAX_ATTEMPTS"] != "5" || env["BACKUP_RETRY_INITIAL_INTERVAL"] != "30" {
+			t.Fatalf("unexpected retry env %v", env)
+		}
+		if _, ok := env["BACKUP_RETRY_MAX_INTERVAL"]; ok {
+			t.Fatal("expected default max interval")
+		}
+	})
+
+	job := func(updateFuncs ...func(job *batchv1.Job)) *batchv1.Job {
+		job := xtrabackup.Job(cluster, cr, "s3://bucket/container", "init-image", storage)
+		xtrabackup.SetTimeouts(job, cluster.Spec.Backup.Timeouts)
+		if err := xtrabackup.SetSourceNode(job, "source-node"); err != nil {
+			t.Fatal(err)
+		}
+		return updateResource(job, updateFuncs...)
 	}
 
-	cr.Name = name
-	cr.Namespace = namespace
-	return cr, nil
+	tests := []struct {
+		name      string
+		cr        *apiv1alpha1.PerconaServerMySQLBackup
+		job       *batchv1.Job
+		state     apiv1alpha1.BackupState
+		stateDesc string
+		canceled  bool
+		deleted   bool
+		notReady  bool
+	}{
+		{
+			name: "waiting for another backup",
+			cr: updateResource(cr.DeepCopy(), func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+				cr.Status.State = apiv1alpha1.BackupStarting
+			}),
+			job: job(func(job *batchv1.Job) {
+				job.Status.Active = 1
+			}),
+			state:     apiv1alpha1.BackupFailed,
+			stateDesc: "backup didn't start in 1m0s",
+			canceled:  true,
+			deleted:   true,
+		},
+		{
+			name: "pod is pending",
+			cr: updateResource(cr.DeepCopy(), func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+				cr.Status.State = apiv1alpha1.BackupStarting
+			}),
+			job:       job(),
+			state:     apiv1alpha1.BackupFailed,
+			stateDesc: "backup didn't start in 1m0s",
+			canceled:  true,
+			deleted:   true,
+		},
+		{
+			name: "cluster is not ready",
+			cr: updateResource(cr.DeepCopy(), func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+				cr.Status.State = apiv1alpha1.BackupNew
+			}),
+			state:     apiv1alpha1.BackupFailed,
+			stateDesc: "backup didn't start in 1m0s: cluster is not ready",
+			notReady:  true,
+		},
+		{
+			name: "starting deadline not exceeded",
+			cr: updateResource(cr.DeepCopy(), func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+				cr.CreationTimestamp = metav1.Now()
+				cr.Status.State = apiv1alpha1.BackupStarting
+			}),
+			job: job(func(job *batchv1.Job) {
+				job.Status.Active = 1
+			}),
+			state: apiv1alpha1.BackupStarting,
+		},
+		{
+			name: "active deadline exceeded",
+			cr: updateResource(cr.DeepCopy(), func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+				cr.Status.State = apiv1alpha1.BackupRunning
+			}),
+			job: job(func(job *batchv1.Job) {
+				job.Status.Conditions = []batchv1.JobCondition{{
+					Type:   batchv1.JobFailed,
+					Status: corev1.ConditionTrue,
+					Reason: batchv1.JobReasonDeadlineExceeded,
+				}}
+			}),
+			state:     apiv1alpha1.BackupFailed,
+			stateDesc: "backup didn't finish in 3600s",
+			canceled:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := cluster.DeepCopy()
+			if tt.notReady {
+				cluster.Status.MySQL.State = apiv1alpha1.StateInitializing
+			}
+			objs := []client.Object{tt.cr, cluster}
+			if tt.job != nil {
+				objs = append(objs, tt.job)
+			}
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithStatusSubresource(tt.cr).Build()
+
+			sidecarClient := new(fakeSidecarClient)
+			r := PerconaServerMySQLBackupReconciler{
+				Client:        cl,
+				Scheme:        scheme,
+				ServerVersion: &platform.ServerVersion{Platform: platform.PlatformKubernetes},
+				NewSidecarClient: func(srcNode string) xtrabackup.SidecarClient {
+					return sidecarClient
+				},
+			}
+			nn := types.NamespacedName{Name: tt.cr.Name, Namespace: tt.cr.Namespace}
+			if _, err := r.Reconcile(ctx, controllerruntime.Request{NamespacedName: nn}); err != nil {
+				t.Fatal(err, "failed to reconcile")
+			}
+
+			bcp := new(apiv1alpha1.PerconaServerMySQLBackup)
+			if err := cl.Get(ctx, nn, bcp); err != nil {
+				t.Fatal(err, "failed to get backup")
+			}
+			if bcp.Status.State != tt.state || bcp.Status.StateDesc != tt.stateDesc {
+				t.Fatalf("expected state %s (%q), got %s (%q)", tt.state, tt.stateDesc, bcp.Status.State, bcp.Status.StateDesc)
+			}
+			if sidecarClient.canceled != tt.canceled {
+				t.Fatalf("expected backup canceled in sidecar: %t, got %t", tt.canceled, sidecarClient.canceled)
+			}
+
+			if tt.job == nil {
+				return
+			}
+			err := cl.Get(ctx, xtrabackup.JobNamespacedName(tt.cr), new(batchv1.Job))
+			if tt.deleted && !k8serrors.IsNotFound(err) {
+				t.Fatalf("expected job to be deleted, got %v", err)
+			}
+			if !tt.deleted && err != nil {
+				t.Fatal(err, "failed to get job")
+			}
+		})
+	}
 }
 
-func updateResource[T any](cr *T, updateFuncs ...func(cr *T)) *T {
-	for _, f := range updateFuncs {
-		f(cr)
+func TestBackupVerification(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add client-go scheme")
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add apis scheme")
+	}
+	namespace := "some-namespace"
+
+	cluster, err := readDefaultCR("cluster1", namespace)
+	if err != nil {
+		t.Fatal(err, "failed to read default cr")
+	}
+	cluster.Spec.InitImage = "init-image"
+	storage, ok := cluster.Spec.Backup.Storages["s3-us-west"]
+	if !ok {
+		t.Fatal("storage not found")
+	}
+
+	cr, err := readDefaultCRBackup("some-name", namespace)
+	if err != nil {
+		t.Fatal(err, "failed to read default backup")
+	}
+	cr.Spec.StorageName = "s3-us-west"
+	cr.Spec.Verify = &apiv1alpha1.BackupVerifySpec{Enabled: true, Query: "SELECT 1"}
+	cr.Status.State = apiv1alpha1.BackupSucceeded
+	cr.Status.Storage = storage
+	cr.Status.Destination.SetS3Destination("bucket", "container")
+
+	reconcile := func(objs ...client.Object) (*apiv1alpha1.PerconaServerMySQLBackup, client.Client) {
+		t.Helper()
+
+		bcp := cr.DeepCopy()
+		cl := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(append(objs, bcp, cluster.DeepCopy())...).
+			WithStatusSubresource(bcp).Build()
+		r := PerconaServerMySQLBackupReconciler{
+			Client:        cl,
+			Scheme:        scheme,
+			ServerVersion: &platform.ServerVersion{Platform: platform.PlatformKubernetes},
+		}
+		nn := types.NamespacedName{Name: bcp.Name, Namespace: bcp.Namespace}
+		if _, err := r.Reconcile(ctx, controllerruntime.Request{NamespacedName: nn}); err != nil {
+			t.Fatal(err, "failed to reconcile")
+		}
+		if err := cl.Get(ctx, nn, bcp); err != nil {
+			t.Fatal(err, "failed to get backup")
+		}
+		return bcp, cl
+	}
+
+	t.Run("creates job", func(t *testing.T) {
+		bcp, cl := reconcile()
+
+		job := new(batchv1.Job)
+		if err := cl.Get(ctx, types.NamespacedName{Name: xtrabackup.VerifyJobName(cr), Namespace: namespace}, job); err != nil {
+			t.Fatal(err, "failed to get verification job")
+		}
+		if !metav1.IsControlledBy(job, bcp) {
+			t.Fatal("expected job to be controlled by backup")
+		}
+		if len(job.Spec.Template.Spec.InitContainers) != 2 {
+			t.Fatalf("expected 2 init containers, got %d", len(job.Spec.Template.Spec.InitContainers))
+		}
+		restore := job.Spec.Template.Spec.InitContainers[1]
+		if !hasEnv(restore.Env, "S3_BUCKET") {
+			t.Fatal("expected storage env vars in restore container")
+		}
+		verify := job.Spec.Template.Spec.Containers[0]
+		if !hasEnv(verify.Env, "VERIFY_QUERY") || verify.Image != cluster.Spec.MySQL.Image {
+			t.Fatalf("unexpected verification container %+v", verify)
+		}
+		if policy := job.Spec.PodFailurePolicy; policy == nil || len(policy.Rules) != 2 ||
+			policy.Rules[0].Action != batchv1.PodFailurePolicyActionIgnore ||
+			*policy.Rules[1].OnExitCodes.ContainerName != verify.Name {
+			t.Fatalf("expected evicted pods to be retried and failed query to fail job, got %+v", policy)
+		}
+		if meta.FindStatusCondition(bcp.Status.Conditions, apiv1alpha1.BackupConditionVerified) != nil {
+			t.Fatal("expected Verified condition to be unset while job is running")
+		}
+	})
+
+	tests := []struct {
+		name       string
+		cond       batchv1.JobConditionType
+		condReason string
+		status     metav1.ConditionStatus
+		reason     string
+		message    string
+	}{
+		{
+			name:    "job completed",
+			cond:    batchv1.JobComplete,
+			status:  metav1.ConditionTrue,
+			reason:  apiv1alpha1.BackupReasonVerified,
+			message: "backup is restored and the verification query succeeded",
+		},
+		{
+			name:       "restore failed",
+			cond:       batchv1.JobFailed,
+			condReason: batchv1.JobReasonBackoffLimitExceeded,
+			status:     metav1.ConditionFalse,
+			reason:     apiv1alpha1.BackupReasonVerificationFailed,
+			message:    "job xb-verify-some-name failed: retries exhausted",
+		},
+		{
+			name:       "query failed",
+			cond:       batchv1.JobFailed,
+			condReason: batchv1.JobReasonPodFailurePolicy,
+			status:     metav1.ConditionFalse,
+			reason:     apiv1alpha1.BackupReasonVerificationFailed,
+			message:    "verification query failed on the restored backup, see logs of job xb-verify-some-name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := xtrabackup.VerifyJob(cluster, cr, storage, "init-image")
+			job.Status.Conditions = []batchv1.JobCondition{{Type: tt.cond, Status: corev1.ConditionTrue, Reason: tt.condReason}}
+			if tt.condReason == batchv1.JobReasonBackoffLimitExceeded {
+				job.Status.Conditions[0].Message = "retries exhausted"
+			}
+
+			bcp, _ := reconcile(job)
+			cond := meta.FindStatusCondition(bcp.Status.Conditions, apiv1alpha1.BackupConditionVerified)
+			if cond == nil {
+				t.Fatal("expected Verified condition to be set")
+			}
+			if cond.Status != tt.status || cond.Reason != tt.reason || cond.Message != tt.message {
+				t.Fatalf("unexpected condition %+v", cond)
+			}
+		})
+	}
+}
+
+func TestBackupCopies(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add client-go scheme")
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add apis scheme")
+	}
+	namespace := "some-namespace"
+
+	cluster, err := readDefaultCR("cluster1", namespace)
+	if err != nil {
+		t.Fatal(err, "failed to read default cr")
+	}
+	cluster.Spec.InitImage = "init-image"
+	source := cluster.Spec.Backup.Storages["s3-us-west"]
+	target := &apiv1alpha1.BackupStorageSpec{
+		Type: apiv1alpha1.BackupStorageS3,
+		S3: &apiv1alpha1.BackupStorageS3Spec{
+			Bucket:            "offsite/prefix",
+			CredentialsSecret: "offsite-credentials",
+		},
+	}
+	cluster.Spec.Backup.Storages["offsite"] = target
+	cluster.Spec.Backup.Storages["fs-pvc"] = &apiv1alpha1.BackupStorageSpec{Type: apiv1alpha1.BackupStorageFilesystem}
+
+	cr, err := readDefaultCRBackup("some-name", namespace)
+	if err != nil {
+		t.Fatal(err, "failed to read default backup")
+	}
+	cr.CreationTimestamp = metav1.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	cr.Spec.StorageName = "s3-us-west"
+	cr.Spec.ReplicateTo = []string{"offsite", "s3-us-west", "fs-pvc", "missing"}
+	cr.Status.State = apiv1alpha1.BackupSucceeded
+	cr.Status.Storage = source
+	cr.Status.Destination.SetS3Destination("bucket", "cluster1-2024-03-01-00:00:00-full")
+
+	reconcile := func(cr *apiv1alpha1.PerconaServerMySQLBackup, objs ...client.Object) (*apiv1alpha1.PerconaServerMySQLBackup, client.Client) {
+		t.Helper()
+
+		bcp := cr.DeepCopy()
+		cl := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(append(objs, bcp, cluster.DeepCopy())...).
+			WithStatusSubresource(bcp).Build()
+		r := PerconaServerMySQLBackupReconciler{
+			Client:        cl,
+			Scheme:        scheme,
+			ServerVersion: &platform.ServerVersion{Platform: platform.PlatformKubernetes},
+		}
+		nn := types.NamespacedName{Name: bcp.Name, Namespace: bcp.Namespace}
+		if _, err := r.Reconcile(ctx, controllerruntime.Request{NamespacedName: nn}); err != nil {
+			t.Fatal(err, "failed to reconcile")
+		}
+		if err := cl.Get(ctx, nn, bcp); err != nil {
+			t.Fatal(err, "failed to get backup")
+		}
+		return bcp, cl
+	}
+
+	t.Run("creates job", func(t *testing.T) {
+		bcp, cl := reconcile(cr)
+
+		job := new(batchv1.Job)
+		if err := cl.Get(ctx, types.NamespacedName{Name: xtrabackup.CopyJobName(cr, "offsite"), Namespace: namespace}, job); err != nil {
+			t.Fatal(err, "failed to get copy job")
+		}
+		if !metav1.IsControlledBy(job, bcp) {
+			t.Fatal("expected job to be controlled by backup")
+		}
+		container := job.Spec.Template.Spec.Containers[0]
+		if !hasEnv(container.Env, "S3_BUCKET") || !hasEnv(container.Env, xtrabackup.TargetEnvPrefix+"S3_BUCKET") {
+			t.Fatal("expected source and target storage env vars in copy container")
+		}
+		expectedCmd := []string{
+			"/opt/percona-server-mysql-operator/backup-stream", "copy",
+			"cluster1-2024-03-01-00:00:00-full", "prefix/cluster1-2024-03-01-00:00:00-full",
+		}
+		if !reflect.DeepEqual(container.Command, expectedCmd) {
+			t.Fatalf("expected command %v, got %v", expectedCmd, container.Command)
+		}
+
+		c := bcp.Status.Copy("offsite")
+		if c == nil || c.State != apiv1alpha1.BackupRunning {
+			t.Fatalf("expected running copy, got %+v", c)
+		}
+		if expected := apiv1alpha1.BackupDestination("s3://offsite/prefix/cluster1-2024-03-01-00:00:00-full"); c.Destination != expected {
+			t.Fatalf("expected destination %s, got %s", expected, c.Destination)
+		}
+		for _, name := range []string{"s3-us-west", "fs-pvc", "missing"} {
+			if c := bcp.Status.Copy(name); c == nil || c.State != apiv1alpha1.BackupFailed {
+				t.Fatalf("expected copy to %s to fail, got %+v", name, c)
+			}
+		}
+	})
+
+	t.Run("job completed", func(t *testing.T) {
+		cr := cr.DeepCopy()
+		cr.Spec.ReplicateTo = []string{"offsite"}
+		cr.Status.Copies = []apiv1alpha1.BackupCopyStatus{{StorageName: "offsite", State: apiv1alpha1.BackupRunning}}
+
+		job := xtrabackup.CopyJob(cluster, cr, "offsite", target, cr.Status.Destination, "init-image")
+		completedAt := metav1.Date(2024, time.March, 1, 1, 0, 0, 0, time.UTC)
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+		job.Status.CompletionTime = &completedAt
+
+		bcp, _ := reconcile(cr, job)
+		c := bcp.Status.Copy("offsite")
+		if c.State != apiv1alpha1.BackupSucceeded || c.CompletedAt == nil || !c.CompletedAt.Equal(&completedAt) {
+			t.Fatalf("expected succeeded copy, got %+v", c)
+		}
+	})
+
+	t.Run("incremental waits for base copy", func(t *testing.T) {
+		base := cr.DeepCopy()
+		base.Name = "base"
+		base.Spec.ReplicateTo = []string{"offsite"}
+		base.Status.Copies = []apiv1alpha1.BackupCopyStatus{{StorageName: "offsite", State: apiv1alpha1.BackupRunning}}
+
+		inc := cr.DeepCopy()
+		inc.Spec.ReplicateTo = []string{"offsite"}
+		inc.Status.Type = apiv1alpha1.BackupTypeIncremental
+		inc.Status.BaseBackupName = base.Name
+
+		bcp, cl := reconcile(inc, base)
+		if c := bcp.Status.Copy("offsite"); c == nil || c.State != apiv1alpha1.BackupNew {
+			t.Fatalf("expected copy to wait for base copy, got %+v", c)
+		}
+		err := cl.Get(ctx, types.NamespacedName{Name: xtrabackup.CopyJobName(inc, "offsite"), Namespace: namespace}, new(batchv1.Job))
+		if !k8serrors.IsNotFound(err) {
+			t.Fatalf("expected copy job not to be created, got %v", err)
+		}
+
+		base.Status.Copies[0].State = apiv1alpha1.BackupSucceeded
+		base.Status.Copies[0].Destination = "s3://offsite/prefix/base"
+		base.Status.Copies[0].Chain = []apiv1alpha1.BackupDestination{"s3://offsite/prefix/full"}
+		bcp, _ = reconcile(inc, base)
+		c := bcp.Status.Copy("offsite")
+		expected := []apiv1alpha1.BackupDestination{"s3://offsite/prefix/full", "s3://offsite/prefix/base"}
+		if c.State != apiv1alpha1.BackupRunning || !reflect.DeepEqual(c.Chain, expected) {
+			t.Fatalf("expected running copy with chain %v, got %+v", expected, c)
+		}
+	})
+
+	t.Run("deletes copies", func(t *testing.T) {
+		cr := cr.DeepCopy()
+		cr.Status.Copies = []apiv1alpha1.BackupCopyStatus{{
+			StorageName: "offsite",
+			State:       apiv1alpha1.BackupSucceeded,
+			Storage:     target,
+			Destination: "s3://offsite/prefix/cluster1-2024-03-01-00:00:00-full",
+		}}
+
+		mem := fakestorage.NewMemoryStorage()
+		for _, name := range []string{
+			"cluster1-2024-03-01-00:00:00-full/xbstream.00000000000000000000",
+			"cluster1-2024-03-01-00:00:00-full/manifest.json",
+			"cluster1-2024-03-01-00:00:00-full.checkpoints",
+			"cluster1-2024-03-02-00:00:00-full/manifest.json",
+		} {
+			if err := mem.PutObject(ctx, name, strings.NewReader(""), 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "offsite-credentials", Namespace: namespace}}
+		r := PerconaServerMySQLBackupReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build(),
+			Scheme: scheme,
+			NewStorageClient: func(ctx context.Context, opts storage.Options) (storage.Storage, error) {
+				return mem, nil
+			},
+		}
+		if err := r.deleteCopies(ctx, cr); err != nil {
+			t.Fatal(err)
+		}
+
+		objects, err := mem.ListObjects(ctx, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := []string{"cluster1-2024-03-02-00:00:00-full/manifest.json"}; !reflect.DeepEqual(objects, expected) {
+			t.Fatalf("expected objects %v, got %v", expected, objects)
+		}
+	})
+}
+
+func TestLogicalBackup(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add client-go scheme")
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add apis scheme")
+	}
+	namespace := "some-namespace"
+
+	cluster, err := readDefaultCR("cluster1", namespace)
+	if err != nil {
+		t.Fatal(err, "failed to read default cr")
+	}
+	cluster.Status.MySQL.State = apiv1alpha1.StateReady
+	storage, ok := cluster.Spec.Backup.Storages["s3-us-west"]
+	if !ok {
+		t.Fatal("storage not found")
+	}
+
+	cr, err := readDefaultCRBackup("some-name", namespace)
+	if err != nil {
+		t.Fatal(err, "failed to read default backup")
+	}
+	cr.Spec.StorageName = "s3-us-west"
+	cr.Spec.Method = apiv1alpha1.BackupMethodLogical
+
+	reconcile := func(t *testing.T, cr *apiv1alpha1.PerconaServerMySQLBackup, cluster *apiv1alpha1.PerconaServerMySQL, objs ...client.Object) *apiv1alpha1.PerconaServerMySQLBackup {
+		t.Helper()
+
+		cl := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(append(objs, cr, cluster)...).
+			WithStatusSubresource(cr).Build()
+		r := PerconaServerMySQLBackupReconciler{
+			Client:        cl,
+			Scheme:        scheme,
+			ServerVersion: &platform.ServerVersion{Platform: platform.PlatformKubernetes},
+			NewSidecarClient: func(srcNode string) xtrabackup.SidecarClient {
+				t.Fatal("sidecar must not be used for logical backups")
+				return nil
+			},
+		}
+		nn := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
+		if _, err := r.Reconcile(ctx, controllerruntime.Request{NamespacedName: nn}); err != nil {
+			t.Fatal(err, "failed to reconcile")
+		}
+		bcp := new(apiv1alpha1.PerconaServerMySQLBackup)
+		if err := cl.Get(ctx, nn, bcp); err != nil {
+			t.Fatal(err, "failed to get backup")
+		}
+		return bcp
+	}
+
+	t.Run("job", func(t *testing.T) {
+		job, err := xtrabackup.LogicalJob(cluster, cr, "s3://bucket/container", "init-image", storage)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := xtrabackup.SetStorageS3(job, storage.S3); err != nil {
+			t.Fatal(err)
+		}
+
+		c := job.Spec.Template.Spec.Containers[0]
+		if c.Image != cluster.Spec.MySQL.Image {
+			t.Fatalf("expected MySQL image %s, got %s", cluster.Spec.MySQL.Image, c.Image)
+		}
+		if !reflect.DeepEqual(c.Command, []string{"/opt/percona/run-logical-backup.sh"}) {
+			t.Fatalf("unexpected command %v", c.Command)
+		}
+		if !hasEnv(c.Env, "S3_BUCKET") {
+			t.Fatal("expected storage env vars in backup container")
+		}
+		mounted := false
+		for _, m := range c.VolumeMounts {
+			if m.MountPath == "/etc/mysql/mysql-users-secret" {
+				mounted = true
+			}
+		}
+		if !mounted {
+			t.Fatal("expected users secret to be mounted")
+		}
+	})
+
+	tests := []struct {
+		name      string
+		cr        *apiv1alpha1.PerconaServerMySQLBackup
+		cluster   *apiv1alpha1.PerconaServerMySQL
+		stateDesc string
+	}{
+		{
+			name: "incremental",
+			cr: updateResource(cr.DeepCopy(), func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+				cr.Spec.Type = apiv1alpha1.BackupTypeIncremental
+			}),
+			cluster:   cluster.DeepCopy(),
+			stateDesc: "incremental backups are not supported for logical backups",
+		},
+		{
+			name: "encryption",
+			cr:   cr.DeepCopy(),
+			cluster: updateResource(cluster.DeepCopy(), func(cr *apiv1alpha1.PerconaServerMySQL) {
+				cr.Spec.Backup.Encryption = &apiv1alpha1.BackupEncryptionSpec{
+					Cipher: apiv1alpha1.BackupEncryptionAES256,
+					KeySecret: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "backup-key"},
+						Key:                  "key",
+					},
+				}
+			}),
+			stateDesc: "encryption is not supported for logical backups",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bcp := reconcile(t, tt.cr, tt.cluster)
+			if bcp.Status.State != apiv1alpha1.BackupError || bcp.Status.StateDesc != tt.stateDesc {
+				t.Fatalf("expected state %s with %q, got %s with %q", apiv1alpha1.BackupError, tt.stateDesc, bcp.Status.State, bcp.Status.StateDesc)
+			}
+		})
+	}
+
+	t.Run("running without sidecar", func(t *testing.T) {
+		bcp := cr.DeepCopy()
+		bcp.Status.State = apiv1alpha1.BackupStarting
+		bcp.Status.Method = apiv1alpha1.BackupMethodLogical
+		job, err := xtrabackup.LogicalJob(cluster, bcp, "s3://bucket/container", "init-image", storage)
+		if err != nil {
+			t.Fatal(err)
+		}
+		job.Status.Active = 1
+
+		bcp = reconcile(t, bcp, cluster.DeepCopy(), job)
+		if bcp.Status.State != apiv1alpha1.BackupRunning {
+			t.Fatalf("expected state %s, got %s", apiv1alpha1.BackupRunning, bcp.Status.State)
+		}
+	})
+
+	t.Run("verification", func(t *testing.T) {
+		bcp := cr.DeepCopy()
+		bcp.Spec.Verify = &apiv1alpha1.BackupVerifySpec{Enabled: true}
+		bcp.Status.State = apiv1alpha1.BackupSucceeded
+		bcp.Status.Method = apiv1alpha1.BackupMethodLogical
+
+		bcp = reconcile(t, bcp, cluster.DeepCopy())
+		cond := meta.FindStatusCondition(bcp.Status.Conditions, apiv1alpha1.BackupConditionVerified)
+		if cond == nil || cond.Status != metav1.ConditionFalse {
+			t.Fatalf("expected Verified condition to be false, got %+v", cond)
+		}
+	})
+}
+
+func hasEnv(env []corev1.EnvVar, name string) bool {
+	for _, e := range env {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCancelBackup(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add client-go scheme")
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add apis scheme")
+	}
+	namespace := "some-namespace"
+
+	cluster, err := readDefaultCR("cluster1", namespace)
+	if err != nil {
+		t.Fatal(err, "failed to read default cr")
+	}
+	cluster.Status.MySQL.State = apiv1alpha1.StateReady
+	storage, ok := cluster.Spec.Backup.Storages["s3-us-west"]
+	if !ok {
+		t.Fatal("storage not found")
+	}
+
+	cr, err := readDefaultCRBackup("some-name", namespace)
+	if err != nil {
+		t.Fatal(err, "failed to read default backup")
+	}
+	cr.Spec.StorageName = "s3-us-west"
+	cr.Spec.Cancel = true
+	cr.Status.State = apiv1alpha1.BackupRunning
+	cr.Status.Storage = storage
+	cr.Status.Destination = "s3://bucket/container"
+
+	logicalJob, err := xtrabackup.LogicalJob(cluster, cr, "s3://bucket/container", "init-image", storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s3Secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      storage.S3.CredentialsSecret,
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			secret.CredentialsAWSAccessKey: []byte("access-key"),
+			secret.CredentialsAWSSecretKey: []byte("secret-key"),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		cr       *apiv1alpha1.PerconaServerMySQLBackup
+		job      *batchv1.Job
+		state    apiv1alpha1.BackupState
+		canceled bool
+		deleted  bool
+	}{
+		{
+			name:     "physical",
+			cr:       cr.DeepCopy(),
+			job:      xtrabackup.Job(cluster, cr, "s3://bucket/container", "init-image", storage),
+			state:    apiv1alpha1.BackupCanceled,
+			canceled: true,
+		},
+		{
+			name: "logical",
+			cr: updateResource(cr.DeepCopy(), func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+				cr.Status.Method = apiv1alpha1.BackupMethodLogical
+			}),
+			job:     logicalJob,
+			state:   apiv1alpha1.BackupCanceled,
+			deleted: true,
+		},
+		{
+			name: "new",
+			cr: updateResource(cr.DeepCopy(), func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+				cr.Status = apiv1alpha1.PerconaServerMySQLBackupStatus{}
+			}),
+			state: apiv1alpha1.BackupCanceled,
+		},
+		{
+			name: "succeeded",
+			cr: updateResource(cr.DeepCopy(), func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+				cr.Status.State = apiv1alpha1.BackupSucceeded
+				cr.Status.Metadata = &apiv1alpha1.BackupMetadata{SizeBytes: 1}
+			}),
+			job:   xtrabackup.Job(cluster, cr, "s3://bucket/container", "init-image", storage),
+			state: apiv1alpha1.BackupSucceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs := []client.Object{tt.cr, cluster.DeepCopy(), s3Secret.DeepCopy()}
+			if tt.job != nil {
+				if err := xtrabackup.SetSourceNode(tt.job, "source-node"); err != nil {
+					t.Fatal(err)
+				}
+				objs = append(objs, tt.job)
+			}
+			cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithStatusSubresource(tt.cr).Build()
+
+			sidecarClient := new(fakeSidecarClient)
+			r := PerconaServerMySQLBackupReconciler{
+				Client:        cl,
+				Scheme:        scheme,
+				ServerVersion: &platform.ServerVersion{Platform: platform.PlatformKubernetes},
+				NewSidecarClient: func(srcNode string) xtrabackup.SidecarClient {
+					if srcNode != "source-node" {
+						t.Fatalf("unexpected sidecar node %s", srcNode)
+					}
+					return sidecarClient
+				},
+			}
+			nn := types.NamespacedName{Name: tt.cr.Name, Namespace: tt.cr.Namespace}
+			if _, err := r.Reconcile(ctx, controllerruntime.Request{NamespacedName: nn}); err != nil {
+				t.Fatal(err, "failed to reconcile")
+			}
+
+			bcp := new(apiv1alpha1.PerconaServerMySQLBackup)
+			if err := cl.Get(ctx, nn, bcp); err != nil {
+				t.Fatal(err, "failed to get backup")
+			}
+			if bcp.Status.State != tt.state {
+				t.Fatalf("expected state %s, got %s", tt.state, bcp.Status.State)
+			}
+			if sidecarClient.canceled != tt.canceled {
+				t.Fatalf("expected backup canceled in sidecar: %t, got %t", tt.canceled, sidecarClient.canceled)
+			}
+			if sidecarClient.deleted != tt.deleted {
+				t.Fatalf("expected backup deleted by sidecar: %t, got %t", tt.deleted, sidecarClient.deleted)
+			}
+
+			if tt.job == nil {
+				return
+			}
+			err := cl.Get(ctx, xtrabackup.JobNamespacedName(tt.cr), new(batchv1.Job))
+			if tt.state == apiv1alpha1.BackupCanceled && !k8serrors.IsNotFound(err) {
+				t.Fatalf("expected job to be deleted, got %v", err)
+			}
+			if tt.state != apiv1alpha1.BackupCanceled && err != nil {
+				t.Fatal(err, "failed to get job")
+			}
+		})
+	}
+}
+
+type fakeSidecarClient struct {
+	destination string
+	progress    *apiv1alpha1.BackupProgress
+	metadata    *apiv1alpha1.BackupMetadata
+
+	canceled bool
+	deleted  bool
+}
+
+func (f *fakeSidecarClient) GetRunningBackupConfig(ctx context.Context) (*xtrabackup.BackupConfig, error) {
+	if f.destination == "" {
+		return nil, nil
+	}
+	return &xtrabackup.BackupConfig{
+		Destination: f.destination,
+	}, nil
+}
+
+func (f *fakeSidecarClient) GetBackupProgress(ctx context.Context, name string) (*apiv1alpha1.BackupProgress, error) {
+	if f.progress == nil {
+		return nil, nil
+	}
+	progress := *f.progress
+	return &progress, nil
+}
+
+func (f *fakeSidecarClient) GetBackupMetadata(ctx context.Context, name string) (*apiv1alpha1.BackupMetadata, error) {
+	return f.metadata, nil
+}
+
+func (f *fakeSidecarClient) DeleteBackup(ctx context.Context, name string, cfg xtrabackup.BackupConfig) error {
+	f.deleted = true
+	return nil
+}
+
+func (f *fakeSidecarClient) CancelBackup(ctx context.Context, name string) error {
+	f.canceled = true
+	return nil
+}
+
+func readDefaultCR(name, namespace string) (*apiv1alpha1.PerconaServerMySQL, error) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "..", "deploy", "cr.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	cr := &apiv1alpha1.PerconaServerMySQL{}
+
+	if err := yaml.Unmarshal(data, cr); err != nil {
+		return nil, err
+	}
+
+	cr.Name = name
+	cr.Namespace = namespace
+	return cr, nil
+}
+
+func TestGetIncrementalBase(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add client-go scheme")
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add apis scheme")
+	}
+	namespace := "some-namespace"
+	cr, err := readDefaultCRBackup("incremental", namespace)
+	if err != nil {
+		t.Fatal(err, "failed to read default backup")
+	}
+	cr.Spec.Type = apiv1alpha1.BackupTypeIncremental
+
+	backup := func(name string, completed int64, updateFuncs ...func(cr *apiv1alpha1.PerconaServerMySQLBackup)) *apiv1alpha1.PerconaServerMySQLBackup {
+		bcp := cr.DeepCopy()
+		bcp.Name = name
+		bcp.Spec.Type = apiv1alpha1.BackupTypeFull
+		bcp.Status.Type = apiv1alpha1.BackupTypeFull
+		bcp.Status.State = apiv1alpha1.BackupSucceeded
+		bcp.Status.CompletedAt = &metav1.Time{Time: metav1.Unix(completed, 0).Time}
+		return updateResource(bcp, updateFuncs...)
+	}
+
+	tests := []struct {
+		name         string
+		cr           *apiv1alpha1.PerconaServerMySQLBackup
+		backups      []*apiv1alpha1.PerconaServerMySQLBackup
+		expectedBase string
+		expectedErr  string
+	}{
+		{
+			name:        "no backups",
+			cr:          cr.DeepCopy(),
+			expectedErr: "no successful full backup found on storage " + cr.Spec.StorageName,
+		},
+		{
+			name: "latest full backup",
+			cr:   cr.DeepCopy(),
+			backups: []*apiv1alpha1.PerconaServerMySQLBackup{
+				backup("full-1", 100),
+				backup("full-2", 200),
+				backup("full-3", 300, func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+					cr.Status.State = apiv1alpha1.BackupFailed
+				}),
+				backup("inc-1", 400, func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+					cr.Status.Type = apiv1alpha1.BackupTypeIncremental
+				}),
+				backup("other-storage", 500, func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+					cr.Spec.StorageName = "other"
+				}),
+				backup("logical", 600, func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+					cr.Spec.Method = apiv1alpha1.BackupMethodLogical
+					cr.Status.Method = apiv1alpha1.BackupMethodLogical
+				}),
+			},
+			expectedBase: "full-2",
+		},
+		{
+			name: "referenced backup is logical",
+			cr: updateResource(cr.DeepCopy(), func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+				cr.Spec.BaseBackupName = "logical"
+			}),
+			backups: []*apiv1alpha1.PerconaServerMySQLBackup{
+				backup("logical", 100, func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+					cr.Spec.Method = apiv1alpha1.BackupMethodLogical
+					cr.Status.Method = apiv1alpha1.BackupMethodLogical
+				}),
+			},
+			expectedErr: "base backup logical is a logical backup, incremental backups require a physical one",
+		},
+		{
+			name: "referenced backup",
+			cr: updateResource(cr.DeepCopy(), func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+				cr.Spec.BaseBackupName = "inc-1"
+			}),
+			backups: []*apiv1alpha1.PerconaServerMySQLBackup{
+				backup("full-1", 100),
+				backup("inc-1", 200, func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+					cr.Status.Type = apiv1alpha1.BackupTypeIncremental
+				}),
+			},
+			expectedBase: "inc-1",
+		},
+		{
+			name: "referenced backup is not succeeded",
+			cr: updateResource(cr.DeepCopy(), func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+				cr.Spec.BaseBackupName = "full-1"
+			}),
+			backups: []*apiv1alpha1.PerconaServerMySQLBackup{
+				backup("full-1", 100, func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+					cr.Status.State = apiv1alpha1.BackupRunning
+				}),
+			},
+			expectedErr: "base backup full-1 is not succeeded",
+		},
+		{
+			name: "referenced backup doesn't exist",
+			cr: updateResource(cr.DeepCopy(), func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+				cr.Spec.BaseBackupName = "full-1"
+			}),
+			expectedErr: "base backup full-1 is not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.cr)
+			for _, bcp := range tt.backups {
+				cb = cb.WithObjects(bcp)
+			}
+			r := PerconaServerMySQLBackupReconciler{
+				Client: cb.Build(),
+				Scheme: scheme,
+			}
+
+			base, err := r.getIncrementalBase(ctx, tt.cr)
+			errStr := ""
+			if err != nil {
+				errStr = err.Error()
+			}
+			if errStr != tt.expectedErr {
+				t.Fatalf("expected err %q, got %q", tt.expectedErr, errStr)
+			}
+			if base != nil && base.Name != tt.expectedBase {
+				t.Fatalf("expected base %s, got %s", tt.expectedBase, base.Name)
+			}
+		})
+	}
+}
+
+// fakeTopologyExec answers the queries used to get the group replication topology.
+type fakeTopologyExec struct {
+	primary  string
+	replicas []string
+}
+
+func (e *fakeTopologyExec) Exec(_ context.Context, _ *corev1.Pod, _ string, command []string, _ io.Reader, stdout, _ io.Writer, _ bool) error {
+	cmd := strings.Join(command, " ")
+
+	switch {
+	case strings.Contains(cmd, "MEMBER_ROLE='PRIMARY'"):
+		fmt.Fprintf(stdout, "host\n%s\n", e.primary)
+	case strings.Contains(cmd, "MEMBER_ROLE='SECONDARY'"):
+		fmt.Fprint(stdout, "host\n")
+		for _, host := range e.replicas {
+			fmt.Fprintln(stdout, host)
+		}
+	default:
+		return fmt.Errorf("unexpected command: %s", cmd)
+	}
+	return nil
+}
+
+func (e *fakeTopologyExec) REST() restclient.Interface {
+	return nil
+}
+
+func TestIncrementalBackupSource(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add client-go scheme")
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add apis scheme")
+	}
+	namespace := "some-namespace"
+
+	cluster, err := readDefaultCR("cluster1", namespace)
+	if err != nil {
+		t.Fatal(err, "failed to read default cr")
+	}
+	cluster.Spec.MySQL.ClusterType = apiv1alpha1.ClusterTypeGR
+	cluster.Spec.InitImage = "init-image"
+	cluster.Status.MySQL.State = apiv1alpha1.StateReady
+	storage := cluster.Spec.Backup.Storages["s3-us-west"]
+
+	host := func(i int) string {
+		return fmt.Sprintf("cluster1-mysql-%d.cluster1-mysql.%s", i, namespace)
+	}
+
+	base, err := readDefaultCRBackup("full", namespace)
+	if err != nil {
+		t.Fatal(err, "failed to read default backup")
+	}
+	base.Spec.StorageName = "s3-us-west"
+	base.Status.State = apiv1alpha1.BackupSucceeded
+	base.Status.Type = apiv1alpha1.BackupTypeFull
+	base.Status.Source = host(2)
+	base.Status.CompletedAt = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+	base.Status.Destination.SetS3Destination("s3-test-bucket", "cluster1-full")
+
+	cr, err := readDefaultCRBackup("incremental", namespace)
+	if err != nil {
+		t.Fatal(err, "failed to read default backup")
+	}
+	cr.Spec.StorageName = "s3-us-west"
+	cr.Spec.Type = apiv1alpha1.BackupTypeIncremental
+	cr.CreationTimestamp = metav1.Now()
+
+	objs := []client.Object{
+		cluster,
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: cluster.InternalSecretName(), Namespace: namespace},
+			Data:       map[string][]byte{string(apiv1alpha1.UserOperator): []byte("pass")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: storage.S3.CredentialsSecret, Namespace: namespace},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster1-mysql-0", Namespace: namespace},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		base      *apiv1alpha1.PerconaServerMySQLBackup
+		exec      *fakeTopologyExec
+		state     apiv1alpha1.BackupState
+		stateDesc string
+		srcNode   string
+	}{
+		{
+			name:    "base source is a replica",
+			base:    base.DeepCopy(),
+			exec:    &fakeTopologyExec{primary: host(0), replicas: []string{host(1), host(2)}},
+			state:   apiv1alpha1.BackupNew,
+			srcNode: host(2),
+		},
+		{
+			name:      "base source is offline",
+			base:      base.DeepCopy(),
+			exec:      &fakeTopologyExec{primary: host(0), replicas: []string{host(1)}},
+			state:     apiv1alpha1.BackupError,
+			stateDesc: "source node cluster1-mysql-2 of base backup full is not an online replica, full backup is required",
+		},
+		{
+			name:      "base source is the primary",
+			base:      base.DeepCopy(),
+			exec:      &fakeTopologyExec{primary: host(2), replicas: []string{host(0), host(1)}},
+			state:     apiv1alpha1.BackupError,
+			stateDesc: "source node cluster1-mysql-2 of base backup full is not an online replica, full backup is required",
+		},
+		{
+			name:    "base source is the primary without replicas",
+			base:    base.DeepCopy(),
+			exec:    &fakeTopologyExec{primary: host(2)},
+			state:   apiv1alpha1.BackupNew,
+			srcNode: host(2),
+		},
+		{
+			name: "base source is unknown",
+			base: updateResource(base.DeepCopy(), func(cr *apiv1alpha1.PerconaServerMySQLBackup) {
+				cr.Status.Source = ""
+			}),
+			exec:      &fakeTopologyExec{primary: host(0), replicas: []string{host(1), host(2)}},
+			state:     apiv1alpha1.BackupError,
+			stateDesc: "source node of base backup full is unknown, full backup is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bcp := cr.DeepCopy()
+			cl := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(append(objs, bcp, tt.base)...).
+				WithStatusSubresource(bcp).Build()
+			r := PerconaServerMySQLBackupReconciler{
+				Client:        cl,
+				Scheme:        scheme,
+				ClientCmd:     tt.exec,
+				ServerVersion: &platform.ServerVersion{Platform: platform.PlatformKubernetes},
+			}
+			nn := types.NamespacedName{Name: bcp.Name, Namespace: bcp.Namespace}
+			if _, err := r.Reconcile(ctx, controllerruntime.Request{NamespacedName: nn}); err != nil {
+				t.Fatal(err, "failed to reconcile")
+			}
+			if err := cl.Get(ctx, nn, bcp); err != nil {
+				t.Fatal(err, "failed to get backup")
+			}
+			if bcp.Status.State != tt.state || bcp.Status.StateDesc != tt.stateDesc {
+				t.Fatalf("expected state %s (%q), got %s (%q)", tt.state, tt.stateDesc, bcp.Status.State, bcp.Status.StateDesc)
+			}
+
+			job := new(batchv1.Job)
+			err := cl.Get(ctx, xtrabackup.JobNamespacedName(bcp), job)
+			if tt.srcNode == "" {
+				if !k8serrors.IsNotFound(err) {
+					t.Fatalf("expected backup job not to be created, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err, "failed to get backup job")
+			}
+			if srcNode, _ := backupJobEnv(job); srcNode != tt.srcNode {
+				t.Fatalf("expected SRC_NODE %s, got %s", tt.srcNode, srcNode)
+			}
+			if bcp.Status.Source != tt.srcNode {
+				t.Fatalf("expected source %s, got %s", tt.srcNode, bcp.Status.Source)
+			}
+		})
+	}
+}
+
+func TestGetEncryptionStatus(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add client-go scheme")
+	}
+	namespace := "some-namespace"
+
+	keySecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backup-key",
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"key": []byte("0123456789abcdef0123456789abcdef"),
+		},
+	}
+	spec := func(cipher apiv1alpha1.BackupEncryptionCipher, key string) *apiv1alpha1.BackupEncryptionSpec {
+		return &apiv1alpha1.BackupEncryptionSpec{
+			Cipher: cipher,
+			KeySecret: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: keySecret.Name},
+				Key:                  key,
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		spec        *apiv1alpha1.BackupEncryptionSpec
+		expectedErr string
+	}{
+		{
+			name: "valid key",
+			spec: spec(apiv1alpha1.BackupEncryptionAES256, "key"),
+		},
+		{
+			name:        "wrong key length",
+			spec:        spec(apiv1alpha1.BackupEncryptionAES128, "key"),
+			expectedErr: "AES128 requires 16 bytes long encryption key, got 32",
+		},
+		{
+			name:        "missing key",
+			spec:        spec(apiv1alpha1.BackupEncryptionAES256, "other"),
+			expectedErr: "secret backup-key has no other key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := PerconaServerMySQLBackupReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(keySecret).Build(),
+				Scheme: scheme,
+			}
+
+			status, err := r.getEncryptionStatus(ctx, namespace, tt.spec)
+			errStr := ""
+			if err != nil {
+				errStr = err.Error()
+			}
+			if errStr != tt.expectedErr {
+				t.Fatalf("expected err %q, got %q", tt.expectedErr, errStr)
+			}
+			if err != nil {
+				return
+			}
+			if fp := xtrabackup.EncryptionKeyFingerprint(keySecret.Data["key"]); status.KeyFingerprint != fp {
+				t.Fatalf("expected fingerprint %s, got %s", fp, status.KeyFingerprint)
+			}
+		})
+	}
+}
+
+func readDefaultCRBackup(name, namespace string) (*apiv1alpha1.PerconaServerMySQLBackup, error) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "..", "deploy", "backup.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	cr := &apiv1alpha1.PerconaServerMySQLBackup{}
+
+	if err := yaml.Unmarshal(data, cr); err != nil {
+		return nil, err
+	}
+
+	cr.Name = name
+	cr.Namespace = namespace
+	return cr, nil
+}
+
+func updateResource[T any](cr *T, updateFuncs ...func(cr *T)) *T {
+	for _, f := range updateFuncs {
+		f(cr)
+	}
+	return cr
+}
+
+func TestSelectBackupSource(t *testing.T) {
+	top := topology{
+		primary: "cluster1-mysql-0.cluster1-mysql.ns",
+		replicas: []string{
+			"cluster1-mysql-1.cluster1-mysql.ns",
+			"cluster1-mysql-2.cluster1-mysql.ns",
+			"cluster1-mysql-3.cluster1-mysql.ns",
+		},
+	}
+	zones := map[string]string{
+		"cluster1-mysql-1.cluster1-mysql.ns": "zone-a",
+		"cluster1-mysql-2.cluster1-mysql.ns": "zone-b",
+		"cluster1-mysql-3.cluster1-mysql.ns": "zone-b",
+	}
+	lag := map[string]int64{
+		"cluster1-mysql-1.cluster1-mysql.ns": 1,
+		"cluster1-mysql-3.cluster1-mysql.ns": 5,
+	}
+	noReplicas := topology{primary: top.primary}
+
+	tests := []struct {
+		name     string
+		policy   *apiv1alpha1.BackupSourcePolicy
+		top      topology
+		expected string
+		err      string
+	}{
+		{
+			name:     "default",
+			top:      top,
+			expected: "cluster1-mysql-1.cluster1-mysql.ns",
+		},
+		{
+			name:     "default without replicas",
+			top:      noReplicas,
+			expected: top.primary,
+		},
+		{
+			name:   "never primary",
+			policy: &apiv1alpha1.BackupSourcePolicy{NeverPrimary: true},
+			top:    noReplicas,
+			err:    "no online replicas found and backups from the primary are not allowed",
+		},
+		{
+			name:     "preferred pod",
+			policy:   &apiv1alpha1.BackupSourcePolicy{PreferredPod: "cluster1-mysql-2"},
+			top:      top,
+			expected: "cluster1-mysql-2.cluster1-mysql.ns",
+		},
+		{
+			name:     "preferred pod is not a replica",
+			policy:   &apiv1alpha1.BackupSourcePolicy{PreferredPod: "cluster1-mysql-0"},
+			top:      top,
+			expected: "cluster1-mysql-1.cluster1-mysql.ns",
+		},
+		{
+			name:     "preferred zone",
+			policy:   &apiv1alpha1.BackupSourcePolicy{PreferredZone: "zone-b"},
+			top:      top,
+			expected: "cluster1-mysql-2.cluster1-mysql.ns",
+		},
+		{
+			name:     "preferred zone without replicas",
+			policy:   &apiv1alpha1.BackupSourcePolicy{PreferredZone: "zone-c"},
+			top:      top,
+			expected: "cluster1-mysql-1.cluster1-mysql.ns",
+		},
+		{
+			name:     "least lagged",
+			policy:   &apiv1alpha1.BackupSourcePolicy{Prefer: apiv1alpha1.BackupSourcePreferLeastLagged},
+			top:      top,
+			expected: "cluster1-mysql-1.cluster1-mysql.ns",
+		},
+		{
+			name: "least lagged in zone",
+			policy: &apiv1alpha1.BackupSourcePolicy{
+				Prefer:        apiv1alpha1.BackupSourcePreferLeastLagged,
+				PreferredZone: "zone-b",
+			},
+			top:      top,
+			expected: "cluster1-mysql-3.cluster1-mysql.ns",
+		},
+		{
+			name:     "dedicated pod",
+			policy:   &apiv1alpha1.BackupSourcePolicy{DedicatedPod: "cluster1-mysql-3", Prefer: apiv1alpha1.BackupSourcePreferLeastLagged},
+			top:      top,
+			expected: "cluster1-mysql-3.cluster1-mysql.ns",
+		},
+		{
+			name:   "dedicated pod is not a replica",
+			policy: &apiv1alpha1.BackupSourcePolicy{DedicatedPod: "cluster1-mysql-0"},
+			top:    top,
+			err:    "dedicated backup pod cluster1-mysql-0 is not an online replica",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := selectBackupSource(tt.policy, tt.top, zones, lag)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if source != tt.expected {
+				t.Fatalf("expected source %s, got %s", tt.expected, source)
+			}
+		})
 	}
-	return cr
 }
@@ -0,0 +1,229 @@
+package psbackup
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/k8s"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/cloud"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
+)
+
+// errCopyPending is returned if the copy can't be started yet.
+var errCopyPending = errors.New("copy is pending")
+
+// reconcileCopies copies the succeeded backup to the storages in spec.replicateTo.
+// Each copy is made by its own job, status.copies tracks their states.
+func (r *PerconaServerMySQLBackupReconciler) reconcileCopies(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup, status *apiv1alpha1.PerconaServerMySQLBackupStatus) error {
+	var pending []string
+	for _, name := range cr.Spec.ReplicateTo {
+		c := status.Copy(name)
+		if c == nil {
+			status.Copies = append(status.Copies, apiv1alpha1.BackupCopyStatus{
+				StorageName: name,
+				State:       apiv1alpha1.BackupNew,
+			})
+		} else if c.State == apiv1alpha1.BackupSucceeded || c.State == apiv1alpha1.BackupFailed {
+			continue
+		}
+		pending = append(pending, name)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	cluster := &apiv1alpha1.PerconaServerMySQL{}
+	nn := types.NamespacedName{Name: cr.Spec.ClusterName, Namespace: cr.Namespace}
+	if err := r.Client.Get(ctx, nn, cluster); err != nil {
+		if k8serrors.IsNotFound(err) {
+			for _, name := range pending {
+				c := status.Copy(name)
+				c.State = apiv1alpha1.BackupFailed
+				c.StateDesc = fmt.Sprintf("PerconaServerMySQL %s in namespace %s is not found", nn.Name, nn.Namespace)
+			}
+			return nil
+		}
+		return errors.Wrapf(err, "get %v", nn.String())
+	}
+	if err := cluster.CheckNSetDefaults(ctx, r.ServerVersion); err != nil {
+		return errors.Wrapf(err, "check and set defaults for %v", nn.String())
+	}
+
+	for _, name := range pending {
+		if err := r.reconcileCopy(ctx, cr, cluster, status.Copy(name)); err != nil {
+			return errors.Wrapf(err, "copy backup to %s", name)
+		}
+	}
+
+	return nil
+}
+
+func (r *PerconaServerMySQLBackupReconciler) reconcileCopy(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup, cluster *apiv1alpha1.PerconaServerMySQL, c *apiv1alpha1.BackupCopyStatus) error {
+	log := logf.FromContext(ctx)
+
+	job := new(batchv1.Job)
+	nn := types.NamespacedName{Name: xtrabackup.CopyJobName(cr, c.StorageName), Namespace: cr.Namespace}
+	err := r.Client.Get(ctx, nn, job)
+	if client.IgnoreNotFound(err) != nil {
+		return errors.Wrapf(err, "get job %s", nn.Name)
+	}
+
+	if k8serrors.IsNotFound(err) {
+		job, err := r.copyJob(ctx, cr, cluster, c)
+		if errors.Is(err, errCopyPending) {
+			return nil
+		}
+		if err != nil {
+			c.State = apiv1alpha1.BackupFailed
+			c.StateDesc = err.Error()
+			return nil
+		}
+
+		log.Info("Creating backup copy job", "jobName", job.Name, "storage", c.StorageName)
+
+		if err := r.Client.Create(ctx, job); err != nil && !k8serrors.IsAlreadyExists(err) {
+			return errors.Wrapf(err, "create job %s", job.Name)
+		}
+		c.State = apiv1alpha1.BackupRunning
+		c.StateDesc = ""
+		return nil
+	}
+
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		switch cond.Type {
+		case batchv1.JobComplete:
+			c.State = apiv1alpha1.BackupSucceeded
+		case batchv1.JobFailed:
+			c.State = apiv1alpha1.BackupFailed
+			c.StateDesc = fmt.Sprintf("job %s failed: %s", job.Name, cond.Message)
+		}
+		c.CompletedAt = job.Status.CompletionTime
+	}
+
+	return nil
+}
+
+// copyJob returns the job copying the backup to the storage of c and fills the destination of the copy.
+// errCopyPending is returned if the backup is incremental and the copy of its base isn't finished yet.
+func (r *PerconaServerMySQLBackupReconciler) copyJob(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup, cluster *apiv1alpha1.PerconaServerMySQL, c *apiv1alpha1.BackupCopyStatus) (*batchv1.Job, error) {
+	if c.StorageName == cr.Spec.StorageName {
+		return nil, errors.Errorf("backup is stored in %s already", c.StorageName)
+	}
+	var target *apiv1alpha1.BackupStorageSpec
+	if cluster.Spec.Backup != nil {
+		target = cluster.Spec.Backup.Storages[c.StorageName]
+	}
+	if target == nil {
+		return nil, errors.Errorf("%s not found in spec.backup.storages in PerconaServerMySQL CustomResource", c.StorageName)
+	}
+	source := cr.Status.Storage
+	if source == nil {
+		return nil, errors.New("backup's status.storage is empty")
+	}
+	if source.Type == apiv1alpha1.BackupStorageFilesystem || target.Type == apiv1alpha1.BackupStorageFilesystem {
+		return nil, errors.New("copying backups from or to filesystem storages is not supported")
+	}
+
+	destination, err := getDestination(target, cr)
+	if err != nil {
+		return nil, err
+	}
+
+	var chain []apiv1alpha1.BackupDestination
+	if cr.Status.IsIncremental() {
+		chain, err = r.copyChain(ctx, cr, c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	initImage, err := k8s.InitImage(ctx, r.Client, cluster, cluster.Spec.Backup)
+	if err != nil {
+		return nil, errors.Wrap(err, "get operator image")
+	}
+
+	job := xtrabackup.CopyJob(cluster, cr, c.StorageName, target, destination, initImage)
+	if err := controllerutil.SetControllerReference(cr, job, r.Scheme); err != nil {
+		return nil, errors.Wrapf(err, "set controller reference to Job %s/%s", job.Namespace, job.Name)
+	}
+
+	c.Storage = target.DeepCopy()
+	c.Destination = destination
+	c.Chain = chain
+
+	return job, nil
+}
+
+// copyChain returns the chain of the incremental backup copy. The copy is restored
+// on top of the copies of the backups in the chain, so they must be in the same storage.
+func (r *PerconaServerMySQLBackupReconciler) copyChain(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup, c *apiv1alpha1.BackupCopyStatus) ([]apiv1alpha1.BackupDestination, error) {
+	base := new(apiv1alpha1.PerconaServerMySQLBackup)
+	nn := types.NamespacedName{Name: cr.Status.BaseBackupName, Namespace: cr.Namespace}
+	if err := r.Client.Get(ctx, nn, base); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, errors.Errorf("base backup %s is not found", nn.Name)
+		}
+		return nil, errors.Wrapf(err, "get base backup %s", nn.Name)
+	}
+
+	baseCopy := base.Status.Copy(c.StorageName)
+	switch {
+	case baseCopy != nil && baseCopy.State == apiv1alpha1.BackupSucceeded:
+		return append(append([]apiv1alpha1.BackupDestination{}, baseCopy.Chain...), baseCopy.Destination), nil
+	case baseCopy != nil && baseCopy.State == apiv1alpha1.BackupFailed,
+		baseCopy == nil && !slices.Contains(base.Spec.ReplicateTo, c.StorageName):
+		return nil, errors.Errorf("base backup %s has no copy in %s", base.Name, c.StorageName)
+	}
+
+	c.StateDesc = fmt.Sprintf("waiting for the copy of base backup %s", base.Name)
+	return nil, errCopyPending
+}
+
+// deleteCopies removes the copies of the backup from the storages they were made to.
+func (r *PerconaServerMySQLBackupReconciler) deleteCopies(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup) error {
+	log := logf.FromContext(ctx)
+
+	for _, c := range cr.Status.Copies {
+		if c.Destination == "" || c.Storage == nil {
+			continue
+		}
+
+		opts, err := storage.GetOptionsFromStorage(ctx, r.Client, cr.Namespace, c.Storage)
+		if err != nil {
+			return errors.Wrapf(err, "get options of storage %s", c.StorageName)
+		}
+		stg, err := r.NewStorageClient(ctx, opts)
+		if err != nil {
+			return errors.Wrapf(err, "new storage client for %s", c.StorageName)
+		}
+
+		name := c.Destination.BackupName()
+		log.Info("Deleting backup copy", "storage", c.StorageName, "destination", c.Destination)
+		if err := cloud.Delete(ctx, stg, name); err != nil {
+			return errors.Wrapf(err, "delete copy in %s", c.StorageName)
+		}
+		for _, obj := range []string{name + ".md5", xtrabackup.CheckpointsObjectName(name)} {
+			if err := stg.DeleteObject(ctx, obj); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+				return errors.Wrapf(err, "delete %s in %s", obj, c.StorageName)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package psbackup
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/hooks"
+)
+
+// runHooks runs the hooks of the stage and reports if they are finished.
+// A failed hook with the Fail policy fails the backup unless the backup is uploaded already.
+func (r *PerconaServerMySQLBackupReconciler) runHooks(
+	ctx context.Context,
+	cr *apiv1alpha1.PerconaServerMySQLBackup,
+	cluster *apiv1alpha1.PerconaServerMySQL,
+	stage apiv1alpha1.HookStage,
+	hks []apiv1alpha1.Hook,
+	status *apiv1alpha1.PerconaServerMySQLBackupStatus,
+) (bool, error) {
+	runner := &hooks.Runner{
+		Client:    r.Client,
+		Scheme:    r.Scheme,
+		ClientCmd: r.ClientCmd,
+		Cluster:   cluster,
+		Owner:     cr,
+		Env:       []corev1.EnvVar{{Name: "BACKUP_NAME", Value: cr.Name}},
+	}
+
+	done, err := runner.Run(ctx, stage, hks, &status.Hooks)
+	var failed *hooks.FailedError
+	if errors.As(err, &failed) {
+		// Only succeeded backups are deleted from the storage, so the uploaded data
+		// is kept and the failure is reported with a condition.
+		if stage == apiv1alpha1.HookStagePostBackup && status.State == apiv1alpha1.BackupSucceeded {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:    apiv1alpha1.BackupConditionPostBackupHooksFailed,
+				Status:  metav1.ConditionTrue,
+				Reason:  apiv1alpha1.BackupReasonHookFailed,
+				Message: failed.Error(),
+			})
+			status.StateDesc = ""
+			return true, nil
+		}
+		status.State = apiv1alpha1.BackupFailed
+		status.StateDesc = failed.Error()
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !done {
+		status.StateDesc = fmt.Sprintf("running %s hooks", stage)
+		return false, nil
+	}
+
+	status.StateDesc = ""
+	return true, nil
+}
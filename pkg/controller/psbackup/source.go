@@ -0,0 +1,227 @@
+package psbackup
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/db"
+	"github.com/percona/percona-server-mysql-operator/pkg/k8s"
+	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
+)
+
+// getBackupSource returns the host of the node the backup should be taken from.
+// The first replica is used if policy is nil.
+func (r *PerconaServerMySQLBackupReconciler) getBackupSource(ctx context.Context, cluster *apiv1alpha1.PerconaServerMySQL, policy *apiv1alpha1.BackupSourcePolicy) (string, error) {
+	log := logf.FromContext(ctx).WithName("getBackupSource")
+
+	operatorPass, err := k8s.UserPassword(ctx, r.Client, cluster, apiv1alpha1.UserOperator)
+	if err != nil {
+		return "", errors.Wrap(err, "get operator password")
+	}
+
+	top, err := getDBTopology(ctx, r.Client, r.ClientCmd, cluster, operatorPass)
+	if err != nil {
+		return "", errors.Wrap(err, "get topology")
+	}
+
+	var zones map[string]string
+	var lag map[string]int64
+	if policy != nil && policy.DedicatedPod == "" && len(top.replicas) > 1 {
+		if policy.PreferredZone != "" {
+			zones, err = r.replicaZones(ctx, cluster, top.replicas)
+			if err != nil {
+				return "", errors.Wrap(err, "get zones of replicas")
+			}
+		}
+		if policy.Prefer == apiv1alpha1.BackupSourcePreferLeastLagged {
+			lag, err = r.replicaLag(ctx, cluster, operatorPass, top.replicas)
+			if err != nil {
+				return "", errors.Wrap(err, "get replication lag")
+			}
+		}
+	}
+
+	source, err := selectBackupSource(policy, top, zones, lag)
+	if err != nil {
+		return "", err
+	}
+	if source == top.primary {
+		log.Info("no replicas found, using primary as the backup source", "primary", top.primary)
+	}
+
+	return source, nil
+}
+
+// getIncrementalBackupSource returns the host of the node the base of an incremental backup was taken from.
+// The incremental backup copies pages changed after the LSN of its base and LSNs are specific to
+// the InnoDB instance, so taking it from another node would silently skip changes.
+func (r *PerconaServerMySQLBackupReconciler) getIncrementalBackupSource(ctx context.Context, cluster *apiv1alpha1.PerconaServerMySQL, base *apiv1alpha1.PerconaServerMySQLBackup) (string, error) {
+	operatorPass, err := k8s.UserPassword(ctx, r.Client, cluster, apiv1alpha1.UserOperator)
+	if err != nil {
+		return "", errors.Wrap(err, "get operator password")
+	}
+
+	top, err := getDBTopology(ctx, r.Client, r.ClientCmd, cluster, operatorPass)
+	if err != nil {
+		return "", errors.Wrap(err, "get topology")
+	}
+
+	return incrementalBackupSource(cluster.Spec.Backup.SourcePolicy, base, top), nil
+}
+
+// incrementalBackupSource returns the host of the base backup source if it's an online replica.
+// The primary is returned only if it could be selected for a full backup, i.e. there are no replicas.
+// An empty string is returned if the node can't be used.
+func incrementalBackupSource(policy *apiv1alpha1.BackupSourcePolicy, base *apiv1alpha1.PerconaServerMySQLBackup, top topology) string {
+	name := hostPodName(base.Status.Source)
+	for _, host := range top.replicas {
+		if hostPodName(host) == name {
+			return host
+		}
+	}
+
+	if len(top.replicas) == 0 && hostPodName(top.primary) == name && (policy == nil || !policy.NeverPrimary) {
+		return top.primary
+	}
+
+	return ""
+}
+
+// selectBackupSource picks the node to take the backup from according to the policy.
+// zones and lag are keyed by replica hosts. Replicas with unknown lag are used last.
+func selectBackupSource(policy *apiv1alpha1.BackupSourcePolicy, top topology, zones map[string]string, lag map[string]int64) (string, error) {
+	if policy == nil {
+		policy = new(apiv1alpha1.BackupSourcePolicy)
+	}
+
+	if policy.DedicatedPod != "" {
+		for _, host := range top.replicas {
+			if hostPodName(host) == policy.DedicatedPod {
+				return host, nil
+			}
+		}
+		return "", errors.Errorf("dedicated backup pod %s is not an online replica", policy.DedicatedPod)
+	}
+
+	if len(top.replicas) == 0 {
+		if policy.NeverPrimary {
+			return "", errors.New("no online replicas found and backups from the primary are not allowed")
+		}
+		return top.primary, nil
+	}
+
+	if policy.PreferredPod != "" {
+		for _, host := range top.replicas {
+			if hostPodName(host) == policy.PreferredPod {
+				return host, nil
+			}
+		}
+	}
+
+	candidates := top.replicas
+	if policy.PreferredZone != "" {
+		var inZone []string
+		for _, host := range candidates {
+			if zones[host] == policy.PreferredZone {
+				inZone = append(inZone, host)
+			}
+		}
+		if len(inZone) > 0 {
+			candidates = inZone
+		}
+	}
+
+	if policy.Prefer == apiv1alpha1.BackupSourcePreferLeastLagged {
+		lagOf := func(host string) int64 {
+			if l, ok := lag[host]; ok {
+				return l
+			}
+			return math.MaxInt64
+		}
+		candidates = append([]string(nil), candidates...)
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return lagOf(candidates[i]) < lagOf(candidates[j])
+		})
+	}
+
+	return candidates[0], nil
+}
+
+// hostPodName returns the name of the pod from its FQDN.
+func hostPodName(host string) string {
+	name, _, _ := strings.Cut(host, ".")
+	return name
+}
+
+// replicaZones returns zones of the nodes replicas are running on.
+func (r *PerconaServerMySQLBackupReconciler) replicaZones(ctx context.Context, cluster *apiv1alpha1.PerconaServerMySQL, replicas []string) (map[string]string, error) {
+	// Nodes aren't cached, so the namespaced operator gets a permission error instead of waiting for the cache.
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+
+	zones := make(map[string]string, len(replicas))
+	for _, host := range replicas {
+		pod := new(corev1.Pod)
+		nn := types.NamespacedName{Namespace: cluster.Namespace, Name: hostPodName(host)}
+		if err := r.Client.Get(ctx, nn, pod); err != nil {
+			return nil, errors.Wrapf(err, "get pod %s", nn)
+		}
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+
+		node := new(corev1.Node)
+		if err := reader.Get(ctx, client.ObjectKey{Name: pod.Spec.NodeName}, node); err != nil {
+			return nil, errors.Wrapf(err, "get node %s", pod.Spec.NodeName)
+		}
+		zones[host] = node.Labels[corev1.LabelTopologyZone]
+	}
+
+	return zones, nil
+}
+
+// replicaLag returns the lag of the replicas. It's the number of seconds behind the primary
+// for asynchronous replication and the size of the applier queue for group replication.
+func (r *PerconaServerMySQLBackupReconciler) replicaLag(ctx context.Context, cluster *apiv1alpha1.PerconaServerMySQL, operatorPass string, replicas []string) (map[string]int64, error) {
+	log := logf.FromContext(ctx)
+
+	if cluster.Spec.MySQL.ClusterType == apiv1alpha1.ClusterTypeGR {
+		pod := new(corev1.Pod)
+		nn := types.NamespacedName{Namespace: cluster.Namespace, Name: mysql.PodName(cluster, 0)}
+		if err := r.Client.Get(ctx, nn, pod); err != nil {
+			return nil, errors.Wrapf(err, "get pod %s", nn)
+		}
+		rm := db.NewReplicationManager(pod, r.ClientCmd, apiv1alpha1.UserOperator, operatorPass, mysql.FQDN(cluster, 0))
+		return rm.GetGroupReplicationApplierQueues(ctx)
+	}
+
+	lag := make(map[string]int64, len(replicas))
+	for _, host := range replicas {
+		pod := new(corev1.Pod)
+		nn := types.NamespacedName{Namespace: cluster.Namespace, Name: hostPodName(host)}
+		if err := r.Client.Get(ctx, nn, pod); err != nil {
+			return nil, errors.Wrapf(err, "get pod %s", nn)
+		}
+
+		rm := db.NewReplicationManager(pod, r.ClientCmd, apiv1alpha1.UserOperator, operatorPass, host)
+		l, err := rm.GetHeartbeatLag(ctx)
+		if err != nil {
+			log.Error(err, "failed to get replication lag", "replica", host)
+			continue
+		}
+		lag[host] = l
+	}
+
+	return lag, nil
+}
@@ -0,0 +1,56 @@
+package psbackup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+)
+
+// startingDeadlineExceeded checks if the backup hasn't started in spec.backup.timeouts.startingDeadlineSeconds.
+func startingDeadlineExceeded(cr *apiv1alpha1.PerconaServerMySQLBackup, cluster *apiv1alpha1.PerconaServerMySQL) bool {
+	deadline := cluster.Spec.Backup.StartingDeadline()
+	return deadline > 0 && time.Since(cr.CreationTimestamp.Time) > deadline
+}
+
+// failStarting stops the backup that hasn't started in time and fails it.
+// Post-backup hooks aren't run for it.
+func (r *PerconaServerMySQLBackupReconciler) failStarting(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup, cluster *apiv1alpha1.PerconaServerMySQL, status *apiv1alpha1.PerconaServerMySQLBackupStatus) error {
+	log := logf.FromContext(ctx)
+
+	if err := r.cancelBackup(ctx, cr); err != nil {
+		return errors.Wrap(err, "cancel backup")
+	}
+
+	desc := fmt.Sprintf("backup didn't start in %s", cluster.Spec.Backup.StartingDeadline())
+	if status.StateDesc != "" {
+		desc += ": " + status.StateDesc
+	}
+	log.Info("Starting deadline exceeded", "reason", desc)
+
+	status.State = apiv1alpha1.BackupFailed
+	status.StateDesc = desc
+	return nil
+}
+
+// stopTimedOut stops the backup in the sidecar after the job exceeded its active deadline.
+// The job itself is stopped by Kubernetes, but it doesn't stop xtrabackup running on the source node.
+func (r *PerconaServerMySQLBackupReconciler) stopTimedOut(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLBackup, job *batchv1.Job, status *apiv1alpha1.PerconaServerMySQLBackupStatus) error {
+	if job.Spec.ActiveDeadlineSeconds != nil {
+		status.StateDesc = fmt.Sprintf("backup didn't finish in %ds", *job.Spec.ActiveDeadlineSeconds)
+	}
+
+	srcNode, _ := backupJobEnv(job)
+	if status.IsLogical() || srcNode == "" {
+		return nil
+	}
+	if err := r.NewSidecarClient(srcNode).CancelBackup(ctx, cr.Name); err != nil {
+		return errors.Wrap(err, "cancel backup in sidecar")
+	}
+	return nil
+}
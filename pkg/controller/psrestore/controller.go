@@ -19,6 +19,8 @@ package psrestore
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strconv"
 	"sync"
 	"time"
 
@@ -38,9 +40,12 @@ import (
 	"github.com/pkg/errors"
 
 	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/clientcmd"
 	"github.com/percona/percona-server-mysql-operator/pkg/haproxy"
+	"github.com/percona/percona-server-mysql-operator/pkg/hooks"
 	"github.com/percona/percona-server-mysql-operator/pkg/k8s"
 	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
+	"github.com/percona/percona-server-mysql-operator/pkg/naming"
 	"github.com/percona/percona-server-mysql-operator/pkg/orchestrator"
 	"github.com/percona/percona-server-mysql-operator/pkg/platform"
 	"github.com/percona/percona-server-mysql-operator/pkg/router"
@@ -53,6 +58,7 @@ type PerconaServerMySQLRestoreReconciler struct {
 	client.Client
 	Scheme           *runtime.Scheme
 	ServerVersion    *platform.ServerVersion
+	ClientCmd        clientcmd.Client
 	NewStorageClient storage.NewClientFunc
 
 	sm sync.Map
@@ -80,14 +86,15 @@ func (r *PerconaServerMySQLRestoreReconciler) Reconcile(ctx context.Context, req
 		return ctrl.Result{}, errors.Wrapf(err, "get CR %s", req.NamespacedName)
 	}
 
-	status := cr.Status
+	// Status is deep copied, so changes to hook statuses aren't made to cr.Status.
+	status := *cr.Status.DeepCopy()
 	if status.State == apiv1alpha1.RestoreError {
 		status.State = apiv1alpha1.RestoreNew
 		status.StateDesc = ""
 	}
 
 	defer func() {
-		if status.State == cr.Status.State && status.StateDesc == cr.Status.StateDesc {
+		if reflect.DeepEqual(status, cr.Status) {
 			return
 		}
 
@@ -138,6 +145,10 @@ func (r *PerconaServerMySQLRestoreReconciler) Reconcile(ctx context.Context, req
 		return ctrl.Result{}, errors.Wrapf(err, "get cluster %s", nn)
 	}
 
+	if hooks.Started(status.Hooks, apiv1alpha1.HookStagePostRestore) {
+		return r.reconcilePostRestoreHooks(ctx, cr, cluster, &status)
+	}
+
 	restoreList := &apiv1alpha1.PerconaServerMySQLRestoreList{}
 	if err := r.List(ctx, restoreList, &client.ListOptions{Namespace: cr.Namespace}); err != nil {
 		return ctrl.Result{}, errors.Wrap(err, "get restore jobs list")
@@ -150,8 +161,15 @@ func (r *PerconaServerMySQLRestoreReconciler) Reconcile(ctx context.Context, req
 		switch restore.Status.State {
 		case apiv1alpha1.RestoreSucceeded, apiv1alpha1.RestoreFailed, apiv1alpha1.RestoreError, apiv1alpha1.RestoreNew:
 		default:
+			waiting := status.State == apiv1alpha1.RestoreNew
 			status.State = apiv1alpha1.RestoreNew
 			status.StateDesc = fmt.Sprintf("PerconaServerMySQLRestore %s is already running", restore.Name)
+			if waiting && startingDeadlineExceeded(cr, cluster) {
+				if err := r.failStarting(ctx, cr, cluster, nil, false, &status); err != nil {
+					return ctrl.Result{}, errors.Wrap(err, "fail restore")
+				}
+				return ctrl.Result{}, nil
+			}
 			log.Info("PerconaServerMySQLRestore is already running", "restore", restore.Name)
 			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 		}
@@ -171,18 +189,72 @@ func (r *PerconaServerMySQLRestoreReconciler) Reconcile(ctx context.Context, req
 	}
 	defer r.sm.Delete(cr.Spec.ClusterName)
 
-	log.Info("Pausing cluster", "cluster", cluster.Name)
-	if err := r.pauseCluster(ctx, cluster); err != nil {
-		if errors.Is(err, ErrWaitingTermination) {
+	if cr.Spec.PITR != nil {
+		nn := types.NamespacedName{Name: xtrabackup.RestoreJobName(cluster, cr), Namespace: req.Namespace}
+		restored, err := r.isBaseRestored(ctx, nn)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "check base backup restore")
+		}
+		if restored {
+			return r.reconcilePITR(ctx, cr, cluster, &status)
+		}
+	}
+
+	bcp, err := getBackup(ctx, r.Client, cr, cluster)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "get backup")
+	}
+
+	// The cluster being bootstrapped from spec.mysql.initFrom waits for
+	// the restore before creating MySQL pods, so there is nothing to pause.
+	inPlace := restoresIntoRunningCluster(cr, bcp)
+	pause := !isInitFromRestore(cr) && !inPlace
+	if inPlace && cluster.Status.MySQL.State != apiv1alpha1.StateReady && status.State == apiv1alpha1.RestoreNew {
+		log.Info("Waiting for cluster to be ready", "cluster", cluster.Name)
+		status.StateDesc = "cluster is not ready"
+		if startingDeadlineExceeded(cr, cluster) {
+			if err := r.failStarting(ctx, cr, cluster, nil, false, &status); err != nil {
+				return ctrl.Result{}, errors.Wrap(err, "fail restore")
+			}
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+	if hks := cr.Spec.Hooks; hks != nil && len(hks.PreRestore) > 0 && status.State == apiv1alpha1.RestoreNew {
+		done, err := r.runHooks(ctx, cr, cluster, apiv1alpha1.HookStagePreRestore, hks.PreRestore, &status)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "run pre-restore hooks")
+		}
+		if !done {
 			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 		}
-		return ctrl.Result{}, errors.Wrap(err, "pause cluster")
+		if status.State == apiv1alpha1.RestoreFailed {
+			return ctrl.Result{}, nil
+		}
 	}
-	log.Info("Cluster paused", "cluster", cluster.Name)
 
-	if cluster.Spec.MySQL.IsGR() {
-		if err := r.removeBootstrapCondition(ctx, cluster); err != nil {
-			return ctrl.Result{}, errors.Wrap(err, "remove bootstrap condition")
+	if pause {
+		log.Info("Pausing cluster", "cluster", cluster.Name)
+		if err := r.pauseCluster(ctx, cr, cluster); err != nil {
+			if errors.Is(err, ErrWaitingTermination) {
+				// The restore job isn't created before the cluster is paused, so its data is untouched.
+				if startingDeadlineExceeded(cr, cluster) {
+					status.StateDesc = "waiting for the cluster to be paused"
+					if err := r.failStarting(ctx, cr, cluster, nil, true, &status); err != nil {
+						return ctrl.Result{}, errors.Wrap(err, "fail restore")
+					}
+					return ctrl.Result{}, nil
+				}
+				return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+			}
+			return ctrl.Result{}, errors.Wrap(err, "pause cluster")
+		}
+		log.Info("Cluster paused", "cluster", cluster.Name)
+
+		if cluster.Spec.MySQL.IsGR() {
+			if err := r.removeBootstrapCondition(ctx, cluster); err != nil {
+				return ctrl.Result{}, errors.Wrap(err, "remove bootstrap condition")
+			}
 		}
 	}
 
@@ -194,6 +266,13 @@ func (r *PerconaServerMySQLRestoreReconciler) Reconcile(ctx context.Context, req
 	}
 
 	if k8serrors.IsNotFound(err) {
+		if startingDeadlineExceeded(cr, cluster) {
+			if err := r.failStarting(ctx, cr, cluster, nil, pause, &status); err != nil {
+				return ctrl.Result{}, errors.Wrap(err, "fail restore")
+			}
+			return ctrl.Result{}, nil
+		}
+
 		log.Info("Creating restore job", "jobName", nn.Name)
 
 		restorer, err := r.getRestorer(ctx, cr, cluster)
@@ -229,24 +308,42 @@ func (r *PerconaServerMySQLRestoreReconciler) Reconcile(ctx context.Context, req
 			switch cond.Type {
 			case batchv1.JobFailed:
 				status.State = apiv1alpha1.RestoreFailed
+				if desc := deadlineExceeded(job, cond); desc != "" {
+					status.StateDesc = desc
+				}
 			case batchv1.JobComplete:
 				status.State = apiv1alpha1.RestoreSucceeded
 			}
 		}
+
+		// The job pod wasn't running yet. The cluster stays paused as after failed restores,
+		// since it can't be told if the pod has touched the data.
+		if status.State == apiv1alpha1.RestoreStarting && startingDeadlineExceeded(cr, cluster) {
+			if err := r.failStarting(ctx, cr, cluster, job, false, &status); err != nil {
+				return ctrl.Result{}, errors.Wrap(err, "fail restore")
+			}
+			return ctrl.Result{}, nil
+		}
 	case apiv1alpha1.RestoreFailed, apiv1alpha1.RestoreSucceeded:
 		return ctrl.Result{}, nil
 	default:
 		status.State = apiv1alpha1.RestoreStarting
+		status.StateDesc = ""
 	}
 
 	if status.State == apiv1alpha1.RestoreSucceeded {
-		if cluster.Spec.MySQL.IsGR() {
-			if err := r.deletePVCs(ctx, cluster); err != nil {
-				return ctrl.Result{}, errors.Wrap(err, "delete PVCs")
+		if pause {
+			if cluster.Spec.MySQL.IsGR() {
+				if err := r.deletePVCs(ctx, cluster); err != nil {
+					return ctrl.Result{}, errors.Wrap(err, "delete PVCs")
+				}
+			}
+			if err := r.unpauseCluster(ctx, cluster); err != nil {
+				return ctrl.Result{}, errors.Wrap(err, "unpause cluster")
 			}
 		}
-		if err := r.unpauseCluster(ctx, cluster); err != nil {
-			return ctrl.Result{}, errors.Wrap(err, "unpause cluster")
+		if startPostRestoreHooks(cr, &status) {
+			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 		}
 		log.Info("PerconaServerMySQLRestore is finished", "restore", cr.Name, "cluster", cluster.Name)
 	}
@@ -254,6 +351,18 @@ func (r *PerconaServerMySQLRestoreReconciler) Reconcile(ctx context.Context, req
 	return ctrl.Result{}, nil
 }
 
+// restoresIntoRunningCluster checks if the restore loads data into the running cluster
+// instead of replacing its data directory. Logical and partial restores do so.
+func restoresIntoRunningCluster(cr *apiv1alpha1.PerconaServerMySQLRestore, bcp *apiv1alpha1.PerconaServerMySQLBackup) bool {
+	return bcp.Status.IsLogical() || cr.Spec.Partial != nil
+}
+
+// isInitFromRestore checks if the restore was created by the operator to bootstrap a new cluster.
+func isInitFromRestore(cr *apiv1alpha1.PerconaServerMySQLRestore) bool {
+	_, ok := cr.Labels[naming.LabelInitFrom]
+	return ok
+}
+
 func (r *PerconaServerMySQLRestoreReconciler) deletePVCs(ctx context.Context, cluster *apiv1alpha1.PerconaServerMySQL) error {
 	log := logf.FromContext(ctx)
 
@@ -306,7 +415,9 @@ func (r *PerconaServerMySQLRestoreReconciler) removeBootstrapCondition(ctx conte
 	return err
 }
 
-func (r *PerconaServerMySQLRestoreReconciler) pauseCluster(ctx context.Context, cluster *apiv1alpha1.PerconaServerMySQL) error {
+// pauseCluster stops the cluster before its data is replaced. The history of the cluster diverges
+// after the restore, so the binlog collector is switched to a new timeline.
+func (r *PerconaServerMySQLRestoreReconciler) pauseCluster(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLRestore, cluster *apiv1alpha1.PerconaServerMySQL) error {
 	err := k8sretry.RetryOnConflict(k8sretry.DefaultRetry, func() error {
 		c := &apiv1alpha1.PerconaServerMySQL{}
 		nn := types.NamespacedName{Name: cluster.Name, Namespace: cluster.Namespace}
@@ -315,6 +426,10 @@ func (r *PerconaServerMySQLRestoreReconciler) pauseCluster(ctx context.Context,
 		}
 
 		c.Spec.Pause = true
+		if c.Annotations == nil {
+			c.Annotations = make(map[string]string)
+		}
+		c.Annotations[string(naming.AnnotationBinlogTimeline)] = strconv.FormatInt(cr.CreationTimestamp.Unix(), 10)
 
 		if err := r.Client.Patch(ctx, c, client.MergeFrom(cluster)); err != nil {
 			return err
@@ -433,6 +548,23 @@ func (r *PerconaServerMySQLRestoreReconciler) validate(ctx context.Context, cr *
 	if err := restorer.Validate(ctx); err != nil {
 		return err
 	}
+	if err := validateEncryption(ctx, r.Client, cr, bcp); err != nil {
+		return err
+	}
+	if err := validatePITR(cr, cluster); err != nil {
+		return err
+	}
+	if bcp.Status.IsLogical() {
+		if cr.Spec.PITR != nil {
+			return errors.New("point-in-time recovery is not supported for logical backups")
+		}
+		if isInitFromRestore(cr) {
+			return errors.New("logical backups can't be used to bootstrap a cluster with spec.mysql.initFrom")
+		}
+	}
+	if err := validatePartial(cr); err != nil {
+		return err
+	}
 
 	return nil
 }
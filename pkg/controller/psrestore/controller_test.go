@@ -3,20 +3,30 @@ package psrestore
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/hooks"
 	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
+	"github.com/percona/percona-server-mysql-operator/pkg/naming"
+	"github.com/percona/percona-server-mysql-operator/pkg/pitr"
 	"github.com/percona/percona-server-mysql-operator/pkg/platform"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup"
 	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
 	fakestorage "github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage/fake"
 )
@@ -430,6 +440,477 @@ func TestRestoreStatusErrStateDesc(t *testing.T) {
 	}
 }
 
+func TestInitFromRestore(t *testing.T) {
+	ctx := context.Background()
+	namespace := "some-namespace"
+
+	cluster, bcp, cr, secret := restoreFixture(namespace)
+	bcp.Spec.ClusterName = "other-cluster"
+	cr.Name = cluster.Name + "-init-from"
+	cr.Labels = map[string]string{naming.LabelInitFrom: cluster.Name}
+
+	// MySQL statefulset doesn't exist yet, so pausing the cluster would fail.
+	restore, cl := reconcileRestore(t, cr, cluster, bcp, secret)
+	if restore.Status.State == apiv1alpha1.RestoreError {
+		t.Fatalf("unexpected error state: %s", restore.Status.StateDesc)
+	}
+
+	job := new(batchv1.Job)
+	if err := cl.Get(ctx, types.NamespacedName{Name: xtrabackup.RestoreJobName(cluster, cr), Namespace: namespace}, job); err != nil {
+		t.Fatal(err, "failed to get restore job")
+	}
+
+	c := new(apiv1alpha1.PerconaServerMySQL)
+	if err := cl.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: namespace}, c); err != nil {
+		t.Fatal(err, "failed to get cluster")
+	}
+	if c.Spec.Pause {
+		t.Fatal("cluster bootstrapped from backup should not be paused")
+	}
+}
+
+func TestRestoreStartsBinlogTimeline(t *testing.T) {
+	ctx := context.Background()
+	namespace := "some-namespace"
+
+	cluster, bcp, cr, secret := restoreFixture(namespace)
+	cluster.Spec.Backup.PITR = apiv1alpha1.PITRSpec{Enabled: true, StorageName: "some-storage"}
+	cr.CreationTimestamp = metav1.NewTime(time.Unix(1700000000, 0))
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mysql.Name(cluster),
+			Namespace: namespace,
+		},
+	}
+
+	restore, cl := reconcileRestore(t, cr, cluster, bcp, secret, sts)
+	if restore.Status.State == apiv1alpha1.RestoreError {
+		t.Fatalf("unexpected error state: %s", restore.Status.StateDesc)
+	}
+
+	c := new(apiv1alpha1.PerconaServerMySQL)
+	if err := cl.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: namespace}, c); err != nil {
+		t.Fatal(err, "failed to get cluster")
+	}
+	if !c.Spec.Pause {
+		t.Fatal("cluster should be paused")
+	}
+	if timeline := c.Annotations[string(naming.AnnotationBinlogTimeline)]; timeline != "1700000000" {
+		t.Fatalf("expected binlog timeline 1700000000, got %q", timeline)
+	}
+
+	deployment, err := pitr.Deployment(c, "operator-image")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timeline := envValue(deployment.Spec.Template.Spec.Containers[0].Env, "BINLOG_TIMELINE"); timeline != "1700000000" {
+		t.Fatalf("expected binlog collector to upload to timeline 1700000000, got %q", timeline)
+	}
+}
+
+func TestLogicalRestore(t *testing.T) {
+	ctx := context.Background()
+	namespace := "some-namespace"
+	cluster, bcp, cr, secret := restoreFixture(namespace)
+	bcp.Spec.Method = apiv1alpha1.BackupMethodLogical
+	bcp.Status.Method = apiv1alpha1.BackupMethodLogical
+
+	// MySQL statefulset doesn't exist, so pausing the cluster would fail.
+	reconcile := func(t *testing.T, cluster *apiv1alpha1.PerconaServerMySQL, cr *apiv1alpha1.PerconaServerMySQLRestore) (*apiv1alpha1.PerconaServerMySQLRestore, client.Client) {
+		t.Helper()
+		return reconcileRestore(t, cr, cluster, bcp.DeepCopy(), secret.DeepCopy())
+	}
+
+	t.Run("running cluster", func(t *testing.T) {
+		restore, cl := reconcile(t, cluster.DeepCopy(), cr.DeepCopy())
+		if restore.Status.State == apiv1alpha1.RestoreError {
+			t.Fatalf("unexpected error state: %s", restore.Status.StateDesc)
+		}
+
+		job := new(batchv1.Job)
+		if err := cl.Get(ctx, types.NamespacedName{Name: xtrabackup.RestoreJobName(cluster, cr), Namespace: namespace}, job); err != nil {
+			t.Fatal(err, "failed to get restore job")
+		}
+		c := job.Spec.Template.Spec.Containers[0]
+		if c.Image != "mysql-image" || c.Command[0] != "/opt/percona/run-logical-restore.sh" {
+			t.Fatalf("unexpected restore container %+v", c)
+		}
+		hosts := envValue(c.Env, "MYSQL_HOSTS")
+		if len(strings.Fields(hosts)) != 3 {
+			t.Fatalf("expected 3 hosts in MYSQL_HOSTS, got %q", hosts)
+		}
+		for _, vol := range job.Spec.Template.Spec.Volumes {
+			if vol.PersistentVolumeClaim != nil {
+				t.Fatalf("logical restore job must not mount PVC %s", vol.PersistentVolumeClaim.ClaimName)
+			}
+		}
+
+		got := new(apiv1alpha1.PerconaServerMySQL)
+		if err := cl.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: namespace}, got); err != nil {
+			t.Fatal(err, "failed to get cluster")
+		}
+		if got.Spec.Pause {
+			t.Fatal("cluster should not be paused for logical restore")
+		}
+	})
+
+	t.Run("cluster is not ready", func(t *testing.T) {
+		notReady := cluster.DeepCopy()
+		notReady.Status.MySQL.State = apiv1alpha1.StateInitializing
+
+		restore, cl := reconcile(t, notReady, cr.DeepCopy())
+		if restore.Status.State != apiv1alpha1.RestoreNew || restore.Status.StateDesc != "cluster is not ready" {
+			t.Fatalf("unexpected status %+v", restore.Status)
+		}
+		err := cl.Get(ctx, types.NamespacedName{Name: xtrabackup.RestoreJobName(cluster, cr), Namespace: namespace}, new(batchv1.Job))
+		if !k8serrors.IsNotFound(err) {
+			t.Fatalf("expected restore job to be absent, got %v", err)
+		}
+	})
+
+	t.Run("init from", func(t *testing.T) {
+		initFrom := cr.DeepCopy()
+		initFrom.Labels = map[string]string{naming.LabelInitFrom: cluster.Name}
+
+		restore, _ := reconcile(t, cluster.DeepCopy(), initFrom)
+		expected := "logical backups can't be used to bootstrap a cluster with spec.mysql.initFrom"
+		if restore.Status.State != apiv1alpha1.RestoreError || restore.Status.StateDesc != expected {
+			t.Fatalf("expected error %q, got %+v", expected, restore.Status)
+		}
+	})
+}
+
+func TestRestoreHooks(t *testing.T) {
+	ctx := context.Background()
+	namespace := "some-namespace"
+	cluster, bcp, cr, secret := restoreFixture(namespace)
+	bcp.Spec.Method = apiv1alpha1.BackupMethodLogical
+	bcp.Status.Method = apiv1alpha1.BackupMethodLogical
+	cr.Spec.Hooks = &apiv1alpha1.RestoreHooks{
+		PreRestore:  []apiv1alpha1.Hook{{Name: "notify", Job: &apiv1alpha1.HookJobSpec{Image: "hook-image"}}},
+		PostRestore: []apiv1alpha1.Hook{{Name: "scrub", Job: &apiv1alpha1.HookJobSpec{Image: "hook-image"}}},
+	}
+
+	hookJob := func(stage apiv1alpha1.HookStage, hook *apiv1alpha1.Hook, cond batchv1.JobConditionType) *batchv1.Job {
+		job := xtrabackup.HookJob(cluster, cr.Name, stage, hook, nil)
+		if cond != "" {
+			job.Status.Conditions = []batchv1.JobCondition{{Type: cond, Status: corev1.ConditionTrue}}
+		} else {
+			job.Status.Active = 1
+		}
+		return job
+	}
+	reconcile := func(t *testing.T, cluster *apiv1alpha1.PerconaServerMySQL, cr *apiv1alpha1.PerconaServerMySQLRestore, objs ...runtime.Object) (*apiv1alpha1.PerconaServerMySQLRestore, client.Client) {
+		t.Helper()
+		return reconcileRestore(t, cr, append(objs, cluster, bcp.DeepCopy(), secret.DeepCopy())...)
+	}
+	restoreJobNN := types.NamespacedName{Name: xtrabackup.RestoreJobName(cluster, cr), Namespace: namespace}
+
+	t.Run("pre-restore hook running", func(t *testing.T) {
+		pre := hookJob(apiv1alpha1.HookStagePreRestore, &cr.Spec.Hooks.PreRestore[0], "")
+
+		restore, cl := reconcile(t, cluster.DeepCopy(), cr.DeepCopy(), pre)
+		if restore.Status.State != apiv1alpha1.RestoreNew || restore.Status.StateDesc != "running preRestore hooks" {
+			t.Fatalf("expected restore to wait for hooks, got %+v", restore.Status)
+		}
+		if err := cl.Get(ctx, restoreJobNN, new(batchv1.Job)); !k8serrors.IsNotFound(err) {
+			t.Fatalf("expected restore job not to be created, got %v", err)
+		}
+	})
+
+	t.Run("pre-restore hook failure ignored", func(t *testing.T) {
+		cr := cr.DeepCopy()
+		cr.Spec.Hooks.PreRestore[0].FailurePolicy = apiv1alpha1.HookFailurePolicyIgnore
+		pre := hookJob(apiv1alpha1.HookStagePreRestore, &cr.Spec.Hooks.PreRestore[0], batchv1.JobFailed)
+
+		restore, cl := reconcile(t, cluster.DeepCopy(), cr, pre)
+		if restore.Status.State != apiv1alpha1.RestoreNew {
+			t.Fatalf("unexpected status %+v", restore.Status)
+		}
+		if st := hooks.Status(restore.Status.Hooks, apiv1alpha1.HookStagePreRestore, "notify"); st == nil || st.State != apiv1alpha1.HookFailed {
+			t.Fatalf("expected failed hook, got %+v", st)
+		}
+		if err := cl.Get(ctx, restoreJobNN, new(batchv1.Job)); err != nil {
+			t.Fatal(err, "failed to get restore job")
+		}
+	})
+
+	restoreJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: restoreJobNN.Name, Namespace: namespace},
+		Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+		},
+	}
+	running := cr.DeepCopy()
+	running.Status.State = apiv1alpha1.RestoreRunning
+	running.Status.Hooks = []apiv1alpha1.HookStatus{{Name: "notify", Stage: apiv1alpha1.HookStagePreRestore, State: apiv1alpha1.HookSucceeded}}
+
+	t.Run("restore job completed", func(t *testing.T) {
+		restore, _ := reconcile(t, cluster.DeepCopy(), running.DeepCopy(), restoreJob.DeepCopy())
+		if restore.Status.State != apiv1alpha1.RestoreRunning {
+			t.Fatalf("expected restore to run post-restore hooks, got %+v", restore.Status)
+		}
+		if st := hooks.Status(restore.Status.Hooks, apiv1alpha1.HookStagePostRestore, "scrub"); st == nil || st.State != apiv1alpha1.HookPending {
+			t.Fatalf("expected pending hook, got %+v", st)
+		}
+	})
+
+	postStarted := running.DeepCopy()
+	postStarted.Status.Hooks = append(postStarted.Status.Hooks, apiv1alpha1.HookStatus{
+		Name: "scrub", Stage: apiv1alpha1.HookStagePostRestore, State: apiv1alpha1.HookRunning,
+	})
+
+	t.Run("cluster is not ready", func(t *testing.T) {
+		notReady := cluster.DeepCopy()
+		notReady.Status.State = apiv1alpha1.StateInitializing
+
+		restore, cl := reconcile(t, notReady, postStarted.DeepCopy(), restoreJob.DeepCopy())
+		if restore.Status.State != apiv1alpha1.RestoreRunning {
+			t.Fatalf("unexpected status %+v", restore.Status)
+		}
+		jobNN := types.NamespacedName{Name: xtrabackup.HookJobName(cr.Name, apiv1alpha1.HookStagePostRestore, "scrub"), Namespace: namespace}
+		if err := cl.Get(ctx, jobNN, new(batchv1.Job)); !k8serrors.IsNotFound(err) {
+			t.Fatalf("expected hook job not to be created, got %v", err)
+		}
+	})
+
+	t.Run("post-restore hook succeeded", func(t *testing.T) {
+		post := hookJob(apiv1alpha1.HookStagePostRestore, &cr.Spec.Hooks.PostRestore[0], batchv1.JobComplete)
+
+		restore, _ := reconcile(t, cluster.DeepCopy(), postStarted.DeepCopy(), restoreJob.DeepCopy(), post)
+		if restore.Status.State != apiv1alpha1.RestoreSucceeded || restore.Status.StateDesc != "" {
+			t.Fatalf("expected restore to succeed, got %+v", restore.Status)
+		}
+	})
+
+	t.Run("post-restore hook failed", func(t *testing.T) {
+		post := hookJob(apiv1alpha1.HookStagePostRestore, &cr.Spec.Hooks.PostRestore[0], batchv1.JobFailed)
+
+		restore, _ := reconcile(t, cluster.DeepCopy(), postStarted.DeepCopy(), restoreJob.DeepCopy(), post)
+		if restore.Status.State != apiv1alpha1.RestoreFailed || !strings.HasPrefix(restore.Status.StateDesc, "postRestore hook scrub failed") {
+			t.Fatalf("expected restore to fail, got %+v", restore.Status)
+		}
+	})
+}
+
+func TestRestoreTimeouts(t *testing.T) {
+	ctx := context.Background()
+	namespace := "some-namespace"
+	startingDeadline, activeDeadline := int64(60), int64(3600)
+
+	cluster, bcp, cr, secret := restoreFixture(namespace)
+	cluster.Spec.Backup.Timeouts = &apiv1alpha1.BackupTimeouts{
+		StartingDeadlineSeconds: &startingDeadline,
+		ActiveDeadlineSeconds:   &activeDeadline,
+	}
+	bcp.Spec.Method = apiv1alpha1.BackupMethodLogical
+	bcp.Status.Method = apiv1alpha1.BackupMethodLogical
+	cr.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+
+	restoreJobNN := types.NamespacedName{Name: xtrabackup.RestoreJobName(cluster, cr), Namespace: namespace}
+	restoreJob := func(status batchv1.JobStatus) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: restoreJobNN.Name, Namespace: namespace},
+			Spec:       batchv1.JobSpec{ActiveDeadlineSeconds: &activeDeadline},
+			Status:     status,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		cluster   *apiv1alpha1.PerconaServerMySQL
+		cr        *apiv1alpha1.PerconaServerMySQLRestore
+		objs      []runtime.Object
+		state     apiv1alpha1.RestoreState
+		stateDesc string
+		jobExists bool
+	}{
+		{
+			name: "cluster is not ready",
+			cluster: updateResource(cluster.DeepCopy(), func(cluster *apiv1alpha1.PerconaServerMySQL) {
+				cluster.Status.MySQL.State = apiv1alpha1.StateInitializing
+			}),
+			cr:        cr.DeepCopy(),
+			state:     apiv1alpha1.RestoreFailed,
+			stateDesc: "restore didn't start in 1m0s: cluster is not ready",
+		},
+		{
+			name:    "waiting for another restore",
+			cluster: cluster.DeepCopy(),
+			cr:      cr.DeepCopy(),
+			objs: []runtime.Object{&apiv1alpha1.PerconaServerMySQLRestore{
+				ObjectMeta: metav1.ObjectMeta{Name: "restore2", Namespace: namespace},
+				Spec:       apiv1alpha1.PerconaServerMySQLRestoreSpec{ClusterName: cluster.Name, BackupName: bcp.Name},
+				Status:     apiv1alpha1.PerconaServerMySQLRestoreStatus{State: apiv1alpha1.RestoreRunning},
+			}},
+			state:     apiv1alpha1.RestoreFailed,
+			stateDesc: "restore didn't start in 1m0s: PerconaServerMySQLRestore restore2 is already running",
+		},
+		{
+			name:    "pod is pending",
+			cluster: cluster.DeepCopy(),
+			cr: updateResource(cr.DeepCopy(), func(cr *apiv1alpha1.PerconaServerMySQLRestore) {
+				cr.Status.State = apiv1alpha1.RestoreStarting
+			}),
+			objs:      []runtime.Object{restoreJob(batchv1.JobStatus{})},
+			state:     apiv1alpha1.RestoreFailed,
+			stateDesc: "restore didn't start in 1m0s",
+		},
+		{
+			name:    "starting deadline not exceeded",
+			cluster: cluster.DeepCopy(),
+			cr: updateResource(cr.DeepCopy(), func(cr *apiv1alpha1.PerconaServerMySQLRestore) {
+				cr.CreationTimestamp = metav1.Now()
+			}),
+			state:     apiv1alpha1.RestoreNew,
+			jobExists: true,
+		},
+		{
+			name:    "active deadline exceeded",
+			cluster: cluster.DeepCopy(),
+			cr: updateResource(cr.DeepCopy(), func(cr *apiv1alpha1.PerconaServerMySQLRestore) {
+				cr.Status.State = apiv1alpha1.RestoreRunning
+			}),
+			objs: []runtime.Object{restoreJob(batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{{
+					Type:   batchv1.JobFailed,
+					Status: corev1.ConditionTrue,
+					Reason: batchv1.JobReasonDeadlineExceeded,
+				}},
+			})},
+			state:     apiv1alpha1.RestoreFailed,
+			stateDesc: "restore didn't finish in 3600s",
+			jobExists: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restore, cl := reconcileRestore(t, tt.cr, append(tt.objs, tt.cluster, bcp.DeepCopy(), secret.DeepCopy())...)
+			if restore.Status.State != tt.state || restore.Status.StateDesc != tt.stateDesc {
+				t.Fatalf("expected state %s (%q), got %s (%q)", tt.state, tt.stateDesc, restore.Status.State, restore.Status.StateDesc)
+			}
+
+			job := new(batchv1.Job)
+			err := cl.Get(ctx, restoreJobNN, job)
+			if !tt.jobExists {
+				if !k8serrors.IsNotFound(err) {
+					t.Fatalf("expected restore job not to exist, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err, "failed to get restore job")
+			}
+			if job.Spec.ActiveDeadlineSeconds == nil || *job.Spec.ActiveDeadlineSeconds != activeDeadline {
+				t.Fatalf("expected active deadline %d, got %v", activeDeadline, job.Spec.ActiveDeadlineSeconds)
+			}
+		})
+	}
+}
+
+func TestPartialRestore(t *testing.T) {
+	ctx := context.Background()
+	namespace := "some-namespace"
+
+	cluster, bcp, cr, secret := restoreFixture(namespace)
+	cr.Spec.Partial = &apiv1alpha1.PartialRestoreSpec{
+		IncludeTables: []string{"shop.orders", "shop.customers"},
+	}
+
+	// MySQL statefulset doesn't exist, so pausing the cluster would fail.
+	restore, cl := reconcileRestore(t, cr, cluster, bcp, secret)
+	if restore.Status.State == apiv1alpha1.RestoreError {
+		t.Fatalf("unexpected error state: %s", restore.Status.StateDesc)
+	}
+
+	job := new(batchv1.Job)
+	if err := cl.Get(ctx, types.NamespacedName{Name: xtrabackup.RestoreJobName(cluster, cr), Namespace: namespace}, job); err != nil {
+		t.Fatal(err, "failed to get restore job")
+	}
+	spec := job.Spec.Template.Spec
+	if len(spec.InitContainers) != 2 || len(spec.Containers) != 1 {
+		t.Fatalf("expected 2 init containers and 1 container, got %d and %d", len(spec.InitContainers), len(spec.Containers))
+	}
+	xb := spec.InitContainers[1]
+	if xb.Image != "xtrabackup-image" || !hasEnv(xb.Env, "S3_BUCKET") {
+		t.Fatalf("unexpected xtrabackup container %+v", xb)
+	}
+	c := spec.Containers[0]
+	if c.Image != "mysql-image" || c.Command[0] != "/opt/percona/run-partial-restore.sh" {
+		t.Fatalf("unexpected restore container %+v", c)
+	}
+	if v := envValue(c.Env, "INCLUDE_TABLES"); v != "shop.orders,shop.customers" {
+		t.Fatalf("expected INCLUDE_TABLES to be set, got %q", v)
+	}
+	for _, vol := range spec.Volumes {
+		if vol.PersistentVolumeClaim != nil {
+			t.Fatalf("partial restore job must not mount PVC %s", vol.PersistentVolumeClaim.ClaimName)
+		}
+	}
+
+	got := new(apiv1alpha1.PerconaServerMySQL)
+	if err := cl.Get(ctx, types.NamespacedName{Name: cluster.Name, Namespace: namespace}, got); err != nil {
+		t.Fatal(err, "failed to get cluster")
+	}
+	if got.Spec.Pause {
+		t.Fatal("cluster should not be paused for partial restore")
+	}
+}
+
+func TestValidatePartial(t *testing.T) {
+	tests := []struct {
+		name        string
+		partial     *apiv1alpha1.PartialRestoreSpec
+		pitr        *apiv1alpha1.RestorePITRSpec
+		expectedErr string
+	}{
+		{
+			name: "without partial",
+		},
+		{
+			name:    "databases",
+			partial: &apiv1alpha1.PartialRestoreSpec{IncludeDatabases: []string{"shop"}, ExcludeTables: []string{"shop.logs"}},
+		},
+		{
+			name:        "empty",
+			partial:     &apiv1alpha1.PartialRestoreSpec{},
+			expectedErr: "spec.partial has no databases or tables to include or exclude",
+		},
+		{
+			name:        "table without database",
+			partial:     &apiv1alpha1.PartialRestoreSpec{IncludeTables: []string{"orders"}},
+			expectedErr: `table "orders" in spec.partial is not in the database.table format`,
+		},
+		{
+			name:    "pitr",
+			partial: &apiv1alpha1.PartialRestoreSpec{IncludeDatabases: []string{"shop"}},
+			pitr: &apiv1alpha1.RestorePITRSpec{
+				Type: apiv1alpha1.PITRGTID,
+				GTID: "3e11fa47-71ca-11e1-9e33-c80aa9429562:23",
+			},
+			expectedErr: "point-in-time recovery is not supported for partial restores",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := readDefaultRestore(t, "test-restore", "namespace")
+			cr.Spec.Partial = tt.partial
+			cr.Spec.PITR = tt.pitr
+
+			err := validatePartial(cr)
+			errStr := ""
+			if err != nil {
+				errStr = err.Error()
+			}
+			if errStr != tt.expectedErr {
+				t.Fatalf("expected error %q, got %q", tt.expectedErr, errStr)
+			}
+		})
+	}
+}
+
 func TestRestorerValidate(t *testing.T) {
 	ctx := context.Background()
 
@@ -740,6 +1221,178 @@ func TestRestorerValidate(t *testing.T) {
 	}
 }
 
+func TestRestoreFromCopy(t *testing.T) {
+	ctx := context.Background()
+
+	const namespace = "namespace"
+	const s3SecretName = "my-cluster-name-backup-s3"
+
+	cluster := readDefaultCluster(t, "test-cluster", namespace)
+	cluster.Spec.Backup.Storages["s3-us-west"].S3.CredentialsSecret = s3SecretName
+	cluster.Spec.Backup.Storages["s3-us-west"].S3.Bucket = "primary"
+	offsite := &apiv1alpha1.BackupStorageSpec{
+		Type: apiv1alpha1.BackupStorageS3,
+		S3: &apiv1alpha1.BackupStorageS3Spec{
+			Bucket:            "offsite",
+			CredentialsSecret: s3SecretName,
+		},
+	}
+	cluster.Spec.Backup.Storages["offsite"] = offsite
+	if err := cluster.CheckNSetDefaults(ctx, new(platform.ServerVersion)); err != nil {
+		t.Fatal(err)
+	}
+
+	bcp := readDefaultBackup(t, "test-backup", namespace)
+	bcp.Spec.StorageName = "s3-us-west"
+	bcp.Status.State = apiv1alpha1.BackupSucceeded
+	bcp.Status.Storage = cluster.Spec.Backup.Storages["s3-us-west"]
+	bcp.Status.Destination.SetS3Destination("primary", "backup")
+	bcp.Status.Chain = []apiv1alpha1.BackupDestination{"s3://primary/full"}
+	bcp.Status.Copies = []apiv1alpha1.BackupCopyStatus{
+		{StorageName: "failed", State: apiv1alpha1.BackupFailed},
+		{
+			StorageName: "offsite",
+			State:       apiv1alpha1.BackupSucceeded,
+			Storage:     offsite,
+			Destination: "s3://offsite/backup",
+			Chain:       []apiv1alpha1.BackupDestination{"s3://offsite/full"},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		unreachable   []string
+		expectedDest  apiv1alpha1.BackupDestination
+		expectedChain []apiv1alpha1.BackupDestination
+		expectedErr   string
+	}{
+		{
+			name:          "backup is reachable",
+			expectedDest:  bcp.Status.Destination,
+			expectedChain: bcp.Status.Chain,
+		},
+		{
+			name:          "backup is not reachable",
+			unreachable:   []string{"primary"},
+			expectedDest:  "s3://offsite/backup",
+			expectedChain: []apiv1alpha1.BackupDestination{"s3://offsite/full"},
+		},
+		{
+			name:        "copies are not reachable",
+			unreachable: []string{"primary", "offsite"},
+			expectedErr: "backup and its copies are not reachable: s3-us-west: backup not found; offsite: backup not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := buildFakeClient(t, readDefaultS3Secret(t, s3SecretName, namespace))
+			r := reconciler(cl)
+			r.NewStorageClient = func(_ context.Context, opts storage.Options) (storage.Storage, error) {
+				for _, bucket := range tt.unreachable {
+					if opts.(*storage.S3Options).BucketName == bucket {
+						return &fakeStorageClient{emptyListObjects: true}, nil
+					}
+				}
+				return new(fakeStorageClient), nil
+			}
+
+			restored, err := r.reachableBackup(ctx, cluster, bcp.DeepCopy())
+			if tt.expectedErr != "" {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Fatalf("expected error %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if restored.Status.Destination != tt.expectedDest {
+				t.Fatalf("expected destination %s, got %s", tt.expectedDest, restored.Status.Destination)
+			}
+			if !reflect.DeepEqual(restored.Status.Chain, tt.expectedChain) {
+				t.Fatalf("expected chain %v, got %v", tt.expectedChain, restored.Status.Chain)
+			}
+		})
+	}
+}
+
+func TestValidatePITR(t *testing.T) {
+	cluster := readDefaultCluster(t, "test-cluster", "namespace")
+	cluster.Spec.Backup.PITR.StorageName = "s3-us-west"
+
+	cr := readDefaultRestore(t, "test-restore", "namespace")
+
+	tests := []struct {
+		name        string
+		pitr        *apiv1alpha1.RestorePITRSpec
+		storageName string
+		expectedErr string
+	}{
+		{
+			name: "without pitr",
+		},
+		{
+			name: "date",
+			pitr: &apiv1alpha1.RestorePITRSpec{
+				Type: apiv1alpha1.PITRDate,
+				Date: "2024-01-01 12:00:00",
+			},
+		},
+		{
+			name: "invalid date",
+			pitr: &apiv1alpha1.RestorePITRSpec{
+				Type: apiv1alpha1.PITRDate,
+				Date: "2024-01-01T12:00:00Z",
+			},
+			expectedErr: `pitr.date "2024-01-01T12:00:00Z" should be in "2006-01-02 15:04:05" format`,
+		},
+		{
+			name: "gtid",
+			pitr: &apiv1alpha1.RestorePITRSpec{
+				Type: apiv1alpha1.PITRGTID,
+				GTID: "3e11fa47-71ca-11e1-9e33-c80aa9429562:23",
+			},
+		},
+		{
+			name: "empty gtid",
+			pitr: &apiv1alpha1.RestorePITRSpec{
+				Type: apiv1alpha1.PITRGTID,
+			},
+			expectedErr: "pitr.gtid is empty",
+		},
+		{
+			name: "unknown storage",
+			pitr: &apiv1alpha1.RestorePITRSpec{
+				Type: apiv1alpha1.PITRGTID,
+				GTID: "3e11fa47-71ca-11e1-9e33-c80aa9429562:23",
+			},
+			storageName: "unknown",
+			expectedErr: "pitr storage unknown not found in spec.backup.storages in PerconaServerMySQL CustomResource",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := cr.DeepCopy()
+			cr.Spec.PITR = tt.pitr
+
+			cluster := cluster.DeepCopy()
+			if tt.storageName != "" {
+				cluster.Spec.Backup.PITR.StorageName = tt.storageName
+			}
+
+			err := validatePITR(cr, cluster)
+			errStr := ""
+			if err != nil {
+				errStr = err.Error()
+			}
+			if errStr != tt.expectedErr {
+				t.Fatal("expected err:", tt.expectedErr, "; got:", errStr)
+			}
+		})
+	}
+}
+
 type fakeStorageClient struct {
 	storage.Storage
 	failListObjects  bool
@@ -755,3 +1408,79 @@ func (c *fakeStorageClient) ListObjects(_ context.Context, _ string) ([]string,
 	}
 	return []string{"some-dest/backup1", "some-dest/backup2"}, nil
 }
+
+func TestValidateEncryption(t *testing.T) {
+	ctx := context.Background()
+	namespace := "namespace"
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	rotatedKey := []byte("fedcba9876543210fedcba9876543210")
+
+	secret := func(name string, key []byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Data: map[string][]byte{"key": key},
+		}
+	}
+	keySelector := func(name string) *corev1.SecretKeySelector {
+		return &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			Key:                  "key",
+		}
+	}
+
+	bcp := readDefaultBackup(t, "backup1", namespace)
+	bcp.Status.Encryption = &apiv1alpha1.BackupEncryptionStatus{
+		Cipher:         apiv1alpha1.BackupEncryptionAES256,
+		KeySecret:      keySelector("backup-key"),
+		KeyFingerprint: xtrabackup.EncryptionKeyFingerprint(key),
+	}
+
+	tests := []struct {
+		name        string
+		keySecret   *corev1.SecretKeySelector
+		objects     []runtime.Object
+		expectedErr string
+	}{
+		{
+			name:    "key from backup status",
+			objects: []runtime.Object{secret("backup-key", key)},
+		},
+		{
+			name:    "rotated key",
+			objects: []runtime.Object{secret("backup-key", rotatedKey)},
+			expectedErr: fmt.Sprintf("backup is encrypted with key %s, but secret backup-key contains key %s: set spec.encryptionKeySecret to the secret with the previous key",
+				xtrabackup.EncryptionKeyFingerprint(key), xtrabackup.EncryptionKeyFingerprint(rotatedKey)),
+		},
+		{
+			name:      "previous key in restore spec",
+			keySecret: keySelector("previous-key"),
+			objects:   []runtime.Object{secret("backup-key", rotatedKey), secret("previous-key", key)},
+		},
+		{
+			name:        "missing secret",
+			expectedErr: "encryption key secret backup-key not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := readDefaultRestore(t, "test-restore", namespace)
+			cr.Spec.EncryptionKeySecret = tt.keySecret
+
+			cl := buildFakeClient(t, tt.objects...)
+
+			err := validateEncryption(ctx, cl, cr, bcp)
+			errStr := ""
+			if err != nil {
+				errStr = err.Error()
+			}
+			if errStr != tt.expectedErr {
+				t.Fatalf("expected err %q, got %q", tt.expectedErr, errStr)
+			}
+		})
+	}
+}
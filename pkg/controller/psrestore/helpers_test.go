@@ -1,6 +1,7 @@
 package psrestore
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -8,12 +9,15 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	controllerruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
 	fakestorage "github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage/fake"
 )
 
@@ -151,3 +155,125 @@ func reconciler(cl client.Client) *PerconaServerMySQLRestoreReconciler {
 		NewStorageClient: fakestorage.NewFakeClient,
 	}
 }
+
+// restoreFixture returns the ready cluster1 with the S3 storage some-storage, the succeeded backup1
+// of the cluster on the storage, restore1 of the backup and the credentials secret of the storage.
+func restoreFixture(namespace string) (*apiv1alpha1.PerconaServerMySQL, *apiv1alpha1.PerconaServerMySQLBackup, *apiv1alpha1.PerconaServerMySQLRestore, *corev1.Secret) {
+	clusterName := "cluster1"
+	storageName := "some-storage"
+
+	cluster := &apiv1alpha1.PerconaServerMySQL{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: namespace,
+		},
+		Spec: apiv1alpha1.PerconaServerMySQLSpec{
+			MySQL: apiv1alpha1.MySQLSpec{
+				PodSpec: apiv1alpha1.PodSpec{
+					Size: 3,
+					ContainerSpec: apiv1alpha1.ContainerSpec{
+						Image: "mysql-image",
+					},
+				},
+			},
+			Backup: &apiv1alpha1.BackupSpec{
+				Image: "xtrabackup-image",
+				Storages: map[string]*apiv1alpha1.BackupStorageSpec{
+					storageName: {
+						S3: &apiv1alpha1.BackupStorageS3Spec{
+							Bucket:            "some-bucket",
+							CredentialsSecret: "aws-secret",
+						},
+						Type: apiv1alpha1.BackupStorageS3,
+					},
+				},
+				InitImage: "operator-image",
+			},
+		},
+		Status: apiv1alpha1.PerconaServerMySQLStatus{
+			MySQL: apiv1alpha1.StatefulAppStatus{
+				State: apiv1alpha1.StateReady,
+			},
+			State: apiv1alpha1.StateReady,
+		},
+	}
+	bcp := &apiv1alpha1.PerconaServerMySQLBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backup1",
+			Namespace: namespace,
+		},
+		Spec: apiv1alpha1.PerconaServerMySQLBackupSpec{
+			ClusterName: clusterName,
+			StorageName: storageName,
+		},
+		Status: apiv1alpha1.PerconaServerMySQLBackupStatus{
+			State:       apiv1alpha1.BackupSucceeded,
+			Destination: "s3://some-bucket/backup1",
+		},
+	}
+	cr := &apiv1alpha1.PerconaServerMySQLRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "restore1",
+			Namespace: namespace,
+		},
+		Spec: apiv1alpha1.PerconaServerMySQLRestoreSpec{
+			ClusterName: clusterName,
+			BackupName:  bcp.Name,
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "aws-secret",
+			Namespace: namespace,
+		},
+	}
+
+	return cluster, bcp, cr, secret
+}
+
+// reconcileRestore reconciles the restore once with the objects in the fake client
+// and returns the updated restore and the client.
+func reconcileRestore(t *testing.T, cr *apiv1alpha1.PerconaServerMySQLRestore, objs ...runtime.Object) (*apiv1alpha1.PerconaServerMySQLRestore, client.Client) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	cl := buildFakeClient(t, append(objs, cr)...)
+	r := reconciler(cl)
+	r.NewStorageClient = func(_ context.Context, opts storage.Options) (storage.Storage, error) {
+		defaultFakeClient, err := fakestorage.NewFakeClient(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &fakeStorageClient{Storage: defaultFakeClient}, nil
+	}
+
+	nn := types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}
+	if _, err := r.Reconcile(ctx, controllerruntime.Request{NamespacedName: nn}); err != nil {
+		t.Fatal(err, "failed to reconcile")
+	}
+
+	restore := new(apiv1alpha1.PerconaServerMySQLRestore)
+	if err := cl.Get(ctx, nn, restore); err != nil {
+		t.Fatal(err, "failed to get restore")
+	}
+	return restore, cl
+}
+
+func hasEnv(env []corev1.EnvVar, name string) bool {
+	for _, e := range env {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func envValue(env []corev1.EnvVar, name string) string {
+	for _, e := range env {
+		if e.Name == name {
+			return e.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package psrestore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/hooks"
+)
+
+// runHooks runs the hooks of the stage and reports if they are finished.
+// A failed hook with the Fail policy fails the restore.
+func (r *PerconaServerMySQLRestoreReconciler) runHooks(
+	ctx context.Context,
+	cr *apiv1alpha1.PerconaServerMySQLRestore,
+	cluster *apiv1alpha1.PerconaServerMySQL,
+	stage apiv1alpha1.HookStage,
+	hks []apiv1alpha1.Hook,
+	status *apiv1alpha1.PerconaServerMySQLRestoreStatus,
+) (bool, error) {
+	runner := &hooks.Runner{
+		Client:    r.Client,
+		Scheme:    r.Scheme,
+		ClientCmd: r.ClientCmd,
+		Cluster:   cluster,
+		Owner:     cr,
+		Env:       []corev1.EnvVar{{Name: "RESTORE_NAME", Value: cr.Name}},
+	}
+
+	done, err := runner.Run(ctx, stage, hks, &status.Hooks)
+	var failed *hooks.FailedError
+	if errors.As(err, &failed) {
+		status.State = apiv1alpha1.RestoreFailed
+		status.StateDesc = failed.Error()
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !done {
+		status.StateDesc = fmt.Sprintf("running %s hooks", stage)
+		return false, nil
+	}
+
+	status.StateDesc = ""
+	return true, nil
+}
+
+// startPostRestoreHooks keeps the restore running if it has post-restore hooks.
+// Their statuses are initialized, so the next reconciliations only run the hooks.
+func startPostRestoreHooks(cr *apiv1alpha1.PerconaServerMySQLRestore, status *apiv1alpha1.PerconaServerMySQLRestoreStatus) bool {
+	if cr.Spec.Hooks == nil || len(cr.Spec.Hooks.PostRestore) == 0 {
+		return false
+	}
+
+	status.State = apiv1alpha1.RestoreRunning
+	status.StateDesc = "waiting for the cluster to be ready to run postRestore hooks"
+	hooks.Pending(apiv1alpha1.HookStagePostRestore, cr.Spec.Hooks.PostRestore, &status.Hooks)
+	return true
+}
+
+// reconcilePostRestoreHooks runs post-restore hooks once the cluster is ready.
+// The restore succeeds after they finish.
+func (r *PerconaServerMySQLRestoreReconciler) reconcilePostRestoreHooks(
+	ctx context.Context,
+	cr *apiv1alpha1.PerconaServerMySQLRestore,
+	cluster *apiv1alpha1.PerconaServerMySQL,
+	status *apiv1alpha1.PerconaServerMySQLRestoreStatus,
+) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	if cluster.Status.State != apiv1alpha1.StateReady {
+		log.V(1).Info("Waiting for cluster to be ready", "cluster", cluster.Name, "state", cluster.Status.State)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	var hks []apiv1alpha1.Hook
+	if cr.Spec.Hooks != nil {
+		hks = cr.Spec.Hooks.PostRestore
+	}
+	done, err := r.runHooks(ctx, cr, cluster, apiv1alpha1.HookStagePostRestore, hks, status)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "run post-restore hooks")
+	}
+	if !done {
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
+	if status.State != apiv1alpha1.RestoreFailed {
+		status.State = apiv1alpha1.RestoreSucceeded
+		log.Info("PerconaServerMySQLRestore is finished", "restore", cr.Name, "cluster", cluster.Name)
+	}
+
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,35 @@
+package psrestore
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+)
+
+// validatePartial checks that spec.partial selects some objects and can be applied to the running cluster.
+func validatePartial(cr *apiv1alpha1.PerconaServerMySQLRestore) error {
+	partial := cr.Spec.Partial
+	if partial == nil {
+		return nil
+	}
+
+	if cr.Spec.PITR != nil {
+		return errors.New("point-in-time recovery is not supported for partial restores")
+	}
+	if isInitFromRestore(cr) {
+		return errors.New("partial restore can't be used to bootstrap a cluster with spec.mysql.initFrom")
+	}
+	if len(partial.IncludeDatabases)+len(partial.ExcludeDatabases)+len(partial.IncludeTables)+len(partial.ExcludeTables) == 0 {
+		return errors.New("spec.partial has no databases or tables to include or exclude")
+	}
+	for _, table := range append(append([]string{}, partial.IncludeTables...), partial.ExcludeTables...) {
+		db, name, ok := strings.Cut(table, ".")
+		if !ok || db == "" || name == "" {
+			return errors.Errorf("table %q in spec.partial is not in the database.table format", table)
+		}
+	}
+
+	return nil
+}
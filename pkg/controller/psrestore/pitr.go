@@ -0,0 +1,156 @@
+package psrestore
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/k8s"
+	"github.com/percona/percona-server-mysql-operator/pkg/pitr"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup"
+)
+
+func validatePITR(cr *apiv1alpha1.PerconaServerMySQLRestore, cluster *apiv1alpha1.PerconaServerMySQL) error {
+	spec := cr.Spec.PITR
+	if spec == nil {
+		return nil
+	}
+
+	storageName := cluster.Spec.Backup.PITR.StorageName
+	if _, ok := cluster.Spec.Backup.Storages[storageName]; !ok {
+		return errors.Errorf("pitr storage %s not found in spec.backup.storages in PerconaServerMySQL CustomResource", storageName)
+	}
+
+	switch spec.Type {
+	case apiv1alpha1.PITRDate:
+		if _, err := time.Parse(apiv1alpha1.PITRDateFormat, spec.Date); err != nil {
+			return errors.Errorf("pitr.date %q should be in %q format", spec.Date, apiv1alpha1.PITRDateFormat)
+		}
+	case apiv1alpha1.PITRGTID:
+		if spec.GTID == "" {
+			return errors.New("pitr.gtid is empty")
+		}
+	default:
+		return errors.Errorf("unknown pitr.type %q", spec.Type)
+	}
+
+	return nil
+}
+
+// isBaseRestored returns true if the restore job of the base backup is completed.
+func (r *PerconaServerMySQLRestoreReconciler) isBaseRestored(ctx context.Context, nn types.NamespacedName) (bool, error) {
+	job := &batchv1.Job{}
+	if err := r.Client.Get(ctx, nn, job); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobComplete && cond.Status == corev1.ConditionTrue {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// reconcilePITR starts the cluster restored from the base backup and applies binary logs on top of it.
+func (r *PerconaServerMySQLRestoreReconciler) reconcilePITR(
+	ctx context.Context,
+	cr *apiv1alpha1.PerconaServerMySQLRestore,
+	cluster *apiv1alpha1.PerconaServerMySQL,
+	status *apiv1alpha1.PerconaServerMySQLRestoreStatus,
+) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	status.State = apiv1alpha1.RestoreRunning
+
+	if cluster.Spec.Pause {
+		if cluster.Spec.MySQL.IsGR() {
+			if err := r.deletePVCs(ctx, cluster); err != nil {
+				return ctrl.Result{}, errors.Wrap(err, "delete PVCs")
+			}
+		}
+		if err := r.unpauseCluster(ctx, cluster); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "unpause cluster")
+		}
+		log.Info("Base backup is restored, starting cluster to apply binary logs", "cluster", cluster.Name)
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
+	if cluster.Status.State != apiv1alpha1.StateReady {
+		log.V(1).Info("Waiting for cluster to be ready", "cluster", cluster.Name, "state", cluster.Status.State)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	job := &batchv1.Job{}
+	nn := types.NamespacedName{Name: pitr.RestoreJobName(cr), Namespace: cr.Namespace}
+	err := r.Client.Get(ctx, nn, job)
+	if client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "get job %s", nn)
+	}
+
+	if k8serrors.IsNotFound(err) {
+		bcp, err := getBackup(ctx, r.Client, cr, cluster)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "get backup")
+		}
+
+		storage := cluster.Spec.Backup.Storages[cluster.Spec.Backup.PITR.StorageName]
+
+		initImage, err := k8s.InitImage(ctx, r.Client, cluster, &cluster.Spec.MySQL.PodSpec)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "get init image")
+		}
+
+		job, err := pitr.RestoreJob(cluster, cr, storage, bcp.Spec.ClusterName, initImage)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "get pitr job")
+		}
+		if timeouts := cluster.Spec.Backup.Timeouts; timeouts != nil {
+			xtrabackup.SetTimeouts(job, timeouts)
+		}
+		if err := controllerutil.SetControllerReference(cr, job, r.Scheme); err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "set controller reference to Job %s/%s", job.Namespace, job.Name)
+		}
+
+		log.Info("Creating pitr job", "jobName", job.Name)
+		if err := r.Create(ctx, job); err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "create job %s/%s", job.Namespace, job.Name)
+		}
+
+		return ctrl.Result{}, nil
+	}
+
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		switch cond.Type {
+		case batchv1.JobFailed:
+			status.State = apiv1alpha1.RestoreFailed
+			status.StateDesc = "failed to apply binary logs"
+			if desc := deadlineExceeded(job, cond); desc != "" {
+				status.StateDesc += ": " + desc
+			}
+		case batchv1.JobComplete:
+			status.State = apiv1alpha1.RestoreSucceeded
+			if startPostRestoreHooks(cr, status) {
+				return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+			}
+			log.Info("PerconaServerMySQLRestore is finished", "restore", cr.Name, "cluster", cluster.Name)
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
@@ -15,6 +15,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
 	"github.com/percona/percona-server-mysql-operator/pkg/k8s"
@@ -78,6 +79,12 @@ type gcs struct {
 }
 
 func (g *gcs) Validate(ctx context.Context) error {
+	if storage := g.bcp.Status.Storage; storage != nil && storage.GCS != nil && storage.GCS.CredentialsSecret == "" {
+		// The job accesses the storage with the service account of its pod,
+		// the operator may have no access to it.
+		return nil
+	}
+
 	job, err := g.Job()
 	if err != nil {
 		return errors.Wrap(err, "get job")
@@ -115,6 +122,11 @@ type azure struct {
 }
 
 func (a *azure) Validate(ctx context.Context) error {
+	if storage := a.bcp.Status.Storage; storage != nil && storage.Azure != nil && storage.Azure.CredentialsSecret == "" {
+		// Skip validation if the storage is accessed with the managed identity of the job pod.
+		return nil
+	}
+
 	job, err := a.Job()
 	if err != nil {
 		return errors.Wrap(err, "get job")
@@ -147,6 +159,49 @@ func (a *azure) Job() (*batchv1.Job, error) {
 
 var _ Restorer = new(azure)
 
+type filesystem struct {
+	*restorerOptions
+}
+
+func (f *filesystem) Validate(ctx context.Context) error {
+	if _, err := f.Job(); err != nil {
+		return errors.Wrap(err, "get job")
+	}
+
+	pvcName := f.pvcName()
+	nn := types.NamespacedName{Name: pvcName, Namespace: f.cr.Namespace}
+	exists, err := k8s.ObjectExists(ctx, f.k8sClient, nn, new(corev1.PersistentVolumeClaim))
+	if err != nil {
+		return errors.Wrapf(err, "check PVC %s exists", pvcName)
+	}
+	if !exists {
+		return errors.Errorf("failed to validate storage: PVC %s not found", pvcName)
+	}
+
+	return nil
+}
+
+func (f *filesystem) Job() (*batchv1.Job, error) {
+	job, err := f.job()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := xtrabackup.SetStoragePVC(job, f.pvcName()); err != nil {
+		return nil, errors.Wrap(err, "set storage PVC")
+	}
+
+	return job, nil
+}
+
+// pvcName returns the name of the PVC the backup is stored on.
+func (f *filesystem) pvcName() string {
+	pvcName, _ := f.bcp.Status.Destination.BucketAndPrefix()
+	return pvcName
+}
+
+var _ Restorer = new(filesystem)
+
 type restorerOptions struct {
 	cluster          *apiv1alpha1.PerconaServerMySQL
 	bcp              *apiv1alpha1.PerconaServerMySQLBackup
@@ -170,6 +225,10 @@ func (r *PerconaServerMySQLRestoreReconciler) getRestorer(
 	if err != nil {
 		return nil, errors.Wrap(err, "get backup")
 	}
+	bcp, err = r.reachableBackup(ctx, cluster, bcp)
+	if err != nil {
+		return nil, err
+	}
 	s := restorerOptions{
 		cr:               cr,
 		bcp:              bcp,
@@ -189,14 +248,97 @@ func (r *PerconaServerMySQLRestoreReconciler) getRestorer(
 	case apiv1alpha1.BackupStorageAzure:
 		sr := azure{&s}
 		return &sr, nil
+	case apiv1alpha1.BackupStorageFilesystem:
+		sr := filesystem{&s}
+		return &sr, nil
 	}
 	return nil, errors.Errorf("unknown backup storage type")
 }
 
+// reachableBackup returns the backup if it's found in its storage. Otherwise the backup
+// is restored from the first succeeded copy which is found in the storage it was made to.
+func (r *PerconaServerMySQLRestoreReconciler) reachableBackup(ctx context.Context, cluster *apiv1alpha1.PerconaServerMySQL, bcp *apiv1alpha1.PerconaServerMySQLBackup) (*apiv1alpha1.PerconaServerMySQLBackup, error) {
+	log := logf.FromContext(ctx)
+
+	var copies []*apiv1alpha1.PerconaServerMySQLBackup
+	for _, c := range bcp.Status.Copies {
+		if c.State != apiv1alpha1.BackupSucceeded || c.Storage == nil {
+			continue
+		}
+		cp := bcp.DeepCopy()
+		cp.Spec.StorageName = c.StorageName
+		cp.Status.Storage = c.Storage
+		if stg, ok := cluster.Spec.Backup.Storages[c.StorageName]; ok {
+			cp.Status.Storage = stg
+		}
+		cp.Status.Destination = c.Destination
+		cp.Status.Chain = c.Chain
+		cp.Status.Copies = nil
+		copies = append(copies, cp)
+	}
+	if len(copies) == 0 || r.NewStorageClient == nil {
+		return bcp, nil
+	}
+
+	reachable := func(bcp *apiv1alpha1.PerconaServerMySQLBackup) error {
+		opts := restorerOptions{
+			cluster:          cluster,
+			bcp:              bcp,
+			k8sClient:        r.Client,
+			newStorageClient: r.NewStorageClient,
+		}
+		return opts.validateStorage(ctx)
+	}
+
+	err := reachable(bcp)
+	if err == nil {
+		return bcp, nil
+	}
+	errs := []string{fmt.Sprintf("%s: %v", bcp.Spec.StorageName, err)}
+	for _, cp := range copies {
+		if err := reachable(cp); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", cp.Spec.StorageName, err))
+			continue
+		}
+		log.Info("Backup is not reachable in its storage, restoring from copy", "storage", cp.Spec.StorageName, "destination", cp.Status.Destination)
+		return cp, nil
+	}
+
+	return nil, errors.Errorf("backup and its copies are not reachable: %s", strings.Join(errs, "; "))
+}
+
 func (s *restorerOptions) job() (*batchv1.Job, error) {
 	pvcName := fmt.Sprintf("%s-%s-mysql-0", mysql.DataVolumeName, s.cluster.Name)
 	storage := s.bcp.Status.Storage
-	job := xtrabackup.RestoreJob(s.cluster, s.bcp.Status.Destination, s.cr, storage, s.initImage, pvcName)
+	var job *batchv1.Job
+	switch {
+	case s.bcp.Status.IsLogical():
+		var err error
+		job, err = xtrabackup.LogicalRestoreJob(s.cluster, s.bcp.Status.Destination, s.cr, storage, s.initImage)
+		if err != nil {
+			return nil, errors.Wrap(err, "logical restore job")
+		}
+	case s.cr.Spec.Partial != nil:
+		job = xtrabackup.PartialRestoreJob(s.cluster, s.bcp.Status.Destination, s.cr, storage, s.initImage)
+	default:
+		job = xtrabackup.RestoreJob(s.cluster, s.bcp.Status.Destination, s.cr, storage, s.initImage, pvcName)
+	}
+	if s.bcp.Status.IsIncremental() {
+		if len(s.bcp.Status.Chain) == 0 {
+			return nil, errors.New("incremental backup has no base backups in status.chain")
+		}
+		if err := xtrabackup.SetBackupChain(job, s.bcp.Status.Chain); err != nil {
+			return nil, errors.Wrap(err, "set backup chain")
+		}
+	}
+	if enc := s.bcp.Status.Encryption; enc != nil {
+		if err := xtrabackup.SetEncryption(job, enc.Cipher, encryptionKeySecret(s.cr, s.bcp)); err != nil {
+			return nil, errors.Wrap(err, "set encryption")
+		}
+	}
+	if backup := s.cluster.Spec.Backup; backup != nil && backup.Timeouts != nil {
+		xtrabackup.SetTimeouts(job, backup.Timeouts)
+	}
 	if err := controllerutil.SetControllerReference(s.cr, job, s.scheme); err != nil {
 		return nil, errors.Wrapf(err, "set controller reference to Job %s/%s", job.Namespace, job.Name)
 	}
@@ -263,6 +405,46 @@ func (opts *restorerOptions) validateJob(ctx context.Context, job *batchv1.Job)
 	return nil
 }
 
+// encryptionKeySecret returns the secret key an encrypted backup is decrypted with.
+func encryptionKeySecret(cr *apiv1alpha1.PerconaServerMySQLRestore, bcp *apiv1alpha1.PerconaServerMySQLBackup) *corev1.SecretKeySelector {
+	if cr.Spec.EncryptionKeySecret != nil {
+		return cr.Spec.EncryptionKeySecret
+	}
+	return bcp.Status.Encryption.KeySecret
+}
+
+// validateEncryption checks that the key backup is decrypted with is the one it was encrypted with.
+func validateEncryption(ctx context.Context, cl client.Client, cr *apiv1alpha1.PerconaServerMySQLRestore, bcp *apiv1alpha1.PerconaServerMySQLBackup) error {
+	enc := bcp.Status.Encryption
+	if enc == nil {
+		return nil
+	}
+
+	keySecret := encryptionKeySecret(cr, bcp)
+	if keySecret == nil {
+		return errors.New("backup is encrypted but encryption key secret is not set")
+	}
+
+	s := new(corev1.Secret)
+	nn := types.NamespacedName{Name: keySecret.Name, Namespace: cr.Namespace}
+	if err := cl.Get(ctx, nn, s); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return errors.Errorf("encryption key secret %s not found", nn.Name)
+		}
+		return errors.Wrapf(err, "get secret %s", nn.Name)
+	}
+
+	key, ok := s.Data[keySecret.Key]
+	if !ok {
+		return errors.Errorf("secret %s has no %s key", nn.Name, keySecret.Key)
+	}
+	if fp := xtrabackup.EncryptionKeyFingerprint(key); fp != enc.KeyFingerprint {
+		return errors.Errorf("backup is encrypted with key %s, but secret %s contains key %s: set spec.encryptionKeySecret to the secret with the previous key", enc.KeyFingerprint, nn.Name, fp)
+	}
+
+	return nil
+}
+
 func getBackup(ctx context.Context, cl client.Client, cr *apiv1alpha1.PerconaServerMySQLRestore, cluster *apiv1alpha1.PerconaServerMySQL) (*apiv1alpha1.PerconaServerMySQLBackup, error) {
 	if cr.Spec.BackupSource != nil {
 		status := cr.Spec.BackupSource.DeepCopy()
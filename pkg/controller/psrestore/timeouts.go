@@ -0,0 +1,64 @@
+package psrestore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+)
+
+// startingDeadlineExceeded checks if the restore hasn't started in spec.backup.timeouts.startingDeadlineSeconds of the cluster.
+func startingDeadlineExceeded(cr *apiv1alpha1.PerconaServerMySQLRestore, cluster *apiv1alpha1.PerconaServerMySQL) bool {
+	deadline := cluster.Spec.Backup.StartingDeadline()
+	return deadline > 0 && time.Since(cr.CreationTimestamp.Time) > deadline
+}
+
+// failStarting fails the restore that hasn't started in time. The restore job is deleted if it exists.
+// The cluster is unpaused only if unpause is set, i.e. the data of the cluster is known to be untouched.
+func (r *PerconaServerMySQLRestoreReconciler) failStarting(
+	ctx context.Context,
+	cr *apiv1alpha1.PerconaServerMySQLRestore,
+	cluster *apiv1alpha1.PerconaServerMySQL,
+	job *batchv1.Job,
+	unpause bool,
+	status *apiv1alpha1.PerconaServerMySQLRestoreStatus,
+) error {
+	log := logf.FromContext(ctx)
+
+	if job != nil {
+		if err := r.Client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			return errors.Wrapf(err, "delete job %s", job.Name)
+		}
+	}
+	if unpause {
+		if err := r.unpauseCluster(ctx, cluster); err != nil {
+			return errors.Wrap(err, "unpause cluster")
+		}
+	}
+
+	desc := fmt.Sprintf("restore didn't start in %s", cluster.Spec.Backup.StartingDeadline())
+	if status.StateDesc != "" {
+		desc += ": " + status.StateDesc
+	}
+	log.Info("Starting deadline exceeded", "reason", desc)
+
+	status.State = apiv1alpha1.RestoreFailed
+	status.StateDesc = desc
+	return nil
+}
+
+// deadlineExceeded returns the description of the restore job stopped by Kubernetes
+// after its active deadline. It's empty if the job failed for another reason.
+func deadlineExceeded(job *batchv1.Job, cond batchv1.JobCondition) string {
+	if cond.Reason != batchv1.JobReasonDeadlineExceeded || job.Spec.ActiveDeadlineSeconds == nil {
+		return ""
+	}
+	return fmt.Sprintf("restore didn't finish in %ds", *job.Spec.ActiveDeadlineSeconds)
+}
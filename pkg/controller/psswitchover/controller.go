@@ -0,0 +1,399 @@
+package psswitchover
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sretry "k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/clientcmd"
+	database "github.com/percona/percona-server-mysql-operator/pkg/db"
+	"github.com/percona/percona-server-mysql-operator/pkg/k8s"
+	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
+	"github.com/percona/percona-server-mysql-operator/pkg/mysqlsh"
+	"github.com/percona/percona-server-mysql-operator/pkg/naming"
+	"github.com/percona/percona-server-mysql-operator/pkg/orchestrator"
+)
+
+const requeueInterval = 5 * time.Second
+
+// PerconaServerMySQLSwitchoverReconciler reconciles a PerconaServerMySQLSwitchover object
+type PerconaServerMySQLSwitchoverReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	ClientCmd clientcmd.Client
+}
+
+//+kubebuilder:rbac:groups=ps.percona.com,resources=perconaservermysqlswitchovers;perconaservermysqlswitchovers/status;perconaservermysqlswitchovers/finalizers,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile makes spec.targetInstance the primary of the cluster. The switchover waits
+// for the cluster to be ready and for the target to catch up with the primary first.
+func (r *PerconaServerMySQLSwitchoverReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx).WithName("PerconaServerMySQLSwitchover").WithValues("name", req.Name, "namespace", req.Namespace)
+	ctx = logf.IntoContext(ctx, log)
+
+	cr := &apiv1alpha1.PerconaServerMySQLSwitchover{}
+	if err := r.Client.Get(ctx, req.NamespacedName, cr); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, errors.Wrapf(err, "get CR %s", req.NamespacedName)
+	}
+
+	status := *cr.Status.DeepCopy()
+	defer func() {
+		if reflect.DeepEqual(status, cr.Status) {
+			return
+		}
+
+		err := k8sretry.RetryOnConflict(k8sretry.DefaultRetry, func() error {
+			cr := &apiv1alpha1.PerconaServerMySQLSwitchover{}
+			if err := r.Client.Get(ctx, req.NamespacedName, cr); err != nil {
+				return errors.Wrapf(err, "get %v", req.NamespacedName.String())
+			}
+
+			cr.Status = status
+			return r.Client.Status().Update(ctx, cr)
+		})
+		if err != nil {
+			log.Error(err, "failed to update status")
+			return
+		}
+		log.Info("Status updated", "state", status.State, "description", status.StateDesc)
+	}()
+
+	switch status.State {
+	case apiv1alpha1.SwitchoverFailed, apiv1alpha1.SwitchoverSucceeded:
+		return ctrl.Result{}, nil
+	}
+
+	cluster := &apiv1alpha1.PerconaServerMySQL{}
+	nn := types.NamespacedName{Name: cr.Spec.ClusterName, Namespace: cr.Namespace}
+	if err := r.Client.Get(ctx, nn, cluster); err != nil {
+		if k8serrors.IsNotFound(err) {
+			status.State = apiv1alpha1.SwitchoverFailed
+			status.StateDesc = fmt.Sprintf("PerconaServerMySQL %s in namespace %s is not found", cr.Spec.ClusterName, cr.Namespace)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, errors.Wrapf(err, "get cluster %s", nn)
+	}
+
+	// Errors of the MySQL and Orchestrator pods are usually transient, e.g. while a pod restarts,
+	// so they are reported in the status and retried until the switchover times out.
+	target, err := r.targetPod(ctx, cr, cluster)
+	if err != nil {
+		return wait(ctx, cr, &status, fmt.Sprintf("failed to get pod %s: %s", cr.Spec.TargetInstance, err))
+	}
+	if target == nil {
+		status.State = apiv1alpha1.SwitchoverFailed
+		status.StateDesc = fmt.Sprintf("%s is not a MySQL pod of %s", cr.Spec.TargetInstance, cluster.Name)
+		return ctrl.Result{}, nil
+	}
+
+	if status.State == apiv1alpha1.SwitchoverRunning {
+		return r.checkPromoted(ctx, cr, cluster, target, &status)
+	}
+
+	running, err := r.runningSwitchover(ctx, cr)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "get running switchover")
+	}
+	if running != "" {
+		return wait(ctx, cr, &status, fmt.Sprintf("PerconaServerMySQLSwitchover %s is already running", running))
+	}
+	if cluster.Status.MySQL.State != apiv1alpha1.StateReady {
+		return wait(ctx, cr, &status, "cluster is not ready")
+	}
+	if !k8s.IsPodReady(*target) {
+		return wait(ctx, cr, &status, fmt.Sprintf("pod %s is not ready", target.Name))
+	}
+
+	operatorPass, err := k8s.UserPassword(ctx, r.Client, cluster, apiv1alpha1.UserOperator)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "get operator password")
+	}
+
+	primary, err := r.primary(ctx, cluster, target, operatorPass)
+	if err != nil {
+		return wait(ctx, cr, &status, fmt.Sprintf("failed to get primary: %s", err))
+	}
+	if primary == target.Name {
+		if err := r.updatePrimaryLabel(ctx, cluster, target.Name); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "update primary label")
+		}
+		status.State = apiv1alpha1.SwitchoverSucceeded
+		status.StateDesc = fmt.Sprintf("%s is already the primary", target.Name)
+		status.PreviousPrimary = primary
+		status.CompletedAt = &metav1.Time{Time: time.Now()}
+		return ctrl.Result{}, nil
+	}
+
+	if reason, err := r.notCaughtUpReason(ctx, cr, cluster, target, operatorPass); err != nil {
+		return wait(ctx, cr, &status, fmt.Sprintf("failed to check lag of %s: %s", target.Name, err))
+	} else if reason != "" {
+		return wait(ctx, cr, &status, reason)
+	}
+
+	log.Info("Switching primary", "from", primary, "to", target.Name)
+
+	status.State = apiv1alpha1.SwitchoverRunning
+	status.StateDesc = ""
+	status.PreviousPrimary = primary
+	status.StartedAt = &metav1.Time{Time: time.Now()}
+
+	if err := r.switchPrimary(ctx, cluster, target, operatorPass); err != nil {
+		log.Error(err, "failed to switch primary")
+		status.State = apiv1alpha1.SwitchoverFailed
+		status.StateDesc = err.Error()
+		return ctrl.Result{}, nil
+	}
+
+	return r.checkPromoted(ctx, cr, cluster, target, &status)
+}
+
+// checkPromoted completes the switchover once the target is the primary and moves
+// the primary label to its pod.
+func (r *PerconaServerMySQLSwitchoverReconciler) checkPromoted(
+	ctx context.Context,
+	cr *apiv1alpha1.PerconaServerMySQLSwitchover,
+	cluster *apiv1alpha1.PerconaServerMySQL,
+	target *corev1.Pod,
+	status *apiv1alpha1.PerconaServerMySQLSwitchoverStatus,
+) (ctrl.Result, error) {
+	operatorPass, err := k8s.UserPassword(ctx, r.Client, cluster, apiv1alpha1.UserOperator)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "get operator password")
+	}
+
+	primary, err := r.primary(ctx, cluster, target, operatorPass)
+	if err != nil {
+		return wait(ctx, cr, status, fmt.Sprintf("failed to get primary: %s", err))
+	}
+	if primary != target.Name {
+		return wait(ctx, cr, status, fmt.Sprintf("waiting for %s to become the primary, the primary is %s", target.Name, primary))
+	}
+
+	if err := r.updatePrimaryLabel(ctx, cluster, target.Name); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "update primary label")
+	}
+
+	logf.FromContext(ctx).Info("Primary switched", "from", status.PreviousPrimary, "to", target.Name)
+
+	status.State = apiv1alpha1.SwitchoverSucceeded
+	status.StateDesc = ""
+	status.CompletedAt = &metav1.Time{Time: time.Now()}
+	return ctrl.Result{}, nil
+}
+
+// wait requeues the switchover until it times out. The timeout of a pending switchover counts
+// from its creation and the timeout of a running one from its start.
+func wait(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLSwitchover, status *apiv1alpha1.PerconaServerMySQLSwitchoverStatus, desc string) (ctrl.Result, error) {
+	timeout := cr.Spec.TimeoutDuration()
+
+	if status.State == apiv1alpha1.SwitchoverRunning {
+		if status.StartedAt != nil && time.Since(status.StartedAt.Time) > timeout {
+			status.State = apiv1alpha1.SwitchoverFailed
+			status.StateDesc = fmt.Sprintf("switchover didn't complete in %s: %s", timeout, desc)
+			return ctrl.Result{}, nil
+		}
+	} else {
+		if time.Since(cr.CreationTimestamp.Time) > timeout {
+			status.State = apiv1alpha1.SwitchoverFailed
+			status.StateDesc = fmt.Sprintf("switchover didn't start in %s: %s", timeout, desc)
+			return ctrl.Result{}, nil
+		}
+		status.State = apiv1alpha1.SwitchoverPending
+	}
+
+	logf.FromContext(ctx).Info("Waiting to switch over", "reason", desc)
+	status.StateDesc = desc
+	return ctrl.Result{RequeueAfter: requeueInterval}, nil
+}
+
+// targetPod returns the pod of spec.targetInstance. It's nil if the pod doesn't belong to the cluster.
+func (r *PerconaServerMySQLSwitchoverReconciler) targetPod(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLSwitchover, cluster *apiv1alpha1.PerconaServerMySQL) (*corev1.Pod, error) {
+	pod := new(corev1.Pod)
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: cr.Spec.TargetInstance, Namespace: cr.Namespace}, pod); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	for k, v := range mysql.MatchLabels(cluster) {
+		if pod.Labels[k] != v {
+			return nil, nil
+		}
+	}
+	return pod, nil
+}
+
+// runningSwitchover returns the name of another switchover of the same cluster that is running.
+func (r *PerconaServerMySQLSwitchoverReconciler) runningSwitchover(ctx context.Context, cr *apiv1alpha1.PerconaServerMySQLSwitchover) (string, error) {
+	list := new(apiv1alpha1.PerconaServerMySQLSwitchoverList)
+	if err := r.Client.List(ctx, list, client.InNamespace(cr.Namespace)); err != nil {
+		return "", err
+	}
+
+	for _, sw := range list.Items {
+		if sw.Name == cr.Name || sw.Spec.ClusterName != cr.Spec.ClusterName {
+			continue
+		}
+		if sw.Status.State == apiv1alpha1.SwitchoverRunning {
+			return sw.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// primary returns the name of the primary pod. Group replication members are queried through the target.
+func (r *PerconaServerMySQLSwitchoverReconciler) primary(ctx context.Context, cluster *apiv1alpha1.PerconaServerMySQL, target *corev1.Pod, operatorPass string) (string, error) {
+	if cluster.Spec.MySQL.IsGR() {
+		rm := database.NewReplicationManager(target, r.ClientCmd, apiv1alpha1.UserOperator, operatorPass, mysql.PodFQDN(cluster, target))
+		host, err := rm.GetGroupReplicationPrimary(ctx)
+		if err != nil {
+			return "", err
+		}
+		name, _, _ := strings.Cut(host, ".")
+		return name, nil
+	}
+
+	orcPod, err := r.orcPod(ctx, cluster)
+	if err != nil {
+		return "", err
+	}
+	primary, err := orchestrator.ClusterPrimary(ctx, r.ClientCmd, orcPod, cluster.ClusterHint())
+	if err != nil {
+		return "", err
+	}
+	return primary.Alias, nil
+}
+
+// notCaughtUpReason returns the reason the target can't be promoted yet or an empty string.
+func (r *PerconaServerMySQLSwitchoverReconciler) notCaughtUpReason(
+	ctx context.Context,
+	cr *apiv1alpha1.PerconaServerMySQLSwitchover,
+	cluster *apiv1alpha1.PerconaServerMySQL,
+	target *corev1.Pod,
+	operatorPass string,
+) (string, error) {
+	host := mysql.PodFQDN(cluster, target)
+	rm := database.NewReplicationManager(target, r.ClientCmd, apiv1alpha1.UserOperator, operatorPass, host)
+
+	var lag int64
+	if cluster.Spec.MySQL.IsGR() {
+		state, err := rm.GetMemberState(ctx, host)
+		if err != nil {
+			return "", errors.Wrap(err, "get member state")
+		}
+		if state != database.MemberStateOnline {
+			return fmt.Sprintf("%s is %s", target.Name, state), nil
+		}
+
+		queues, err := rm.GetGroupReplicationApplierQueues(ctx)
+		if err != nil {
+			return "", errors.Wrap(err, "get applier queues")
+		}
+		lag = queues[host]
+	} else {
+		replStatus, _, err := rm.ReplicationStatus(ctx)
+		if err != nil {
+			return "", errors.Wrap(err, "get replication status")
+		}
+		if replStatus != database.ReplicationStatusActive {
+			return fmt.Sprintf("replication is not running on %s", target.Name), nil
+		}
+
+		lag, err = rm.GetHeartbeatLag(ctx)
+		if err != nil {
+			return "", errors.Wrap(err, "get replication lag")
+		}
+	}
+
+	if lag > cr.Spec.MaxLag() {
+		return fmt.Sprintf("replication lag %d of %s is above %d", lag, target.Name, cr.Spec.MaxLag()), nil
+	}
+	return "", nil
+}
+
+// switchPrimary makes the target the primary with MySQL Shell for group replication
+// and with a graceful takeover by Orchestrator for asynchronous replication.
+func (r *PerconaServerMySQLSwitchoverReconciler) switchPrimary(ctx context.Context, cluster *apiv1alpha1.PerconaServerMySQL, target *corev1.Pod, operatorPass string) error {
+	if cluster.Spec.MySQL.IsGR() {
+		host := mysql.PodFQDN(cluster, target)
+		uri := fmt.Sprintf("%s:%s@%s", apiv1alpha1.UserOperator, operatorPass, host)
+
+		mysh, err := mysqlsh.NewWithExec(r.ClientCmd, target, uri)
+		if err != nil {
+			return err
+		}
+		return mysh.SetPrimaryInstanceWithExec(ctx, cluster.InnoDBClusterName(), host)
+	}
+
+	orcPod, err := r.orcPod(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	return orchestrator.EnsureNodeIsPrimary(ctx, r.ClientCmd, orcPod, cluster.ClusterHint(), target.Name, mysql.DefaultPort)
+}
+
+func (r *PerconaServerMySQLSwitchoverReconciler) orcPod(ctx context.Context, cluster *apiv1alpha1.PerconaServerMySQL) (*corev1.Pod, error) {
+	pods, err := k8s.PodsByLabels(ctx, r.Client, orchestrator.MatchLabels(cluster), cluster.Namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "get orchestrator pods")
+	}
+	for i := range pods {
+		if k8s.IsPodReady(pods[i]) {
+			return &pods[i], nil
+		}
+	}
+	return nil, errors.New("no ready orchestrator pods")
+}
+
+// updatePrimaryLabel sets the primary label on the pod of the primary and removes it from the other pods.
+func (r *PerconaServerMySQLSwitchoverReconciler) updatePrimaryLabel(ctx context.Context, cluster *apiv1alpha1.PerconaServerMySQL, primary string) error {
+	pods, err := k8s.PodsByLabels(ctx, r.Client, mysql.MatchLabels(cluster), cluster.Namespace)
+	if err != nil {
+		return errors.Wrap(err, "get MySQL pods")
+	}
+
+	for i := range pods {
+		isPrimary := pods[i].Name == primary
+		if (pods[i].Labels[naming.LabelMySQLPrimary] == "true") == isPrimary {
+			continue
+		}
+
+		pod := pods[i].DeepCopy()
+		if isPrimary {
+			k8s.AddLabel(pod, naming.LabelMySQLPrimary, "true")
+		} else {
+			k8s.RemoveLabel(pod, naming.LabelMySQLPrimary)
+		}
+		if err := r.Client.Patch(ctx, pod, client.StrategicMergeFrom(&pods[i])); err != nil {
+			return errors.Wrapf(err, "patch pod %s", pod.Name)
+		}
+	}
+
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PerconaServerMySQLSwitchoverReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&apiv1alpha1.PerconaServerMySQLSwitchover{}).
+		Named("psswitchover-controller").
+		Complete(r)
+}
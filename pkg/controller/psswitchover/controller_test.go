@@ -0,0 +1,264 @@
+package psswitchover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	restclient "k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
+	"github.com/percona/percona-server-mysql-operator/pkg/naming"
+	"github.com/percona/percona-server-mysql-operator/pkg/orchestrator"
+)
+
+// fakeExec models the primary of the cluster for the mysql, mysqlsh and orchestrator commands.
+type fakeExec struct {
+	primary  string
+	lag      int64
+	switched bool
+	failing  bool
+}
+
+var (
+	setPrimaryRe = regexp.MustCompile(`setPrimaryInstance\('([^.']+)`)
+	takeoverRe   = regexp.MustCompile(`graceful-master-takeover-auto/[^/]+/([^/]+)/`)
+)
+
+func (e *fakeExec) Exec(_ context.Context, _ *corev1.Pod, _ string, command []string, _ io.Reader, stdout, _ io.Writer, _ bool) error {
+	cmd := strings.Join(command, " ")
+	if e.failing {
+		return errors.New("connection refused")
+	}
+
+	switch {
+	case setPrimaryRe.MatchString(cmd):
+		e.primary = setPrimaryRe.FindStringSubmatch(cmd)[1]
+		e.switched = true
+	case takeoverRe.MatchString(cmd):
+		e.primary = takeoverRe.FindStringSubmatch(cmd)[1]
+		e.switched = true
+		fmt.Fprint(stdout, `{"Code":"OK"}`)
+	case strings.Contains(cmd, "api/master/"):
+		fmt.Fprintf(stdout, `{"InstanceAlias":"%s"}`, e.primary)
+	case strings.Contains(cmd, "MEMBER_ROLE='PRIMARY'"):
+		fmt.Fprintf(stdout, "host\n%s.cluster1-mysql.ns\n", e.primary)
+	case strings.Contains(cmd, "MEMBER_STATE as state"):
+		fmt.Fprint(stdout, "state\nONLINE\n")
+	case strings.Contains(cmd, "COUNT_TRANSACTIONS_REMOTE_IN_APPLIER_QUEUE"):
+		fmt.Fprintf(stdout, "host\tqueue\ncluster1-mysql-1.cluster1-mysql.ns\t%d\n", e.lag)
+	case strings.Contains(cmd, "conn_state"):
+		fmt.Fprint(stdout, "conn_state\tapplier_state\thost\nON\tON\tcluster1-mysql-0.cluster1-mysql.ns\n")
+	case strings.Contains(cmd, "heartbeat"):
+		fmt.Fprintf(stdout, "lag\n%d\n", e.lag)
+	}
+	return nil
+}
+
+func (e *fakeExec) REST() restclient.Interface {
+	return nil
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	cluster := func(clusterType apiv1alpha1.ClusterType) *apiv1alpha1.PerconaServerMySQL {
+		cr := &apiv1alpha1.PerconaServerMySQL{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Namespace: "ns"},
+			Spec: apiv1alpha1.PerconaServerMySQLSpec{
+				MySQL: apiv1alpha1.MySQLSpec{ClusterType: clusterType},
+			},
+		}
+		cr.Status.MySQL.State = apiv1alpha1.StateReady
+		return cr
+	}
+	readyPod := func(name string, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Labels: labels},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.ContainersReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+	objects := func(cluster *apiv1alpha1.PerconaServerMySQL, sw *apiv1alpha1.PerconaServerMySQLSwitchover) []client.Object {
+		primaryLabels := mysql.MatchLabels(cluster)
+		primaryLabels[naming.LabelMySQLPrimary] = "true"
+		return []client.Object{
+			cluster,
+			sw,
+			readyPod("cluster1-mysql-0", primaryLabels),
+			readyPod("cluster1-mysql-1", mysql.MatchLabels(cluster)),
+			readyPod("cluster1-orc-0", orchestrator.MatchLabels(cluster)),
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: cluster.InternalSecretName(), Namespace: "ns"},
+				Data:       map[string][]byte{string(apiv1alpha1.UserOperator): []byte("operator-pass")},
+			},
+		}
+	}
+	switchover := func(target string, created time.Time) *apiv1alpha1.PerconaServerMySQLSwitchover {
+		return &apiv1alpha1.PerconaServerMySQLSwitchover{
+			ObjectMeta: metav1.ObjectMeta{Name: "switchover1", Namespace: "ns", CreationTimestamp: metav1.NewTime(created)},
+			Spec:       apiv1alpha1.PerconaServerMySQLSwitchoverSpec{ClusterName: "cluster1", TargetInstance: target},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		clusterType apiv1alpha1.ClusterType
+		target      string
+		lag         int64
+		created     time.Time
+		startedAt   time.Time
+		failing     bool
+		state       apiv1alpha1.SwitchoverState
+		desc        string
+		switched    bool
+	}{
+		{
+			name:        "group replication",
+			clusterType: apiv1alpha1.ClusterTypeGR,
+			target:      "cluster1-mysql-1",
+			state:       apiv1alpha1.SwitchoverSucceeded,
+			switched:    true,
+		},
+		{
+			name:        "async",
+			clusterType: apiv1alpha1.ClusterTypeAsync,
+			target:      "cluster1-mysql-1",
+			state:       apiv1alpha1.SwitchoverSucceeded,
+			switched:    true,
+		},
+		{
+			name:        "lag",
+			clusterType: apiv1alpha1.ClusterTypeGR,
+			target:      "cluster1-mysql-1",
+			lag:         100,
+			state:       apiv1alpha1.SwitchoverPending,
+			desc:        "replication lag 100 of cluster1-mysql-1 is above 10",
+		},
+		{
+			name:        "lag timeout",
+			clusterType: apiv1alpha1.ClusterTypeAsync,
+			target:      "cluster1-mysql-1",
+			lag:         100,
+			created:     time.Now().Add(-time.Hour),
+			state:       apiv1alpha1.SwitchoverFailed,
+			desc:        "switchover didn't start in 5m0s: replication lag 100 of cluster1-mysql-1 is above 10",
+		},
+		{
+			name:        "mysql error",
+			clusterType: apiv1alpha1.ClusterTypeGR,
+			target:      "cluster1-mysql-1",
+			failing:     true,
+			state:       apiv1alpha1.SwitchoverPending,
+			desc:        "failed to get primary: query primary member: stdout: , stderr: : connection refused",
+		},
+		{
+			name:        "mysql error timeout",
+			clusterType: apiv1alpha1.ClusterTypeGR,
+			target:      "cluster1-mysql-1",
+			failing:     true,
+			created:     time.Now().Add(-time.Hour),
+			state:       apiv1alpha1.SwitchoverFailed,
+			desc:        "switchover didn't start in 5m0s: failed to get primary: query primary member: stdout: , stderr: : connection refused",
+		},
+		{
+			name:        "running timeout",
+			clusterType: apiv1alpha1.ClusterTypeAsync,
+			target:      "cluster1-mysql-1",
+			failing:     true,
+			created:     time.Now().Add(-time.Hour),
+			startedAt:   time.Now().Add(-time.Hour),
+			state:       apiv1alpha1.SwitchoverFailed,
+			desc:        "switchover didn't complete in 5m0s: failed to get primary: run [curl localhost:3000/api/master/cluster1.ns], stdout: , stderr: : connection refused",
+		},
+		{
+			name:        "already primary",
+			clusterType: apiv1alpha1.ClusterTypeGR,
+			target:      "cluster1-mysql-0",
+			state:       apiv1alpha1.SwitchoverSucceeded,
+			desc:        "cluster1-mysql-0 is already the primary",
+		},
+		{
+			name:        "not a cluster pod",
+			clusterType: apiv1alpha1.ClusterTypeGR,
+			target:      "cluster1-orc-0",
+			state:       apiv1alpha1.SwitchoverFailed,
+			desc:        "cluster1-orc-0 is not a MySQL pod of cluster1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := tt.created
+			if created.IsZero() {
+				created = time.Now()
+			}
+
+			sw := switchover(tt.target, created)
+			if !tt.startedAt.IsZero() {
+				sw.Status.State = apiv1alpha1.SwitchoverRunning
+				sw.Status.StartedAt = &metav1.Time{Time: tt.startedAt}
+			}
+			cl := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objects(cluster(tt.clusterType), sw)...).
+				WithStatusSubresource(sw).
+				Build()
+			exec := &fakeExec{primary: "cluster1-mysql-0", lag: tt.lag, failing: tt.failing}
+			r := &PerconaServerMySQLSwitchoverReconciler{Client: cl, Scheme: scheme, ClientCmd: exec}
+
+			nn := types.NamespacedName{Name: sw.Name, Namespace: sw.Namespace}
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: nn}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := cl.Get(ctx, nn, sw); err != nil {
+				t.Fatal(err)
+			}
+			if sw.Status.State != tt.state || sw.Status.StateDesc != tt.desc {
+				t.Fatalf("expected %s state with %q, got %s with %q", tt.state, tt.desc, sw.Status.State, sw.Status.StateDesc)
+			}
+			if exec.switched != tt.switched {
+				t.Fatalf("expected switched to be %t", tt.switched)
+			}
+			if tt.state != apiv1alpha1.SwitchoverSucceeded {
+				return
+			}
+
+			if sw.Status.PreviousPrimary != "cluster1-mysql-0" || sw.Status.CompletedAt == nil {
+				t.Fatalf("unexpected status: %+v", sw.Status)
+			}
+			pods := new(corev1.PodList)
+			if err := cl.List(ctx, pods, client.MatchingLabels{naming.LabelMySQLPrimary: "true"}); err != nil {
+				t.Fatal(err)
+			}
+			if len(pods.Items) != 1 || pods.Items[0].Name != tt.target {
+				t.Fatalf("expected only %s to have the primary label, got %v", tt.target, pods.Items)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ReplicationChannel is the configuration of a replication channel from an external source.
+type ReplicationChannel struct {
+	Name         string
+	Host         string
+	Port         int32
+	User         string
+	Password     string
+	SSL          bool
+	SSLVerify    bool
+	SSLCA        string
+	RetryCount   int32
+	ConnectRetry int32
+}
+
+// ReplicationChannelStatus is the configuration and the state of the replication channel on the server.
+type ReplicationChannelStatus struct {
+	Host         string `csv:"host"`
+	Port         int32  `csv:"port"`
+	User         string `csv:"user"`
+	SSL          string `csv:"ssl"`
+	SSLVerify    string `csv:"ssl_verify"`
+	SSLCA        string `csv:"ssl_ca"`
+	RetryCount   int32  `csv:"retry_count"`
+	ConnectRetry int32  `csv:"connect_retry"`
+	ConnState    string `csv:"conn_state"`
+	ApplierState string `csv:"applier_state"`
+	Error        string `csv:"error"`
+}
+
+// ReplicationSource is a source in the asynchronous connection failover list of the channel.
+type ReplicationSource struct {
+	Host   string `csv:"host"`
+	Port   int32  `csv:"port"`
+	Weight int32  `csv:"weight"`
+}
+
+// ReplicationChannelStatus returns the status of the channel. It's nil if the channel isn't configured.
+func (m *ReplicationDBManager) ReplicationChannelStatus(ctx context.Context, name string) (*ReplicationChannelStatus, error) {
+	rows := []*ReplicationChannelStatus{}
+
+	q := fmt.Sprintf(`
+		SELECT
+			conf.HOST AS host,
+			conf.PORT AS port,
+			conf.USER AS user,
+			conf.SSL_ALLOWED AS ssl,
+			conf.SSL_VERIFY_SERVER_CERTIFICATE AS ssl_verify,
+			conf.SSL_CA_FILE AS ssl_ca,
+			conf.CONNECTION_RETRY_COUNT AS retry_count,
+			conf.CONNECTION_RETRY_INTERVAL AS connect_retry,
+			conn.SERVICE_STATE AS conn_state,
+			applier.SERVICE_STATE AS applier_state,
+			CONCAT_WS('; ',
+				NULLIF(conn.LAST_ERROR_MESSAGE, ''),
+				(SELECT LAST_ERROR_MESSAGE FROM replication_applier_status_by_worker worker
+					WHERE worker.CHANNEL_NAME = conf.CHANNEL_NAME AND worker.LAST_ERROR_NUMBER <> 0 LIMIT 1)
+			) AS error
+		FROM replication_connection_configuration conf
+		JOIN replication_connection_status conn ON conn.CHANNEL_NAME = conf.CHANNEL_NAME
+		JOIN replication_applier_status applier ON applier.CHANNEL_NAME = conf.CHANNEL_NAME
+		WHERE conf.CHANNEL_NAME = '%s'
+		`, escape(name))
+	if err := m.query(ctx, q, &rows); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "query status of channel %s", name)
+	}
+
+	return rows[0], nil
+}
+
+// ChangeReplicationChannel configures the channel. It must be stopped if it's configured already.
+func (m *ReplicationDBManager) ChangeReplicationChannel(ctx context.Context, ch ReplicationChannel) error {
+	opts := []string{
+		fmt.Sprintf("SOURCE_HOST='%s'", escape(ch.Host)),
+		fmt.Sprintf("SOURCE_PORT=%d", ch.Port),
+		fmt.Sprintf("SOURCE_USER='%s'", escape(ch.User)),
+		fmt.Sprintf("SOURCE_PASSWORD='%s'", escape(ch.Password)),
+		fmt.Sprintf("SOURCE_SSL=%d", boolToInt(ch.SSL)),
+		fmt.Sprintf("SOURCE_SSL_VERIFY_SERVER_CERT=%d", boolToInt(ch.SSLVerify)),
+		fmt.Sprintf("SOURCE_SSL_CA='%s'", escape(ch.SSLCA)),
+		fmt.Sprintf("SOURCE_RETRY_COUNT=%d", ch.RetryCount),
+		fmt.Sprintf("SOURCE_CONNECT_RETRY=%d", ch.ConnectRetry),
+		"SOURCE_AUTO_POSITION=1",
+		"SOURCE_CONNECTION_AUTO_FAILOVER=1",
+	}
+	q := fmt.Sprintf("CHANGE REPLICATION SOURCE TO %s FOR CHANNEL '%s'", strings.Join(opts, ", "), escape(ch.Name))
+
+	var errb, outb bytes.Buffer
+	if err := m.db.exec(ctx, q, &outb, &errb); err != nil {
+		return errors.Wrapf(err, "change replication source of channel %s", ch.Name)
+	}
+	return nil
+}
+
+// StartReplicationChannel starts the configured channel.
+func (m *ReplicationDBManager) StartReplicationChannel(ctx context.Context, name string) error {
+	var errb, outb bytes.Buffer
+	if err := m.db.exec(ctx, fmt.Sprintf("START REPLICA FOR CHANNEL '%s'", escape(name)), &outb, &errb); err != nil {
+		return errors.Wrapf(err, "start channel %s", name)
+	}
+	return nil
+}
+
+// StopReplicationChannel stops the configured channel.
+func (m *ReplicationDBManager) StopReplicationChannel(ctx context.Context, name string) error {
+	var errb, outb bytes.Buffer
+	if err := m.db.exec(ctx, fmt.Sprintf("STOP REPLICA FOR CHANNEL '%s'", escape(name)), &outb, &errb); err != nil {
+		return errors.Wrapf(err, "stop channel %s", name)
+	}
+	return nil
+}
+
+// ResetReplicationChannel stops the configured channel and removes it with its sources.
+func (m *ReplicationDBManager) ResetReplicationChannel(ctx context.Context, name string) error {
+	q := fmt.Sprintf("STOP REPLICA FOR CHANNEL '%[1]s'; RESET REPLICA ALL FOR CHANNEL '%[1]s'", escape(name))
+
+	var errb, outb bytes.Buffer
+	if err := m.db.exec(ctx, q, &outb, &errb); err != nil {
+		return errors.Wrapf(err, "reset channel %s", name)
+	}
+	return nil
+}
+
+// ReplicationChannelSources returns the sources the channel can fail over to.
+func (m *ReplicationDBManager) ReplicationChannelSources(ctx context.Context, name string) ([]ReplicationSource, error) {
+	rows := []*ReplicationSource{}
+
+	q := fmt.Sprintf(`SELECT HOST AS host, PORT AS port, WEIGHT AS weight
+		FROM replication_asynchronous_connection_failover WHERE CHANNEL_NAME = '%s'`, escape(name))
+	if err := m.query(ctx, q, &rows); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "query sources of channel %s", name)
+	}
+
+	sources := make([]ReplicationSource, 0, len(rows))
+	for _, row := range rows {
+		sources = append(sources, *row)
+	}
+	return sources, nil
+}
+
+// AddReplicationChannelSource adds the source to the failover list of the channel.
+func (m *ReplicationDBManager) AddReplicationChannelSource(ctx context.Context, name string, src ReplicationSource) error {
+	q := fmt.Sprintf("SELECT asynchronous_connection_failover_add_source('%s', '%s', %d, '', %d)",
+		escape(name), escape(src.Host), src.Port, src.Weight)
+
+	var errb, outb bytes.Buffer
+	if err := m.db.exec(ctx, q, &outb, &errb); err != nil {
+		return errors.Wrapf(err, "add source %s:%d to channel %s", src.Host, src.Port, name)
+	}
+	return nil
+}
+
+// DeleteReplicationChannelSource removes the source from the failover list of the channel.
+func (m *ReplicationDBManager) DeleteReplicationChannelSource(ctx context.Context, name string, src ReplicationSource) error {
+	q := fmt.Sprintf("SELECT asynchronous_connection_failover_delete_source('%s', '%s', %d, '')",
+		escape(name), escape(src.Host), src.Port)
+
+	var errb, outb bytes.Buffer
+	if err := m.db.exec(ctx, q, &outb, &errb); err != nil {
+		return errors.Wrapf(err, "delete source %s:%d from channel %s", src.Host, src.Port, name)
+	}
+	return nil
+}
+
+// SetSuperReadOnly enables or disables super_read_only on the server.
+// read_only is disabled together with super_read_only.
+func (m *ReplicationDBManager) SetSuperReadOnly(ctx context.Context, enabled bool) error {
+	q := "SET GLOBAL super_read_only=ON"
+	if !enabled {
+		q = "SET GLOBAL super_read_only=OFF; SET GLOBAL read_only=OFF"
+	}
+
+	var errb, outb bytes.Buffer
+	if err := m.db.exec(ctx, q, &outb, &errb); err != nil {
+		return errors.Wrap(err, "set super_read_only")
+	}
+	return nil
+}
+
+// escape escapes the value for a single-quoted SQL string.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
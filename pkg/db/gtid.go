@@ -0,0 +1,84 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// GetErrantTransactions returns the GTIDs of the set that the server didn't execute.
+// It's empty if the set is a subset of gtid_executed of the server.
+func (m *ReplicationDBManager) GetErrantTransactions(ctx context.Context, gtidSet string) (string, error) {
+	rows := []*struct {
+		Errant string `csv:"errant"`
+	}{}
+
+	q := fmt.Sprintf("SELECT REPLACE(GTID_SUBTRACT('%s', @@GLOBAL.gtid_executed), '\\n', '') AS errant FROM DUAL HAVING errant <> ''", gtidSet)
+	err := m.query(ctx, q, &rows)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", errors.Wrap(err, "subtract gtid_executed")
+	}
+
+	return rows[0].Errant, nil
+}
+
+// InjectEmptyTransactions commits an empty transaction with each of the GTIDs.
+func (m *ReplicationDBManager) InjectEmptyTransactions(ctx context.Context, gtids []string) error {
+	var q strings.Builder
+	for _, gtid := range gtids {
+		fmt.Fprintf(&q, "SET GTID_NEXT='%s'; BEGIN; COMMIT; ", gtid)
+	}
+	q.WriteString("SET GTID_NEXT='AUTOMATIC'")
+
+	var errb, outb bytes.Buffer
+	if err := m.db.exec(ctx, q.String(), &outb, &errb); err != nil {
+		return errors.Wrap(err, "inject empty transactions")
+	}
+	return nil
+}
+
+// ExpandGTIDSet returns the GTIDs of the set, e.g. uuid:1-2:5 is expanded to uuid:1, uuid:2 and uuid:5.
+// It fails if the set has more than limit GTIDs.
+func ExpandGTIDSet(set string, limit int) ([]string, error) {
+	var gtids []string
+	for _, uuidSet := range strings.Split(set, ",") {
+		parts := strings.Split(strings.TrimSpace(uuidSet), ":")
+		if len(parts) < 2 {
+			return nil, errors.Errorf("invalid GTID set %s", uuidSet)
+		}
+
+		prefix := parts[0]
+		for _, interval := range parts[1:] {
+			from, to, isRange := strings.Cut(interval, "-")
+			start, err := strconv.ParseInt(from, 10, 64)
+			if err != nil {
+				// Intervals after a tag belong to the tagged GTIDs.
+				prefix = parts[0] + ":" + interval
+				continue
+			}
+			end := start
+			if isRange {
+				end, err = strconv.ParseInt(to, 10, 64)
+				if err != nil || end < start {
+					return nil, errors.Errorf("invalid GTID interval %s", interval)
+				}
+			}
+			if int64(len(gtids))+end-start+1 > int64(limit) {
+				return nil, errors.Errorf("GTID set %s has more than %d transactions", set, limit)
+			}
+			for n := start; n <= end; n++ {
+				gtids = append(gtids, fmt.Sprintf("%s:%d", prefix, n))
+			}
+		}
+	}
+
+	return gtids, nil
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/clientcmd"
+)
+
+// StatementError is returned by HookManager.Exec if the server failed to execute a statement.
+type StatementError struct {
+	Message string
+}
+
+func (e *StatementError) Error() string {
+	return e.Message
+}
+
+var mysqlErrorRe = regexp.MustCompile(`ERROR (\d+) \(`)
+
+type HookManager struct {
+	db *db
+}
+
+func NewHookManager(pod *corev1.Pod, cliCmd clientcmd.Client, user apiv1alpha1.SystemUser, pass, host string) *HookManager {
+	return &HookManager{db: newDB(pod, cliCmd, user, pass, host)}
+}
+
+// Exec executes statements one by one in a single session.
+// Execution stops at the first failed statement, *StatementError is returned then.
+// Other errors, e.g. if the server isn't reachable, are transient.
+func (m *HookManager) Exec(ctx context.Context, statements []string) error {
+	stms := make([]string, 0, len(statements))
+	for _, stm := range statements {
+		stm = strings.TrimRight(strings.TrimSpace(stm), ";")
+		if stm != "" {
+			stms = append(stms, stm)
+		}
+	}
+	if len(stms) == 0 {
+		return nil
+	}
+
+	var errb, outb bytes.Buffer
+	if err := m.db.exec(ctx, strings.Join(stms, ";\n"), &outb, &errb); err != nil {
+		// Errors 2000-2999 are reported by the client, e.g. if it can't connect to the server.
+		if m := mysqlErrorRe.FindStringSubmatch(errb.String()); m != nil {
+			if code, _ := strconv.Atoi(m[1]); code < 2000 || code >= 3000 {
+				return &StatementError{Message: strings.TrimSpace(errb.String())}
+			}
+		}
+		return errors.Wrap(err, "execute statements")
+	}
+
+	return nil
+}
@@ -173,17 +173,21 @@ func (m *ReplicationDBManager) GetGroupReplicationMembers(ctx context.Context) (
 	rows := []*struct {
 		Member string `csv:"member"`
 		State  string `csv:"state"`
+		Role   string `csv:"role"`
 	}{}
 
-	err := m.query(ctx, "SELECT MEMBER_HOST as member, MEMBER_STATE as state FROM replication_group_members", &rows)
+	err := m.query(ctx, "SELECT MEMBER_HOST as member, MEMBER_STATE as state, MEMBER_ROLE as role FROM replication_group_members", &rows)
 	if err != nil {
 		return nil, errors.Wrap(err, "query members")
 	}
 
 	members := make([]innodbcluster.Member, 0)
 	for _, row := range rows {
-		state := innodbcluster.MemberState(row.State)
-		members = append(members, innodbcluster.Member{Address: row.Member, MemberState: state})
+		members = append(members, innodbcluster.Member{
+			Address:     row.Member,
+			MemberState: innodbcluster.MemberState(row.State),
+			MemberRole:  innodbcluster.MemberRole(row.Role),
+		})
 	}
 
 	return members, nil
@@ -203,3 +207,89 @@ func (m *ReplicationDBManager) CheckIfDatabaseExists(ctx context.Context, name s
 	}
 	return true, nil
 }
+
+// GetHeartbeatLag returns the number of seconds the server is behind the primary
+// according to the table updated by pt-heartbeat.
+func (m *ReplicationDBManager) GetHeartbeatLag(ctx context.Context) (int64, error) {
+	rows := []*struct {
+		Lag int64 `csv:"lag"`
+	}{}
+
+	err := m.query(ctx, "SELECT GREATEST(TIMESTAMPDIFF(SECOND, MAX(ts), NOW()), 0) AS lag FROM sys_operator.heartbeat HAVING lag IS NOT NULL", &rows)
+	if err != nil {
+		return 0, errors.Wrap(err, "query heartbeat")
+	}
+
+	return rows[0].Lag, nil
+}
+
+// GetGroupReplicationApplierQueues returns the number of transactions waiting
+// to be applied by each member of the group.
+func (m *ReplicationDBManager) GetGroupReplicationApplierQueues(ctx context.Context) (map[string]int64, error) {
+	rows := []*struct {
+		Host  string `csv:"host"`
+		Queue int64  `csv:"queue"`
+	}{}
+
+	q := `SELECT members.MEMBER_HOST AS host, stats.COUNT_TRANSACTIONS_REMOTE_IN_APPLIER_QUEUE AS queue
+		FROM replication_group_members members
+		JOIN replication_group_member_stats stats ON members.MEMBER_ID = stats.MEMBER_ID`
+	err := m.query(ctx, q, &rows)
+	if err != nil {
+		return nil, errors.Wrap(err, "query member stats")
+	}
+
+	queues := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		queues[row.Host] = row.Queue
+	}
+
+	return queues, nil
+}
+
+// ServerReplicationState is the state of replication on the server.
+type ServerReplicationState struct {
+	GTIDExecuted  string `csv:"gtid_executed"`
+	ReceiverState string `csv:"receiver_state"`
+	ApplierState  string `csv:"applier_state"`
+}
+
+// GetServerReplicationState returns gtid_executed of the server and the state of the threads of the channel.
+// The states are empty if the channel isn't configured.
+func (m *ReplicationDBManager) GetServerReplicationState(ctx context.Context, channel string) (ServerReplicationState, error) {
+	rows := []*ServerReplicationState{}
+
+	q := fmt.Sprintf(`SELECT
+			REPLACE(@@GLOBAL.gtid_executed, '\n', '') AS gtid_executed,
+			IFNULL((SELECT SERVICE_STATE FROM replication_connection_status WHERE CHANNEL_NAME = '%[1]s'), '') AS receiver_state,
+			IFNULL((SELECT SERVICE_STATE FROM replication_applier_status WHERE CHANNEL_NAME = '%[1]s'), '') AS applier_state`, channel)
+	if err := m.query(ctx, q, &rows); err != nil {
+		return ServerReplicationState{}, errors.Wrap(err, "query replication state")
+	}
+
+	return *rows[0], nil
+}
+
+// GetReplicationApplierError returns the last error of the applier of the default channel if it's stopped.
+// The error number is 0 if the applier isn't stopped by an error.
+func (m *ReplicationDBManager) GetReplicationApplierError(ctx context.Context) (int, string, error) {
+	rows := []*struct {
+		Number  int    `csv:"error_number"`
+		Message string `csv:"error_message"`
+	}{}
+
+	q := fmt.Sprintf(`SELECT w.LAST_ERROR_NUMBER AS error_number, REPLACE(w.LAST_ERROR_MESSAGE, '"', '''') AS error_message
+		FROM replication_applier_status a
+		JOIN replication_applier_status_by_worker w ON a.CHANNEL_NAME = w.CHANNEL_NAME
+		WHERE a.CHANNEL_NAME = '%s' AND a.SERVICE_STATE = 'OFF' AND w.LAST_ERROR_NUMBER <> 0
+		ORDER BY w.LAST_ERROR_TIMESTAMP DESC LIMIT 1`, defaultChannelName)
+	err := m.query(ctx, q, &rows)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, "", nil
+		}
+		return 0, "", errors.Wrap(err, "query applier error")
+	}
+
+	return rows[0].Number, rows[0].Message, nil
+}
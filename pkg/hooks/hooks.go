@@ -0,0 +1,283 @@
+// Package hooks runs the hooks configured for backups and restores.
+package hooks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/clientcmd"
+	"github.com/percona/percona-server-mysql-operator/pkg/db"
+	"github.com/percona/percona-server-mysql-operator/pkg/k8s"
+	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
+	"github.com/percona/percona-server-mysql-operator/pkg/orchestrator"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup"
+)
+
+// FailedError is returned by Runner.Run if a hook with the Fail policy failed.
+type FailedError struct {
+	Stage   apiv1alpha1.HookStage
+	Name    string
+	Message string
+}
+
+func (e *FailedError) Error() string {
+	return fmt.Sprintf("%s hook %s failed: %s", e.Stage, e.Name, e.Message)
+}
+
+// Runner runs the hooks of the backup or the restore of the cluster.
+type Runner struct {
+	Client    client.Client
+	Scheme    *runtime.Scheme
+	ClientCmd clientcmd.Client
+	Cluster   *apiv1alpha1.PerconaServerMySQL
+	// Owner is the backup or the restore the hooks are run for. Hook jobs are controlled by it.
+	Owner client.Object
+	// Env is added to the environment of hook jobs.
+	Env []corev1.EnvVar
+
+	primary string
+}
+
+// Run runs hooks of the stage one after another and records their states in statuses.
+// Jobs are created without waiting for them, so Run has to be called until it reports
+// that all hooks are finished. *FailedError is returned if a hook with the Fail policy failed,
+// the hooks after it aren't run.
+func (r *Runner) Run(ctx context.Context, stage apiv1alpha1.HookStage, hooks []apiv1alpha1.Hook, statuses *[]apiv1alpha1.HookStatus) (bool, error) {
+	for i := range hooks {
+		hook := &hooks[i]
+
+		st := Status(*statuses, stage, hook.Name)
+		if st == nil {
+			*statuses = append(*statuses, apiv1alpha1.HookStatus{
+				Name:  hook.Name,
+				Stage: stage,
+				State: apiv1alpha1.HookPending,
+			})
+			st = &(*statuses)[len(*statuses)-1]
+		}
+
+		if st.State != apiv1alpha1.HookSucceeded && st.State != apiv1alpha1.HookFailed {
+			if err := r.run(ctx, stage, hook, st); err != nil {
+				return false, errors.Wrapf(err, "run %s hook %s", stage, hook.Name)
+			}
+		}
+
+		switch st.State {
+		case apiv1alpha1.HookSucceeded:
+		case apiv1alpha1.HookFailed:
+			if hook.FailurePolicy != apiv1alpha1.HookFailurePolicyIgnore {
+				return true, &FailedError{Stage: stage, Name: hook.Name, Message: st.Message}
+			}
+		default:
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// Pending initializes statuses of the hooks, so the stage is known to be started before they run.
+func Pending(stage apiv1alpha1.HookStage, hooks []apiv1alpha1.Hook, statuses *[]apiv1alpha1.HookStatus) {
+	for _, hook := range hooks {
+		if Status(*statuses, stage, hook.Name) != nil {
+			continue
+		}
+		*statuses = append(*statuses, apiv1alpha1.HookStatus{
+			Name:  hook.Name,
+			Stage: stage,
+			State: apiv1alpha1.HookPending,
+		})
+	}
+}
+
+// Started checks if any hook of the stage has a status.
+func Started(statuses []apiv1alpha1.HookStatus, stage apiv1alpha1.HookStage) bool {
+	for _, st := range statuses {
+		if st.Stage == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// Status returns the status of the hook of the stage or nil if it's not started yet.
+func Status(statuses []apiv1alpha1.HookStatus, stage apiv1alpha1.HookStage, name string) *apiv1alpha1.HookStatus {
+	for i := range statuses {
+		if statuses[i].Stage == stage && statuses[i].Name == name {
+			return &statuses[i]
+		}
+	}
+	return nil
+}
+
+func (r *Runner) run(ctx context.Context, stage apiv1alpha1.HookStage, hook *apiv1alpha1.Hook, st *apiv1alpha1.HookStatus) error {
+	log := logf.FromContext(ctx)
+
+	switch {
+	case hook.Job != nil:
+		return r.runJob(ctx, stage, hook, st)
+	case len(hook.SQL) > 0:
+		hm, err := r.hookManager(ctx)
+		if err != nil {
+			return err
+		}
+
+		log.Info("Running hook", "stage", stage, "hook", hook.Name)
+		if err := hm.Exec(ctx, hook.SQL); err != nil {
+			var stmErr *db.StatementError
+			if !errors.As(err, &stmErr) {
+				return err
+			}
+			st.State = apiv1alpha1.HookFailed
+			st.Message = stmErr.Error()
+			return nil
+		}
+		st.State = apiv1alpha1.HookSucceeded
+		st.Message = ""
+	default:
+		st.State = apiv1alpha1.HookFailed
+		st.Message = "either sql or job is required"
+	}
+
+	return nil
+}
+
+func (r *Runner) runJob(ctx context.Context, stage apiv1alpha1.HookStage, hook *apiv1alpha1.Hook, st *apiv1alpha1.HookStatus) error {
+	log := logf.FromContext(ctx)
+
+	job := new(batchv1.Job)
+	nn := types.NamespacedName{Name: xtrabackup.HookJobName(r.Owner.GetName(), stage, hook.Name), Namespace: r.Owner.GetNamespace()}
+	err := r.Client.Get(ctx, nn, job)
+	if client.IgnoreNotFound(err) != nil {
+		return errors.Wrapf(err, "get job %s", nn.Name)
+	}
+
+	if k8serrors.IsNotFound(err) {
+		primary, err := r.primaryHost(ctx)
+		if err != nil {
+			return errors.Wrap(err, "get primary host")
+		}
+
+		env := append([]corev1.EnvVar{
+			{Name: "CLUSTER_NAME", Value: r.Cluster.Name},
+			{Name: "MYSQL_PRIMARY_HOST", Value: primary},
+			{Name: "HOOK_STAGE", Value: string(stage)},
+		}, r.Env...)
+		job := xtrabackup.HookJob(r.Cluster, r.Owner.GetName(), stage, hook, env)
+		if err := controllerutil.SetControllerReference(r.Owner, job, r.Scheme); err != nil {
+			return errors.Wrapf(err, "set controller reference to Job %s/%s", job.Namespace, job.Name)
+		}
+
+		log.Info("Creating hook job", "stage", stage, "hook", hook.Name, "jobName", job.Name)
+		if err := r.Client.Create(ctx, job); err != nil && !k8serrors.IsAlreadyExists(err) {
+			return errors.Wrapf(err, "create job %s", job.Name)
+		}
+		st.State = apiv1alpha1.HookRunning
+		return nil
+	}
+
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		switch cond.Type {
+		case batchv1.JobComplete:
+			st.State = apiv1alpha1.HookSucceeded
+			st.Message = ""
+		case batchv1.JobFailed:
+			st.State = apiv1alpha1.HookFailed
+			st.Message = fmt.Sprintf("job %s failed: %s", job.Name, cond.Message)
+		}
+	}
+
+	return nil
+}
+
+// hookManager returns the manager executing statements on the primary.
+// Errors it returns are transient, unlike errors of the statements.
+func (r *Runner) hookManager(ctx context.Context) (*db.HookManager, error) {
+	primary, err := r.primaryHost(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get primary host")
+	}
+
+	operatorPass, err := k8s.UserPassword(ctx, r.Client, r.Cluster, apiv1alpha1.UserOperator)
+	if err != nil {
+		return nil, errors.Wrap(err, "get operator password")
+	}
+
+	pod, err := r.firstPod(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.NewHookManager(pod, r.ClientCmd, apiv1alpha1.UserOperator, operatorPass, primary), nil
+}
+
+// primaryHost returns the host of the cluster primary. It's looked up once per Runner.
+func (r *Runner) primaryHost(ctx context.Context) (string, error) {
+	if r.primary != "" {
+		return r.primary, nil
+	}
+
+	cluster := r.Cluster
+	switch cluster.Spec.MySQL.ClusterType {
+	case apiv1alpha1.ClusterTypeGR:
+		operatorPass, err := k8s.UserPassword(ctx, r.Client, cluster, apiv1alpha1.UserOperator)
+		if err != nil {
+			return "", errors.Wrap(err, "get operator password")
+		}
+
+		pod, err := r.firstPod(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		rm := db.NewReplicationManager(pod, r.ClientCmd, apiv1alpha1.UserOperator, operatorPass, mysql.FQDN(cluster, 0))
+		primary, err := rm.GetGroupReplicationPrimary(ctx)
+		if err != nil {
+			return "", errors.Wrap(err, "get group-replication primary")
+		}
+		r.primary = primary
+	case apiv1alpha1.ClusterTypeAsync:
+		pod := new(corev1.Pod)
+		nn := types.NamespacedName{Namespace: cluster.Namespace, Name: orchestrator.PodName(cluster, 0)}
+		if err := r.Client.Get(ctx, nn, pod); err != nil {
+			return "", errors.Wrapf(err, "get pod %s", nn.Name)
+		}
+
+		primary, err := orchestrator.ClusterPrimary(ctx, r.ClientCmd, pod, cluster.ClusterHint())
+		if err != nil {
+			return "", errors.Wrap(err, "get cluster primary")
+		}
+		if primary.Key.Hostname == "" {
+			primary.Key.Hostname = fmt.Sprintf("%s.%s.%s", primary.Alias, mysql.ServiceName(cluster), cluster.Namespace)
+		}
+		r.primary = primary.Key.Hostname
+	default:
+		return "", errors.New("unknown cluster type")
+	}
+
+	return r.primary, nil
+}
+
+func (r *Runner) firstPod(ctx context.Context) (*corev1.Pod, error) {
+	pod := new(corev1.Pod)
+	nn := types.NamespacedName{Namespace: r.Cluster.Namespace, Name: mysql.PodName(r.Cluster, 0)}
+	if err := r.Client.Get(ctx, nn, pod); err != nil {
+		return nil, errors.Wrapf(err, "get pod %s", nn.Name)
+	}
+	return pod, nil
+}
@@ -0,0 +1,232 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	restclient "k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup"
+)
+
+// fakeExec answers the group replication primary query and records other statements.
+// Statements containing "fail" return an SQL error, statements containing "unreachable" a connection error.
+type fakeExec struct {
+	statements []string
+	hosts      []string
+}
+
+func (e *fakeExec) Exec(_ context.Context, _ *corev1.Pod, _ string, command []string, _ io.Reader, stdout, stderr io.Writer, _ bool) error {
+	var stm, host string
+	for i, arg := range command {
+		switch arg {
+		case "-e":
+			stm = command[i+1]
+		case "-h":
+			host = command[i+1]
+		}
+	}
+	if strings.Contains(stm, "replication_group_members") {
+		fmt.Fprint(stdout, "host\ncluster1-mysql-1.cluster1-mysql.some-namespace\n")
+		return nil
+	}
+
+	e.statements = append(e.statements, stm)
+	e.hosts = append(e.hosts, host)
+	switch {
+	case strings.Contains(stm, "fail"):
+		fmt.Fprint(stderr, "ERROR 1064 (42000): You have an error in your SQL syntax")
+	case strings.Contains(stm, "unreachable"):
+		fmt.Fprint(stderr, "ERROR 2003 (HY000): Can't connect to MySQL server")
+		return errors.New("command terminated with exit code 1")
+	}
+	return nil
+}
+
+func (e *fakeExec) REST() restclient.Interface {
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	namespace := "some-namespace"
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add client-go scheme")
+	}
+	if err := apiv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err, "failed to add apis scheme")
+	}
+
+	cluster := &apiv1alpha1.PerconaServerMySQL{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Namespace: namespace},
+		Spec: apiv1alpha1.PerconaServerMySQLSpec{
+			MySQL: apiv1alpha1.MySQLSpec{ClusterType: apiv1alpha1.ClusterTypeGR},
+		},
+	}
+	owner := &apiv1alpha1.PerconaServerMySQLBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup1", Namespace: namespace, UID: "backup1-uid"},
+	}
+	objects := func() []client.Object {
+		return []client.Object{
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: mysql.PodName(cluster, 0), Namespace: namespace}},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: cluster.InternalSecretName(), Namespace: namespace},
+				Data:       map[string][]byte{string(apiv1alpha1.UserOperator): []byte("operator-pass")},
+			},
+		}
+	}
+	runner := func(cl client.Client, exec *fakeExec) *Runner {
+		return &Runner{
+			Client:    cl,
+			Scheme:    scheme,
+			ClientCmd: exec,
+			Cluster:   cluster,
+			Owner:     owner,
+			Env:       []corev1.EnvVar{{Name: "BACKUP_NAME", Value: owner.Name}},
+		}
+	}
+
+	t.Run("sql", func(t *testing.T) {
+		exec := new(fakeExec)
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects()...).Build()
+
+		hooks := []apiv1alpha1.Hook{{
+			Name: "marker",
+			SQL:  []string{"FLUSH TABLES;", "INSERT INTO ops.backups (name) VALUES ('backup1')"},
+		}}
+		var statuses []apiv1alpha1.HookStatus
+		done, err := runner(cl, exec).Run(ctx, apiv1alpha1.HookStagePreBackup, hooks, &statuses)
+		if err != nil || !done {
+			t.Fatalf("expected hooks to be done, got %t, %v", done, err)
+		}
+
+		expected := "FLUSH TABLES;\nINSERT INTO ops.backups (name) VALUES ('backup1')"
+		if len(exec.statements) != 1 || exec.statements[0] != expected {
+			t.Fatalf("expected statements to be executed in one session, got %q", exec.statements)
+		}
+		if exec.hosts[0] != "cluster1-mysql-1.cluster1-mysql.some-namespace" {
+			t.Fatalf("expected statements to be executed on the primary, got %s", exec.hosts[0])
+		}
+		if st := Status(statuses, apiv1alpha1.HookStagePreBackup, "marker"); st == nil || st.State != apiv1alpha1.HookSucceeded {
+			t.Fatalf("expected succeeded hook, got %+v", st)
+		}
+	})
+
+	t.Run("failure policy", func(t *testing.T) {
+		hooks := []apiv1alpha1.Hook{
+			{Name: "ignored", SQL: []string{"fail"}, FailurePolicy: apiv1alpha1.HookFailurePolicyIgnore},
+			{Name: "failed", SQL: []string{"fail again"}},
+			{Name: "skipped", SQL: []string{"SELECT 1"}},
+		}
+
+		exec := new(fakeExec)
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects()...).Build()
+		var statuses []apiv1alpha1.HookStatus
+		done, err := runner(cl, exec).Run(ctx, apiv1alpha1.HookStagePostBackup, hooks, &statuses)
+
+		var failed *FailedError
+		if !done || !errors.As(err, &failed) || failed.Name != "failed" {
+			t.Fatalf("expected hook failed to fail the stage, got %t, %v", done, err)
+		}
+		if len(exec.statements) != 2 {
+			t.Fatalf("expected hooks after the failed one to be skipped, got %q", exec.statements)
+		}
+		if st := Status(statuses, apiv1alpha1.HookStagePostBackup, "ignored"); st.State != apiv1alpha1.HookFailed || st.Message == "" {
+			t.Fatalf("expected ignored hook failure to be recorded, got %+v", st)
+		}
+
+		// Failed hooks aren't retried.
+		if _, err := runner(cl, exec).Run(ctx, apiv1alpha1.HookStagePostBackup, hooks, &statuses); !errors.As(err, &failed) {
+			t.Fatalf("expected hook failed to fail the stage, got %v", err)
+		}
+		if len(exec.statements) != 2 {
+			t.Fatalf("expected failed hooks not to be retried, got %q", exec.statements)
+		}
+	})
+
+	t.Run("transient error", func(t *testing.T) {
+		hooks := []apiv1alpha1.Hook{{Name: "marker", SQL: []string{"SELECT 'unreachable'"}}}
+
+		exec := new(fakeExec)
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects()...).Build()
+		var statuses []apiv1alpha1.HookStatus
+		done, err := runner(cl, exec).Run(ctx, apiv1alpha1.HookStagePreBackup, hooks, &statuses)
+
+		var failed *FailedError
+		if done || err == nil || errors.As(err, &failed) {
+			t.Fatalf("expected hook to be retried, got %t, %v", done, err)
+		}
+		if st := Status(statuses, apiv1alpha1.HookStagePreBackup, "marker"); st.State != apiv1alpha1.HookPending {
+			t.Fatalf("expected pending hook, got %+v", st)
+		}
+	})
+
+	t.Run("job", func(t *testing.T) {
+		hooks := []apiv1alpha1.Hook{{
+			Name: "notify",
+			Job: &apiv1alpha1.HookJobSpec{
+				Image:   "curlimages/curl",
+				Command: []string{"curl", "-X", "POST", "http://backup-monitor/start"},
+			},
+		}}
+
+		exec := new(fakeExec)
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects()...).Build()
+		var statuses []apiv1alpha1.HookStatus
+		done, err := runner(cl, exec).Run(ctx, apiv1alpha1.HookStagePreBackup, hooks, &statuses)
+		if err != nil || done {
+			t.Fatalf("expected hook job to be running, got %t, %v", done, err)
+		}
+
+		job := new(batchv1.Job)
+		nn := types.NamespacedName{Name: xtrabackup.HookJobName(owner.Name, apiv1alpha1.HookStagePreBackup, "notify"), Namespace: namespace}
+		if err := cl.Get(ctx, nn, job); err != nil {
+			t.Fatal(err, "failed to get hook job")
+		}
+		if !metav1.IsControlledBy(job, owner) {
+			t.Fatal("expected hook job to be controlled by the backup")
+		}
+		env := make(map[string]string)
+		for _, e := range job.Spec.Template.Spec.Containers[0].Env {
+			env[e.Name] = e.Value
+		}
+		for k, v := range map[string]string{
+			"CLUSTER_NAME":       "cluster1",
+			"BACKUP_NAME":        "backup1",
+			"HOOK_STAGE":         "preBackup",
+			"MYSQL_PRIMARY_HOST": "cluster1-mysql-1.cluster1-mysql.some-namespace",
+		} {
+			if env[k] != v {
+				t.Errorf("expected %s=%s, got %q", k, v, env[k])
+			}
+		}
+
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+		if err := cl.Status().Update(ctx, job); err != nil {
+			t.Fatal(err)
+		}
+		done, err = runner(cl, exec).Run(ctx, apiv1alpha1.HookStagePreBackup, hooks, &statuses)
+		if err != nil || !done {
+			t.Fatalf("expected hooks to be done, got %t, %v", done, err)
+		}
+		if st := Status(statuses, apiv1alpha1.HookStagePreBackup, "notify"); st.State != apiv1alpha1.HookSucceeded {
+			t.Fatalf("expected succeeded hook, got %+v", st)
+		}
+	})
+}
@@ -34,9 +34,17 @@ const (
 	MemberStateMissing     MemberState = "(MISSING)"
 )
 
+type MemberRole string
+
+const (
+	MemberRolePrimary   MemberRole = "PRIMARY"
+	MemberRoleSecondary MemberRole = "SECONDARY"
+)
+
 type Member struct {
 	Address        string      `json:"address"`
 	MemberState    MemberState `json:"status"`
+	MemberRole     MemberRole  `json:"memberRole"`
 	InstanceErrors []string    `json:"instanceErrors"`
 }
 
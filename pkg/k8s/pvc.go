@@ -7,15 +7,21 @@ import (
 	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
 )
 
+// PVC returns the claim of the volume spec. Its spec is empty if the volume isn't a PVC.
 func PVC(name string, spec *apiv1alpha1.VolumeSpec) corev1.PersistentVolumeClaim {
-	return corev1.PersistentVolumeClaim{
+	pvc := corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: name,
 		},
-		Spec: corev1.PersistentVolumeClaimSpec{
-			StorageClassName: spec.PersistentVolumeClaim.StorageClassName,
-			AccessModes:      spec.PersistentVolumeClaim.AccessModes,
-			Resources:        spec.PersistentVolumeClaim.Resources,
-		},
 	}
+	if spec == nil || spec.PersistentVolumeClaim == nil {
+		return pvc
+	}
+
+	pvc.Spec = corev1.PersistentVolumeClaimSpec{
+		StorageClassName: spec.PersistentVolumeClaim.StorageClassName,
+		AccessModes:      spec.PersistentVolumeClaim.AccessModes,
+		Resources:        spec.PersistentVolumeClaim.Resources,
+	}
+	return pvc
 }
@@ -26,6 +26,7 @@ const (
 	CredsMountPath   = "/etc/mysql/mysql-users-secret"
 	tlsVolumeName    = "tls"
 	tlsMountPath     = "/etc/mysql/mysql-tls-secret"
+	TLSCAPath        = tlsMountPath + "/ca.crt"
 	BackupLogDir     = "/var/log/xtrabackup"
 )
 
@@ -286,6 +287,15 @@ func updateStrategy(cr *apiv1alpha1.PerconaServerMySQL) appsv1.StatefulSetUpdate
 	}
 }
 
+// DataVolumeClaim returns the data volume claim of the pod with the given index,
+// named the same way the statefulset names claims created from its templates.
+func DataVolumeClaim(cr *apiv1alpha1.PerconaServerMySQL, idx int) *corev1.PersistentVolumeClaim {
+	pvc := k8s.PVC(DataVolumeName+"-"+PodName(cr, idx), cr.MySQLSpec().VolumeSpec)
+	pvc.Namespace = cr.Namespace
+	pvc.Labels = MatchLabels(cr)
+	return &pvc
+}
+
 func volumeClaimTemplates(spec *apiv1alpha1.MySQLSpec) []corev1.PersistentVolumeClaim {
 	pvcs := []corev1.PersistentVolumeClaim{
 		k8s.PVC(DataVolumeName, spec.VolumeSpec),
@@ -25,6 +25,10 @@ const (
 const (
 	LabelBackupType     = perconaPrefix + "backup-type"
 	LabelBackupAncestor = perconaPrefix + "backup-ancestor"
+	LabelInitFrom       = perconaPrefix + "init-from"
+
+	// BackupTypeImported is the value of LabelBackupType for backups created from the contents of a storage.
+	BackupTypeImported = "imported"
 )
 
 const (
@@ -46,4 +50,13 @@ const (
 	AnnotationTLSHash          AnnotationKey = perconaPrefix + "last-applied-tls"
 	AnnotationPasswordsUpdated AnnotationKey = perconaPrefix + "passwords-updated"
 	AnnotationLastConfigHash   AnnotationKey = perconaPrefix + "last-config-hash"
+	// AnnotationSyncBackups contains comma separated names of storages backups of the cluster are imported from.
+	// The operator removes it after the import.
+	AnnotationSyncBackups AnnotationKey = perconaPrefix + "sync-backups"
+	// AnnotationReplicationErrorSince marks a MySQL pod stopped by a replication error
+	// with the time the error was found. The pod is rebuilt if it's still there after spec.mysql.autoRebuild.errorTimeout.
+	AnnotationReplicationErrorSince AnnotationKey = perconaPrefix + "replication-error-since"
+	// AnnotationBinlogTimeline contains the creation time (Unix seconds) of the last restore that replaced
+	// the data of the cluster. The binlog collector uploads binary logs of each timeline under its own prefix.
+	AnnotationBinlogTimeline AnnotationKey = perconaPrefix + "binlog-timeline"
 )
@@ -3,6 +3,7 @@ package orchestrator
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
@@ -51,6 +52,13 @@ type Instance struct {
 	Replicas  []InstanceKey `json:"Replicas"`
 	ReadOnly  bool          `json:"ReadOnly"`
 	Problems  []string      `json:"Problems"`
+
+	ExecutedGtidSet       string        `json:"ExecutedGtidSet"`
+	ReplicationLagSeconds sql.NullInt64 `json:"ReplicationLagSeconds"`
+	// The field names are misspelled by Orchestrator.
+	ReplicationIOThreadRunning  bool `json:"ReplicationIOThreadRuning"`
+	ReplicationSQLThreadRunning bool `json:"ReplicationSQLThreadRuning"`
+	IsLastCheckValid            bool `json:"IsLastCheckValid"`
 }
 
 var (
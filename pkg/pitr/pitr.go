@@ -0,0 +1,408 @@
+package pitr
+
+import (
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/k8s"
+	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
+	"github.com/percona/percona-server-mysql-operator/pkg/naming"
+	"github.com/percona/percona-server-mysql-operator/pkg/secret"
+	"github.com/percona/percona-server-mysql-operator/pkg/util"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup"
+)
+
+const (
+	ComponentName        = "binlog-collector"
+	restoreComponentName = "pitr"
+	credsVolumeName      = "users"
+	workVolumeName       = "binlogs"
+	WorkMountPath        = "/var/lib/binlogs"
+)
+
+func Name(cr *apiv1alpha1.PerconaServerMySQL) string {
+	return cr.Name + "-" + ComponentName
+}
+
+func RestoreJobName(cr *apiv1alpha1.PerconaServerMySQLRestore) string {
+	return restoreComponentName + "-restore-" + cr.Name
+}
+
+func MatchLabels(cr *apiv1alpha1.PerconaServerMySQL) map[string]string {
+	return util.SSMapMerge(map[string]string{naming.LabelComponent: ComponentName}, cr.Labels())
+}
+
+// Timeline returns the timeline binary logs of the cluster are currently uploaded to.
+// A new timeline is started by every restore that replaces the data of the cluster.
+func Timeline(cr *apiv1alpha1.PerconaServerMySQL) string {
+	if timeline, ok := cr.Annotations[string(naming.AnnotationBinlogTimeline)]; ok {
+		return timeline
+	}
+	return "0"
+}
+
+// BinlogsPrefix returns the prefix binary logs of the cluster are stored under
+// relative to the bucket or container of the storage.
+func BinlogsPrefix(storage *apiv1alpha1.BackupStorageSpec, clusterName string) (string, error) {
+	var prefix string
+	switch storage.Type {
+	case apiv1alpha1.BackupStorageS3:
+		_, prefix = storage.S3.BucketAndPrefix()
+	case apiv1alpha1.BackupStorageGCS:
+		_, prefix = storage.GCS.BucketAndPrefix()
+	case apiv1alpha1.BackupStorageAzure:
+		_, prefix = storage.Azure.ContainerAndPrefix()
+	default:
+		return "", errors.Errorf("storage type %s is not supported for binlogs", storage.Type)
+	}
+
+	return path.Join(prefix, clusterName+"-binlogs") + "/", nil
+}
+
+func Deployment(cr *apiv1alpha1.PerconaServerMySQL, initImage string) (*appsv1.Deployment, error) {
+	labels := MatchLabels(cr)
+	spec := cr.Spec.Backup.PITR
+
+	replicas := int32(1)
+	if cr.Spec.Pause {
+		replicas = 0
+	}
+
+	storage, ok := cr.Spec.Backup.Storages[spec.StorageName]
+	if !ok {
+		return nil, errors.Errorf("storage %s doesn't exist", spec.StorageName)
+	}
+
+	env, err := storageEnv(storage, cr.Name)
+	if err != nil {
+		return nil, err
+	}
+	env = append(env, clusterEnv(cr)...)
+	env = append(env,
+		corev1.EnvVar{
+			Name:  "TIME_BETWEEN_UPLOADS",
+			Value: strconv.Itoa(int(spec.TimeBetweenUploads)),
+		},
+		corev1.EnvVar{
+			Name:  "OBJECT_TAGS",
+			Value: xtrabackup.EncodeObjectTags(xtrabackup.ObjectTags(cr, storage, "binlog")),
+		},
+		corev1.EnvVar{
+			Name:  "BINLOG_TIMELINE",
+			Value: Timeline(cr),
+		},
+	)
+
+	container := corev1.Container{
+		Name:                     ComponentName,
+		Image:                    cr.Spec.MySQL.Image,
+		ImagePullPolicy:          cr.Spec.MySQL.ImagePullPolicy,
+		Resources:                spec.Resources,
+		Env:                      env,
+		VolumeMounts:             volumeMounts(),
+		Command:                  []string{"/opt/percona/pitr", "collect"},
+		TerminationMessagePath:   "/dev/termination-log",
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		SecurityContext:          spec.ContainerSecurityContext,
+	}
+
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      Name(cr),
+			Namespace: cr.Namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			// Two collectors must never upload the same binary logs concurrently.
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RecreateDeploymentStrategyType,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{
+						k8s.InitContainer(
+							ComponentName,
+							initImage,
+							cr.Spec.MySQL.ImagePullPolicy,
+							spec.ContainerSecurityContext,
+						),
+					},
+					Containers:       []corev1.Container{container},
+					ImagePullSecrets: cr.Spec.MySQL.ImagePullSecrets,
+					RestartPolicy:    corev1.RestartPolicyAlways,
+					DNSPolicy:        corev1.DNSClusterFirst,
+					SecurityContext:  cr.Spec.MySQL.PodSecurityContext,
+					Volumes:          volumes(cr),
+				},
+			},
+		},
+	}, nil
+}
+
+// RestoreJob returns the job that replays binary logs collected for the sourceCluster
+// on the primary of the cluster up to the point defined in restore.Spec.PITR.
+func RestoreJob(
+	cluster *apiv1alpha1.PerconaServerMySQL,
+	restore *apiv1alpha1.PerconaServerMySQLRestore,
+	storage *apiv1alpha1.BackupStorageSpec,
+	sourceCluster string,
+	initImage string,
+) (*batchv1.Job, error) {
+	if restore.Spec.PITR == nil {
+		return nil, errors.New("pitr is not set")
+	}
+
+	one := int32(1)
+	labels := util.SSMapMerge(storage.Labels, MatchLabels(cluster), map[string]string{naming.LabelComponent: restoreComponentName})
+
+	env, err := storageEnv(storage, sourceCluster)
+	if err != nil {
+		return nil, err
+	}
+	env = append(env, clusterEnv(cluster)...)
+	env = append(env, []corev1.EnvVar{
+		{
+			Name:  "PITR_TYPE",
+			Value: string(restore.Spec.PITR.Type),
+		},
+		{
+			Name:  "PITR_DATE",
+			Value: restore.Spec.PITR.Date,
+		},
+		{
+			Name:  "PITR_GTID",
+			Value: restore.Spec.PITR.GTID,
+		},
+	}...)
+
+	container := corev1.Container{
+		Name:                     restoreComponentName,
+		Image:                    cluster.Spec.MySQL.Image,
+		ImagePullPolicy:          cluster.Spec.MySQL.ImagePullPolicy,
+		Env:                      env,
+		VolumeMounts:             volumeMounts(),
+		Command:                  []string{"/opt/percona/pitr", "restore"},
+		TerminationMessagePath:   "/dev/termination-log",
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		SecurityContext:          storage.ContainerSecurityContext,
+		Resources:                storage.Resources,
+	}
+
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        RestoreJobName(restore),
+			Namespace:   cluster.Namespace,
+			Labels:      labels,
+			Annotations: storage.Annotations,
+		},
+		Spec: batchv1.JobSpec{
+			Parallelism: &one,
+			Completions: &one,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					InitContainers: []corev1.Container{
+						k8s.InitContainer(
+							restoreComponentName,
+							initImage,
+							cluster.Spec.MySQL.ImagePullPolicy,
+							storage.ContainerSecurityContext,
+						),
+					},
+					Containers:                []corev1.Container{container},
+					ImagePullSecrets:          cluster.Spec.MySQL.ImagePullSecrets,
+					Affinity:                  storage.Affinity,
+					TopologySpreadConstraints: storage.TopologySpreadConstraints,
+					Tolerations:               storage.Tolerations,
+					NodeSelector:              storage.NodeSelector,
+					SchedulerName:             storage.SchedulerName,
+					PriorityClassName:         storage.PriorityClassName,
+					RuntimeClassName:          storage.RuntimeClassName,
+					DNSPolicy:                 corev1.DNSClusterFirst,
+					SecurityContext:           storage.PodSecurityContext,
+					Volumes:                   volumes(cluster),
+				},
+			},
+			BackoffLimit: func(i int32) *int32 { return &i }(4),
+		},
+	}, nil
+}
+
+func volumes(cr *apiv1alpha1.PerconaServerMySQL) []corev1.Volume {
+	return []corev1.Volume{
+		{
+			Name: apiv1alpha1.BinVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+		{
+			Name: workVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+		{
+			Name: credsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: cr.InternalSecretName(),
+				},
+			},
+		},
+	}
+}
+
+func volumeMounts() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{
+			Name:      apiv1alpha1.BinVolumeName,
+			MountPath: apiv1alpha1.BinVolumePath,
+		},
+		{
+			Name:      workVolumeName,
+			MountPath: WorkMountPath,
+		},
+		{
+			Name:      credsVolumeName,
+			MountPath: mysql.CredsMountPath,
+		},
+	}
+}
+
+func clusterEnv(cr *apiv1alpha1.PerconaServerMySQL) []corev1.EnvVar {
+	hosts := make([]string, 0, cr.Spec.MySQL.Size)
+	for i := 0; i < int(cr.Spec.MySQL.Size); i++ {
+		hosts = append(hosts, mysql.FQDN(cr, i))
+	}
+
+	return []corev1.EnvVar{
+		{
+			Name:  "CLUSTER_TYPE",
+			Value: string(cr.Spec.MySQL.ClusterType),
+		},
+		{
+			Name:  "MYSQL_HOSTS",
+			Value: strings.Join(hosts, ","),
+		},
+	}
+}
+
+func storageEnv(storage *apiv1alpha1.BackupStorageSpec, clusterName string) ([]corev1.EnvVar, error) {
+	prefix, err := BinlogsPrefix(storage, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	verifyTLS := true
+	if storage.VerifyTLS != nil {
+		verifyTLS = *storage.VerifyTLS
+	}
+
+	env := []corev1.EnvVar{
+		{
+			Name:  "STORAGE_TYPE",
+			Value: string(storage.Type),
+		},
+		{
+			Name:  "BINLOGS_PREFIX",
+			Value: prefix,
+		},
+		{
+			Name:  "VERIFY_TLS",
+			Value: strconv.FormatBool(verifyTLS),
+		},
+	}
+
+	secretEnv := func(name, secretName, key string) []corev1.EnvVar {
+		// Empty credentials secret means that access is granted by the environment (e.g. IAM role).
+		if secretName == "" {
+			return nil
+		}
+		return []corev1.EnvVar{{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: k8s.SecretKeySelector(secretName, key),
+			},
+		}}
+	}
+
+	// optionalSecretEnv is used for keys the secret contains only some of, e.g. HMAC keys or a service account key.
+	optionalSecretEnv := func(name, secretName, key string) []corev1.EnvVar {
+		env := secretEnv(name, secretName, key)
+		for i := range env {
+			optional := true
+			env[i].ValueFrom.SecretKeyRef.Optional = &optional
+		}
+		return env
+	}
+
+	switch storage.Type {
+	case apiv1alpha1.BackupStorageS3:
+		s3 := storage.S3
+		bucket, _ := s3.BucketAndPrefix()
+		env = append(env, secretEnv("AWS_ACCESS_KEY_ID", s3.CredentialsSecret, secret.CredentialsAWSAccessKey)...)
+		env = append(env, secretEnv("AWS_SECRET_ACCESS_KEY", s3.CredentialsSecret, secret.CredentialsAWSSecretKey)...)
+		env = append(env, []corev1.EnvVar{
+			{Name: "S3_ROLE_ARN", Value: s3.RoleARN},
+			{Name: "S3_WEB_IDENTITY_TOKEN_FILE", Value: s3.WebIdentityTokenFile},
+			{Name: "S3_STS_ENDPOINT", Value: s3.STSEndpoint},
+			{Name: "AWS_DEFAULT_REGION", Value: s3.Region},
+			{Name: "AWS_ENDPOINT", Value: s3.EndpointURL},
+			{Name: "S3_BUCKET", Value: bucket},
+		}...)
+	case apiv1alpha1.BackupStorageGCS:
+		gcs := storage.GCS
+		bucket, _ := gcs.BucketAndPrefix()
+		env = append(env, optionalSecretEnv("ACCESS_KEY_ID", gcs.CredentialsSecret, secret.CredentialsGCSAccessKey)...)
+		env = append(env, optionalSecretEnv("SECRET_ACCESS_KEY", gcs.CredentialsSecret, secret.CredentialsGCSSecretKey)...)
+		env = append(env, optionalSecretEnv("GCS_SERVICE_ACCOUNT_KEY", gcs.CredentialsSecret, secret.CredentialsGCSServiceAccount)...)
+		env = append(env, []corev1.EnvVar{
+			{Name: "GCS_ENDPOINT", Value: gcs.EndpointURL},
+			{Name: "GCS_BUCKET", Value: bucket},
+		}...)
+	case apiv1alpha1.BackupStorageAzure:
+		azure := storage.Azure
+		container, _ := azure.ContainerAndPrefix()
+		if azure.StorageAccount != "" {
+			env = append(env, corev1.EnvVar{Name: "AZURE_STORAGE_ACCOUNT", Value: azure.StorageAccount})
+		} else {
+			env = append(env, secretEnv("AZURE_STORAGE_ACCOUNT", azure.CredentialsSecret, secret.CredentialsAzureStorageAccount)...)
+		}
+		env = append(env, optionalSecretEnv("AZURE_ACCESS_KEY", azure.CredentialsSecret, secret.CredentialsAzureAccessKey)...)
+		env = append(env, optionalSecretEnv("AZURE_SAS_TOKEN", azure.CredentialsSecret, secret.CredentialsAzureSASToken)...)
+		env = append(env, []corev1.EnvVar{
+			{Name: "AZURE_ENDPOINT", Value: azure.EndpointURL},
+			{Name: "AZURE_CONTAINER_NAME", Value: container},
+		}...)
+	}
+	env = append(env, xtrabackup.ObjectOptionsEnv(storage)...)
+
+	return env, nil
+}
@@ -95,8 +95,10 @@ func generatePass() ([]byte, error) {
 const (
 	CredentialsAzureStorageAccount = "AZURE_STORAGE_ACCOUNT_NAME"
 	CredentialsAzureAccessKey      = "AZURE_STORAGE_ACCOUNT_KEY"
+	CredentialsAzureSASToken       = "AZURE_STORAGE_SAS_TOKEN"
 	CredentialsAWSAccessKey        = "AWS_ACCESS_KEY_ID"
 	CredentialsAWSSecretKey        = "AWS_SECRET_ACCESS_KEY"
 	CredentialsGCSAccessKey        = "ACCESS_KEY_ID"
 	CredentialsGCSSecretKey        = "SECRET_ACCESS_KEY"
+	CredentialsGCSServiceAccount   = "GCS_SERVICE_ACCOUNT_KEY"
 )
@@ -0,0 +1,332 @@
+// Package cloud stores xbstream output in object storages.
+//
+// The stream is split into chunks that are uploaded in parallel and retried
+// independently. After all chunks are uploaded, the manifest listing them is
+// written next to them. A backup without manifest is incomplete.
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
+)
+
+const (
+	DefaultChunkSize     = 16 << 20
+	DefaultParallel      = 4
+	DefaultRetries       = 5
+	DefaultRetryInterval = time.Second
+
+	ManifestName = "manifest.json"
+	chunkPrefix  = "xbstream."
+)
+
+type Options struct {
+	// ChunkSize is the size of the objects the stream is split into.
+	ChunkSize int
+	// Parallel is the number of chunks transferred at the same time.
+	// At most Parallel+1 chunks are kept in memory.
+	Parallel int
+	// Retries is the number of times a chunk is retried before the transfer fails.
+	// Zero means DefaultRetries, negative value disables retries.
+	Retries int
+	// RetryInterval is the delay before the first retry. It's doubled after each attempt.
+	RetryInterval time.Duration
+	// Bandwidth is the maximum number of bytes per second uploaded. Zero means no limit.
+	Bandwidth int64
+}
+
+func (o Options) withDefaults() Options {
+	if o.ChunkSize <= 0 {
+		o.ChunkSize = DefaultChunkSize
+	}
+	if o.Parallel <= 0 {
+		o.Parallel = DefaultParallel
+	}
+	if o.Retries < 0 {
+		o.Retries = 0
+	} else if o.Retries == 0 {
+		o.Retries = DefaultRetries
+	}
+	if o.RetryInterval <= 0 {
+		o.RetryInterval = DefaultRetryInterval
+	}
+	return o
+}
+
+type Chunk struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	MD5  string `json:"md5"`
+}
+
+// Manifest lists chunks of the stream in the order they have to be concatenated.
+type Manifest struct {
+	Size   int64   `json:"size"`
+	Chunks []Chunk `json:"chunks"`
+}
+
+func chunkName(i int) string {
+	return fmt.Sprintf("%s%020d", chunkPrefix, i)
+}
+
+func manifestPath(dest string) string {
+	return path.Join(dest, ManifestName)
+}
+
+type Uploader struct {
+	stg  storage.Storage
+	opts Options
+	pool sync.Pool
+
+	read     atomic.Int64
+	uploaded atomic.Int64
+}
+
+func NewUploader(stg storage.Storage, opts Options) *Uploader {
+	opts = opts.withDefaults()
+	u := &Uploader{stg: stg, opts: opts}
+	u.pool.New = func() any {
+		b := make([]byte, opts.ChunkSize)
+		return &b
+	}
+	return u
+}
+
+// Progress returns the number of bytes read from the stream and the number of bytes stored in the storage.
+func (u *Uploader) Progress() (read, uploaded int64) {
+	return u.read.Load(), u.uploaded.Load()
+}
+
+// Upload stores chunks of the stream under dest. It doesn't write the manifest,
+// the caller does it with PutManifest after making sure the stream is complete.
+func (u *Uploader) Upload(ctx context.Context, dest string, r io.Reader) (*Manifest, error) {
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(u.opts.Parallel)
+
+	r = LimitReader(gCtx, r, u.opts.Bandwidth)
+
+	manifest := new(Manifest)
+	for i := 0; gCtx.Err() == nil; i++ {
+		buf := u.pool.Get().(*[]byte)
+		n, err := io.ReadFull(r, *buf)
+		if n == 0 {
+			u.pool.Put(buf)
+		} else {
+			data := (*buf)[:n]
+			sum := md5.Sum(data)
+			c := Chunk{
+				Name: chunkName(i),
+				Size: int64(n),
+				MD5:  hex.EncodeToString(sum[:]),
+			}
+			manifest.Chunks = append(manifest.Chunks, c)
+			manifest.Size += c.Size
+			u.read.Add(c.Size)
+
+			g.Go(func() error {
+				defer u.pool.Put(buf)
+
+				name := path.Join(dest, c.Name)
+				err := retry(gCtx, u.opts, func() error {
+					return u.stg.PutObject(gCtx, name, bytes.NewReader(data), c.Size)
+				})
+				if err != nil {
+					return errors.Wrapf(err, "put %s", name)
+				}
+				u.uploaded.Add(c.Size)
+				return nil
+			})
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			_ = g.Wait()
+			return nil, errors.Wrap(err, "read stream")
+		}
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(manifest.Chunks) == 0 {
+		return nil, errors.New("stream is empty")
+	}
+
+	return manifest, nil
+}
+
+// PutManifest marks the backup stored under dest as complete.
+func PutManifest(ctx context.Context, stg storage.Storage, dest string, manifest *Manifest, opts Options) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return errors.Wrap(err, "marshal manifest")
+	}
+
+	name := manifestPath(dest)
+	err = retry(ctx, opts.withDefaults(), func() error {
+		return stg.PutObject(ctx, name, bytes.NewReader(data), int64(len(data)))
+	})
+	return errors.Wrapf(err, "put %s", name)
+}
+
+// GetManifest returns the manifest of the backup stored under dest.
+// storage.ErrObjectNotFound is returned if the backup is incomplete or
+// wasn't uploaded by this package.
+func GetManifest(ctx context.Context, stg storage.Storage, dest string) (*Manifest, error) {
+	manifest := new(Manifest)
+	err := retry(ctx, Options{}.withDefaults(), func() error {
+		r, err := stg.GetObject(ctx, manifestPath(dest))
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		return errors.Wrap(json.NewDecoder(r).Decode(manifest), "decode manifest")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Download writes the stream stored under dest to w.
+// Chunks are fetched in parallel and verified against the manifest before they are written.
+func Download(ctx context.Context, stg storage.Storage, dest string, w io.Writer, opts Options) error {
+	opts = opts.withDefaults()
+
+	manifest, err := GetManifest(ctx, stg, dest)
+	if err != nil {
+		return errors.Wrap(err, "get manifest")
+	}
+
+	results := make([]chan []byte, len(manifest.Chunks))
+	for i := range results {
+		results[i] = make(chan []byte, 1)
+	}
+	sem := make(chan struct{}, opts.Parallel)
+
+	// Stops fetching chunks if writing the stream fails.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	g, gCtx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		for i, c := range manifest.Chunks {
+			select {
+			case sem <- struct{}{}:
+			case <-gCtx.Done():
+				return gCtx.Err()
+			}
+
+			g.Go(func() error {
+				data, err := getChunk(gCtx, stg, dest, c, opts)
+				if err != nil {
+					return err
+				}
+				results[i] <- data
+				return nil
+			})
+		}
+		return nil
+	})
+
+	for i := range manifest.Chunks {
+		select {
+		case data := <-results[i]:
+			if _, err := w.Write(data); err != nil {
+				return errors.Wrap(err, "write stream")
+			}
+			<-sem
+		case <-gCtx.Done():
+			return g.Wait()
+		}
+	}
+
+	return g.Wait()
+}
+
+func getChunk(ctx context.Context, stg storage.Storage, dest string, c Chunk, opts Options) ([]byte, error) {
+	name := path.Join(dest, c.Name)
+
+	var data []byte
+	err := retry(ctx, opts, func() error {
+		r, err := stg.GetObject(ctx, name)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		data, err = io.ReadAll(r)
+		if err != nil {
+			return errors.Wrap(err, "read object")
+		}
+		if int64(len(data)) != c.Size {
+			return errors.Errorf("size mismatch: expected %d, got %d", c.Size, len(data))
+		}
+		if sum := md5.Sum(data); hex.EncodeToString(sum[:]) != c.MD5 {
+			return errors.New("md5 mismatch")
+		}
+		return nil
+	})
+
+	return data, errors.Wrapf(err, "get %s", name)
+}
+
+// Delete removes all objects stored under dest, starting with the manifest.
+func Delete(ctx context.Context, stg storage.Storage, dest string) error {
+	if err := stg.DeleteObject(ctx, manifestPath(dest)); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+		return errors.Wrap(err, "delete manifest")
+	}
+
+	objects, err := stg.ListObjects(ctx, dest+"/")
+	if err != nil {
+		return errors.Wrap(err, "list objects")
+	}
+	for _, obj := range objects {
+		if err := stg.DeleteObject(ctx, obj); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+			return errors.Wrapf(err, "delete %s", obj)
+		}
+	}
+
+	return nil
+}
+
+func retry(ctx context.Context, opts Options, f func() error) error {
+	interval := opts.RetryInterval
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = f(); err == nil {
+			return nil
+		}
+		if errors.Is(err, storage.ErrObjectNotFound) || attempt >= opts.Retries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		interval *= 2
+	}
+}
@@ -0,0 +1,162 @@
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage/fake"
+)
+
+// flakyStorage fails the first request for each object.
+type flakyStorage struct {
+	storage.Storage
+
+	mu     sync.Mutex
+	failed map[string]bool
+}
+
+func (s *flakyStorage) fail(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failed[name] {
+		return false
+	}
+	s.failed[name] = true
+	return true
+}
+
+func (s *flakyStorage) PutObject(ctx context.Context, name string, data io.Reader, size int64) error {
+	if s.fail("put " + name) {
+		return errors.New("connection reset")
+	}
+	return s.Storage.PutObject(ctx, name, data, size)
+}
+
+func (s *flakyStorage) GetObject(ctx context.Context, name string) (io.ReadCloser, error) {
+	if s.fail("get " + name) {
+		return nil, errors.New("connection reset")
+	}
+	return s.Storage.GetObject(ctx, name)
+}
+
+func TestUploadDownload(t *testing.T) {
+	ctx := context.Background()
+	opts := Options{
+		ChunkSize:     1024,
+		Parallel:      3,
+		RetryInterval: time.Millisecond,
+	}
+	dest := "cluster1-2024-01-01-00:00:00-full"
+
+	tests := []struct {
+		name string
+		size int
+	}{
+		{"single chunk", 100},
+		{"exact chunks", 4 * 1024},
+		{"partial last chunk", 10*1024 + 17},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stg := &flakyStorage{Storage: fake.NewMemoryStorage(), failed: make(map[string]bool)}
+
+			data := make([]byte, tt.size)
+			rand.New(rand.NewSource(int64(tt.size))).Read(data)
+
+			u := NewUploader(stg, opts)
+			manifest, err := u.Upload(ctx, dest, bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if read, uploaded := u.Progress(); read != int64(tt.size) || uploaded != int64(tt.size) {
+				t.Fatalf("expected %d bytes read and uploaded, got %d and %d", tt.size, read, uploaded)
+			}
+
+			if _, err := GetManifest(ctx, stg.Storage, dest); !errors.Is(err, storage.ErrObjectNotFound) {
+				t.Fatalf("expected manifest to be missing before PutManifest, got %v", err)
+			}
+			if err := PutManifest(ctx, stg, dest, manifest, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			buf := new(bytes.Buffer)
+			if err := Download(ctx, stg, dest, buf, opts); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), data) {
+				t.Fatalf("downloaded stream differs from uploaded one")
+			}
+
+			if err := Delete(ctx, stg, dest); err != nil {
+				t.Fatal(err)
+			}
+			objects, err := stg.ListObjects(ctx, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(objects) != 0 {
+				t.Fatalf("expected no objects after delete, got %v", objects)
+			}
+		})
+	}
+}
+
+func TestDownloadCorruptedChunk(t *testing.T) {
+	ctx := context.Background()
+	opts := Options{
+		ChunkSize:     16,
+		Retries:       -1,
+		RetryInterval: time.Millisecond,
+	}
+	dest := "backup"
+	stg := fake.NewMemoryStorage()
+
+	manifest, err := NewUploader(stg, opts).Upload(ctx, dest, bytes.NewReader(make([]byte, 40)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := PutManifest(ctx, stg, dest, manifest, opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := stg.PutObject(ctx, dest+"/"+chunkName(1), bytes.NewReader(make([]byte, 15)), 15); err != nil {
+		t.Fatal(err)
+	}
+
+	err = Download(ctx, stg, dest, io.Discard, opts)
+	expected := "get backup/xbstream.00000000000000000001: size mismatch: expected 16, got 15"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error %q, got %v", expected, err)
+	}
+}
+
+func TestUploadBandwidth(t *testing.T) {
+	ctx := context.Background()
+
+	data := make([]byte, 512<<10)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	stg := fake.NewMemoryStorage()
+	u := NewUploader(stg, Options{ChunkSize: 64 << 10, Bandwidth: 1 << 20})
+
+	start := time.Now()
+	manifest, err := u.Upload(ctx, "backup", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first 256KiB are read at once, the rest takes 250ms at 1MiB/s.
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("upload wasn't throttled, took %s", elapsed)
+	}
+	if manifest.Size != int64(len(data)) {
+		t.Fatalf("expected %d bytes uploaded, got %d", len(data), manifest.Size)
+	}
+}
@@ -0,0 +1,80 @@
+package cloud
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
+
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
+)
+
+// Copy stores objects of the backup stored under srcDest in src under dstDest in dst.
+// The manifest and the dump completion file are copied after all other objects,
+// so an interrupted copy is incomplete the same way an interrupted upload is.
+func Copy(ctx context.Context, src, dst storage.Storage, srcDest, dstDest string, opts Options) error {
+	opts = opts.withDefaults()
+
+	objects, err := src.ListObjects(ctx, srcDest+"/")
+	if err != nil {
+		return errors.Wrap(err, "list objects")
+	}
+	if len(objects) == 0 {
+		return storage.ErrObjectNotFound
+	}
+
+	limiter := newLimiter(opts.Bandwidth)
+
+	var markers []string
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(opts.Parallel)
+	for _, obj := range objects {
+		rel := strings.TrimPrefix(strings.TrimPrefix(obj, "/"), srcDest+"/")
+		if rel == "" {
+			continue
+		}
+		if rel == ManifestName || rel == DumpDoneName {
+			markers = append(markers, obj)
+			continue
+		}
+		g.Go(func() error {
+			return copyObject(gCtx, src, dst, obj, path.Join(dstDest, rel), opts, limiter)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	for _, obj := range markers {
+		rel := strings.TrimPrefix(strings.TrimPrefix(obj, "/"), srcDest+"/")
+		if err := copyObject(ctx, src, dst, obj, path.Join(dstDest, rel), opts, limiter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CopyObject stores the object srcName of src as dstName in dst.
+// storage.ErrObjectNotFound is returned if the object doesn't exist.
+func CopyObject(ctx context.Context, src, dst storage.Storage, srcName, dstName string, opts Options) error {
+	opts = opts.withDefaults()
+	return copyObject(ctx, src, dst, srcName, dstName, opts, newLimiter(opts.Bandwidth))
+}
+
+// copyObject streams the object from src to dst, so only the buffers of the storage clients are kept in memory.
+// The whole transfer is retried since the stream can't be rewound.
+func copyObject(ctx context.Context, src, dst storage.Storage, srcName, dstName string, opts Options, limiter *rate.Limiter) error {
+	return retry(ctx, opts, func() error {
+		r, err := src.GetObject(ctx, srcName)
+		if err != nil {
+			return errors.Wrapf(err, "get %s", srcName)
+		}
+		defer r.Close()
+
+		err = dst.PutObject(ctx, dstName, limitReader(ctx, r, limiter), -1)
+		return errors.Wrapf(err, "put %s", dstName)
+	})
+}
@@ -0,0 +1,57 @@
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage/fake"
+)
+
+func TestCopy(t *testing.T) {
+	ctx := context.Background()
+	opts := Options{
+		ChunkSize:     1024,
+		Parallel:      3,
+		RetryInterval: time.Millisecond,
+	}
+
+	data := make([]byte, 5*1024+3)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	src := &flakyStorage{Storage: fake.NewMemoryStorage(), failed: make(map[string]bool)}
+	manifest, err := NewUploader(src, opts).Upload(ctx, "cluster1-full", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := PutManifest(ctx, src, "cluster1-full", manifest, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := &orderedStorage{Storage: &flakyStorage{Storage: fake.NewMemoryStorage(), failed: make(map[string]bool)}}
+	if err := Copy(ctx, src, dst, "cluster1-full", "offsite/cluster1-full", opts); err != nil {
+		t.Fatal(err)
+	}
+	if last := dst.put[len(dst.put)-1]; last != "offsite/cluster1-full/"+ManifestName {
+		t.Fatalf("expected %s to be copied last, got %s", ManifestName, last)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := Download(ctx, dst, "offsite/cluster1-full", buf, opts); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Fatal("copied stream differs from uploaded one")
+	}
+
+	if err := Copy(ctx, src, dst, "missing", "offsite/missing", opts); !errors.Is(err, storage.ErrObjectNotFound) {
+		t.Fatalf("expected %v copying missing backup, got %v", storage.ErrObjectNotFound, err)
+	}
+	if err := CopyObject(ctx, src, dst, "missing.checkpoints", "offsite/missing.checkpoints", opts); !errors.Is(err, storage.ErrObjectNotFound) {
+		t.Fatalf("expected %v copying missing object, got %v", storage.ErrObjectNotFound, err)
+	}
+}
@@ -0,0 +1,137 @@
+package cloud
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
+)
+
+// DumpDoneName is the file MySQL Shell writes after the dump is finished.
+// It's uploaded after all other files, so a dump without it is incomplete.
+const DumpDoneName = "@.done.json"
+
+// UploadDir stores files of the directory under dest keeping their relative paths.
+func UploadDir(ctx context.Context, stg storage.Storage, dest, dir string, opts Options) error {
+	opts = opts.withDefaults()
+
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "walk %s", dir)
+	}
+
+	// The limit is shared by files uploaded in parallel.
+	limiter := newLimiter(opts.Bandwidth)
+
+	put := func(ctx context.Context, file string) error {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		name := path.Join(dest, filepath.ToSlash(rel))
+		err = retry(ctx, opts, func() error {
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			fi, err := f.Stat()
+			if err != nil {
+				return err
+			}
+			return stg.PutObject(ctx, name, limitReader(ctx, f, limiter), fi.Size())
+		})
+		return errors.Wrapf(err, "put %s", name)
+	}
+
+	var done string
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(opts.Parallel)
+	for _, file := range files {
+		if filepath.Base(file) == DumpDoneName && filepath.Dir(file) == filepath.Clean(dir) {
+			done = file
+			continue
+		}
+		g.Go(func() error {
+			return put(gCtx, file)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	if done != "" {
+		return put(ctx, done)
+	}
+	return nil
+}
+
+// DownloadDir writes objects stored under dest to the directory keeping their relative paths.
+func DownloadDir(ctx context.Context, stg storage.Storage, dest, dir string, opts Options) error {
+	opts = opts.withDefaults()
+
+	objects, err := stg.ListObjects(ctx, dest+"/")
+	if err != nil {
+		return errors.Wrap(err, "list objects")
+	}
+	if len(objects) == 0 {
+		return storage.ErrObjectNotFound
+	}
+
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(opts.Parallel)
+	for _, obj := range objects {
+		rel := strings.TrimPrefix(strings.TrimPrefix(obj, "/"), dest+"/")
+		if rel == "" || strings.HasPrefix(rel, "../") || strings.Contains(rel, "/../") {
+			continue
+		}
+		file := filepath.Join(dir, filepath.FromSlash(rel))
+
+		g.Go(func() error {
+			err := retry(gCtx, opts, func() error {
+				return getFile(gCtx, stg, obj, file)
+			})
+			return errors.Wrapf(err, "get %s", obj)
+		})
+	}
+
+	return g.Wait()
+}
+
+func getFile(ctx context.Context, stg storage.Storage, name, file string) error {
+	r, err := stg.GetObject(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return errors.Wrap(err, "write file")
+	}
+	return f.Close()
+}
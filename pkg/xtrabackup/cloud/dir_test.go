@@ -0,0 +1,86 @@
+package cloud
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
+	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage/fake"
+)
+
+// orderedStorage records names of the objects in the order they were put.
+type orderedStorage struct {
+	storage.Storage
+
+	mu  sync.Mutex
+	put []string
+}
+
+func (s *orderedStorage) PutObject(ctx context.Context, name string, data io.Reader, size int64) error {
+	s.mu.Lock()
+	s.put = append(s.put, name)
+	s.mu.Unlock()
+	return s.Storage.PutObject(ctx, name, data, size)
+}
+
+func TestUploadDownloadDir(t *testing.T) {
+	ctx := context.Background()
+	opts := Options{
+		Parallel:      2,
+		RetryInterval: time.Millisecond,
+	}
+	dest := "cluster1-2024-01-01-00:00:00-full"
+
+	files := map[string]string{
+		"@.json":                       `{"dumper":"mysqlsh"}`,
+		"@.sql":                        "",
+		"db1.json":                     `{"schema":"db1"}`,
+		"db1@t1@@0.tsv.zst":            "rows",
+		"db1@t1@@0.tsv.zst.idx":        "index",
+		"nested/db2@t2@@0.tsv.zst":     "more rows",
+		DumpDoneName:                   `{"end":"2024-01-01 00:00:10"}`,
+		"db1@t1@@0.tsv.zst.idx.backup": "x",
+	}
+
+	src := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(src, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stg := &orderedStorage{Storage: &flakyStorage{Storage: fake.NewMemoryStorage(), failed: make(map[string]bool)}}
+	if err := UploadDir(ctx, stg, dest, src, opts); err != nil {
+		t.Fatal(err)
+	}
+	if last := stg.put[len(stg.put)-1]; last != dest+"/"+DumpDoneName {
+		t.Fatalf("expected %s to be uploaded last, got %s", DumpDoneName, last)
+	}
+
+	dst := filepath.Join(t.TempDir(), "dump")
+	if err := DownloadDir(ctx, stg, dest, dst, opts); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Fatalf("expected %s to contain %q, got %q", name, content, data)
+		}
+	}
+
+	if err := DownloadDir(ctx, stg, "missing", dst, opts); err == nil {
+		t.Fatal("expected error for missing backup")
+	}
+}
@@ -0,0 +1,51 @@
+package cloud
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+)
+
+// maxRateBurst is the largest number of bytes read at once from a rate limited reader.
+const maxRateBurst = 256 << 10
+
+type rateLimitedReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *rate.Limiter
+}
+
+// newLimiter returns nil if bytesPerSecond is not positive.
+func newLimiter(bytesPerSecond int64) *rate.Limiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), int(min(bytesPerSecond, maxRateBurst)))
+}
+
+func limitReader(ctx context.Context, r io.Reader, limiter *rate.Limiter) io.Reader {
+	if limiter == nil {
+		return r
+	}
+	return &rateLimitedReader{ctx: ctx, r: r, limiter: limiter}
+}
+
+// LimitReader returns a reader which reads from r with at most bytesPerSecond.
+// r is returned as is if bytesPerSecond is not positive.
+func LimitReader(ctx context.Context, r io.Reader, bytesPerSecond int64) io.Reader {
+	return limitReader(ctx, r, newLimiter(bytesPerSecond))
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	if len(p) > r.limiter.Burst() {
+		p = p[:r.limiter.Burst()]
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if werr := r.limiter.WaitN(r.ctx, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
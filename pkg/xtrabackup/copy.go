@@ -0,0 +1,122 @@
+package xtrabackup
+
+import (
+	"strconv"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/util"
+)
+
+// TargetEnvPrefix is prepended to the storage environment variables of the storage the backup is copied to.
+const TargetEnvPrefix = "TARGET_"
+
+func CopyName(cr *apiv1alpha1.PerconaServerMySQLBackup, storageName string) string {
+	return componentShortName + "-copy-" + cr.Name + "-" + storageName
+}
+
+func CopyJobName(cr *apiv1alpha1.PerconaServerMySQLBackup, storageName string) string {
+	return hashedJobName(CopyName(cr, storageName))
+}
+
+// CopyJob returns the job that copies the backup from its storage to the target one with backup-stream.
+// The job is scheduled according to the target storage.
+func CopyJob(
+	cluster *apiv1alpha1.PerconaServerMySQL,
+	cr *apiv1alpha1.PerconaServerMySQLBackup,
+	storageName string,
+	target *apiv1alpha1.BackupStorageSpec,
+	destination apiv1alpha1.BackupDestination,
+	initImage string,
+) *batchv1.Job {
+	one := int32(1)
+
+	labels := util.SSMapMerge(target.Labels, MatchLabels(cluster))
+
+	source := cr.Status.Storage
+	env := append([]corev1.EnvVar{
+		{
+			Name:  "VERIFY_TLS",
+			Value: strconv.FormatBool(storageVerifyTLS(source)),
+		},
+		{
+			Name:  TargetEnvPrefix + "VERIFY_TLS",
+			Value: strconv.FormatBool(storageVerifyTLS(target)),
+		},
+	}, storageEnv(source)...)
+
+	backupType := string(cr.Status.Type)
+	if cr.Status.IsLogical() {
+		backupType = string(apiv1alpha1.BackupMethodLogical)
+	}
+	targetEnv := append(storageEnv(target), corev1.EnvVar{
+		Name:  "OBJECT_TAGS",
+		Value: EncodeObjectTags(ObjectTags(cluster, target, backupType)),
+	})
+	for _, e := range targetEnv {
+		e.Name = TargetEnvPrefix + e.Name
+		env = append(env, e)
+	}
+
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        CopyJobName(cr, storageName),
+			Namespace:   cr.Namespace,
+			Labels:      labels,
+			Annotations: target.Annotations,
+		},
+		Spec: batchv1.JobSpec{
+			Parallelism: &one,
+			Completions: &one,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:            componentName,
+							Image:           initImage,
+							ImagePullPolicy: cluster.Spec.Backup.ImagePullPolicy,
+							Env:             env,
+							Command: []string{
+								"/opt/percona-server-mysql-operator/backup-stream",
+								"copy",
+								cr.Status.Destination.PathWithoutBucket(),
+								destination.PathWithoutBucket(),
+							},
+							TerminationMessagePath:   "/dev/termination-log",
+							TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+							SecurityContext:          target.ContainerSecurityContext,
+							Resources:                target.Resources,
+						},
+					},
+					Affinity:                  target.Affinity,
+					TopologySpreadConstraints: target.TopologySpreadConstraints,
+					Tolerations:               target.Tolerations,
+					NodeSelector:              target.NodeSelector,
+					SchedulerName:             target.SchedulerName,
+					PriorityClassName:         target.PriorityClassName,
+					RuntimeClassName:          target.RuntimeClassName,
+					DNSPolicy:                 corev1.DNSClusterFirst,
+					SecurityContext:           target.PodSecurityContext,
+				},
+			},
+		},
+	}
+}
+
+func storageVerifyTLS(storage *apiv1alpha1.BackupStorageSpec) bool {
+	if storage.VerifyTLS != nil {
+		return *storage.VerifyTLS
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package xtrabackup
+
+import (
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+)
+
+// HookJobName returns the name of the job of the hook run for the backup or the restore named ownerName.
+func HookJobName(ownerName string, stage apiv1alpha1.HookStage, hookName string) string {
+	return hashedJobName(componentShortName + "-" + strings.ToLower(string(stage)) + "-" + ownerName + "-" + hookName)
+}
+
+// HookJob returns the job running the container of the hook. env is appended to the hook's own environment.
+func HookJob(
+	cluster *apiv1alpha1.PerconaServerMySQL,
+	ownerName string,
+	stage apiv1alpha1.HookStage,
+	hook *apiv1alpha1.Hook,
+	env []corev1.EnvVar,
+) *batchv1.Job {
+	one := int32(1)
+
+	labels := MatchLabels(cluster)
+	spec := hook.Job
+
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      HookJobName(ownerName, stage, hook.Name),
+			Namespace: cluster.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			Parallelism:           &one,
+			Completions:           &one,
+			BackoffLimit:          spec.BackoffLimit,
+			ActiveDeadlineSeconds: spec.ActiveDeadlineSeconds,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:                     "hook",
+							Image:                    spec.Image,
+							ImagePullPolicy:          spec.ImagePullPolicy,
+							Command:                  spec.Command,
+							Args:                     spec.Args,
+							Env:                      append(append([]corev1.EnvVar{}, spec.Env...), env...),
+							EnvFrom:                  spec.EnvFrom,
+							Resources:                spec.Resources,
+							TerminationMessagePath:   "/dev/termination-log",
+							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+						},
+					},
+					ServiceAccountName: spec.ServiceAccountName,
+					ImagePullSecrets:   spec.ImagePullSecrets,
+					DNSPolicy:          corev1.DNSClusterFirst,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,97 @@
+package xtrabackup
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
+)
+
+// LogicalJob returns the job that dumps the source node with MySQL Shell.
+// The dump is written to the data volume of the job before it's uploaded,
+// so the node should have enough ephemeral storage for it.
+func LogicalJob(
+	cluster *apiv1alpha1.PerconaServerMySQL,
+	cr *apiv1alpha1.PerconaServerMySQLBackup,
+	destination apiv1alpha1.BackupDestination, initImage string,
+	storage *apiv1alpha1.BackupStorageSpec,
+) (*batchv1.Job, error) {
+	job := Job(cluster, cr, destination, initImage, storage)
+	if err := setLogical(job, cluster, "/opt/percona/run-logical-backup.sh"); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// LogicalRestoreJob returns the job that loads the dump into the primary of the running cluster.
+// Only the objects selected by spec.partial are loaded if it's set.
+func LogicalRestoreJob(
+	cluster *apiv1alpha1.PerconaServerMySQL,
+	destination apiv1alpha1.BackupDestination,
+	restore *apiv1alpha1.PerconaServerMySQLRestore,
+	storage *apiv1alpha1.BackupStorageSpec,
+	initImage string,
+) (*batchv1.Job, error) {
+	job := RestoreJob(cluster, destination, restore, storage, initImage, "")
+
+	spec := &job.Spec.Template.Spec
+	for i := range spec.Volumes {
+		if spec.Volumes[i].Name == dataVolumeName {
+			spec.Volumes[i].VolumeSource = corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			}
+		}
+	}
+
+	env := append([]corev1.EnvVar{
+		{Name: "MYSQL_HOSTS", Value: strings.Join(mysqlHosts(cluster), " ")},
+	}, partialRestoreEnv(restore.Spec.Partial)...)
+	if err := setLogical(job, cluster, "/opt/percona/run-logical-restore.sh", env...); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// mysqlHosts returns FQDNs of all MySQL pods. Restore jobs look for the primary among them.
+func mysqlHosts(cluster *apiv1alpha1.PerconaServerMySQL) []string {
+	hosts := make([]string, 0, cluster.Spec.MySQL.Size)
+	for i := 0; i < int(cluster.Spec.MySQL.Size); i++ {
+		hosts = append(hosts, mysql.FQDN(cluster, i))
+	}
+	return hosts
+}
+
+// setLogical makes the xtrabackup container of the job run the script with MySQL Shell from the MySQL image.
+// The container keeps its name, so storage settings are applied to it the same way as for physical backups.
+func setLogical(job *batchv1.Job, cluster *apiv1alpha1.PerconaServerMySQL, script string, env ...corev1.EnvVar) error {
+	container, err := xtrabackupJobContainer(job)
+	if err != nil {
+		return errors.Wrap(err, "get xtrabackup container")
+	}
+
+	container.Image = cluster.Spec.MySQL.Image
+	container.ImagePullPolicy = cluster.Spec.MySQL.ImagePullPolicy
+	container.Command = []string{script}
+	container.Env = append(container.Env, env...)
+
+	mounted := false
+	for _, m := range container.VolumeMounts {
+		if m.Name == credsVolumeName {
+			mounted = true
+		}
+	}
+	if !mounted {
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      credsVolumeName,
+			MountPath: credsMountPath,
+		})
+	}
+
+	job.Spec.Template.Spec.ImagePullSecrets = cluster.Spec.MySQL.ImagePullSecrets
+	return nil
+}
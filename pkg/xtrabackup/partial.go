@@ -0,0 +1,96 @@
+package xtrabackup
+
+import (
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+)
+
+const (
+	dumpVolumeName = "dump"
+	dumpMountPath  = "/dump"
+)
+
+// PartialRestoreJob restores the physical backup into an emptyDir volume with xtrabackup running in an init container,
+// then starts mysqld on the restored data, dumps the objects selected by spec.partial and loads them into the primary.
+func PartialRestoreJob(
+	cluster *apiv1alpha1.PerconaServerMySQL,
+	destination apiv1alpha1.BackupDestination,
+	restore *apiv1alpha1.PerconaServerMySQLRestore,
+	storage *apiv1alpha1.BackupStorageSpec,
+	initImage string,
+) *batchv1.Job {
+	mysqlContainer := corev1.Container{
+		Env: append([]corev1.EnvVar{
+			{
+				Name:  "RESTORE_NAME",
+				Value: restore.Name,
+			},
+			{
+				Name:  "BACKUP_DEST",
+				Value: destination.PathWithoutBucket(),
+			},
+			{
+				Name:  "MYSQL_HOSTS",
+				Value: strings.Join(mysqlHosts(cluster), " "),
+			},
+		}, partialRestoreEnv(restore.Spec.Partial)...),
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      dumpVolumeName,
+				MountPath: dumpMountPath,
+			},
+			{
+				Name:      credsVolumeName,
+				MountPath: credsMountPath,
+			},
+		},
+		Command: []string{"/opt/percona/run-partial-restore.sh"},
+	}
+
+	return scratchRestoreJob(cluster, storage, RestoreJobName(cluster, restore), restore.Name, destination, initImage, mysqlContainer,
+		corev1.Volume{
+			Name: dumpVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+		corev1.Volume{
+			Name: credsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: cluster.Spec.SecretsName,
+				},
+			},
+		},
+	)
+}
+
+// partialRestoreEnv passes filters of the partial restore to MySQL Shell as comma-separated lists.
+func partialRestoreEnv(spec *apiv1alpha1.PartialRestoreSpec) []corev1.EnvVar {
+	if spec == nil {
+		return nil
+	}
+
+	return []corev1.EnvVar{
+		{
+			Name:  "INCLUDE_DATABASES",
+			Value: strings.Join(spec.IncludeDatabases, ","),
+		},
+		{
+			Name:  "EXCLUDE_DATABASES",
+			Value: strings.Join(spec.ExcludeDatabases, ","),
+		},
+		{
+			Name:  "INCLUDE_TABLES",
+			Value: strings.Join(spec.IncludeTables, ","),
+		},
+		{
+			Name:  "EXCLUDE_TABLES",
+			Value: strings.Join(spec.ExcludeTables, ","),
+		},
+	}
+}
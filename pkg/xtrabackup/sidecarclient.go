@@ -9,13 +9,18 @@ import (
 	"net/url"
 	"strconv"
 
-	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
 	"github.com/pkg/errors"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/mysql"
 )
 
 type SidecarClient interface {
 	GetRunningBackupConfig(ctx context.Context) (*BackupConfig, error)
+	GetBackupProgress(ctx context.Context, name string) (*apiv1alpha1.BackupProgress, error)
+	GetBackupMetadata(ctx context.Context, name string) (*apiv1alpha1.BackupMetadata, error)
 	DeleteBackup(ctx context.Context, name string, cfg BackupConfig) error
+	CancelBackup(ctx context.Context, name string) error
 }
 
 type NewSidecarClientFunc func(srcNode string) SidecarClient
@@ -66,6 +71,57 @@ func (c *sidecarClient) GetRunningBackupConfig(ctx context.Context) (*BackupConf
 	return backupConf, nil
 }
 
+// GetBackupProgress returns nil if the backup is not running.
+func (c *sidecarClient) GetBackupProgress(ctx context.Context, name string) (*apiv1alpha1.BackupProgress, error) {
+	progress := new(apiv1alpha1.BackupProgress)
+	found, err := c.get(ctx, "/progress/"+name, progress)
+	if err != nil || !found {
+		return nil, errors.Wrap(err, "get backup progress")
+	}
+	return progress, nil
+}
+
+// GetBackupMetadata returns nil if the sidecar has no metadata of the backup.
+func (c *sidecarClient) GetBackupMetadata(ctx context.Context, name string) (*apiv1alpha1.BackupMetadata, error) {
+	metadata := new(apiv1alpha1.BackupMetadata)
+	found, err := c.get(ctx, "/metadata/"+name, metadata)
+	if err != nil || !found {
+		return nil, errors.Wrap(err, "get backup metadata")
+	}
+	return metadata, nil
+}
+
+func (c *sidecarClient) get(ctx context.Context, path string, v any) (bool, error) {
+	sidecarURL := url.URL{
+		Host:   c.srcNode + ":" + c.port(),
+		Scheme: "http",
+		Path:   path,
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sidecarURL.String(), nil)
+	if err != nil {
+		return false, errors.Wrap(err, "create http request")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, errors.Wrap(err, "do request")
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, errors.Wrap(err, "read response body")
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, errors.Errorf("%s %d", string(data), resp.StatusCode)
+	}
+
+	return true, errors.Wrap(json.Unmarshal(data, v), "failed to unmarshal")
+}
+
 func (c *sidecarClient) DeleteBackup(ctx context.Context, name string, cfg BackupConfig) error {
 	sidecarURL := url.URL{
 		Host:   c.srcNode + ":" + c.port(),
@@ -94,3 +150,32 @@ func (c *sidecarClient) DeleteBackup(ctx context.Context, name string, cfg Backu
 	}
 	return nil
 }
+
+// CancelBackup stops the running backup. The sidecar deletes the objects uploaded so far.
+// It returns nil if the backup is not running.
+func (c *sidecarClient) CancelBackup(ctx context.Context, name string) error {
+	sidecarURL := url.URL{
+		Host:   c.srcNode + ":" + c.port(),
+		Scheme: "http",
+		Path:   "/cancel/" + name,
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sidecarURL.String(), nil)
+	if err != nil {
+		return errors.Wrap(err, "create http request")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "cancel backup")
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNotFound:
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "read response body")
+	}
+	return errors.Errorf("cancel backup failed: %s %d", string(body), resp.StatusCode)
+}
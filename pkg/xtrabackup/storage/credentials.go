@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jwt"
+)
+
+// s3Credentials returns static credentials if the keys are set.
+// Otherwise temporary credentials are requested from STS with the web identity token of the pod
+// or, if there is no token, from the ECS or EC2 instance metadata.
+func s3Credentials(opts *S3Options) *credentials.Credentials {
+	if opts.AccessKeyID != "" || opts.SecretAccessKey != "" {
+		return credentials.NewStaticV4(opts.AccessKeyID, opts.SecretAccessKey, "")
+	}
+
+	tokenFile := opts.WebIdentityTokenFile
+	if tokenFile == "" {
+		tokenFile = os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	}
+	if tokenFile == "" {
+		return credentials.NewIAM("")
+	}
+
+	roleARN := opts.RoleARN
+	if roleARN == "" {
+		roleARN = os.Getenv("AWS_ROLE_ARN")
+	}
+
+	endpoint := opts.STSEndpoint
+	if endpoint == "" {
+		endpoint = credentials.DefaultSTSRoleEndpoint
+		if opts.Region != "" {
+			endpoint = "https://sts." + opts.Region + ".amazonaws.com"
+		}
+	}
+
+	return credentials.New(&credentials.STSWebIdentity{
+		Client:      &http.Client{Transport: http.DefaultTransport},
+		STSEndpoint: endpoint,
+		RoleARN:     roleARN,
+		GetWebIDTokenExpiry: func() (*credentials.WebIdentityToken, error) {
+			// The token is read on each refresh since kubelet rotates it.
+			token, err := os.ReadFile(tokenFile)
+			if err != nil {
+				return nil, errors.Wrap(err, "read web identity token")
+			}
+			return &credentials.WebIdentityToken{Token: strings.TrimSpace(string(token))}, nil
+		},
+	})
+}
+
+const (
+	gcsEndpoint     = "https://storage.googleapis.com"
+	gcsScope        = "https://www.googleapis.com/auth/devstorage.read_write"
+	gcsJWTTokenURL  = "https://oauth2.googleapis.com/token"
+	gceMetadataHost = "169.254.169.254"
+)
+
+func gcsTokenSource(ctx context.Context, serviceAccountKey string) (oauth2.TokenSource, error) {
+	if serviceAccountKey == "" {
+		host := os.Getenv("GCE_METADATA_HOST")
+		if host == "" {
+			host = gceMetadataHost
+		}
+		return oauth2.ReuseTokenSource(nil, &metadataTokenSource{
+			client: &http.Client{Timeout: 10 * time.Second},
+			url:    "http://" + host + "/computeMetadata/v1/instance/service-accounts/default/token",
+		}), nil
+	}
+
+	var key struct {
+		Type         string `json:"type"`
+		ClientEmail  string `json:"client_email"`
+		PrivateKey   string `json:"private_key"`
+		PrivateKeyID string `json:"private_key_id"`
+		TokenURI     string `json:"token_uri"`
+	}
+	if err := json.Unmarshal([]byte(serviceAccountKey), &key); err != nil {
+		return nil, errors.Wrap(err, "parse service account key")
+	}
+	if key.Type != "service_account" {
+		return nil, errors.Errorf("unsupported credentials type %q", key.Type)
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = gcsJWTTokenURL
+	}
+
+	conf := &jwt.Config{
+		Email:        key.ClientEmail,
+		PrivateKey:   []byte(key.PrivateKey),
+		PrivateKeyID: key.PrivateKeyID,
+		Scopes:       []string{gcsScope},
+		TokenURL:     key.TokenURI,
+	}
+	// The context is only used to get the HTTP client tokens are requested with,
+	// so it must not be canceled before the client stops being used.
+	return conf.TokenSource(context.WithoutCancel(ctx)), nil
+}
+
+// metadataTokenSource requests access tokens of the default service account from the GCE metadata server.
+type metadataTokenSource struct {
+	client *http.Client
+	url    string
+}
+
+func (s *metadataTokenSource) Token() (*oauth2.Token, error) {
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "new request")
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "request token from metadata server")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("metadata server returned %s", resp.Status)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+		TokenType   string `json:"token_type"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, errors.Wrap(err, "decode token")
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("metadata server returned empty token")
+	}
+
+	return &oauth2.Token{
+		AccessToken: token.AccessToken,
+		TokenType:   token.TokenType,
+		Expiry:      time.Now().Add(time.Duration(token.ExpiresIn) * time.Second),
+	}, nil
+}
+
+// azureIdentityCredential returns the workload identity credential if the federated token is injected
+// into the pod. Otherwise the managed identity of the node is used, AZURE_CLIENT_ID selects
+// the user-assigned identity.
+func azureIdentityCredential() (azcore.TokenCredential, error) {
+	if os.Getenv("AZURE_FEDERATED_TOKEN_FILE") != "" {
+		return azidentity.NewWorkloadIdentityCredential(nil)
+	}
+
+	opts := new(azidentity.ManagedIdentityCredentialOptions)
+	if id := os.Getenv("AZURE_CLIENT_ID"); id != "" {
+		opts.ID = azidentity.ClientID(id)
+	}
+	return azidentity.NewManagedIdentityCredential(opts)
+}
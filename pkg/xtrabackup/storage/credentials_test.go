@@ -0,0 +1,298 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeObjectStorage is a minimal S3-compatible stand-in that records the Authorization headers of requests.
+type fakeObjectStorage struct {
+	mu    sync.Mutex
+	auth  []string
+	token string
+
+	// handleAuth, if set, serves the requests to get credentials.
+	handleAuth func(w http.ResponseWriter, r *http.Request) bool
+}
+
+func (s *fakeObjectStorage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.handleAuth != nil && s.handleAuth(w, r) {
+		return
+	}
+
+	s.mu.Lock()
+	s.auth = append(s.auth, r.Header.Get("Authorization"))
+	s.token = r.Header.Get("X-Amz-Security-Token")
+	s.mu.Unlock()
+
+	if _, ok := r.URL.Query()["location"]; ok {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></LocationConstraint>`)
+	}
+}
+
+func (s *fakeObjectStorage) authHeaders() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.auth...)
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestS3WebIdentity(t *testing.T) {
+	ctx := context.Background()
+
+	tokenFile := writeFile(t, "token", "fake-web-identity-token\n")
+
+	var stsForm map[string][]string
+	stg := &fakeObjectStorage{
+		handleAuth: func(w http.ResponseWriter, r *http.Request) bool {
+			if r.Method != http.MethodPost || r.URL.Path != "/sts" {
+				return false
+			}
+			if err := r.ParseForm(); err != nil {
+				t.Error(err)
+			}
+			stsForm = r.PostForm
+			fmt.Fprint(w, `<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>ASIATEMPORARY</AccessKeyId>
+      <SecretAccessKey>temporary-secret</SecretAccessKey>
+      <SessionToken>session-token</SessionToken>
+      <Expiration>2100-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`)
+			return true
+		},
+	}
+	srv := httptest.NewServer(stg)
+	defer srv.Close()
+
+	_, err := NewClient(ctx, &S3Options{
+		Endpoint:             srv.URL,
+		RoleARN:              "arn:aws:iam::123456789012:role/backup",
+		WebIdentityTokenFile: tokenFile,
+		STSEndpoint:          srv.URL + "/sts",
+		BucketName:           "bucket",
+		Region:               "us-east-1",
+		VerifyTLS:            true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stsForm == nil {
+		t.Fatal("role is not assumed")
+	}
+	for key, expected := range map[string]string{
+		"Action":           "AssumeRoleWithWebIdentity",
+		"RoleArn":          "arn:aws:iam::123456789012:role/backup",
+		"WebIdentityToken": "fake-web-identity-token",
+	} {
+		if v := stsForm[key]; len(v) != 1 || v[0] != expected {
+			t.Errorf("expected %s=%s in STS request, got %v", key, expected, v)
+		}
+	}
+
+	auth := stg.authHeaders()
+	if len(auth) == 0 {
+		t.Fatal("no requests to the storage")
+	}
+	for _, a := range auth {
+		if !strings.Contains(a, "Credential=ASIATEMPORARY/") {
+			t.Errorf("request is not signed with temporary credentials: %q", a)
+		}
+	}
+	if stg.token != "session-token" {
+		t.Errorf("expected session token to be sent, got %q", stg.token)
+	}
+}
+
+func TestS3StaticKeys(t *testing.T) {
+	stg := new(fakeObjectStorage)
+	srv := httptest.NewServer(stg)
+	defer srv.Close()
+
+	// The web identity token of the pod must not be used if the keys are set.
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", "/nonexistent")
+
+	_, err := NewClient(context.Background(), &S3Options{
+		Endpoint:        srv.URL,
+		AccessKeyID:     "AKIASTATIC",
+		SecretAccessKey: "static-secret",
+		BucketName:      "bucket",
+		Region:          "us-east-1",
+		VerifyTLS:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range stg.authHeaders() {
+		if !strings.Contains(a, "Credential=AKIASTATIC/") {
+			t.Errorf("request is not signed with static keys: %q", a)
+		}
+	}
+}
+
+func TestGCSServiceAccountKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var grantType string
+	stg := &fakeObjectStorage{
+		handleAuth: func(w http.ResponseWriter, r *http.Request) bool {
+			if r.URL.Path != "/token" {
+				return false
+			}
+			if err := r.ParseForm(); err != nil {
+				t.Error(err)
+			}
+			grantType = r.PostForm.Get("grant_type")
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"access_token":"service-account-token","token_type":"Bearer","expires_in":3600}`)
+			return true
+		},
+	}
+	srv := httptest.NewServer(stg)
+	defer srv.Close()
+
+	saKey, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   "backup@project.iam.gserviceaccount.com",
+		"private_key_id": "key-id",
+		"private_key":    string(keyPEM),
+		"token_uri":      srv.URL + "/token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewClient(context.Background(), &GCSOptions{
+		Endpoint:          srv.URL,
+		ServiceAccountKey: string(saKey),
+		BucketName:        "bucket",
+		VerifyTLS:         true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if grantType != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+		t.Errorf("unexpected grant type %q", grantType)
+	}
+	auth := stg.authHeaders()
+	if len(auth) == 0 {
+		t.Fatal("no requests to the storage")
+	}
+	for _, a := range auth {
+		if a != "Bearer service-account-token" {
+			t.Errorf("unexpected Authorization header %q", a)
+		}
+	}
+}
+
+func TestGCSMetadataServer(t *testing.T) {
+	requests := 0
+	stg := &fakeObjectStorage{
+		handleAuth: func(w http.ResponseWriter, r *http.Request) bool {
+			if r.URL.Path != "/computeMetadata/v1/instance/service-accounts/default/token" {
+				return false
+			}
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				w.WriteHeader(http.StatusForbidden)
+				return true
+			}
+			requests++
+			fmt.Fprint(w, `{"access_token":"metadata-token","token_type":"Bearer","expires_in":3600}`)
+			return true
+		},
+	}
+	srv := httptest.NewServer(stg)
+	defer srv.Close()
+
+	t.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(srv.URL, "http://"))
+
+	_, err := NewClient(context.Background(), &GCSOptions{
+		Endpoint:   srv.URL,
+		BucketName: "bucket",
+		VerifyTLS:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if requests != 1 {
+		t.Errorf("expected the token to be requested once, got %d requests", requests)
+	}
+	for _, a := range stg.authHeaders() {
+		if a != "Bearer metadata-token" {
+			t.Errorf("unexpected Authorization header %q", a)
+		}
+	}
+}
+
+func TestAzureSASToken(t *testing.T) {
+	var query, auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		auth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/xml")
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?>
+<EnumerationResults ContainerName="container">
+  <Blobs>
+    <Blob><Name>prefix/backup/file</Name><Properties></Properties></Blob>
+  </Blobs>
+  <NextMarker />
+</EnumerationResults>`)
+	}))
+	defer srv.Close()
+
+	stg, err := NewClient(context.Background(), &AzureOptions{
+		StorageAccount: "account",
+		SASToken:       "?sv=2022-11-02&sp=rwdl&sig=c2lnbmF0dXJl",
+		Endpoint:       srv.URL + "/",
+		Container:      "container",
+		Prefix:         "prefix/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	objs, err := stg.ListObjects(context.Background(), "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 1 || objs[0] != "backup/file" {
+		t.Errorf("unexpected objects %v", objs)
+	}
+	if !strings.Contains(query, "sig=c2lnbmF0dXJl") || !strings.Contains(query, "sv=2022-11-02") {
+		t.Errorf("SAS token is not in the query: %s", query)
+	}
+	if auth != "" {
+		t.Errorf("request must not be signed with the account key, got %q", auth)
+	}
+}
@@ -1,9 +1,14 @@
 package fake
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup/storage"
 )
@@ -42,3 +47,67 @@ func (c *FakeStorageClient) ListObjects(ctx context.Context, prefix string) ([]s
 func (c *FakeStorageClient) DeleteObject(ctx context.Context, objectName string) error { return nil }
 func (c *FakeStorageClient) SetPrefix(prefix string)                                   {}
 func (c *FakeStorageClient) GetPrefix() string                                         { return "" }
+
+// MemoryStorage keeps objects in memory. ListObjects returns names in lexical order.
+type MemoryStorage struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	prefix  string
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{objects: make(map[string][]byte)}
+}
+
+func (m *MemoryStorage) GetObject(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, ok := m.objects[path.Join(m.prefix, objectName)]
+	if !ok {
+		return nil, storage.ErrObjectNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *MemoryStorage) PutObject(ctx context.Context, name string, data io.Reader, size int64) error {
+	b, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.objects[path.Join(m.prefix, name)] = b
+	return nil
+}
+
+func (m *MemoryStorage) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var list []string
+	for name := range m.objects {
+		if strings.HasPrefix(name, m.prefix+prefix) {
+			list = append(list, strings.TrimPrefix(name, m.prefix))
+		}
+	}
+	sort.Strings(list)
+	return list, nil
+}
+
+func (m *MemoryStorage) DeleteObject(ctx context.Context, objectName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name := path.Join(m.prefix, objectName)
+	if _, ok := m.objects[name]; !ok {
+		return storage.ErrObjectNotFound
+	}
+	delete(m.objects, name)
+	return nil
+}
+
+func (m *MemoryStorage) SetPrefix(prefix string) { m.prefix = prefix }
+func (m *MemoryStorage) GetPrefix() string       { return m.prefix }
@@ -2,6 +2,10 @@ package storage
 
 import (
 	"context"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -9,6 +13,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	apisecret "github.com/percona/percona-server-mysql-operator/pkg/secret"
 	"github.com/percona/percona-server-mysql-operator/pkg/xtrabackup"
 )
 
@@ -17,38 +22,179 @@ type Options interface {
 }
 
 func GetOptionsFromBackupConfig(cfg *xtrabackup.BackupConfig) (Options, error) {
+	tags, err := parseObjectTags(cfg.Tags)
+	if err != nil {
+		return nil, err
+	}
+
 	switch cfg.Type {
 	case apiv1alpha1.BackupStorageAzure:
 		a := cfg.Azure
+		immutability, err := parseObjectLock(a.ImmutabilityMode, a.ImmutabilityRetainFor, a.LegalHold)
+		if err != nil {
+			return nil, errors.Wrap(err, "immutability policy")
+		}
 		return &AzureOptions{
-			StorageAccount: a.StorageAccount,
-			AccessKey:      a.AccessKey,
-			Endpoint:       a.EndpointURL,
-			Container:      a.ContainerName,
+			StorageAccount:  a.StorageAccount,
+			AccessKey:       a.AccessKey,
+			SASToken:        a.SASToken,
+			Endpoint:        a.EndpointURL,
+			Container:       a.ContainerName,
+			EncryptionScope: a.EncryptionScope,
+			CustomerKey:     a.CustomerKey,
+			Tags:            tags,
+			Immutability:    immutability,
 		}, nil
 	case apiv1alpha1.BackupStorageGCS:
 		g := cfg.GCS
 		return &GCSOptions{
-			Endpoint:        g.EndpointURL,
-			AccessKeyID:     g.AccessKey,
-			SecretAccessKey: g.SecretKey,
-			BucketName:      g.Bucket,
-			VerifyTLS:       cfg.VerifyTLS,
+			Endpoint:          g.EndpointURL,
+			AccessKeyID:       g.AccessKey,
+			SecretAccessKey:   g.SecretKey,
+			ServiceAccountKey: g.ServiceAccountKey,
+			BucketName:        g.Bucket,
+			VerifyTLS:         cfg.VerifyTLS,
+			KMSKeyName:        g.KMSKeyName,
+			Metadata:          tags,
 		}, nil
 	case apiv1alpha1.BackupStorageS3:
 		s3 := cfg.S3
+		lock, err := parseObjectLock(s3.ObjectLockMode, s3.ObjectLockRetainFor, s3.ObjectLockLegalHold)
+		if err != nil {
+			return nil, errors.Wrap(err, "object lock")
+		}
 		return &S3Options{
-			Endpoint:        s3.EndpointURL,
-			AccessKeyID:     s3.AccessKey,
-			SecretAccessKey: s3.SecretKey,
-			BucketName:      s3.Bucket,
-			Region:          s3.Region,
-			VerifyTLS:       cfg.VerifyTLS,
+			Endpoint:             s3.EndpointURL,
+			AccessKeyID:          s3.AccessKey,
+			SecretAccessKey:      s3.SecretKey,
+			RoleARN:              s3.RoleARN,
+			WebIdentityTokenFile: s3.WebIdentityTokenFile,
+			STSEndpoint:          s3.STSEndpoint,
+			BucketName:           s3.Bucket,
+			Region:               s3.Region,
+			VerifyTLS:            cfg.VerifyTLS,
+			SSEAlgorithm:         s3.SSEAlgorithm,
+			KMSKeyID:             s3.KMSKeyID,
+			SSECustomerKey:       s3.SSECustomerKey,
+			Tags:                 tags,
+			ObjectLock:           lock,
 		}, nil
 	}
 	return nil, errors.Errorf("storage type %s is not supported", cfg.Type)
 }
 
+// parseObjectTags parses tags encoded by xtrabackup.EncodeObjectTags.
+func parseObjectTags(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	values, err := url.ParseQuery(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse object tags")
+	}
+	tags := make(map[string]string, len(values))
+	for k := range values {
+		tags[k] = values.Get(k)
+	}
+	return tags, nil
+}
+
+func parseObjectLock(mode, retainFor string, legalHold bool) (ObjectLock, error) {
+	lock := ObjectLock{Mode: mode, LegalHold: legalHold}
+	if mode == "" {
+		return lock, nil
+	}
+	d, err := time.ParseDuration(retainFor)
+	if err != nil {
+		return lock, errors.Wrap(err, "parse retention period")
+	}
+	lock.RetainFor = d
+	return lock, nil
+}
+
+// GetOptionsFromEnv returns options of the storage set to the job container by xtrabackup.SetStorage* functions.
+func GetOptionsFromEnv(prefix string) (Options, error) {
+	return getOptionsFromEnv(os.Getenv, prefix)
+}
+
+// GetTargetOptionsFromEnv returns options of the storage the backup is copied to by the job
+// created with xtrabackup.CopyJob.
+func GetTargetOptionsFromEnv(prefix string) (Options, error) {
+	return getOptionsFromEnv(func(key string) string {
+		return os.Getenv(xtrabackup.TargetEnvPrefix + key)
+	}, prefix)
+}
+
+func getOptionsFromEnv(getenv func(string) string, prefix string) (Options, error) {
+	verifyTLS, err := strconv.ParseBool(getenv("VERIFY_TLS"))
+	if err != nil {
+		verifyTLS = true
+	}
+
+	tags, err := parseObjectTags(getenv("OBJECT_TAGS"))
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := apiv1alpha1.BackupStorageType(getenv("STORAGE_TYPE")); t {
+	case apiv1alpha1.BackupStorageS3:
+		legalHold, _ := strconv.ParseBool(getenv("S3_OBJECT_LOCK_LEGAL_HOLD"))
+		lock, err := parseObjectLock(getenv("S3_OBJECT_LOCK_MODE"), getenv("S3_OBJECT_LOCK_RETAIN_FOR"), legalHold)
+		if err != nil {
+			return nil, errors.Wrap(err, "object lock")
+		}
+		return &S3Options{
+			Endpoint:             getenv("AWS_ENDPOINT"),
+			AccessKeyID:          getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey:      getenv("AWS_SECRET_ACCESS_KEY"),
+			RoleARN:              getenv("S3_ROLE_ARN"),
+			WebIdentityTokenFile: getenv("S3_WEB_IDENTITY_TOKEN_FILE"),
+			STSEndpoint:          getenv("S3_STS_ENDPOINT"),
+			BucketName:           getenv("S3_BUCKET"),
+			Prefix:               prefix,
+			Region:               getenv("AWS_DEFAULT_REGION"),
+			VerifyTLS:            verifyTLS,
+			SSEAlgorithm:         getenv("S3_SSE_ALGORITHM"),
+			KMSKeyID:             getenv("S3_SSE_KMS_KEY_ID"),
+			SSECustomerKey:       getenv("S3_SSE_CUSTOMER_KEY"),
+			Tags:                 tags,
+			ObjectLock:           lock,
+		}, nil
+	case apiv1alpha1.BackupStorageGCS:
+		return &GCSOptions{
+			Endpoint:          getenv("GCS_ENDPOINT"),
+			AccessKeyID:       getenv("ACCESS_KEY_ID"),
+			SecretAccessKey:   getenv("SECRET_ACCESS_KEY"),
+			ServiceAccountKey: getenv("GCS_SERVICE_ACCOUNT_KEY"),
+			BucketName:        getenv("GCS_BUCKET"),
+			Prefix:            prefix,
+			VerifyTLS:         verifyTLS,
+			KMSKeyName:        getenv("GCS_KMS_KEY_NAME"),
+			Metadata:          tags,
+		}, nil
+	case apiv1alpha1.BackupStorageAzure:
+		legalHold, _ := strconv.ParseBool(getenv("AZURE_LEGAL_HOLD"))
+		immutability, err := parseObjectLock(getenv("AZURE_IMMUTABILITY_MODE"), getenv("AZURE_IMMUTABILITY_RETAIN_FOR"), legalHold)
+		if err != nil {
+			return nil, errors.Wrap(err, "immutability policy")
+		}
+		return &AzureOptions{
+			StorageAccount:  getenv("AZURE_STORAGE_ACCOUNT"),
+			AccessKey:       getenv("AZURE_ACCESS_KEY"),
+			SASToken:        getenv("AZURE_SAS_TOKEN"),
+			Endpoint:        getenv("AZURE_ENDPOINT"),
+			Container:       getenv("AZURE_CONTAINER_NAME"),
+			Prefix:          prefix,
+			EncryptionScope: getenv("AZURE_ENCRYPTION_SCOPE"),
+			CustomerKey:     getenv("AZURE_CUSTOMER_KEY"),
+			Tags:            tags,
+			Immutability:    immutability,
+		}, nil
+	default:
+		return nil, errors.Errorf("storage type %s is not supported", t)
+	}
+}
+
 func GetOptionsFromBackup(ctx context.Context, cl client.Client, cluster *apiv1alpha1.PerconaServerMySQL, backup *apiv1alpha1.PerconaServerMySQLBackup) (Options, error) {
 	switch {
 	case backup.Status.Storage.S3 != nil:
@@ -62,15 +208,155 @@ func GetOptionsFromBackup(ctx context.Context, cl client.Client, cluster *apiv1a
 	}
 }
 
-func getGCSOptions(ctx context.Context, cl client.Client, cluster *apiv1alpha1.PerconaServerMySQL, backup *apiv1alpha1.PerconaServerMySQLBackup) (Options, error) {
+// GetOptionsFromStorage returns options to access the storage from the cluster's spec.backup.storages.
+// Filesystem storages are not supported.
+func GetOptionsFromStorage(ctx context.Context, cl client.Client, namespace string, stg *apiv1alpha1.BackupStorageSpec) (Options, error) {
+	verifyTLS := true
+	if stg.VerifyTLS != nil {
+		verifyTLS = *stg.VerifyTLS
+	}
+
+	getSecret := func(name string) (*corev1.Secret, error) {
+		return getCredentialsSecret(ctx, cl, namespace, name)
+	}
+
+	switch stg.Type {
+	case apiv1alpha1.BackupStorageS3:
+		if stg.S3 == nil {
+			return nil, errors.New("s3 stanza is empty")
+		}
+		secret, err := getSecret(stg.S3.CredentialsSecret)
+		if err != nil {
+			return nil, err
+		}
+		bucket, prefix := stg.S3.BucketAndPrefix()
+		region := stg.S3.Region
+		if region == "" {
+			region = "us-east-1"
+		}
+		opts := &S3Options{
+			Endpoint:             stg.S3.EndpointURL,
+			AccessKeyID:          string(secret.Data[apisecret.CredentialsAWSAccessKey]),
+			SecretAccessKey:      string(secret.Data[apisecret.CredentialsAWSSecretKey]),
+			RoleARN:              stg.S3.RoleARN,
+			WebIdentityTokenFile: stg.S3.WebIdentityTokenFile,
+			STSEndpoint:          stg.S3.STSEndpoint,
+			BucketName:           bucket,
+			Prefix:               prefix,
+			Region:               region,
+			VerifyTLS:            verifyTLS,
+		}
+		if err := setS3Encryption(ctx, cl, namespace, opts, stg.S3.ServerSideEncryption); err != nil {
+			return nil, err
+		}
+		return opts, nil
+	case apiv1alpha1.BackupStorageGCS:
+		if stg.GCS == nil {
+			return nil, errors.New("gcs stanza is empty")
+		}
+		secret, err := getSecret(stg.GCS.CredentialsSecret)
+		if err != nil {
+			return nil, err
+		}
+		bucket, prefix := stg.GCS.BucketAndPrefix()
+		return &GCSOptions{
+			Endpoint:          stg.GCS.EndpointURL,
+			AccessKeyID:       string(secret.Data["AWS_ACCESS_KEY_ID"]),
+			SecretAccessKey:   string(secret.Data["AWS_SECRET_ACCESS_KEY"]),
+			ServiceAccountKey: string(secret.Data[apisecret.CredentialsGCSServiceAccount]),
+			BucketName:        bucket,
+			Prefix:            prefix,
+			VerifyTLS:         verifyTLS,
+			KMSKeyName:        stg.GCS.KMSKeyName,
+		}, nil
+	case apiv1alpha1.BackupStorageAzure:
+		if stg.Azure == nil {
+			return nil, errors.New("azure stanza is empty")
+		}
+		secret, err := getSecret(stg.Azure.CredentialsSecret)
+		if err != nil {
+			return nil, err
+		}
+		customerKey, err := getSecretKey(ctx, cl, namespace, stg.Azure.CustomerKeySecret)
+		if err != nil {
+			return nil, err
+		}
+		container, prefix := stg.Azure.ContainerAndPrefix()
+		return &AzureOptions{
+			StorageAccount:  azureStorageAccount(stg.Azure, secret),
+			AccessKey:       string(secret.Data[apisecret.CredentialsAzureAccessKey]),
+			SASToken:        string(secret.Data[apisecret.CredentialsAzureSASToken]),
+			Endpoint:        stg.Azure.EndpointURL,
+			Container:       container,
+			Prefix:          prefix,
+			EncryptionScope: stg.Azure.EncryptionScope,
+			CustomerKey:     customerKey,
+		}, nil
+	default:
+		return nil, errors.Errorf("storage type %s is not supported", stg.Type)
+	}
+}
+
+// getCredentialsSecret returns the secret with the storage credentials.
+// An empty secret is returned if the name is not set, the storage is accessed with the identity of the pod then.
+func getCredentialsSecret(ctx context.Context, cl client.Client, namespace, name string) (*corev1.Secret, error) {
 	secret := new(corev1.Secret)
-	err := cl.Get(ctx, types.NamespacedName{
-		Name:      backup.Status.Storage.GCS.CredentialsSecret,
-		Namespace: backup.Namespace,
-	}, secret)
-	if client.IgnoreNotFound(err) != nil {
+	if name == "" {
+		return secret, nil
+	}
+	if err := cl.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+		return nil, errors.Wrapf(err, "get secret %s", name)
+	}
+	return secret, nil
+}
+
+// getSecretKey returns the value of the secret key or an empty string if the selector is not set.
+func getSecretKey(ctx context.Context, cl client.Client, namespace string, sel *corev1.SecretKeySelector) (string, error) {
+	if sel == nil {
+		return "", nil
+	}
+	secret, err := getCredentialsSecret(ctx, cl, namespace, sel.Name)
+	if err != nil {
+		return "", err
+	}
+	value, ok := secret.Data[sel.Key]
+	if !ok {
+		return "", errors.Errorf("no key %s in secret %s", sel.Key, sel.Name)
+	}
+	return string(value), nil
+}
+
+// setS3Encryption sets the server-side encryption options. The customer key is needed to read the objects.
+func setS3Encryption(ctx context.Context, cl client.Client, namespace string, opts *S3Options, sse *apiv1alpha1.S3ServerSideEncryption) error {
+	if sse == nil {
+		return nil
+	}
+	key, err := getSecretKey(ctx, cl, namespace, sse.SSECustomerKeySecret)
+	if err != nil {
+		return err
+	}
+	opts.SSEAlgorithm = sse.SSEAlgorithm
+	opts.KMSKeyID = sse.KMSKeyID
+	opts.SSECustomerKey = key
+	return nil
+}
+
+// azureStorageAccount returns the storage account from the credentials secret or, if it's not there, from the spec.
+func azureStorageAccount(azure *apiv1alpha1.BackupStorageAzureSpec, secret *corev1.Secret) string {
+	if account, ok := secret.Data[apisecret.CredentialsAzureStorageAccount]; ok {
+		return string(account)
+	}
+	return azure.StorageAccount
+}
+
+func getGCSOptions(ctx context.Context, cl client.Client, cluster *apiv1alpha1.PerconaServerMySQL, backup *apiv1alpha1.PerconaServerMySQLBackup) (Options, error) {
+	secret, err := getCredentialsSecret(ctx, cl, backup.Namespace, backup.Status.Storage.GCS.CredentialsSecret)
+	if client.IgnoreNotFound(errors.Cause(err)) != nil {
 		return nil, errors.Wrap(err, "failed to get secret")
 	}
+	if secret == nil {
+		secret = new(corev1.Secret)
+	}
 	accessKeyID := string(secret.Data["AWS_ACCESS_KEY_ID"])
 	secretAccessKey := string(secret.Data["AWS_SECRET_ACCESS_KEY"])
 
@@ -95,25 +381,23 @@ func getGCSOptions(ctx context.Context, cl client.Client, cluster *apiv1alpha1.P
 	}
 
 	return &GCSOptions{
-		Endpoint:        backup.Status.Storage.GCS.EndpointURL,
-		AccessKeyID:     accessKeyID,
-		SecretAccessKey: secretAccessKey,
-		BucketName:      bucket,
-		Prefix:          prefix,
-		VerifyTLS:       verifyTLS,
+		Endpoint:          backup.Status.Storage.GCS.EndpointURL,
+		AccessKeyID:       accessKeyID,
+		SecretAccessKey:   secretAccessKey,
+		ServiceAccountKey: string(secret.Data[apisecret.CredentialsGCSServiceAccount]),
+		BucketName:        bucket,
+		Prefix:            prefix,
+		VerifyTLS:         verifyTLS,
+		KMSKeyName:        backup.Status.Storage.GCS.KMSKeyName,
 	}, nil
 }
 
 func getAzureOptions(ctx context.Context, cl client.Client, backup *apiv1alpha1.PerconaServerMySQLBackup) (*AzureOptions, error) {
-	secret := new(corev1.Secret)
-	err := cl.Get(ctx, types.NamespacedName{
-		Name:      backup.Status.Storage.Azure.CredentialsSecret,
-		Namespace: backup.Namespace,
-	}, secret)
+	secret, err := getCredentialsSecret(ctx, cl, backup.Namespace, backup.Status.Storage.Azure.CredentialsSecret)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get secret")
 	}
-	accountName := string(secret.Data["AZURE_STORAGE_ACCOUNT_NAME"])
+	accountName := azureStorageAccount(backup.Status.Storage.Azure, secret)
 	accountKey := string(secret.Data["AZURE_STORAGE_ACCOUNT_KEY"])
 
 	container, prefix := backup.Status.Storage.Azure.ContainerAndPrefix()
@@ -125,24 +409,31 @@ func getAzureOptions(ctx context.Context, cl client.Client, backup *apiv1alpha1.
 		return nil, errors.New("container name is not set")
 	}
 
+	customerKey, err := getSecretKey(ctx, cl, backup.Namespace, backup.Status.Storage.Azure.CustomerKeySecret)
+	if err != nil {
+		return nil, err
+	}
+
 	return &AzureOptions{
-		StorageAccount: accountName,
-		AccessKey:      accountKey,
-		Endpoint:       backup.Status.Storage.Azure.EndpointURL,
-		Container:      container,
-		Prefix:         prefix,
+		StorageAccount:  accountName,
+		AccessKey:       accountKey,
+		SASToken:        string(secret.Data[apisecret.CredentialsAzureSASToken]),
+		Endpoint:        backup.Status.Storage.Azure.EndpointURL,
+		Container:       container,
+		Prefix:          prefix,
+		EncryptionScope: backup.Status.Storage.Azure.EncryptionScope,
+		CustomerKey:     customerKey,
 	}, nil
 }
 
 func getS3Options(ctx context.Context, cl client.Client, cluster *apiv1alpha1.PerconaServerMySQL, backup *apiv1alpha1.PerconaServerMySQLBackup) (*S3Options, error) {
-	secret := new(corev1.Secret)
-	err := cl.Get(ctx, types.NamespacedName{
-		Name:      backup.Status.Storage.S3.CredentialsSecret,
-		Namespace: backup.Namespace,
-	}, secret)
-	if client.IgnoreNotFound(err) != nil {
+	secret, err := getCredentialsSecret(ctx, cl, backup.Namespace, backup.Status.Storage.S3.CredentialsSecret)
+	if client.IgnoreNotFound(errors.Cause(err)) != nil {
 		return nil, errors.Wrap(err, "failed to get secret")
 	}
+	if secret == nil {
+		secret = new(corev1.Secret)
+	}
 	accessKeyID := string(secret.Data["AWS_ACCESS_KEY_ID"])
 	secretAccessKey := string(secret.Data["AWS_SECRET_ACCESS_KEY"])
 
@@ -171,15 +462,22 @@ func getS3Options(ctx context.Context, cl client.Client, cluster *apiv1alpha1.Pe
 		}
 	}
 
-	return &S3Options{
-		Endpoint:        backup.Status.Storage.S3.EndpointURL,
-		AccessKeyID:     accessKeyID,
-		SecretAccessKey: secretAccessKey,
-		BucketName:      bucket,
-		Prefix:          prefix,
-		Region:          region,
-		VerifyTLS:       verifyTLS,
-	}, nil
+	opts := &S3Options{
+		Endpoint:             backup.Status.Storage.S3.EndpointURL,
+		AccessKeyID:          accessKeyID,
+		SecretAccessKey:      secretAccessKey,
+		RoleARN:              backup.Status.Storage.S3.RoleARN,
+		WebIdentityTokenFile: backup.Status.Storage.S3.WebIdentityTokenFile,
+		STSEndpoint:          backup.Status.Storage.S3.STSEndpoint,
+		BucketName:           bucket,
+		Prefix:               prefix,
+		Region:               region,
+		VerifyTLS:            verifyTLS,
+	}
+	if err := setS3Encryption(ctx, cl, backup.Namespace, opts, backup.Status.Storage.S3.ServerSideEncryption); err != nil {
+		return nil, err
+	}
+	return opts, nil
 }
 
 var _ = Options(new(S3Options))
@@ -188,10 +486,22 @@ type S3Options struct {
 	Endpoint        string
 	AccessKeyID     string
 	SecretAccessKey string
-	BucketName      string
-	Prefix          string
-	Region          string
-	VerifyTLS       bool
+	// RoleARN, WebIdentityTokenFile and STSEndpoint are used to get credentials
+	// from the web identity token if the keys are empty.
+	RoleARN              string
+	WebIdentityTokenFile string
+	STSEndpoint          string
+	BucketName           string
+	Prefix               string
+	Region               string
+	VerifyTLS            bool
+
+	// SSEAlgorithm is AES256 or aws:kms. SSECustomerKey is a base64 encoded key used instead of it.
+	SSEAlgorithm   string
+	KMSKeyID       string
+	SSECustomerKey string
+	Tags           map[string]string
+	ObjectLock     ObjectLock
 }
 
 func (o *S3Options) Type() apiv1alpha1.BackupStorageType {
@@ -204,9 +514,14 @@ type GCSOptions struct {
 	Endpoint        string
 	AccessKeyID     string
 	SecretAccessKey string
-	BucketName      string
-	Prefix          string
-	VerifyTLS       bool
+	// ServiceAccountKey is a service account JSON key used if HMAC keys are empty.
+	// Without it the token is requested from the metadata server.
+	ServiceAccountKey string
+	BucketName        string
+	Prefix            string
+	VerifyTLS         bool
+	KMSKeyName        string
+	Metadata          map[string]string
 }
 
 func (o *GCSOptions) Type() apiv1alpha1.BackupStorageType {
@@ -218,11 +533,28 @@ var _ = Options(new(AzureOptions))
 type AzureOptions struct {
 	StorageAccount string
 	AccessKey      string
-	Endpoint       string
-	Container      string
-	Prefix         string
+	// SASToken is used if AccessKey is empty. Without both the managed identity is used.
+	SASToken  string
+	Endpoint  string
+	Container string
+	Prefix    string
+
+	EncryptionScope string
+	// CustomerKey is a base64 encoded key blobs are encrypted with.
+	CustomerKey  string
+	Tags         map[string]string
+	Immutability ObjectLock
 }
 
 func (o *AzureOptions) Type() apiv1alpha1.BackupStorageType {
 	return apiv1alpha1.BackupStorageAzure
 }
+
+// ObjectLock protects every object written to the storage from deletion and overwriting.
+type ObjectLock struct {
+	// Mode is GOVERNANCE or COMPLIANCE for S3, Unlocked or Locked for Azure.
+	// The object is not locked if it's empty.
+	Mode      string
+	RetainFor time.Duration
+	LegalHold bool
+}
@@ -2,19 +2,25 @@ package storage
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"net/http"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
 	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
 
 	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
 )
@@ -39,19 +45,28 @@ func NewClient(ctx context.Context, opts Options) (Storage, error) {
 		if !ok {
 			return nil, errors.New("invalid options type")
 		}
-		return NewS3(ctx, opts.Endpoint, opts.AccessKeyID, opts.SecretAccessKey, opts.BucketName, opts.Prefix, opts.Region, opts.VerifyTLS)
+		sse, err := s3ServerSideEncryption(opts.SSEAlgorithm, opts.KMSKeyID, opts.SSECustomerKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "server-side encryption")
+		}
+		s, err := newS3(ctx, opts.Endpoint, s3Credentials(opts), nil, opts.BucketName, opts.Prefix, opts.Region, opts.VerifyTLS)
+		if err != nil {
+			return nil, err
+		}
+		s.objectOpts = s3ObjectOptions{sse: sse, tags: opts.Tags, lock: opts.ObjectLock}
+		return s, nil
 	case apiv1alpha1.BackupStorageGCS:
 		opts, ok := opts.(*GCSOptions)
 		if !ok {
 			return nil, errors.New("invalid options type")
 		}
-		return NewGCS(ctx, opts.Endpoint, opts.AccessKeyID, opts.SecretAccessKey, opts.BucketName, opts.Prefix, opts.VerifyTLS)
+		return newGCS(ctx, opts)
 	case apiv1alpha1.BackupStorageAzure:
 		opts, ok := opts.(*AzureOptions)
 		if !ok {
 			return nil, errors.New("invalid options type")
 		}
-		return NewAzure(opts.StorageAccount, opts.AccessKey, opts.Endpoint, opts.Container, opts.Prefix)
+		return newAzure(opts)
 	}
 	return nil, errors.New("invalid storage type")
 }
@@ -61,6 +76,33 @@ type S3 struct {
 	client     *minio.Client // minio client for work with storage
 	bucketName string        // S3 bucket name where binlogs will be stored
 	prefix     string        // prefix for S3 requests
+	objectOpts s3ObjectOptions
+}
+
+// s3ObjectOptions are applied to every object written to the storage.
+type s3ObjectOptions struct {
+	sse      encrypt.ServerSide
+	tags     map[string]string
+	metadata map[string]string
+	lock     ObjectLock
+}
+
+func s3ServerSideEncryption(algorithm, kmsKeyID, customerKey string) (encrypt.ServerSide, error) {
+	switch {
+	case customerKey != "":
+		key, err := base64.StdEncoding.DecodeString(customerKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "decode customer key")
+		}
+		return encrypt.NewSSEC(key)
+	case algorithm == "aws:kms":
+		return encrypt.NewSSEKMS(kmsKeyID, nil)
+	case algorithm == "AES256":
+		return encrypt.NewSSE(), nil
+	case algorithm == "":
+		return nil, nil
+	}
+	return nil, errors.Errorf("unknown algorithm %s", algorithm)
 }
 
 // NewGCS return new Manager, useSSL using ssl for connection with storage
@@ -72,8 +114,85 @@ func NewGCS(ctx context.Context, endpoint, accessKeyID, secretAccessKey, bucketN
 	return NewS3(ctx, endpoint, accessKeyID, secretAccessKey, bucketName, prefix, region, verifyTLS)
 }
 
+// newGCS creates the client for the XML API of GCS. Requests are signed with HMAC keys if they are set,
+// otherwise they are authorized with OAuth 2.0 tokens.
+func newGCS(ctx context.Context, opts *GCSOptions) (Storage, error) {
+	endpoint := opts.Endpoint
+	if endpoint == "" {
+		endpoint = gcsEndpoint
+	}
+
+	creds := credentials.NewStaticV4(opts.AccessKeyID, opts.SecretAccessKey, "")
+	var wrappers []func(http.RoundTripper) http.RoundTripper
+	if opts.AccessKeyID == "" && opts.SecretAccessKey == "" {
+		ts, err := gcsTokenSource(ctx, opts.ServiceAccountKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "get token source")
+		}
+		// Requests are not signed, the token is set to the Authorization header by the transport.
+		creds = credentials.NewStatic("", "", "", credentials.SignatureAnonymous)
+		wrappers = append(wrappers, func(base http.RoundTripper) http.RoundTripper {
+			return &oauth2.Transport{Source: ts, Base: base}
+		})
+	}
+	if opts.KMSKeyName != "" {
+		wrappers = append(wrappers, func(base http.RoundTripper) http.RoundTripper {
+			return &gcsKMSTransport{keyName: opts.KMSKeyName, base: base}
+		})
+	}
+	wrap := func(rt http.RoundTripper) http.RoundTripper {
+		for _, w := range wrappers {
+			rt = w(rt)
+		}
+		return rt
+	}
+
+	s, err := newS3(ctx, endpoint, creds, wrap, opts.BucketName, opts.Prefix, "", opts.VerifyTLS)
+	if err != nil {
+		return nil, err
+	}
+	// GCS doesn't support object tags, custom metadata is set instead.
+	s.objectOpts = s3ObjectOptions{metadata: opts.Metadata}
+	return s, nil
+}
+
+// gcsKMSTransport sets the Cloud KMS key to the requests creating objects.
+// The key can't be set with the S3 server-side encryption headers.
+type gcsKMSTransport struct {
+	keyName string
+	base    http.RoundTripper
+}
+
+func (t *gcsKMSTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	q := req.URL.Query()
+	isPut := req.Method == http.MethodPut && !q.Has("partNumber")
+	isMultipartInit := req.Method == http.MethodPost && q.Has("uploads")
+	if isPut || isMultipartInit {
+		req = req.Clone(req.Context())
+		req.Header.Set("x-goog-encryption-kms-key-name", t.keyName)
+	}
+	return t.base.RoundTrip(req)
+}
+
 // NewS3 return new Manager, useSSL using ssl for connection with storage
 func NewS3(ctx context.Context, endpoint, accessKeyID, secretAccessKey, bucketName, prefix, region string, verifyTLS bool) (Storage, error) {
+	s, err := newS3(ctx, endpoint, credentials.NewStaticV4(accessKeyID, secretAccessKey, ""), nil, bucketName, prefix, region, verifyTLS)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// newS3 creates the client with the given credentials. wrapTransport, if set, wraps the transport
+// of the client to authorize requests that are not signed with the credentials.
+func newS3(
+	ctx context.Context,
+	endpoint string,
+	creds *credentials.Credentials,
+	wrapTransport func(http.RoundTripper) http.RoundTripper,
+	bucketName, prefix, region string,
+	verifyTLS bool,
+) (*S3, error) {
 	if endpoint == "" {
 		endpoint = "https://s3.amazonaws.com"
 		// We can't use default endpoint if region is not us-east-1
@@ -88,8 +207,11 @@ func NewS3(ctx context.Context, endpoint, accessKeyID, secretAccessKey, bucketNa
 	transport.(*http.Transport).TLSClientConfig = &tls.Config{
 		InsecureSkipVerify: !verifyTLS,
 	}
+	if wrapTransport != nil {
+		transport = wrapTransport(transport)
+	}
 	minioClient, err := minio.New(strings.TrimRight(endpoint, "/"), &minio.Options{
-		Creds:     credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
+		Creds:     creds,
 		Secure:    useSSL,
 		Region:    region,
 		Transport: transport,
@@ -119,7 +241,12 @@ func NewS3(ctx context.Context, endpoint, accessKeyID, secretAccessKey, bucketNa
 // GetObject return content by given object name
 func (s *S3) GetObject(ctx context.Context, objectName string) (io.ReadCloser, error) {
 	objPath := path.Join(s.prefix, objectName)
-	oldObj, err := s.client.GetObject(ctx, s.bucketName, objPath, minio.GetObjectOptions{})
+	opts := minio.GetObjectOptions{}
+	// Objects encrypted with the customer key can't be read without it.
+	if sse := s.objectOpts.sse; sse != nil && sse.Type() == encrypt.SSEC {
+		opts.ServerSideEncryption = sse
+	}
+	oldObj, err := s.client.GetObject(ctx, s.bucketName, objPath, opts)
 	if err != nil {
 		return nil, errors.Wrapf(err, "get object %s", objPath)
 	}
@@ -141,10 +268,19 @@ func (s *S3) GetObject(ctx context.Context, objectName string) (io.ReadCloser, e
 	return oldObj, nil
 }
 
-// PutObject puts new object to storage with given name and content
+// streamPartSize is the size of the parts objects of unknown size are uploaded in.
+// The client buffers a part in memory, by default it's hundreds of megabytes for such objects.
+const streamPartSize = 16 << 20
+
+// PutObject puts new object to storage with given name and content.
+// The size is -1 if it's unknown.
 func (s *S3) PutObject(ctx context.Context, name string, data io.Reader, size int64) error {
 	objPath := path.Join(s.prefix, name)
-	_, err := s.client.PutObject(ctx, s.bucketName, objPath, data, size, minio.PutObjectOptions{})
+	opts := s.putObjectOptions()
+	if size < 0 {
+		opts.PartSize = streamPartSize
+	}
+	_, err := s.client.PutObject(ctx, s.bucketName, objPath, data, size, opts)
 	if err != nil {
 		return errors.Wrapf(err, "put object %s", objPath)
 	}
@@ -152,6 +288,25 @@ func (s *S3) PutObject(ctx context.Context, name string, data io.Reader, size in
 	return nil
 }
 
+func (s *S3) putObjectOptions() minio.PutObjectOptions {
+	o := s.objectOpts
+	opts := minio.PutObjectOptions{
+		ServerSideEncryption: o.sse,
+		UserTags:             o.tags,
+		UserMetadata:         o.metadata,
+	}
+	if o.lock.Mode != "" {
+		opts.Mode = minio.RetentionMode(o.lock.Mode)
+		opts.RetainUntilDate = time.Now().Add(o.lock.RetainFor)
+		// Objects written to buckets with object lock must have Content-MD5.
+		opts.SendContentMd5 = true
+	}
+	if o.lock.LegalHold {
+		opts.LegalHold = minio.LegalHoldEnabled
+	}
+	return opts
+}
+
 func (s *S3) ListObjects(ctx context.Context, prefix string) ([]string, error) {
 	opts := minio.ListObjectsOptions{
 		UseV1:     true,
@@ -203,34 +358,101 @@ func (s *S3) DeleteObject(ctx context.Context, objectName string) error {
 
 // Azure is a type for working with Azure Blob storages
 type Azure struct {
-	client    *azblob.Client // azure client for work with storage
-	container string
-	prefix    string
+	client       *azblob.Client // azure client for work with storage
+	container    string
+	prefix       string
+	tags         map[string]string
+	cpk          *blob.CPKInfo
+	cpkScope     *blob.CPKScopeInfo
+	immutability ObjectLock
 }
 
 func NewAzure(storageAccount, accessKey, endpoint, container, prefix string) (Storage, error) {
-	credential, err := azblob.NewSharedKeyCredential(storageAccount, accessKey)
-	if err != nil {
-		return nil, errors.Wrap(err, "new credentials")
-	}
+	return newAzure(&AzureOptions{
+		StorageAccount: storageAccount,
+		AccessKey:      accessKey,
+		Endpoint:       endpoint,
+		Container:      container,
+		Prefix:         prefix,
+	})
+}
+
+// newAzure authorizes requests with the account key, the SAS token or, if both are empty, the managed identity.
+func newAzure(opts *AzureOptions) (Storage, error) {
+	endpoint := opts.Endpoint
 	if endpoint == "" {
-		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net/", storageAccount)
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net/", opts.StorageAccount)
 	}
-	cli, err := azblob.NewClientWithSharedKeyCredential(endpoint, credential, nil)
-	if err != nil {
-		return nil, errors.Wrap(err, "new client")
+
+	var cli *azblob.Client
+	switch {
+	case opts.AccessKey != "":
+		credential, err := azblob.NewSharedKeyCredential(opts.StorageAccount, opts.AccessKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "new credentials")
+		}
+		cli, err = azblob.NewClientWithSharedKeyCredential(endpoint, credential, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "new client")
+		}
+	case opts.SASToken != "":
+		var err error
+		cli, err = azblob.NewClientWithNoCredential(strings.TrimSuffix(endpoint, "?")+"?"+strings.TrimPrefix(opts.SASToken, "?"), nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "new client")
+		}
+	default:
+		credential, err := azureIdentityCredential()
+		if err != nil {
+			return nil, errors.Wrap(err, "new credentials")
+		}
+		cli, err = azblob.NewClient(endpoint, credential, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "new client")
+		}
+	}
+
+	a := &Azure{
+		client:       cli,
+		container:    opts.Container,
+		prefix:       opts.Prefix,
+		tags:         opts.Tags,
+		immutability: opts.Immutability,
+	}
+	if opts.EncryptionScope != "" {
+		a.cpkScope = &blob.CPKScopeInfo{EncryptionScope: &opts.EncryptionScope}
 	}
+	if opts.CustomerKey != "" {
+		cpk, err := azureCustomerKey(opts.CustomerKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "customer key")
+		}
+		a.cpk = cpk
+	}
+	return a, nil
+}
 
-	return &Azure{
-		client:    cli,
-		container: container,
-		prefix:    prefix,
+func azureCustomerKey(customerKey string) (*blob.CPKInfo, error) {
+	key, err := base64.StdEncoding.DecodeString(customerKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode")
+	}
+	if len(key) != 32 {
+		return nil, errors.New("key must be 256 bits long")
+	}
+	sum := sha256.Sum256(key)
+	keySHA256 := base64.StdEncoding.EncodeToString(sum[:])
+	algorithm := blob.EncryptionAlgorithmTypeAES256
+	return &blob.CPKInfo{
+		EncryptionAlgorithm: &algorithm,
+		EncryptionKey:       &customerKey,
+		EncryptionKeySHA256: &keySHA256,
 	}, nil
 }
 
 func (a *Azure) GetObject(ctx context.Context, name string) (io.ReadCloser, error) {
 	objPath := path.Join(a.prefix, name)
-	resp, err := a.client.DownloadStream(ctx, a.container, objPath, &azblob.DownloadStreamOptions{})
+	resp, err := a.client.DownloadStream(ctx, a.container, objPath, &azblob.DownloadStreamOptions{CPKInfo: a.cpk})
 	if err != nil {
 		if bloberror.HasCode(errors.Cause(err), bloberror.BlobNotFound) {
 			return nil, ErrObjectNotFound
@@ -242,15 +464,36 @@ func (a *Azure) GetObject(ctx context.Context, name string) (io.ReadCloser, erro
 
 func (a *Azure) PutObject(ctx context.Context, name string, data io.Reader, _ int64) error {
 	objPath := path.Join(a.prefix, name)
-	_, err := a.client.UploadStream(ctx, a.container, objPath, data, nil)
+	_, err := a.client.UploadStream(ctx, a.container, objPath, data, &azblob.UploadStreamOptions{
+		Tags:         a.tags,
+		CPKInfo:      a.cpk,
+		CPKScopeInfo: a.cpkScope,
+	})
 	if err != nil {
 		return errors.Wrapf(err, "upload stream: %s", objPath)
 	}
+
+	bc := a.client.ServiceClient().NewContainerClient(a.container).NewBlobClient(objPath)
+	if a.immutability.Mode != "" {
+		mode := blob.ImmutabilityPolicySetting(a.immutability.Mode)
+		expiry := time.Now().Add(a.immutability.RetainFor)
+		if _, err := bc.SetImmutabilityPolicy(ctx, expiry, &blob.SetImmutabilityPolicyOptions{Mode: &mode}); err != nil {
+			return errors.Wrapf(err, "set immutability policy: %s", objPath)
+		}
+	}
+	if a.immutability.LegalHold {
+		if _, err := bc.SetLegalHold(ctx, true, nil); err != nil {
+			return errors.Wrapf(err, "set legal hold: %s", objPath)
+		}
+	}
 	return nil
 }
 
 func (a *Azure) ListObjects(ctx context.Context, prefix string) ([]string, error) {
-	listPrefix := path.Join(a.prefix, prefix) + "/"
+	listPrefix := path.Join(a.prefix, prefix)
+	if listPrefix != "" {
+		listPrefix += "/"
+	}
 	pg := a.client.NewListBlobsFlatPager(a.container, &container.ListBlobsFlatOptions{
 		Prefix: &listPrefix,
 	})
@@ -0,0 +1,270 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingServer serves S3 and Azure Blob requests and records the headers of requests writing objects.
+type recordingServer struct {
+	mu     sync.Mutex
+	writes []*http.Request
+	reads  []*http.Request
+}
+
+func (s *recordingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	_, _ = io.Copy(io.Discard, r.Body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := r.URL.Query()
+	switch {
+	case q.Has("location"):
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></LocationConstraint>`)
+	case r.Method == http.MethodGet:
+		s.reads = append(s.reads, r)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		fmt.Fprint(w, "data")
+	case r.Method == http.MethodPut && q.Get("comp") == "" && r.Header.Get("X-Ms-Blob-Type") != "":
+		// Azure responds with 201 to the blob upload.
+		s.writes = append(s.writes, r)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut:
+		s.writes = append(s.writes, r)
+	}
+}
+
+func (s *recordingServer) requests() (writes, reads []*http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append(writes, s.writes...), append(reads, s.reads...)
+}
+
+func TestS3ObjectOptions(t *testing.T) {
+	ctx := context.Background()
+
+	srv := httptest.NewServer(new(recordingServer))
+	defer srv.Close()
+	rec := srv.Config.Handler.(*recordingServer)
+
+	stg, err := NewClient(ctx, &S3Options{
+		Endpoint:        srv.URL,
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+		BucketName:      "bucket",
+		Region:          "us-east-1",
+		SSEAlgorithm:    "aws:kms",
+		KMSKeyID:        "arn:aws:kms:us-east-1:123456789012:key/backup",
+		Tags:            map[string]string{"cluster": "cluster1", "backup-type": "full"},
+		ObjectLock:      ObjectLock{Mode: "COMPLIANCE", RetainFor: 24 * time.Hour, LegalHold: true},
+		VerifyTLS:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := stg.PutObject(ctx, "backup/file", strings.NewReader("data"), 4); err != nil {
+		t.Fatal(err)
+	}
+
+	writes, _ := rec.requests()
+	if len(writes) != 1 {
+		t.Fatalf("expected 1 write, got %d", len(writes))
+	}
+	h := writes[0].Header
+	for key, expected := range map[string]string{
+		"X-Amz-Server-Side-Encryption":                "aws:kms",
+		"X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id": "arn:aws:kms:us-east-1:123456789012:key/backup",
+		"X-Amz-Object-Lock-Mode":                      "COMPLIANCE",
+		"X-Amz-Object-Lock-Legal-Hold":                "ON",
+	} {
+		if v := h.Get(key); v != expected {
+			t.Errorf("expected %s: %s, got %q", key, expected, v)
+		}
+	}
+	if h.Get("Content-Md5") == "" {
+		t.Error("Content-MD5 must be set for objects with retention")
+	}
+
+	tags, err := url.ParseQuery(h.Get("X-Amz-Tagging"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags.Get("cluster") != "cluster1" || tags.Get("backup-type") != "full" {
+		t.Errorf("unexpected tags %v", tags)
+	}
+
+	retainUntil, err := time.Parse(time.RFC3339, h.Get("X-Amz-Object-Lock-Retain-Until-Date"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := retainUntil.Sub(start); d < 23*time.Hour || d > 25*time.Hour {
+		t.Errorf("unexpected retain until date %s", retainUntil)
+	}
+}
+
+func TestS3CustomerKey(t *testing.T) {
+	ctx := context.Background()
+
+	srv := httptest.NewServer(new(recordingServer))
+	defer srv.Close()
+	rec := srv.Config.Handler.(*recordingServer)
+
+	key := strings.Repeat("k", 32)
+	stg, err := NewClient(ctx, &S3Options{
+		Endpoint:        srv.URL,
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+		BucketName:      "bucket",
+		Region:          "us-east-1",
+		SSECustomerKey:  base64.StdEncoding.EncodeToString([]byte(key)),
+		VerifyTLS:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := stg.PutObject(ctx, "backup/file", strings.NewReader("data"), 4); err != nil {
+		t.Fatal(err)
+	}
+	r, err := stg.GetObject(ctx, "backup/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+
+	writes, reads := rec.requests()
+	if len(writes) != 1 || len(reads) == 0 {
+		t.Fatalf("expected 1 write and a read, got %d writes and %d reads", len(writes), len(reads))
+	}
+	// The key must be sent with both requests, otherwise the object can't be read.
+	for _, r := range []*http.Request{writes[0], reads[0]} {
+		if v := r.Header.Get("X-Amz-Server-Side-Encryption-Customer-Key"); v != base64.StdEncoding.EncodeToString([]byte(key)) {
+			t.Errorf("%s: unexpected customer key %q", r.Method, v)
+		}
+	}
+}
+
+func TestGCSKMSKey(t *testing.T) {
+	ctx := context.Background()
+
+	srv := httptest.NewServer(new(recordingServer))
+	defer srv.Close()
+	rec := srv.Config.Handler.(*recordingServer)
+
+	keyName := "projects/p/locations/global/keyRings/r/cryptoKeys/backup"
+	stg, err := NewClient(ctx, &GCSOptions{
+		Endpoint:        srv.URL,
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+		BucketName:      "bucket",
+		KMSKeyName:      keyName,
+		Metadata:        map[string]string{"cluster": "cluster1"},
+		VerifyTLS:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := stg.PutObject(ctx, "backup/file", strings.NewReader("data"), 4); err != nil {
+		t.Fatal(err)
+	}
+
+	writes, _ := rec.requests()
+	if len(writes) != 1 {
+		t.Fatalf("expected 1 write, got %d", len(writes))
+	}
+	h := writes[0].Header
+	if v := h.Get("X-Goog-Encryption-Kms-Key-Name"); v != keyName {
+		t.Errorf("unexpected KMS key %q", v)
+	}
+	if v := h.Get("X-Amz-Meta-Cluster"); v != "cluster1" {
+		t.Errorf("unexpected metadata %q", v)
+	}
+	if v := h.Get("X-Amz-Tagging"); v != "" {
+		t.Errorf("tags must not be set, got %q", v)
+	}
+}
+
+func TestAzureObjectOptions(t *testing.T) {
+	ctx := context.Background()
+
+	srv := httptest.NewServer(new(recordingServer))
+	defer srv.Close()
+	rec := srv.Config.Handler.(*recordingServer)
+
+	key := strings.Repeat("k", 32)
+	stg, err := NewClient(ctx, &AzureOptions{
+		StorageAccount: "account",
+		SASToken:       "sv=2022-11-02&sig=c2lnbmF0dXJl",
+		Endpoint:       srv.URL + "/",
+		Container:      "container",
+		CustomerKey:    base64.StdEncoding.EncodeToString([]byte(key)),
+		Tags:           map[string]string{"cluster": "cluster1"},
+		Immutability:   ObjectLock{Mode: "Unlocked", RetainFor: time.Hour, LegalHold: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := stg.PutObject(ctx, "backup/file", strings.NewReader("data"), 4); err != nil {
+		t.Fatal(err)
+	}
+
+	writes, _ := rec.requests()
+	if len(writes) != 3 {
+		t.Fatalf("expected upload, immutability policy and legal hold requests, got %d", len(writes))
+	}
+
+	commit := writes[0].Header
+	keySHA256 := sha256.Sum256([]byte(key))
+	for k, expected := range map[string]string{
+		"X-Ms-Tags":                  "cluster=cluster1",
+		"X-Ms-Encryption-Key":        base64.StdEncoding.EncodeToString([]byte(key)),
+		"X-Ms-Encryption-Key-Sha256": base64.StdEncoding.EncodeToString(keySHA256[:]),
+		"X-Ms-Encryption-Algorithm":  "AES256",
+	} {
+		if v := commit.Get(k); v != expected {
+			t.Errorf("expected %s: %s, got %q", k, expected, v)
+		}
+	}
+
+	policy := writes[1]
+	if comp := policy.URL.Query().Get("comp"); comp != "immutabilityPolicies" {
+		t.Errorf("expected immutability policy to be set, got comp=%s", comp)
+	}
+	if v := policy.Header.Get("X-Ms-Immutability-Policy-Mode"); v != "Unlocked" {
+		t.Errorf("unexpected immutability policy mode %q", v)
+	}
+	legalHold := writes[2]
+	if comp := legalHold.URL.Query().Get("comp"); comp != "legalhold" {
+		t.Errorf("expected legal hold to be set, got comp=%s", comp)
+	}
+	if v := legalHold.Header.Get("X-Ms-Legal-Hold"); v != "true" {
+		t.Errorf("unexpected legal hold %q", v)
+	}
+}
+
+func TestS3ServerSideEncryptionInvalid(t *testing.T) {
+	if _, err := s3ServerSideEncryption("", "", "not base64"); err == nil {
+		t.Error("expected error for invalid customer key")
+	}
+	if _, err := s3ServerSideEncryption("aws:unknown", "", ""); err == nil {
+		t.Error("expected error for unknown algorithm")
+	}
+	if sse, err := s3ServerSideEncryption("", "", ""); err != nil || sse != nil {
+		t.Errorf("expected no encryption, got %v, %v", sse, err)
+	}
+}
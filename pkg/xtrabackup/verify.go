@@ -0,0 +1,213 @@
+package xtrabackup
+
+import (
+	"strconv"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1alpha1 "github.com/percona/percona-server-mysql-operator/api/v1alpha1"
+	"github.com/percona/percona-server-mysql-operator/pkg/util"
+)
+
+// verifyBackoffLimit is the number of retries of the verification pod.
+const verifyBackoffLimit = 3
+
+func VerifyName(cr *apiv1alpha1.PerconaServerMySQLBackup) string {
+	return componentShortName + "-verify-" + cr.Name
+}
+
+func VerifyJobName(cr *apiv1alpha1.PerconaServerMySQLBackup) string {
+	return trimJobName(VerifyName(cr))
+}
+
+// VerifyJob restores the backup into an emptyDir volume with xtrabackup running in an init container,
+// then starts mysqld on the restored data and runs the verification query.
+func VerifyJob(
+	cluster *apiv1alpha1.PerconaServerMySQL,
+	cr *apiv1alpha1.PerconaServerMySQLBackup,
+	storage *apiv1alpha1.BackupStorageSpec,
+	initImage string,
+) *batchv1.Job {
+	query := apiv1alpha1.DefaultVerifyQuery
+	var resources corev1.ResourceRequirements
+	if verify := cr.Spec.Verify; verify != nil {
+		if verify.Query != "" {
+			query = verify.Query
+		}
+		resources = verify.Resources
+	}
+
+	mysqlContainer := corev1.Container{
+		Name: "mysql",
+		Env: []corev1.EnvVar{
+			{
+				Name:  "BACKUP_DEST",
+				Value: cr.Status.Destination.PathWithoutBucket(),
+			},
+			{
+				Name:  "VERIFY_QUERY",
+				Value: query,
+			},
+		},
+		Command:   []string{"/opt/percona/verify-backup.sh"},
+		Resources: resources,
+	}
+
+	job := scratchRestoreJob(cluster, storage, VerifyJobName(cr), VerifyName(cr), cr.Status.Destination, initImage, mysqlContainer)
+
+	// Pods are retried if the restore fails, e.g. since the storage is unreachable, and evicted or preempted pods
+	// don't count against the limit. The job fails at once if the verification query fails on the restored data.
+	job.Spec.BackoffLimit = func(i int32) *int32 { return &i }(verifyBackoffLimit)
+	job.Spec.PodFailurePolicy = &batchv1.PodFailurePolicy{
+		Rules: []batchv1.PodFailurePolicyRule{
+			{
+				Action: batchv1.PodFailurePolicyActionIgnore,
+				OnPodConditions: []batchv1.PodFailurePolicyOnPodConditionsPattern{
+					{
+						Type:   corev1.DisruptionTarget,
+						Status: corev1.ConditionTrue,
+					},
+				},
+			},
+			{
+				Action: batchv1.PodFailurePolicyActionFailJob,
+				OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+					ContainerName: &mysqlContainer.Name,
+					Operator:      batchv1.PodFailurePolicyOnExitCodesOpNotIn,
+					Values:        []int32{0},
+				},
+			},
+		},
+	}
+
+	return job
+}
+
+// scratchRestoreJob restores the backup into an emptyDir volume with xtrabackup running in an init container
+// and runs mysqlContainer with the MySQL image on the restored data. The bin and data volumes are mounted
+// into mysqlContainer before its own mounts, volumes are added to the pod.
+func scratchRestoreJob(
+	cluster *apiv1alpha1.PerconaServerMySQL,
+	storage *apiv1alpha1.BackupStorageSpec,
+	jobName, restoreName string,
+	destination apiv1alpha1.BackupDestination,
+	initImage string,
+	mysqlContainer corev1.Container,
+	volumes ...corev1.Volume,
+) *batchv1.Job {
+	one := int32(1)
+
+	labels := util.SSMapMerge(storage.Labels, MatchLabels(cluster))
+
+	verifyTLS := true
+	if storage.VerifyTLS != nil {
+		verifyTLS = *storage.VerifyTLS
+	}
+
+	binMount := corev1.VolumeMount{
+		Name:      apiv1alpha1.BinVolumeName,
+		MountPath: apiv1alpha1.BinVolumePath,
+	}
+	dataMount := corev1.VolumeMount{
+		Name:      dataVolumeName,
+		MountPath: dataMountPath,
+	}
+
+	mysqlContainer.Name = "mysql"
+	mysqlContainer.Image = cluster.Spec.MySQL.Image
+	mysqlContainer.ImagePullPolicy = cluster.Spec.MySQL.ImagePullPolicy
+	mysqlContainer.VolumeMounts = append([]corev1.VolumeMount{binMount, dataMount}, mysqlContainer.VolumeMounts...)
+	mysqlContainer.TerminationMessagePath = "/dev/termination-log"
+	mysqlContainer.TerminationMessagePolicy = corev1.TerminationMessageReadFile
+	mysqlContainer.SecurityContext = cluster.Spec.MySQL.ContainerSecurityContext
+
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        jobName,
+			Namespace:   cluster.Namespace,
+			Labels:      labels,
+			Annotations: storage.Annotations,
+		},
+		Spec: batchv1.JobSpec{
+			Parallelism: &one,
+			Completions: &one,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					InitContainers: []corev1.Container{
+						{
+							Name:                     componentName + "-init",
+							Image:                    initImage,
+							ImagePullPolicy:          cluster.Spec.Backup.ImagePullPolicy,
+							VolumeMounts:             []corev1.VolumeMount{binMount},
+							Command:                  []string{"/opt/percona-server-mysql-operator/ps-init-entrypoint.sh"},
+							TerminationMessagePath:   "/dev/termination-log",
+							TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+							SecurityContext:          storage.ContainerSecurityContext,
+						},
+						{
+							Name:            componentName,
+							Image:           cluster.Spec.Backup.Image,
+							ImagePullPolicy: cluster.Spec.Backup.ImagePullPolicy,
+							Env: []corev1.EnvVar{
+								{
+									Name:  "RESTORE_NAME",
+									Value: restoreName,
+								},
+								{
+									Name:  "BACKUP_DEST",
+									Value: destination.PathWithoutBucket(),
+								},
+								{
+									Name:  "VERIFY_TLS",
+									Value: strconv.FormatBool(verifyTLS),
+								},
+							},
+							VolumeMounts:             []corev1.VolumeMount{binMount, dataMount},
+							Command:                  []string{"/opt/percona/run-restore.sh"},
+							TerminationMessagePath:   "/dev/termination-log",
+							TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+							SecurityContext:          storage.ContainerSecurityContext,
+							Resources:                storage.Resources,
+						},
+					},
+					Containers:                []corev1.Container{mysqlContainer},
+					Affinity:                  storage.Affinity,
+					TopologySpreadConstraints: storage.TopologySpreadConstraints,
+					Tolerations:               storage.Tolerations,
+					NodeSelector:              storage.NodeSelector,
+					SchedulerName:             storage.SchedulerName,
+					PriorityClassName:         storage.PriorityClassName,
+					RuntimeClassName:          storage.RuntimeClassName,
+					DNSPolicy:                 corev1.DNSClusterFirst,
+					SecurityContext:           storage.PodSecurityContext,
+					ImagePullSecrets:          cluster.Spec.MySQL.ImagePullSecrets,
+					Volumes: append([]corev1.Volume{
+						{
+							Name: apiv1alpha1.BinVolumeName,
+							VolumeSource: corev1.VolumeSource{
+								EmptyDir: &corev1.EmptyDirVolumeSource{},
+							},
+						},
+						{
+							Name: dataVolumeName,
+							VolumeSource: corev1.VolumeSource{
+								EmptyDir: &corev1.EmptyDirVolumeSource{},
+							},
+						},
+					}, volumes...),
+				},
+			},
+			BackoffLimit: func(i int32) *int32 { return &i }(1),
+		},
+	}
+}
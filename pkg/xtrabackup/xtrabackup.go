@@ -1,8 +1,13 @@
 package xtrabackup
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	batchv1 "k8s.io/api/batch/v1"
@@ -59,6 +64,19 @@ func DeleteJobName(cr *apiv1alpha1.PerconaServerMySQLBackup) string {
 	return trimJobName(DeleteName(cr))
 }
 
+// hashedJobName returns trimmed name with a short hash of the whole name at the end.
+// Names that differ only past the length limit of the job name still get different jobs.
+func hashedJobName(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	suffix := hex.EncodeToString(sum[:])[:8]
+
+	name = trimJobName(name)
+	if maxLen := validation.DNS1035LabelMaxLength - len(suffix) - 1; len(name) > maxLen {
+		name = trimJobName(name[:maxLen])
+	}
+	return name + "-" + suffix
+}
+
 // trimJobName trims the provided string to ensure it stays within the 63-character limit.
 // The job name will be included in the "batch.kubernetes.io/job-name" label in the ".spec.template" section of the job.
 // Labels have a maximum length of 63 characters, so this function ensures the job name fits within that limit.
@@ -246,6 +264,8 @@ const (
 	XBCloudActionDelete XBCloudAction = "delete"
 )
 
+// XBCloudArgs returns the arguments of xbcloud.
+// Credentials are not passed on the command line, xbcloud reads them from the environment set by storageEnv.
 func XBCloudArgs(action XBCloudAction, conf *BackupConfig) []string {
 	args := []string{string(action), "--parallel=10", "--curl-retriable-errors=7"}
 
@@ -261,8 +281,6 @@ func XBCloudArgs(action XBCloudAction, conf *BackupConfig) []string {
 				"--md5",
 				"--storage=google",
 				fmt.Sprintf("--google-bucket=%s", conf.GCS.Bucket),
-				fmt.Sprintf("--google-access-key=%s", conf.GCS.AccessKey),
-				fmt.Sprintf("--google-secret-key=%s", conf.GCS.SecretKey),
 			}...,
 		)
 		if len(conf.GCS.EndpointURL) > 0 {
@@ -276,8 +294,6 @@ func XBCloudArgs(action XBCloudAction, conf *BackupConfig) []string {
 				"--storage=s3",
 				fmt.Sprintf("--s3-bucket=%s", conf.S3.Bucket),
 				fmt.Sprintf("--s3-region=%s", conf.S3.Region),
-				fmt.Sprintf("--s3-access-key=%s", conf.S3.AccessKey),
-				fmt.Sprintf("--s3-secret-key=%s", conf.S3.SecretKey),
 			}...,
 		)
 		if len(conf.S3.EndpointURL) > 0 {
@@ -288,9 +304,7 @@ func XBCloudArgs(action XBCloudAction, conf *BackupConfig) []string {
 			args,
 			[]string{
 				"--storage=azure",
-				fmt.Sprintf("--azure-storage-account=%s", conf.Azure.StorageAccount),
 				fmt.Sprintf("--azure-container-name=%s", conf.Azure.ContainerName),
-				fmt.Sprintf("--azure-access-key=%s", conf.Azure.AccessKey),
 			}...,
 		)
 		if len(conf.Azure.EndpointURL) > 0 {
@@ -314,6 +328,7 @@ func deleteContainer(image string, conf *BackupConfig, storage *apiv1alpha1.Back
 				MountPath: apiv1alpha1.BinVolumePath,
 			},
 		},
+		Env:                      storageEnv(storage),
 		Command:                  append([]string{"xbcloud"}, XBCloudArgs(XBCloudActionDelete, conf)...),
 		TerminationMessagePath:   "/dev/termination-log",
 		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
@@ -537,51 +552,60 @@ func restoreContainer(cluster *apiv1alpha1.PerconaServerMySQL, restore *apiv1alp
 	}
 }
 
+func PVCName(cr *apiv1alpha1.PerconaServerMySQLBackup) string {
+	return JobName(cr)
+}
+
 func PVC(cluster *apiv1alpha1.PerconaServerMySQL, cr *apiv1alpha1.PerconaServerMySQLBackup, storage *apiv1alpha1.BackupStorageSpec) *corev1.PersistentVolumeClaim {
-	if len(storage.Volume.PersistentVolumeClaim.AccessModes) == 0 {
-		storage.Volume.PersistentVolumeClaim.AccessModes = []corev1.PersistentVolumeAccessMode{
+	spec := storage.Volume.PersistentVolumeClaim.DeepCopy()
+	if len(spec.AccessModes) == 0 {
+		spec.AccessModes = []corev1.PersistentVolumeAccessMode{
 			corev1.ReadWriteOnce,
 		}
 	}
 
 	return &corev1.PersistentVolumeClaim{
 		TypeMeta: metav1.TypeMeta{
-			APIVersion: "batch/v1",
-			Kind:       "Job",
+			APIVersion: "v1",
+			Kind:       "PersistentVolumeClaim",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      JobName(cr),
+			Name:      PVCName(cr),
 			Namespace: cluster.Namespace,
+			Labels:    util.SSMapMerge(storage.Labels, MatchLabels(cluster)),
 		},
-		Spec: *storage.Volume.PersistentVolumeClaim,
+		Spec: *spec,
 	}
 }
 
-func SetStoragePVC(job *batchv1.Job, pvc *corev1.PersistentVolumeClaim) error {
+func SetStoragePVC(job *batchv1.Job, pvcName string) error {
 	spec := &job.Spec.Template.Spec
 
-	vol := corev1.Volume{Name: componentName}
-	vol.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name}
+	vol := corev1.Volume{Name: backupVolumeName}
+	vol.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvcName}
 
 	spec.Volumes = append(spec.Volumes, vol)
 
-	for i := range spec.Containers {
-		container := &spec.Containers[i]
-		if container.Name == componentName {
-			container.VolumeMounts = append(
-				container.VolumeMounts,
-				corev1.VolumeMount{Name: backupVolumeName, MountPath: backupMountPath},
-			)
-			return nil
-		}
+	container, err := xtrabackupJobContainer(job)
+	if err != nil {
+		return err
 	}
-
-	return errors.Errorf("no container named %s in Job spec", componentName)
+	container.Env = append(container.Env, corev1.EnvVar{
+		Name:  "STORAGE_TYPE",
+		Value: string(apiv1alpha1.BackupStorageFilesystem),
+	})
+	container.VolumeMounts = append(
+		container.VolumeMounts,
+		corev1.VolumeMount{Name: backupVolumeName, MountPath: backupMountPath},
+	)
+	return nil
 }
 
 func SetStorageS3(job *batchv1.Job, s3 *apiv1alpha1.BackupStorageS3Spec) error {
-	spec := &job.Spec.Template.Spec
+	return setEnv(job, s3Env(s3)...)
+}
 
+func s3Env(s3 *apiv1alpha1.BackupStorageS3Spec) []corev1.EnvVar {
 	bucket, _ := s3.BucketAndPrefix()
 
 	env := []corev1.EnvVar{
@@ -589,51 +613,68 @@ func SetStorageS3(job *batchv1.Job, s3 *apiv1alpha1.BackupStorageS3Spec) error {
 			Name:  "STORAGE_TYPE",
 			Value: string(apiv1alpha1.BackupStorageS3),
 		},
-		{
-			Name: "AWS_ACCESS_KEY_ID",
-			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: k8s.SecretKeySelector(s3.CredentialsSecret, secret.CredentialsAWSAccessKey),
+	}
+	if s3.CredentialsSecret != "" {
+		env = append(env,
+			corev1.EnvVar{
+				Name: "AWS_ACCESS_KEY_ID",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: k8s.SecretKeySelector(s3.CredentialsSecret, secret.CredentialsAWSAccessKey),
+				},
 			},
-		},
-		{
-			Name: "AWS_SECRET_ACCESS_KEY",
-			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: k8s.SecretKeySelector(s3.CredentialsSecret, secret.CredentialsAWSSecretKey),
+			corev1.EnvVar{
+				Name: "AWS_SECRET_ACCESS_KEY",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: k8s.SecretKeySelector(s3.CredentialsSecret, secret.CredentialsAWSSecretKey),
+				},
 			},
-		},
-		{
+		)
+	} else {
+		// AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE are not used to keep
+		// the variables injected into the pod by EKS from being overwritten.
+		env = append(env,
+			corev1.EnvVar{
+				Name:  "S3_ROLE_ARN",
+				Value: s3.RoleARN,
+			},
+			corev1.EnvVar{
+				Name:  "S3_WEB_IDENTITY_TOKEN_FILE",
+				Value: s3.WebIdentityTokenFile,
+			},
+			corev1.EnvVar{
+				Name:  "S3_STS_ENDPOINT",
+				Value: s3.STSEndpoint,
+			},
+		)
+	}
+
+	env = append(env, s3ObjectEnv(s3)...)
+
+	return append(env,
+		corev1.EnvVar{
 			Name:  "AWS_DEFAULT_REGION",
 			Value: s3.Region,
 		},
-		{
+		corev1.EnvVar{
 			Name:  "AWS_ENDPOINT",
 			Value: s3.EndpointURL,
 		},
-		{
+		corev1.EnvVar{
 			Name:  "S3_BUCKET",
 			Value: bucket,
 		},
-		{
+		corev1.EnvVar{
 			Name:  "S3_STORAGE_CLASS",
 			Value: s3.StorageClass,
 		},
-	}
-
-	for i := range spec.Containers {
-		container := &spec.Containers[i]
-
-		if container.Name == componentName {
-			container.Env = append(container.Env, env...)
-			return nil
-		}
-	}
-
-	return errors.Errorf("no container named %s in Job spec", componentName)
+	)
 }
 
 func SetStorageGCS(job *batchv1.Job, gcs *apiv1alpha1.BackupStorageGCSSpec) error {
-	spec := &job.Spec.Template.Spec
+	return setEnv(job, gcsEnv(gcs)...)
+}
 
+func gcsEnv(gcs *apiv1alpha1.BackupStorageGCSSpec) []corev1.EnvVar {
 	bucket, _ := gcs.BucketAndPrefix()
 
 	env := []corev1.EnvVar{
@@ -641,47 +682,38 @@ func SetStorageGCS(job *batchv1.Job, gcs *apiv1alpha1.BackupStorageGCSSpec) erro
 			Name:  "STORAGE_TYPE",
 			Value: string(apiv1alpha1.BackupStorageGCS),
 		},
-		{
-			Name: "ACCESS_KEY_ID",
-			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: k8s.SecretKeySelector(gcs.CredentialsSecret, secret.CredentialsGCSAccessKey),
-			},
-		},
-		{
-			Name: "SECRET_ACCESS_KEY",
-			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: k8s.SecretKeySelector(gcs.CredentialsSecret, secret.CredentialsGCSSecretKey),
-			},
-		},
-		{
+	}
+	if gcs.CredentialsSecret != "" {
+		// The secret contains either HMAC keys or a service account key.
+		env = append(env,
+			optionalSecretEnv("ACCESS_KEY_ID", gcs.CredentialsSecret, secret.CredentialsGCSAccessKey),
+			optionalSecretEnv("SECRET_ACCESS_KEY", gcs.CredentialsSecret, secret.CredentialsGCSSecretKey),
+			optionalSecretEnv("GCS_SERVICE_ACCOUNT_KEY", gcs.CredentialsSecret, secret.CredentialsGCSServiceAccount),
+		)
+	}
+	env = append(env, gcsObjectEnv(gcs)...)
+
+	return append(env,
+		corev1.EnvVar{
 			Name:  "GCS_ENDPOINT",
 			Value: gcs.EndpointURL,
 		},
-		{
+		corev1.EnvVar{
 			Name:  "GCS_BUCKET",
 			Value: bucket,
 		},
-		{
+		corev1.EnvVar{
 			Name:  "GCS_STORAGE_CLASS",
 			Value: gcs.StorageClass,
 		},
-	}
-
-	for i := range spec.Containers {
-		container := &spec.Containers[i]
-
-		if container.Name == componentName {
-			container.Env = append(container.Env, env...)
-			return nil
-		}
-	}
-
-	return errors.Errorf("no container named %s in Job spec", componentName)
+	)
 }
 
 func SetStorageAzure(job *batchv1.Job, azure *apiv1alpha1.BackupStorageAzureSpec) error {
-	spec := &job.Spec.Template.Spec
+	return setEnv(job, azureEnv(azure)...)
+}
 
+func azureEnv(azure *apiv1alpha1.BackupStorageAzureSpec) []corev1.EnvVar {
 	container, _ := azure.ContainerAndPrefix()
 
 	env := []corev1.EnvVar{
@@ -693,53 +725,311 @@ func SetStorageAzure(job *batchv1.Job, azure *apiv1alpha1.BackupStorageAzureSpec
 			Name:  "AZURE_CONTAINER_NAME",
 			Value: container,
 		},
-		{
+	}
+	switch {
+	case azure.StorageAccount != "":
+		env = append(env, corev1.EnvVar{
+			Name:  "AZURE_STORAGE_ACCOUNT",
+			Value: azure.StorageAccount,
+		})
+	case azure.CredentialsSecret != "":
+		env = append(env, corev1.EnvVar{
 			Name: "AZURE_STORAGE_ACCOUNT",
 			ValueFrom: &corev1.EnvVarSource{
 				SecretKeyRef: k8s.SecretKeySelector(azure.CredentialsSecret, secret.CredentialsAzureStorageAccount),
 			},
-		},
-		{
-			Name: "AZURE_ACCESS_KEY",
-			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: k8s.SecretKeySelector(azure.CredentialsSecret, secret.CredentialsAzureAccessKey),
-			},
-		},
-		{
+		})
+	}
+	if azure.CredentialsSecret != "" {
+		// The secret contains either the account key or a SAS token.
+		env = append(env,
+			optionalSecretEnv("AZURE_ACCESS_KEY", azure.CredentialsSecret, secret.CredentialsAzureAccessKey),
+			optionalSecretEnv("AZURE_SAS_TOKEN", azure.CredentialsSecret, secret.CredentialsAzureSASToken),
+		)
+	}
+
+	env = append(env, azureObjectEnv(azure)...)
+
+	return append(env,
+		corev1.EnvVar{
 			Name:  "AZURE_ENDPOINT",
 			Value: azure.EndpointURL,
 		},
-		{
+		corev1.EnvVar{
 			Name:  "AZURE_STORAGE_CLASS",
 			Value: azure.StorageClass,
 		},
+	)
+}
+
+func optionalSecretEnv(name, secretName, key string) corev1.EnvVar {
+	optional := true
+	selector := k8s.SecretKeySelector(secretName, key)
+	selector.Optional = &optional
+	return corev1.EnvVar{
+		Name:      name,
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: selector},
+	}
+}
+
+// storageEnv returns the environment xbcloud reads the storage credentials from.
+func storageEnv(storage *apiv1alpha1.BackupStorageSpec) []corev1.EnvVar {
+	switch {
+	case storage.S3 != nil:
+		return s3Env(storage.S3)
+	case storage.GCS != nil:
+		return gcsEnv(storage.GCS)
+	case storage.Azure != nil:
+		return azureEnv(storage.Azure)
 	}
+	return nil
+}
 
-	for i := range spec.Containers {
-		container := &spec.Containers[i]
+// ObjectOptionsEnv returns the encryption and retention options of the storage
+// that are applied to every object written to it.
+func ObjectOptionsEnv(storage *apiv1alpha1.BackupStorageSpec) []corev1.EnvVar {
+	switch {
+	case storage.S3 != nil:
+		return s3ObjectEnv(storage.S3)
+	case storage.GCS != nil:
+		return gcsObjectEnv(storage.GCS)
+	case storage.Azure != nil:
+		return azureObjectEnv(storage.Azure)
+	}
+	return nil
+}
 
-		if container.Name == componentName {
-			container.Env = append(container.Env, env...)
-			return nil
+func s3ObjectEnv(s3 *apiv1alpha1.BackupStorageS3Spec) []corev1.EnvVar {
+	var env []corev1.EnvVar
+	if sse := s3.ServerSideEncryption; sse != nil {
+		env = append(env,
+			corev1.EnvVar{
+				Name:  "S3_SSE_ALGORITHM",
+				Value: sse.SSEAlgorithm,
+			},
+			corev1.EnvVar{
+				Name:  "S3_SSE_KMS_KEY_ID",
+				Value: sse.KMSKeyID,
+			},
+		)
+		if sse.SSECustomerKeySecret != nil {
+			env = append(env, corev1.EnvVar{
+				Name: "S3_SSE_CUSTOMER_KEY",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: sse.SSECustomerKeySecret,
+				},
+			})
 		}
 	}
+	if lock := s3.ObjectLock; lock != nil {
+		env = append(env,
+			corev1.EnvVar{
+				Name:  "S3_OBJECT_LOCK_MODE",
+				Value: lock.Mode,
+			},
+			corev1.EnvVar{
+				Name:  "S3_OBJECT_LOCK_RETAIN_FOR",
+				Value: lock.RetainFor.Duration.String(),
+			},
+			corev1.EnvVar{
+				Name:  "S3_OBJECT_LOCK_LEGAL_HOLD",
+				Value: strconv.FormatBool(lock.LegalHold),
+			},
+		)
+	}
+	return env
+}
 
-	return errors.Errorf("no container named %s in Job spec", componentName)
+func gcsObjectEnv(gcs *apiv1alpha1.BackupStorageGCSSpec) []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{
+			Name:  "GCS_KMS_KEY_NAME",
+			Value: gcs.KMSKeyName,
+		},
+	}
+}
+
+func azureObjectEnv(azure *apiv1alpha1.BackupStorageAzureSpec) []corev1.EnvVar {
+	env := []corev1.EnvVar{
+		{
+			Name:  "AZURE_ENCRYPTION_SCOPE",
+			Value: azure.EncryptionScope,
+		},
+	}
+	if azure.CustomerKeySecret != nil {
+		env = append(env, corev1.EnvVar{
+			Name: "AZURE_CUSTOMER_KEY",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: azure.CustomerKeySecret,
+			},
+		})
+	}
+	if policy := azure.ImmutabilityPolicy; policy != nil {
+		env = append(env,
+			corev1.EnvVar{
+				Name:  "AZURE_IMMUTABILITY_MODE",
+				Value: policy.Mode,
+			},
+			corev1.EnvVar{
+				Name:  "AZURE_IMMUTABILITY_RETAIN_FOR",
+				Value: policy.RetainFor.Duration.String(),
+			},
+			corev1.EnvVar{
+				Name:  "AZURE_LEGAL_HOLD",
+				Value: strconv.FormatBool(policy.LegalHold),
+			},
+		)
+	}
+	return env
+}
+
+// ObjectTags returns the tags of the storage along with the cluster, namespace and type of the backup.
+// Tags of the storage can't override them.
+func ObjectTags(cluster *apiv1alpha1.PerconaServerMySQL, storage *apiv1alpha1.BackupStorageSpec, backupType string) map[string]string {
+	tags := make(map[string]string)
+	var storageTags map[string]string
+	switch {
+	case storage.S3 != nil:
+		storageTags = storage.S3.Tags
+	case storage.GCS != nil:
+		storageTags = storage.GCS.Metadata
+	case storage.Azure != nil:
+		storageTags = storage.Azure.Tags
+	}
+	for k, v := range storageTags {
+		tags[k] = v
+	}
+	tags["cluster"] = cluster.Name
+	tags["namespace"] = cluster.Namespace
+	tags["backup-type"] = backupType
+	return tags
+}
+
+// EncodeObjectTags returns the tags in the format of the x-amz-tagging header.
+func EncodeObjectTags(tags map[string]string) string {
+	v := url.Values{}
+	for k, val := range tags {
+		v.Set(k, val)
+	}
+	return v.Encode()
+}
+
+// SetObjectTags makes the job set the tags to every object it writes to the storage.
+func SetObjectTags(job *batchv1.Job, tags map[string]string) error {
+	return setEnv(job, corev1.EnvVar{Name: "OBJECT_TAGS", Value: EncodeObjectTags(tags)})
 }
 
 func SetSourceNode(job *batchv1.Job, src string) error {
-	spec := &job.Spec.Template.Spec
+	return setEnv(job, corev1.EnvVar{Name: "SRC_NODE", Value: src})
+}
+
+// SetIncrementalBase makes the backup job take an incremental backup on top of the base backup.
+func SetIncrementalBase(job *batchv1.Job, base apiv1alpha1.BackupDestination) error {
+	return setEnv(job, corev1.EnvVar{Name: "INCREMENTAL_BASE_DEST", Value: base.PathWithoutBucket()})
+}
+
+// SetBackupChain makes the restore job apply the backups in the chain before the restored backup.
+func SetBackupChain(job *batchv1.Job, chain []apiv1alpha1.BackupDestination) error {
+	dests := make([]string, 0, len(chain))
+	for _, d := range chain {
+		dests = append(dests, d.PathWithoutBucket())
+	}
+	return setEnv(job, corev1.EnvVar{Name: "BACKUP_CHAIN", Value: strings.Join(dests, " ")})
+}
 
-	for i := range spec.Containers {
-		container := &spec.Containers[i]
+// SetEncryption makes the job encrypt or decrypt the backup with the key from the secret.
+func SetEncryption(job *batchv1.Job, cipher apiv1alpha1.BackupEncryptionCipher, keySecret *corev1.SecretKeySelector) error {
+	return setEnv(job,
+		corev1.EnvVar{
+			Name:  "XB_ENCRYPT_CIPHER",
+			Value: string(cipher),
+		},
+		corev1.EnvVar{
+			Name: "XB_ENCRYPT_KEY",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: keySecret,
+			},
+		},
+	)
+}
 
-		if container.Name == componentName {
-			container.Env = append(container.Env, corev1.EnvVar{Name: "SRC_NODE", Value: src})
-			return nil
+// SetThrottle makes the job limit the load the backup puts on the source node.
+func SetThrottle(job *batchv1.Job, throttle *apiv1alpha1.BackupThrottleSpec) error {
+	var bandwidth int64
+	if throttle.UploadBandwidth != nil {
+		bandwidth = throttle.UploadBandwidth.Value()
+	}
+	return setEnv(job,
+		corev1.EnvVar{Name: "XB_THROTTLE", Value: strconv.Itoa(int(throttle.Throttle))},
+		corev1.EnvVar{Name: "XB_PARALLEL", Value: strconv.Itoa(int(throttle.Parallel))},
+		corev1.EnvVar{Name: "UPLOAD_BANDWIDTH", Value: strconv.FormatInt(bandwidth, 10)},
+	)
+}
+
+// SetTimeouts limits the time the job can run for.
+func SetTimeouts(job *batchv1.Job, timeouts *apiv1alpha1.BackupTimeouts) {
+	job.Spec.ActiveDeadlineSeconds = timeouts.ActiveDeadlineSeconds
+}
+
+// SetRetryPolicy configures how the job requests the backup again while another one is running on the source node.
+func SetRetryPolicy(job *batchv1.Job, policy *apiv1alpha1.BackupRetryPolicy) error {
+	env := []corev1.EnvVar{
+		{Name: "BACKUP_RETRY_MAX_ATTEMPTS", Value: strconv.Itoa(int(policy.MaxAttempts))},
+	}
+	if policy.InitialInterval != nil {
+		env = append(env, corev1.EnvVar{Name: "BACKUP_RETRY_INITIAL_INTERVAL", Value: durationSeconds(policy.InitialInterval.Duration)})
+	}
+	if policy.MaxInterval != nil {
+		env = append(env, corev1.EnvVar{Name: "BACKUP_RETRY_MAX_INTERVAL", Value: durationSeconds(policy.MaxInterval.Duration)})
+	}
+	return setEnv(job, env...)
+}
+
+// durationSeconds formats d as a whole number of seconds, at least 1.
+func durationSeconds(d time.Duration) string {
+	return strconv.FormatInt(max(int64(d/time.Second), 1), 10)
+}
+
+// EncryptionKeyFingerprint returns the fingerprint of the encryption key stored in the backup status.
+func EncryptionKeyFingerprint(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// MetadataObjectName returns the name of the object BackupMetadata is stored in next to the backup.
+func MetadataObjectName(destination string) string {
+	return destination + "/metadata.json"
+}
+
+// CheckpointsObjectName returns the name of the object xtrabackup_checkpoints of the backup is stored in.
+// Incremental backups read to_lsn of the base backup from it.
+func CheckpointsObjectName(destination string) string {
+	return destination + ".checkpoints"
+}
+
+func setEnv(job *batchv1.Job, env ...corev1.EnvVar) error {
+	container, err := xtrabackupJobContainer(job)
+	if err != nil {
+		return err
+	}
+	container.Env = append(container.Env, env...)
+	return nil
+}
+
+// xtrabackupJobContainer returns the container of the job that runs xtrabackup.
+// It's an init container in the verification job, since mysqld runs after it.
+func xtrabackupJobContainer(job *batchv1.Job) (*corev1.Container, error) {
+	spec := &job.Spec.Template.Spec
+
+	for _, containers := range [][]corev1.Container{spec.Containers, spec.InitContainers} {
+		for i := range containers {
+			if containers[i].Name == componentName {
+				return &containers[i], nil
+			}
 		}
 	}
 
-	return errors.Errorf("no container named %s in Job spec", componentName)
+	return nil, errors.Errorf("no container named %s in Job spec", componentName)
 }
 
 type BackupConfig struct {
@@ -753,6 +1043,19 @@ type BackupConfig struct {
 		StorageClass string `json:"storageClass,omitempty"`
 		AccessKey    string `json:"accessKey,omitempty"`
 		SecretKey    string `json:"secretKey,omitempty"`
+
+		// The fields below are used to get credentials from the web identity token if there are no keys.
+		RoleARN              string `json:"roleArn,omitempty"`
+		WebIdentityTokenFile string `json:"webIdentityTokenFile,omitempty"`
+		STSEndpoint          string `json:"stsEndpoint,omitempty"`
+
+		SSEAlgorithm   string `json:"sseAlgorithm,omitempty"`
+		KMSKeyID       string `json:"kmsKeyID,omitempty"`
+		SSECustomerKey string `json:"sseCustomerKey,omitempty"`
+		// ObjectLockRetainFor is a duration string, e.g. 720h.
+		ObjectLockMode      string `json:"objectLockMode,omitempty"`
+		ObjectLockRetainFor string `json:"objectLockRetainFor,omitempty"`
+		ObjectLockLegalHold bool   `json:"objectLockLegalHold,omitempty"`
 	} `json:"s3,omitempty"`
 	GCS struct {
 		Bucket       string `json:"bucket"`
@@ -760,6 +1063,9 @@ type BackupConfig struct {
 		StorageClass string `json:"storageClass,omitempty"`
 		AccessKey    string `json:"accessKey,omitempty"`
 		SecretKey    string `json:"secretKey,omitempty"`
+		// ServiceAccountKey is a service account JSON key used instead of HMAC keys.
+		ServiceAccountKey string `json:"serviceAccountKey,omitempty"`
+		KMSKeyName        string `json:"kmsKeyName,omitempty"`
 	} `json:"gcs,omitempty"`
 	Azure struct {
 		ContainerName  string `json:"containerName"`
@@ -767,5 +1073,32 @@ type BackupConfig struct {
 		StorageClass   string `json:"storageClass,omitempty"`
 		StorageAccount string `json:"storageAccount,omitempty"`
 		AccessKey      string `json:"accessKey,omitempty"`
+		SASToken       string `json:"sasToken,omitempty"`
+
+		EncryptionScope string `json:"encryptionScope,omitempty"`
+		CustomerKey     string `json:"customerKey,omitempty"`
+		// ImmutabilityRetainFor is a duration string, e.g. 720h.
+		ImmutabilityMode      string `json:"immutabilityMode,omitempty"`
+		ImmutabilityRetainFor string `json:"immutabilityRetainFor,omitempty"`
+		LegalHold             bool   `json:"legalHold,omitempty"`
 	} `json:"azure,omitempty"`
+
+	// Tags are set to every object of the backup. They are URL-encoded, e.g. cluster=cluster1&namespace=db.
+	// GCS sets them as object metadata.
+	Tags string `json:"tags,omitempty"`
+
+	// IncrementalBaseDestination is the destination of the backup an incremental backup is taken on top of.
+	IncrementalBaseDestination string `json:"incrementalBaseDestination,omitempty"`
+
+	Encryption struct {
+		Cipher string `json:"cipher,omitempty"`
+		Key    string `json:"key,omitempty"`
+	} `json:"encryption,omitempty"`
+
+	Throttle struct {
+		Throttle int32 `json:"throttle,omitempty"`
+		Parallel int32 `json:"parallel,omitempty"`
+		// UploadBandwidth is in bytes per second.
+		UploadBandwidth int64 `json:"uploadBandwidth,omitempty"`
+	} `json:"throttle,omitempty"`
 }